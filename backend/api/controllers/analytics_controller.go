@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// AnalyticsController handles class analytics requests
+type AnalyticsController struct {
+	analyticsService services.AnalyticsService
+	classService     services.ClassService
+}
+
+// NewAnalyticsController creates a new AnalyticsController
+func NewAnalyticsController(analyticsService services.AnalyticsService, classService services.ClassService) *AnalyticsController {
+	return &AnalyticsController{
+		analyticsService: analyticsService,
+		classService:     classService,
+	}
+}
+
+// GetAssignmentAnalytics handles GET /api/classes/:id/analytics/assignments
+func (c *AnalyticsController) GetAssignmentAnalytics(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	analytics, err := c.analyticsService.GetAssignmentAnalytics(classID)
+	if err != nil {
+		respondAnalyticsError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+// GetGradeDistribution handles GET /api/classes/:id/analytics/assignments/:assignmentId/grades
+func (c *AnalyticsController) GetGradeDistribution(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	assignmentID, err := strconv.Atoi(ctx.Param("assignmentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return
+	}
+
+	distribution, err := c.analyticsService.GetGradeDistribution(classID, assignmentID)
+	if err != nil {
+		respondAnalyticsError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, distribution)
+}
+
+// GetStudentAnalytics handles GET /api/classes/:id/analytics/students
+func (c *AnalyticsController) GetStudentAnalytics(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	students, err := c.analyticsService.GetStudentAnalytics(classID)
+	if err != nil {
+		respondAnalyticsError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, students)
+}
+
+// GetTrends handles GET /api/classes/:id/analytics/trends?interval=&from=&to=
+func (c *AnalyticsController) GetTrends(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := models.ParseTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		from = &parsed
+	}
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := models.ParseTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		to = &parsed
+	}
+
+	trends, err := c.analyticsService.GetTrends(classID, ctx.Query("interval"), from, to)
+	if err != nil {
+		respondAnalyticsError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trends)
+}
+
+// parseClass reads the class ID and checks that the user teaches the class
+func (c *AnalyticsController) parseClass(ctx *gin.Context) (int, bool) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return 0, false
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return 0, false
+	}
+
+	return classID, true
+}
+
+func respondAnalyticsError(ctx *gin.Context, err error) {
+	log.Printf("Analytics error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -1,22 +1,27 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
 	"github.com/yongdilun/classconnect-backend/api/services"
 )
 
 // AnnouncementController handles announcement-related requests
 type AnnouncementController struct {
 	announcementService services.AnnouncementService
+	classService        services.ClassService
 }
 
 // NewAnnouncementController creates a new AnnouncementController
-func NewAnnouncementController(announcementService services.AnnouncementService) *AnnouncementController {
+func NewAnnouncementController(announcementService services.AnnouncementService, classService services.ClassService) *AnnouncementController {
 	return &AnnouncementController{
 		announcementService: announcementService,
+		classService:        classService,
 	}
 }
 
@@ -30,8 +35,14 @@ func (c *AnnouncementController) GetAnnouncements(ctx *gin.Context) {
 		return
 	}
 
-	// Get announcements for the class
-	announcements, err := c.announcementService.GetAnnouncements(classID)
+	// Students only see announcements for the whole class or their own section
+	var announcements []models.AnnouncementResponse
+	userID, _ := ctx.Get("userId")
+	if userRole, _ := ctx.Get("userRole"); userRole == "student" {
+		announcements, err = c.announcementService.GetStudentAnnouncements(classID, userID.(int))
+	} else {
+		announcements, err = c.announcementService.GetAnnouncements(classID)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -50,9 +61,24 @@ func (c *AnnouncementController) GetAnnouncement(ctx *gin.Context) {
 		return
 	}
 
-	// Get the announcement
-	announcement, err := c.announcementService.GetAnnouncement(announcementID)
+	// Students only see announcements for the whole class or their own section
+	var announcement models.AnnouncementResponse
+	userID, _ := ctx.Get("userId")
+	if userRole, _ := ctx.Get("userRole"); userRole == "student" {
+		classID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+			return
+		}
+		announcement, err = c.announcementService.GetStudentAnnouncement(classID, announcementID, userID.(int))
+	} else {
+		announcement, err = c.announcementService.GetAnnouncement(announcementID)
+	}
 	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -79,8 +105,9 @@ func (c *AnnouncementController) CreateAnnouncement(ctx *gin.Context) {
 
 	// Parse request body
 	var request struct {
-		Content string `json:"content" binding:"required"`
-		Title   string `json:"title"`
+		Content   string `json:"content" binding:"required"`
+		Title     string `json:"title"`
+		SectionID *int   `json:"sectionId"`
 	}
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
@@ -88,8 +115,12 @@ func (c *AnnouncementController) CreateAnnouncement(ctx *gin.Context) {
 	}
 
 	// Create announcement
-	announcement, err := c.announcementService.CreateAnnouncement(classID, userID.(int), request.Content, request.Title)
+	announcement, err := c.announcementService.CreateAnnouncement(classID, userID.(int), request.Content, request.Title, request.SectionID)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -127,6 +158,10 @@ func (c *AnnouncementController) UpdateAnnouncement(ctx *gin.Context) {
 	// Update announcement
 	announcement, err := c.announcementService.UpdateAnnouncement(announcementID, userID.(int), request.Content, request.Title)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -154,9 +189,59 @@ func (c *AnnouncementController) DeleteAnnouncement(ctx *gin.Context) {
 	// Delete announcement
 	err = c.announcementService.DeleteAnnouncement(announcementID, userID.(int))
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Announcement deleted successfully"})
 }
+
+// PinAnnouncement handles PUT /api/classes/:id/announcements/:announcementId/pin
+func (c *AnnouncementController) PinAnnouncement(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	// Parse announcement ID from URL
+	announcementID, err := strconv.Atoi(ctx.Param("announcementId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	// Parse request body
+	var request struct {
+		Pinned bool `json:"pinned"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	announcement, err := c.announcementService.SetAnnouncementPinned(classID, announcementID, request.Pinned)
+	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, announcement)
+}
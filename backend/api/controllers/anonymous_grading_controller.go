@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// AnonymousGradingController handles anonymous grading requests
+type AnonymousGradingController struct {
+	anonymousGradingService services.AnonymousGradingService
+	classService            services.ClassService
+}
+
+// NewAnonymousGradingController creates a new AnonymousGradingController
+func NewAnonymousGradingController(anonymousGradingService services.AnonymousGradingService, classService services.ClassService) *AnonymousGradingController {
+	return &AnonymousGradingController{
+		anonymousGradingService: anonymousGradingService,
+		classService:            classService,
+	}
+}
+
+// GetStatus handles GET /api/classes/:id/assignments/:assignmentId/anonymous-grading
+func (c *AnonymousGradingController) GetStatus(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	status, err := c.anonymousGradingService.GetStatus(classID, assignmentID)
+	if err != nil {
+		respondAnonymousGradingError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// GetAuditLog handles GET /api/classes/:id/assignments/:assignmentId/anonymous-grading/audit
+func (c *AnonymousGradingController) GetAuditLog(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	entries, err := c.anonymousGradingService.GetAuditLog(classID, assignmentID)
+	if err != nil {
+		respondAnonymousGradingError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func respondAnonymousGradingError(ctx *gin.Context, err error) {
+	log.Printf("Anonymous grading error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
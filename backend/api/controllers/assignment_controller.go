@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -22,13 +23,15 @@ func min(a, b int) int {
 
 // AssignmentController handles assignment-related requests
 type AssignmentController struct {
-	assignmentService services.AssignmentService
+	assignmentService       services.AssignmentService
+	anonymousGradingService services.AnonymousGradingService
 }
 
 // NewAssignmentController creates a new AssignmentController
-func NewAssignmentController(assignmentService services.AssignmentService) *AssignmentController {
+func NewAssignmentController(assignmentService services.AssignmentService, anonymousGradingService services.AnonymousGradingService) *AssignmentController {
 	return &AssignmentController{
-		assignmentService: assignmentService,
+		assignmentService:       assignmentService,
+		anonymousGradingService: anonymousGradingService,
 	}
 }
 
@@ -42,8 +45,14 @@ func (c *AssignmentController) GetAssignments(ctx *gin.Context) {
 		return
 	}
 
-	// Get assignments for the class
-	assignments, err := c.assignmentService.GetAssignments(classID)
+	// Students only see assignments for the whole class or their own section
+	var assignments []models.AssignmentResponse
+	userID, _ := ctx.Get("userId")
+	if userRole, _ := ctx.Get("userRole"); userRole == "student" {
+		assignments, err = c.assignmentService.GetStudentAssignments(classID, userID.(int))
+	} else {
+		assignments, err = c.assignmentService.GetAssignments(classID)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -75,8 +84,13 @@ func (c *AssignmentController) GetAssignment(ctx *gin.Context) {
 	log.Printf("GetAssignment request: classID=%d, assignmentID=%d, userID=%v, userRole=%v",
 		classID, assignmentID, userID, userRole)
 
-	// Get the assignment
-	assignment, err := c.assignmentService.GetAssignment(classID, assignmentID)
+	// Students only see assignments for the whole class or their own section
+	var assignment models.AssignmentResponse
+	if userRole == "student" {
+		assignment, err = c.assignmentService.GetStudentAssignment(classID, assignmentID, userID.(int))
+	} else {
+		assignment, err = c.assignmentService.GetAssignment(classID, assignmentID)
+	}
 	if err != nil {
 		log.Printf("Error getting assignment: %v", err)
 
@@ -122,12 +136,34 @@ func (c *AssignmentController) CreateAssignment(ctx *gin.Context) {
 		DueDate        string `json:"dueDate"`
 		PointsPossible int    `json:"pointsPossible"`
 		IsPublished    bool   `json:"isPublished"`
+		SectionID      *int   `json:"sectionId"`
+
+		MaxResubmissions          *int `json:"maxResubmissions"` // Omit for unlimited resubmissions
+		AllowResubmitAfterGrading bool `json:"allowResubmitAfterGrading"`
+
+		GroupMode    string `json:"groupMode"`    // none (default), teacher or self
+		MaxGroupSize *int   `json:"maxGroupSize"` // Omit for no limit
+
+		AnonymousGrading bool `json:"anonymousGrading"`
 	}
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
+	if request.MaxResubmissions != nil && *request.MaxResubmissions < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxResubmissions cannot be negative"})
+		return
+	}
+	if request.GroupMode != "" && !models.IsValidGroupMode(request.GroupMode) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "groupMode must be none, teacher or self"})
+		return
+	}
+	if request.MaxGroupSize != nil && *request.MaxGroupSize < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxGroupSize must be at least 1"})
+		return
+	}
+
 	// Create assignment
 	assignment := models.Assignment{
 		ClassID:        classID,
@@ -136,6 +172,13 @@ func (c *AssignmentController) CreateAssignment(ctx *gin.Context) {
 		PointsPossible: request.PointsPossible,
 		IsPublished:    true,         // Always publish assignments
 		CreatedBy:      userID.(int), // Set the creator ID
+		SectionID:      request.SectionID,
+
+		MaxResubmissions:          request.MaxResubmissions,
+		AllowResubmitAfterGrading: request.AllowResubmitAfterGrading,
+		GroupMode:                 request.GroupMode,
+		MaxGroupSize:              request.MaxGroupSize,
+		AnonymousGrading:          request.AnonymousGrading,
 	}
 
 	// Parse due date if provided
@@ -150,6 +193,10 @@ func (c *AssignmentController) CreateAssignment(ctx *gin.Context) {
 	// Create the assignment
 	createdAssignment, err := c.assignmentService.CreateAssignment(classID, userID.(int), assignment)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -187,12 +234,34 @@ func (c *AssignmentController) UpdateAssignment(ctx *gin.Context) {
 		Description    string `json:"description"`
 		DueDate        string `json:"dueDate"`
 		PointsPossible int    `json:"pointsPossible"`
+		SectionID      *int   `json:"sectionId"` // Omit to keep the current section, 0 for the whole class
+
+		MaxResubmissions          *int  `json:"maxResubmissions"` // Omit to keep the current limit, -1 for unlimited
+		AllowResubmitAfterGrading *bool `json:"allowResubmitAfterGrading"`
+
+		GroupMode    string `json:"groupMode"`    // Omit to keep the current mode
+		MaxGroupSize *int   `json:"maxGroupSize"` // Omit to keep the current limit, 0 for no limit
+
+		AnonymousGrading *bool `json:"anonymousGrading"` // Cannot be turned off before all grades are released
 	}
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
+	if request.MaxResubmissions != nil && *request.MaxResubmissions < -1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxResubmissions must be -1 (unlimited) or more"})
+		return
+	}
+	if request.GroupMode != "" && !models.IsValidGroupMode(request.GroupMode) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "groupMode must be none, teacher or self"})
+		return
+	}
+	if request.MaxGroupSize != nil && *request.MaxGroupSize < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxGroupSize cannot be negative"})
+		return
+	}
+
 	// Get the existing assignment to preserve fields like created_by
 	existingAssignment, err := c.assignmentService.GetAssignment(classID, assignmentID)
 	if err != nil {
@@ -207,6 +276,42 @@ func (c *AssignmentController) UpdateAssignment(ctx *gin.Context) {
 		PointsPossible: request.PointsPossible,
 		IsPublished:    true,                         // Always publish assignments
 		CreatedBy:      existingAssignment.CreatedBy, // Preserve the creator ID
+		SectionID:      existingAssignment.SectionID,
+
+		MaxResubmissions:          existingAssignment.MaxResubmissions,
+		AllowResubmitAfterGrading: existingAssignment.AllowResubmitAfterGrading,
+		GroupMode:                 existingAssignment.GroupMode,
+		MaxGroupSize:              existingAssignment.MaxGroupSize,
+		AnonymousGrading:          existingAssignment.AnonymousGrading,
+	}
+
+	if request.SectionID != nil {
+		assignment.SectionID = request.SectionID
+		if *request.SectionID == 0 {
+			assignment.SectionID = nil
+		}
+	}
+
+	if request.MaxResubmissions != nil {
+		assignment.MaxResubmissions = request.MaxResubmissions
+		if *request.MaxResubmissions == -1 {
+			assignment.MaxResubmissions = nil
+		}
+	}
+	if request.AllowResubmitAfterGrading != nil {
+		assignment.AllowResubmitAfterGrading = *request.AllowResubmitAfterGrading
+	}
+	if request.GroupMode != "" {
+		assignment.GroupMode = request.GroupMode
+	}
+	if request.MaxGroupSize != nil {
+		assignment.MaxGroupSize = request.MaxGroupSize
+		if *request.MaxGroupSize == 0 {
+			assignment.MaxGroupSize = nil
+		}
+	}
+	if request.AnonymousGrading != nil {
+		assignment.AnonymousGrading = *request.AnonymousGrading
 	}
 
 	// Parse due date if provided
@@ -221,6 +326,14 @@ func (c *AssignmentController) UpdateAssignment(ctx *gin.Context) {
 	// Update the assignment
 	updatedAssignment, err := c.assignmentService.UpdateAssignment(classID, assignmentID, assignment)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "anonymous grading") {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -272,6 +385,14 @@ func (c *AssignmentController) SubmitAssignment(ctx *gin.Context) {
 	submission, err := c.assignmentService.SubmitAssignment(classID, assignmentID, studentID.(int), request.Content, request.FileURL)
 	if err != nil {
 		log.Printf("Error submitting assignment: %v", err)
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "resubmission") || strings.Contains(err.Error(), "not in a group") {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -288,6 +409,9 @@ func (c *AssignmentController) SubmitAssignment(ctx *gin.Context) {
 		log.Printf("Submission does NOT include assignment data")
 	}
 
+	// Students do not see draft grades
+	submission.HideUnreleasedGrade()
+
 	ctx.JSON(http.StatusCreated, submission)
 }
 
@@ -308,10 +432,9 @@ func (c *AssignmentController) GetSubmission(ctx *gin.Context) {
 		return
 	}
 
-	studentIDStr := ctx.Param("studentId")
-	studentID, err := strconv.Atoi(studentIDStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+	// Graders of anonymously graded assignments use the anonymous ID
+	studentID, ok := c.resolveSubmissionStudent(ctx, classID, assignmentID)
+	if !ok {
 		return
 	}
 
@@ -349,6 +472,14 @@ func (c *AssignmentController) GetSubmission(ctx *gin.Context) {
 		log.Printf("Submission does NOT include assignment data")
 	}
 
+	// Students see "graded_pending_release" until the grade is released,
+	// graders see a pseudonym while grading is anonymous
+	if userRole == "student" {
+		submission.HideUnreleasedGrade()
+	} else if !c.anonymize(ctx, classID, assignmentID, &submission) {
+		return
+	}
+
 	ctx.JSON(http.StatusOK, submission)
 }
 
@@ -388,6 +519,13 @@ func (c *AssignmentController) GetAssignmentSubmissions(ctx *gin.Context) {
 		return
 	}
 
+	// Hide student identities while grading is anonymous
+	if err := c.anonymousGradingService.AnonymizeSubmissions(classID, assignmentID, submissions); err != nil {
+		log.Printf("Error anonymizing submissions: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	log.Printf("Found %d submissions for assignment %d", len(submissions), assignmentID)
 	ctx.JSON(http.StatusOK, submissions)
 }
@@ -409,10 +547,9 @@ func (c *AssignmentController) GradeSubmission(ctx *gin.Context) {
 		return
 	}
 
-	studentIDStr := ctx.Param("studentId")
-	studentID, err := strconv.Atoi(studentIDStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+	// Graders of anonymously graded assignments use the anonymous ID
+	studentID, ok := c.resolveSubmissionStudent(ctx, classID, assignmentID)
+	if !ok {
 		return
 	}
 
@@ -423,19 +560,61 @@ func (c *AssignmentController) GradeSubmission(ctx *gin.Context) {
 		return
 	}
 
-	// Parse request body
+	// Parse request body. A submission is graded either with a single grade
+	// or with a score for every criterion of the assignment's rubric.
 	var request struct {
-		Grade    int    `json:"grade" binding:"required"`
-		Feedback string `json:"feedback"`
+		Grade        *int                 `json:"grade"`
+		Feedback     string               `json:"feedback"`
+		RubricScores []models.RubricScore `json:"rubricScores"`
 	}
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
+	if len(request.RubricScores) > 0 {
+		log.Printf("GradeSubmission request: classID=%d, assignmentID=%d, studentID=%d, teacherID=%v, rubric scores=%d",
+			classID, assignmentID, studentID, teacherID, len(request.RubricScores))
+
+		graded, err := c.assignmentService.GradeSubmissionWithRubric(
+			classID,
+			assignmentID,
+			studentID,
+			teacherID.(int),
+			request.RubricScores,
+			request.Feedback,
+		)
+		if err != nil {
+			log.Printf("Error grading submission with rubric: %v", err)
+			switch {
+			case errors.Is(err, services.ErrClassArchived), strings.Contains(err.Error(), "not a teacher"):
+				ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case strings.HasPrefix(err.Error(), "failed to"):
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			default:
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		if !c.anonymize(ctx, classID, assignmentID, &graded) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, graded)
+		return
+	}
+
+	if request.Grade == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Either grade or rubricScores is required"})
+		return
+	}
+
 	// Log the request
 	log.Printf("GradeSubmission request: classID=%d, assignmentID=%d, studentID=%d, teacherID=%v, grade=%d",
-		classID, assignmentID, studentID, teacherID, request.Grade)
+		classID, assignmentID, studentID, teacherID, *request.Grade)
 
 	// Get the assignment to check the maximum points
 	assignment, err := c.assignmentService.GetAssignment(classID, assignmentID)
@@ -445,7 +624,7 @@ func (c *AssignmentController) GradeSubmission(ctx *gin.Context) {
 	}
 
 	// Check if the grade is valid
-	if request.Grade < 0 || request.Grade > assignment.PointsPossible {
+	if *request.Grade < 0 || *request.Grade > assignment.PointsPossible {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": fmt.Sprintf("Grade must be between 0 and %d", assignment.PointsPossible),
 		})
@@ -458,15 +637,68 @@ func (c *AssignmentController) GradeSubmission(ctx *gin.Context) {
 		assignmentID, 
 		studentID, 
 		teacherID.(int), 
-		request.Grade, 
+		*request.Grade, 
 		request.Feedback,
 	)
 	if err != nil {
 		log.Printf("Error grading submission: %v", err)
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	log.Printf("Successfully graded submission for student %d, assignment %d", studentID, assignmentID)
+
+	if !c.anonymize(ctx, classID, assignmentID, &graded) {
+		return
+	}
+
 	ctx.JSON(http.StatusOK, graded)
 }
+
+// resolveSubmissionStudent returns the student of a submission URL. Students
+// use their own ID; graders of anonymously graded assignments must use the
+// student's anonymous ID until identities are revealed.
+func (c *AssignmentController) resolveSubmissionStudent(ctx *gin.Context, classID, assignmentID int) (int, bool) {
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		studentID, err := strconv.Atoi(ctx.Param("studentId"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+			return 0, false
+		}
+		return studentID, true
+	}
+
+	studentID, err := c.anonymousGradingService.ResolveStudentID(classID, assignmentID, ctx.Param("studentId"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIdentitiesHidden):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "not found"):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "failed to"):
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return 0, false
+	}
+
+	return studentID, true
+}
+
+// anonymize replaces the student of a submission response with a pseudonym
+// while the assignment is graded anonymously
+func (c *AssignmentController) anonymize(ctx *gin.Context, classID, assignmentID int, submission *models.SubmissionResponse) bool {
+	responses := []models.SubmissionResponse{*submission}
+	if err := c.anonymousGradingService.AnonymizeSubmissions(classID, assignmentID, responses); err != nil {
+		log.Printf("Error anonymizing submission: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	*submission = responses[0]
+	return true
+}
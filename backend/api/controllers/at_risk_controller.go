@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// AtRiskController handles at-risk rules, the at-risk dashboard and guardians
+type AtRiskController struct {
+	atRiskService   services.AtRiskService
+	guardianService services.GuardianService
+	classService    services.ClassService
+}
+
+// NewAtRiskController creates a new AtRiskController
+func NewAtRiskController(atRiskService services.AtRiskService, guardianService services.GuardianService, classService services.ClassService) *AtRiskController {
+	return &AtRiskController{
+		atRiskService:   atRiskService,
+		guardianService: guardianService,
+		classService:    classService,
+	}
+}
+
+// atRiskRuleRequest is the body of rule requests. Alerts to teachers and the
+// rule itself are on unless turned off; alerts to guardians are opt-in.
+type atRiskRuleRequest struct {
+	Name            string  `json:"name"`
+	RuleType        string  `json:"ruleType" binding:"required"`
+	Threshold       float64 `json:"threshold"`
+	IsEnabled       *bool   `json:"isEnabled"`
+	NotifyTeachers  *bool   `json:"notifyTeachers"`
+	NotifyGuardians bool    `json:"notifyGuardians"`
+}
+
+func (r atRiskRuleRequest) toRule() models.AtRiskRule {
+	return models.AtRiskRule{
+		Name:            r.Name,
+		RuleType:        r.RuleType,
+		Threshold:       r.Threshold,
+		IsEnabled:       r.IsEnabled == nil || *r.IsEnabled,
+		NotifyTeachers:  r.NotifyTeachers == nil || *r.NotifyTeachers,
+		NotifyGuardians: r.NotifyGuardians,
+	}
+}
+
+// GetRules handles GET /api/classes/:id/at-risk/rules
+func (c *AtRiskController) GetRules(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	rules, err := c.atRiskService.GetRules(classID)
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+// CreateRule handles POST /api/classes/:id/at-risk/rules
+func (c *AtRiskController) CreateRule(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	var request atRiskRuleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userID, _ := ctx.Get("userId")
+	rule, err := c.atRiskService.CreateRule(classID, userID.(int), request.toRule())
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule handles PUT /api/classes/:id/at-risk/rules/:ruleId
+func (c *AtRiskController) UpdateRule(ctx *gin.Context) {
+	classID, ruleID, ok := c.parseRule(ctx)
+	if !ok {
+		return
+	}
+
+	var request atRiskRuleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	rule, err := c.atRiskService.UpdateRule(classID, ruleID, request.toRule())
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule handles DELETE /api/classes/:id/at-risk/rules/:ruleId
+func (c *AtRiskController) DeleteRule(ctx *gin.Context) {
+	classID, ruleID, ok := c.parseRule(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.atRiskService.DeleteRule(classID, ruleID); err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "At-risk rule deleted successfully"})
+}
+
+// GetAtRiskStudents handles GET /api/classes/:id/at-risk
+func (c *AtRiskController) GetAtRiskStudents(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	students, err := c.atRiskService.GetAtRiskStudents(classID)
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, students)
+}
+
+// EvaluateClass handles POST /api/classes/:id/at-risk/evaluate
+func (c *AtRiskController) EvaluateClass(ctx *gin.Context) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return
+	}
+
+	evaluation, err := c.atRiskService.EvaluateClass(classID)
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, evaluation)
+}
+
+// GetGuardians handles GET /api/students/:studentId/guardians
+func (c *AtRiskController) GetGuardians(ctx *gin.Context) {
+	studentID, ok := c.parseStudent(ctx)
+	if !ok {
+		return
+	}
+
+	guardians, err := c.guardianService.GetGuardians(studentID)
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, guardians)
+}
+
+// guardianRequest is the body of guardian requests. Guardians receive
+// alerts unless turned off.
+type guardianRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Email          string `json:"email" binding:"required"`
+	Relationship   string `json:"relationship"`
+	ReceivesAlerts *bool  `json:"receivesAlerts"`
+}
+
+func (r guardianRequest) toGuardian() models.Guardian {
+	return models.Guardian{
+		Name:           r.Name,
+		Email:          r.Email,
+		Relationship:   r.Relationship,
+		ReceivesAlerts: r.ReceivesAlerts == nil || *r.ReceivesAlerts,
+	}
+}
+
+// AddGuardian handles POST /api/students/:studentId/guardians
+func (c *AtRiskController) AddGuardian(ctx *gin.Context) {
+	studentID, ok := c.parseStudent(ctx)
+	if !ok {
+		return
+	}
+
+	var request guardianRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	guardian, err := c.guardianService.AddGuardian(studentID, request.toGuardian())
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, guardian)
+}
+
+// UpdateGuardian handles PUT /api/students/:studentId/guardians/:guardianId
+func (c *AtRiskController) UpdateGuardian(ctx *gin.Context) {
+	studentID, guardianID, ok := c.parseGuardian(ctx)
+	if !ok {
+		return
+	}
+
+	var request guardianRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	guardian, err := c.guardianService.UpdateGuardian(studentID, guardianID, request.toGuardian())
+	if err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, guardian)
+}
+
+// DeleteGuardian handles DELETE /api/students/:studentId/guardians/:guardianId
+func (c *AtRiskController) DeleteGuardian(ctx *gin.Context) {
+	studentID, guardianID, ok := c.parseGuardian(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.guardianService.DeleteGuardian(studentID, guardianID); err != nil {
+		respondAtRiskError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Guardian deleted successfully"})
+}
+
+// parseClass reads the class ID and checks that the user teaches the class
+func (c *AtRiskController) parseClass(ctx *gin.Context) (int, bool) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return 0, false
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return 0, false
+	}
+
+	return classID, true
+}
+
+func (c *AtRiskController) parseRule(ctx *gin.Context) (int, int, bool) {
+	classID, ok := c.parseClass(ctx)
+	if !ok {
+		return 0, 0, false
+	}
+
+	ruleID, err := strconv.Atoi(ctx.Param("ruleId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return 0, 0, false
+	}
+
+	return classID, ruleID, true
+}
+
+// parseStudent reads the student ID and checks that the user is an admin or
+// teaches the student
+func (c *AtRiskController) parseStudent(ctx *gin.Context) (int, bool) {
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return 0, false
+	}
+
+	if role, _ := ctx.Get("userRole"); role == "admin" {
+		return studentID, true
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, false
+	}
+
+	teaches, err := c.guardianService.TeachesStudent(userID.(int), studentID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify class membership"})
+		return 0, false
+	}
+	if !teaches {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "User does not teach this student"})
+		return 0, false
+	}
+
+	return studentID, true
+}
+
+func (c *AtRiskController) parseGuardian(ctx *gin.Context) (int, int, bool) {
+	studentID, ok := c.parseStudent(ctx)
+	if !ok {
+		return 0, 0, false
+	}
+
+	guardianID, err := strconv.Atoi(ctx.Param("guardianId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid guardian ID"})
+		return 0, 0, false
+	}
+
+	return studentID, guardianID, true
+}
+
+func respondAtRiskError(ctx *gin.Context, err error) {
+	log.Printf("At-risk error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "already exists"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
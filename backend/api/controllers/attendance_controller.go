@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// AttendanceController handles schedule, session and attendance requests
+type AttendanceController struct {
+	attendanceService services.AttendanceService
+	classService      services.ClassService
+}
+
+// NewAttendanceController creates a new AttendanceController
+func NewAttendanceController(attendanceService services.AttendanceService, classService services.ClassService) *AttendanceController {
+	return &AttendanceController{
+		attendanceService: attendanceService,
+		classService:      classService,
+	}
+}
+
+// CreateSchedule handles POST /api/classes/:id/schedules
+func (c *AttendanceController) CreateSchedule(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		DayOfWeek *int   `json:"dayOfWeek" binding:"required"`
+		StartTime string `json:"startTime" binding:"required"`
+		EndTime   string `json:"endTime" binding:"required"`
+		StartDate string `json:"startDate" binding:"required"`
+		EndDate   string `json:"endDate" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	startDate, err := models.ParseTime(request.StartDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		return
+	}
+	endDate, err := models.ParseTime(request.EndDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+		return
+	}
+
+	schedule, sessionsCreated, err := c.attendanceService.CreateSchedule(classID, *request.DayOfWeek,
+		request.StartTime, request.EndTime, startDate, endDate)
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"schedule":        schedule,
+		"sessionsCreated": sessionsCreated,
+	})
+}
+
+// GetSchedules handles GET /api/classes/:id/schedules
+func (c *AttendanceController) GetSchedules(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	schedules, err := c.attendanceService.GetSchedules(classID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+// DeleteSchedule handles DELETE /api/classes/:id/schedules/:scheduleId
+func (c *AttendanceController) DeleteSchedule(ctx *gin.Context) {
+	// Parse class ID and schedule ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	scheduleID, err := strconv.Atoi(ctx.Param("scheduleId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.attendanceService.DeleteSchedule(classID, scheduleID); err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+// CreateSession handles POST /api/classes/:id/sessions
+func (c *AttendanceController) CreateSession(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		StartTime string `json:"startTime" binding:"required"`
+		EndTime   string `json:"endTime" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	startTime, err := models.ParseTime(request.StartTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start time format"})
+		return
+	}
+	endTime, err := models.ParseTime(request.EndTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time format"})
+		return
+	}
+
+	session, err := c.attendanceService.CreateSession(classID, startTime, endTime)
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// GetSessions handles GET /api/classes/:id/sessions?from=&to=
+func (c *AttendanceController) GetSessions(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	var from, to *time.Time
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := models.ParseTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		from = &parsed
+	}
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := models.ParseTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		to = &parsed
+	}
+
+	sessions, err := c.attendanceService.GetSessions(classID, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// GetSessionAttendance handles GET /api/classes/:id/sessions/:sessionId/attendance
+func (c *AttendanceController) GetSessionAttendance(ctx *gin.Context) {
+	classID, sessionID, ok := parseSessionParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	sheet, err := c.attendanceService.GetSessionAttendance(classID, sessionID)
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sheet)
+}
+
+// MarkAttendance handles PUT /api/classes/:id/sessions/:sessionId/attendance
+func (c *AttendanceController) MarkAttendance(ctx *gin.Context) {
+	classID, sessionID, ok := parseSessionParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		Records []services.AttendanceMark `json:"records" binding:"required,dive"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	teacherID, _ := ctx.Get("userId")
+
+	if err := c.attendanceService.MarkAttendance(classID, sessionID, teacherID.(int), request.Records); err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Attendance saved successfully"})
+}
+
+// GetCheckInCode handles GET /api/classes/:id/sessions/:sessionId/check-in-code
+// The code rotates every 30 seconds and is meant to be shown on the classroom screen.
+func (c *AttendanceController) GetCheckInCode(ctx *gin.Context) {
+	classID, sessionID, ok := parseSessionParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	code, expiresAt, err := c.attendanceService.GetCheckInCode(classID, sessionID)
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"code":      code,
+		"expiresAt": expiresAt,
+	})
+}
+
+// CheckIn handles POST /api/classes/:id/sessions/:sessionId/check-in
+func (c *AttendanceController) CheckIn(ctx *gin.Context) {
+	classID, sessionID, ok := parseSessionParams(ctx)
+	if !ok {
+		return
+	}
+
+	// Get student ID from context
+	studentID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	record, err := c.attendanceService.CheckIn(classID, sessionID, studentID.(int), strings.TrimSpace(request.Code))
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, record)
+}
+
+// GetClassReport handles GET /api/classes/:id/attendance/report
+func (c *AttendanceController) GetClassReport(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	report, err := c.attendanceService.GetClassReport(classID)
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetStudentReport handles GET /api/classes/:id/attendance/students/:studentId
+// Students can only view their own report.
+func (c *AttendanceController) GetStudentReport(ctx *gin.Context) {
+	// Parse class ID and student ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	userID, _ := ctx.Get("userId")
+	if userRole, _ := ctx.Get("userRole"); userRole == "student" {
+		if userID.(int) != studentID {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Students can only view their own attendance"})
+			return
+		}
+	} else if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	report, err := c.attendanceService.GetStudentReport(classID, studentID)
+	if err != nil {
+		respondAttendanceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// parseSessionParams parses the class and session IDs from the URL.
+// It writes an error response and returns false if either is invalid.
+func parseSessionParams(ctx *gin.Context) (int, int, bool) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return 0, 0, false
+	}
+
+	sessionID, err := strconv.Atoi(ctx.Param("sessionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return 0, 0, false
+	}
+
+	return classID, sessionID, true
+}
+
+// respondAttendanceError maps an attendance service error to an HTTP response
+func respondAttendanceError(ctx *gin.Context, err error) {
+	log.Printf("Attendance error: %v", err)
+	if errors.Is(err, services.ErrClassArchived) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.Contains(err.Error(), "not found") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.HasPrefix(err.Error(), "failed to") {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
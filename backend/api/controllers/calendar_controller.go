@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// CalendarController handles calendar requests
+type CalendarController struct {
+	calendarService services.CalendarService
+}
+
+// NewCalendarController creates a new CalendarController
+func NewCalendarController(calendarService services.CalendarService) *CalendarController {
+	return &CalendarController{
+		calendarService: calendarService,
+	}
+}
+
+// GetEvents handles GET /api/calendar?from=&to=
+// Without a range it returns the last 30 days and the next 90 days.
+func (c *CalendarController) GetEvents(ctx *gin.Context) {
+	// Get user ID from context
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -30)
+	to := now.AddDate(0, 0, 90)
+
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := models.ParseTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		from = parsed
+	}
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := models.ParseTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		to = parsed
+	}
+
+	events, err := c.calendarService.GetEvents(userID.(int), from, to)
+	if err != nil {
+		log.Printf("Error getting calendar for user %v: %v", userID, err)
+		if strings.HasPrefix(err.Error(), "failed to") {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// GetFeedURL handles GET /api/calendar/feed
+// It returns the secret URL calendar apps can subscribe to.
+func (c *CalendarController) GetFeedURL(ctx *gin.Context) {
+	// Get user ID from context
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	feedToken, err := c.calendarService.GetFeedToken(userID.(int))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar feed"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"url": feedURL(ctx, feedToken.Token)})
+}
+
+// ResetFeedURL handles POST /api/calendar/feed/reset
+// The previous feed URL stops working.
+func (c *CalendarController) ResetFeedURL(ctx *gin.Context) {
+	// Get user ID from context
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	feedToken, err := c.calendarService.ResetFeedToken(userID.(int))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset calendar feed"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"url": feedURL(ctx, feedToken.Token)})
+}
+
+// GetFeed handles GET /api/calendar/feed/:token.ics
+// This route is public: the token in the URL is the only credential.
+func (c *CalendarController) GetFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	feed, err := c.calendarService.GetFeed(token)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		log.Printf("Error rendering calendar feed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render calendar feed"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="classconnect.ics"`)
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
+
+// feedURL builds the absolute URL of a calendar feed
+func feedURL(ctx *gin.Context, token string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := ctx.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s/api/calendar/feed/%s.ics", scheme, ctx.Request.Host, token)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -76,6 +77,10 @@ func (c *ChatController) SendChatMessage(ctx *gin.Context) {
 	// Send chat message
 	message, err := c.chatService.SendChatMessage(classID, userID, request.Content)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -1,11 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
 	"github.com/yongdilun/classconnect-backend/api/services"
 )
 
@@ -30,6 +33,9 @@ func (c *ClassController) RegisterRoutes(router *gin.RouterGroup) {
 		classRoutes.GET("/:id", c.GetClass)
 		classRoutes.PUT("/:id", c.UpdateClass)
 		classRoutes.DELETE("/:id", c.DeleteClass)
+		classRoutes.POST("/:id/archive", c.ArchiveClass)
+		classRoutes.POST("/:id/unarchive", c.UnarchiveClass)
+		classRoutes.POST("/:id/clone", c.CloneClass)
 
 		// Teacher-specific routes
 		classRoutes.GET("/teacher/:teacherId", c.GetTeacherClasses)
@@ -41,6 +47,19 @@ func (c *ClassController) RegisterRoutes(router *gin.RouterGroup) {
 		classRoutes.POST("/join", c.JoinClass)
 		classRoutes.DELETE("/:id/students/:studentId", c.RemoveStudentFromClass)
 		classRoutes.GET("/:id/students", c.GetClassStudents)
+
+		// Enrollment approval routes
+		classRoutes.PUT("/:id/enrollment-settings", c.UpdateEnrollmentSettings)
+		classRoutes.GET("/:id/enrollments", c.GetEnrollments)
+		classRoutes.POST("/:id/enrollments/:studentId/approve", c.ApproveEnrollment)
+		classRoutes.POST("/:id/enrollments/:studentId/reject", c.RejectEnrollment)
+
+		// Term and section routes
+		classRoutes.PUT("/:id/term", c.SetClassTerm)
+		classRoutes.GET("/:id/sections", c.GetSections)
+		classRoutes.POST("/:id/sections", c.CreateSection)
+		classRoutes.DELETE("/:id/sections/:sectionId", c.DeleteSection)
+		classRoutes.PUT("/:id/students/:studentId/section", c.SetStudentSection)
 	}
 }
 
@@ -174,6 +193,99 @@ func (c *ClassController) DeleteClass(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Class deleted successfully"})
 }
 
+// ArchiveClass handles the request to archive a class.
+// Archived classes are read-only and hidden from class lists by default.
+func (c *ClassController) ArchiveClass(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.classService.ArchiveClass(classID); err != nil {
+		log.Printf("Error archiving class %d: %v", classID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive class"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Class archived successfully"})
+}
+
+// UnarchiveClass handles the request to restore an archived class
+func (c *ClassController) UnarchiveClass(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.classService.UnarchiveClass(classID); err != nil {
+		log.Printf("Error restoring class %d: %v", classID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore class"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Class restored successfully"})
+}
+
+// CloneClass handles the request to copy a class as a template for a new term
+func (c *ClassController) CloneClass(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var req struct {
+		ClassName                  string `json:"className"`
+		DueDateOffsetDays          int    `json:"dueDateOffsetDays"`
+		IncludePinnedAnnouncements bool   `json:"includePinnedAnnouncements"`
+		IncludeCoTeachers          bool   `json:"includeCoTeachers"`
+		TermID                     *int   `json:"termId"`
+	}
+
+	// The request body is optional
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	userID, _ := ctx.Get("userId")
+
+	class, err := c.classService.CloneClass(classID, userID.(int), services.CloneClassOptions{
+		ClassName:                  req.ClassName,
+		DueDateOffsetDays:          req.DueDateOffsetDays,
+		IncludePinnedAnnouncements: req.IncludePinnedAnnouncements,
+		IncludeCoTeachers:          req.IncludeCoTeachers,
+		TermID:                     req.TermID,
+	})
+	if err != nil {
+		log.Printf("Error cloning class %d: %v", classID, err)
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone class"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, class)
+}
+
 // GetTeacherClasses handles the request to get all classes for a teacher
 func (c *ClassController) GetTeacherClasses(ctx *gin.Context) {
 	teacherID, err := strconv.Atoi(ctx.Param("teacherId"))
@@ -182,7 +294,12 @@ func (c *ClassController) GetTeacherClasses(ctx *gin.Context) {
 		return
 	}
 
-	classes, err := c.classService.GetTeacherClasses(teacherID)
+	options, ok := classListOptions(ctx)
+	if !ok {
+		return
+	}
+
+	classes, err := c.classService.GetTeacherClasses(teacherID, options)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teacher classes"})
 		return
@@ -249,7 +366,12 @@ func (c *ClassController) GetStudentClasses(ctx *gin.Context) {
 		return
 	}
 
-	classes, err := c.classService.GetStudentClasses(studentID)
+	options, ok := classListOptions(ctx)
+	if !ok {
+		return
+	}
+
+	classes, err := c.classService.GetStudentClasses(studentID, options)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get student classes"})
 		return
@@ -300,14 +422,27 @@ func (c *ClassController) JoinClass(ctx *gin.Context) {
 	}
 
 	// Enroll student in class
-	class, err := c.classService.EnrollStudentInClass(studentID, req.ClassCode)
+	class, enrollment, err := c.classService.EnrollStudentInClass(studentID, req.ClassCode)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	message := "Successfully joined class"
+	switch enrollment.Status {
+	case models.EnrollmentStatusPending:
+		message = "Enrollment request sent. A teacher must approve it before you can access the class"
+	case models.EnrollmentStatusWaitlisted:
+		message = "The class is full. You have been added to the waitlist"
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Successfully joined class",
+		"message": message,
+		"status":  enrollment.Status,
 		"class":   class,
 	})
 }
@@ -328,6 +463,10 @@ func (c *ClassController) RemoveStudentFromClass(ctx *gin.Context) {
 
 	err = c.classService.RemoveStudentFromClass(studentID, classID)
 	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove student from class"})
 		return
 	}
@@ -351,3 +490,342 @@ func (c *ClassController) GetClassStudents(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, students)
 }
+
+// UpdateEnrollmentSettings handles the request to change approval and capacity settings
+func (c *ClassController) UpdateEnrollmentSettings(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var req struct {
+		RequiresApproval bool `json:"requiresApproval"`
+		MaxCapacity      *int `json:"maxCapacity"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := c.classService.UpdateEnrollmentSettings(classID, req.RequiresApproval, req.MaxCapacity); err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Enrollment settings updated successfully"})
+}
+
+// GetEnrollments handles the request to list pending or waitlisted enrollments
+func (c *ClassController) GetEnrollments(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	status := ctx.DefaultQuery("status", models.EnrollmentStatusPending)
+	switch status {
+	case models.EnrollmentStatusActive, models.EnrollmentStatusPending, models.EnrollmentStatusWaitlisted,
+		models.EnrollmentStatusRejected, models.EnrollmentStatusRemoved:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment status"})
+		return
+	}
+
+	enrollments, err := c.classService.GetEnrollments(classID, status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get enrollments"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollments)
+}
+
+// ApproveEnrollment handles the request to approve a pending enrollment
+func (c *ClassController) ApproveEnrollment(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	enrollment, err := c.classService.ApproveEnrollment(classID, studentID)
+	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "Enrollment approved successfully"
+	if enrollment.Status == models.EnrollmentStatusWaitlisted {
+		message = "Class is full. Student has been added to the waitlist"
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  enrollment.Status,
+	})
+}
+
+// RejectEnrollment handles the request to reject a pending or waitlisted enrollment
+func (c *ClassController) RejectEnrollment(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.classService.RejectEnrollment(classID, studentID); err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Enrollment rejected successfully"})
+}
+
+// requireClassTeacher checks that the authenticated user teaches the class.
+// Admins are always allowed. It writes an error response and returns false otherwise.
+func requireClassTeacher(ctx *gin.Context, classService services.ClassService, classID int) bool {
+	if role, _ := ctx.Get("userRole"); role == "admin" {
+		return true
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return false
+	}
+
+	isTeacher, err := classService.IsTeacherInClass(userID.(int), classID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify class membership"})
+		return false
+	}
+
+	if !isTeacher {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "User is not a teacher for this class"})
+		return false
+	}
+
+	return true
+}
+
+// classListOptions parses the includeArchived and termId query parameters.
+// It writes an error response and returns false if they are invalid.
+func classListOptions(ctx *gin.Context) (services.ClassListOptions, bool) {
+	options := services.ClassListOptions{
+		IncludeArchived: queryBool(ctx, "includeArchived", false),
+	}
+
+	if termIDStr := ctx.Query("termId"); termIDStr != "" {
+		termID, err := strconv.Atoi(termIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term ID"})
+			return options, false
+		}
+		options.TermID = &termID
+	}
+
+	return options, true
+}
+
+// SetClassTerm handles the request to assign a class to a term
+func (c *ClassController) SetClassTerm(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	// A null termId removes the class from its term
+	var req struct {
+		TermID *int `json:"termId"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := c.classService.SetClassTerm(classID, req.TermID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update class term"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Class term updated successfully"})
+}
+
+// GetSections handles the request to list the sections of a class
+func (c *ClassController) GetSections(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	sections, err := c.classService.GetSections(classID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sections"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sections)
+}
+
+// CreateSection handles the request to create a section in a class
+func (c *ClassController) CreateSection(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	section, err := c.classService.CreateSection(classID, req.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create section"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, section)
+}
+
+// DeleteSection handles the request to delete a section from a class
+func (c *ClassController) DeleteSection(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	sectionID, err := strconv.Atoi(ctx.Param("sectionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid section ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.classService.DeleteSection(classID, sectionID); err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete section"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Section deleted successfully"})
+}
+
+// SetStudentSection handles the request to move a student into a section
+func (c *ClassController) SetStudentSection(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	// A null sectionId moves the student back to the whole class
+	var req struct {
+		SectionID *int `json:"sectionId"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := c.classService.SetStudentSection(classID, studentID, req.SectionID); err != nil {
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Student section updated successfully"})
+}
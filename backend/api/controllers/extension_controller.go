@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// ExtensionController handles due date extensions and accommodations
+type ExtensionController struct {
+	extensionService services.ExtensionService
+	classService     services.ClassService
+}
+
+// NewExtensionController creates a new ExtensionController
+func NewExtensionController(extensionService services.ExtensionService, classService services.ClassService) *ExtensionController {
+	return &ExtensionController{
+		extensionService: extensionService,
+		classService:     classService,
+	}
+}
+
+// GetExtensions handles GET /api/classes/:id/assignments/:assignmentId/extensions
+func (c *ExtensionController) GetExtensions(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	extensions, err := c.extensionService.GetExtensions(classID, assignmentID)
+	if err != nil {
+		respondExtensionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, extensions)
+}
+
+// GrantExtension handles PUT /api/classes/:id/assignments/:assignmentId/extensions/:studentId
+func (c *ExtensionController) GrantExtension(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		DueDate string `json:"dueDate" binding:"required"`
+		Reason  string `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	dueDate, err := models.ParseTime(request.DueDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due date format"})
+		return
+	}
+
+	userID, _ := ctx.Get("userId")
+	extension, err := c.extensionService.GrantExtension(classID, assignmentID, studentID, userID.(int), dueDate, request.Reason)
+	if err != nil {
+		respondExtensionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, extension)
+}
+
+// RevokeExtension handles DELETE /api/classes/:id/assignments/:assignmentId/extensions/:studentId
+func (c *ExtensionController) RevokeExtension(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.extensionService.RevokeExtension(classID, assignmentID, studentID); err != nil {
+		respondExtensionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Extension revoked successfully"})
+}
+
+// GetAccommodations handles GET /api/classes/:id/accommodations
+func (c *ExtensionController) GetAccommodations(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	accommodations, err := c.extensionService.GetAccommodations(classID)
+	if err != nil {
+		respondExtensionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accommodations)
+}
+
+// SetAccommodation handles PUT /api/classes/:id/accommodations/:studentId
+func (c *ExtensionController) SetAccommodation(ctx *gin.Context) {
+	classID, studentID, ok := c.parseAccommodationParams(ctx)
+	if !ok {
+		return
+	}
+
+	var accommodation models.Accommodation
+	if err := ctx.ShouldBindJSON(&accommodation); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userID, _ := ctx.Get("userId")
+	saved, err := c.extensionService.SetAccommodation(classID, studentID, userID.(int), accommodation)
+	if err != nil {
+		respondExtensionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+// DeleteAccommodation handles DELETE /api/classes/:id/accommodations/:studentId
+func (c *ExtensionController) DeleteAccommodation(ctx *gin.Context) {
+	classID, studentID, ok := c.parseAccommodationParams(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.extensionService.DeleteAccommodation(classID, studentID); err != nil {
+		respondExtensionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Accommodation deleted successfully"})
+}
+
+// parseAccommodationParams parses the class and student IDs from the URL and
+// checks that the user teaches the class. It writes an error response and
+// returns false otherwise.
+func (c *ExtensionController) parseAccommodationParams(ctx *gin.Context) (int, int, bool) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return 0, 0, false
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return 0, 0, false
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return 0, 0, false
+	}
+
+	return classID, studentID, true
+}
+
+// respondExtensionError maps an extension service error to an HTTP response
+func respondExtensionError(ctx *gin.Context, err error) {
+	log.Printf("Extension error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// GradingController handles grade release requests
+type GradingController struct {
+	gradingService services.GradingService
+	classService   services.ClassService
+}
+
+// NewGradingController creates a new GradingController
+func NewGradingController(gradingService services.GradingService, classService services.ClassService) *GradingController {
+	return &GradingController{
+		gradingService: gradingService,
+		classService:   classService,
+	}
+}
+
+// GetGradeRelease handles GET /api/classes/:id/assignments/:assignmentId/grades/release
+func (c *GradingController) GetGradeRelease(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	release, err := c.gradingService.GetGradeRelease(classID, assignmentID)
+	if err != nil {
+		respondGradingError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, release)
+}
+
+// ReleaseGrades handles POST /api/classes/:id/assignments/:assignmentId/grades/release
+func (c *GradingController) ReleaseGrades(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	userID, _ := ctx.Get("userId")
+	release, err := c.gradingService.ReleaseGrades(classID, assignmentID, userID.(int))
+	if err != nil {
+		respondGradingError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, release)
+}
+
+// maxGradeUploadSize limits the size of an uploaded grades file (5 MB)
+const maxGradeUploadSize = 5 << 20
+
+// ImportGrades handles POST /api/classes/:id/assignments/:assignmentId/grades/import
+// The CSV can be sent as a multipart "file" field or as the raw request body.
+// With dryRun the rows are only checked, to preview the import.
+func (c *GradingController) ImportGrades(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	csvData, closeFn, err := readUpload(ctx, maxGradeUploadSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeFn()
+
+	userID, _ := ctx.Get("userId")
+	report, err := c.gradingService.ImportGrades(classID, assignmentID, userID.(int), csvData, queryBool(ctx, "dryRun", false))
+	if err != nil {
+		respondGradingError(ctx, err)
+		return
+	}
+
+	// Nothing is applied when a row has an error
+	if report.Errors > 0 && !report.DryRun {
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+func respondGradingError(ctx *gin.Context, err error) {
+	log.Printf("Grading error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "no unreleased grades"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// GroupController handles the groups of group assignments
+type GroupController struct {
+	groupService services.GroupService
+	classService services.ClassService
+}
+
+// NewGroupController creates a new GroupController
+func NewGroupController(groupService services.GroupService, classService services.ClassService) *GroupController {
+	return &GroupController{
+		groupService: groupService,
+		classService: classService,
+	}
+}
+
+// GetGroups handles GET /api/classes/:id/assignments/:assignmentId/groups
+func (c *GroupController) GetGroups(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !c.requireClassMember(ctx, classID) {
+		return
+	}
+
+	groups, err := c.groupService.GetGroups(classID, assignmentID)
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, groups)
+}
+
+// CreateGroup handles POST /api/classes/:id/assignments/:assignmentId/groups
+// Teachers choose the members; a student creating a group becomes its first member.
+func (c *GroupController) CreateGroup(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Name       string `json:"name" binding:"required"`
+		StudentIDs []int  `json:"studentIds"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var group *models.AssignmentGroup
+	var err error
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		group, err = c.groupService.CreateOwnGroup(classID, assignmentID, userID.(int), request.Name)
+	} else {
+		if !requireClassTeacher(ctx, c.classService, classID) {
+			return
+		}
+		group, err = c.groupService.CreateGroup(classID, assignmentID, userID.(int), request.Name, request.StudentIDs)
+	}
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, group)
+}
+
+// SetMembers handles PUT /api/classes/:id/assignments/:assignmentId/groups/:groupId/members
+func (c *GroupController) SetMembers(ctx *gin.Context) {
+	classID, assignmentID, groupID, ok := parseGroupParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		StudentIDs []int `json:"studentIds"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	group, err := c.groupService.SetMembers(classID, assignmentID, groupID, request.StudentIDs)
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup handles DELETE /api/classes/:id/assignments/:assignmentId/groups/:groupId
+func (c *GroupController) DeleteGroup(ctx *gin.Context) {
+	classID, assignmentID, groupID, ok := parseGroupParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	if err := c.groupService.DeleteGroup(classID, assignmentID, groupID); err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// JoinGroup handles POST /api/classes/:id/assignments/:assignmentId/groups/:groupId/join
+func (c *GroupController) JoinGroup(ctx *gin.Context) {
+	classID, assignmentID, groupID, ok := parseGroupParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	group, err := c.groupService.JoinGroup(classID, assignmentID, groupID, userID.(int))
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+// LeaveGroup handles POST /api/classes/:id/assignments/:assignmentId/groups/:groupId/leave
+func (c *GroupController) LeaveGroup(ctx *gin.Context) {
+	classID, assignmentID, groupID, ok := parseGroupParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := c.groupService.LeaveGroup(classID, assignmentID, groupID, userID.(int)); err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Left group successfully"})
+}
+
+// GradeGroup handles PUT /api/classes/:id/assignments/:assignmentId/groups/:groupId/grade
+func (c *GroupController) GradeGroup(ctx *gin.Context) {
+	classID, assignmentID, groupID, ok := parseGroupParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		Grade    *int   `json:"grade" binding:"required"`
+		Feedback string `json:"feedback"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userID, _ := ctx.Get("userId")
+	submissions, err := c.groupService.GradeGroup(classID, assignmentID, groupID, userID.(int), *request.Grade, request.Feedback)
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, submissions)
+}
+
+// requireClassMember checks that the user teaches or is enrolled in the class.
+// It writes an error response and returns false otherwise.
+func (c *GroupController) requireClassMember(ctx *gin.Context, classID int) bool {
+	role, _ := ctx.Get("userRole")
+	if role != "student" {
+		return requireClassTeacher(ctx, c.classService, classID)
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return false
+	}
+
+	isStudent, err := c.classService.IsStudentInClass(userID.(int), classID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify class membership"})
+		return false
+	}
+	if !isStudent {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Student is not enrolled in this class"})
+		return false
+	}
+
+	return true
+}
+
+// parseGroupParams parses the class, assignment and group IDs from the URL.
+// It writes an error response and returns false if any of them is invalid.
+func parseGroupParams(ctx *gin.Context) (int, int, int, bool) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	groupID, err := strconv.Atoi(ctx.Param("groupId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return 0, 0, 0, false
+	}
+
+	return classID, assignmentID, groupID, true
+}
+
+// respondGroupError maps a group service error to an HTTP response
+func respondGroupError(ctx *gin.Context, err error) {
+	log.Printf("Group error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "set by the teacher"), strings.Contains(message, "read-only"):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "already"), strings.Contains(message, "is full"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
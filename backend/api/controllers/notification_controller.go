@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// NotificationController handles notification requests
+type NotificationController struct {
+	notificationService services.NotificationService
+}
+
+// NewNotificationController creates a new NotificationController
+func NewNotificationController(notificationService services.NotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications handles GET /api/notifications
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	notifications, err := c.notificationService.GetNotifications(userID.(int), queryBool(ctx, "unread", false))
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notifications)
+}
+
+// MarkRead handles PUT /api/notifications/:notificationId/read
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	notificationID, err := strconv.Atoi(ctx.Param("notificationId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	notification, err := c.notificationService.MarkRead(userID.(int), notificationID)
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead handles PUT /api/notifications/read
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	count, err := c.notificationService.MarkAllRead(userID.(int))
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"marked": count})
+}
+
+func respondNotificationError(ctx *gin.Context, err error) {
+	log.Printf("Notification error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
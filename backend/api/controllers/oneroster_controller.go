@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// maxOneRosterUploadSize limits the size of an uploaded OneRoster bundle (50 MB)
+const maxOneRosterUploadSize = 50 << 20
+
+// OneRosterController handles OneRoster import and export requests
+type OneRosterController struct {
+	oneRosterService services.OneRosterService
+}
+
+// NewOneRosterController creates a new OneRosterController
+func NewOneRosterController(oneRosterService services.OneRosterService) *OneRosterController {
+	return &OneRosterController{
+		oneRosterService: oneRosterService,
+	}
+}
+
+// ImportBundle handles POST /api/admin/oneroster/import
+// The zip bundle can be sent as a multipart "file" field or as the raw request body.
+func (c *OneRosterController) ImportBundle(ctx *gin.Context) {
+	// Get user ID from context
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	upload, closeFn, err := readUpload(ctx, maxOneRosterUploadSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeFn()
+
+	bundle, err := io.ReadAll(upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+
+	report, err := c.oneRosterService.ImportBundle(userID.(int), bundle, queryBool(ctx, "dryRun", false))
+	if err != nil {
+		log.Printf("Error importing OneRoster bundle: %v", err)
+		if strings.HasPrefix(err.Error(), "invalid OneRoster bundle") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// ExportResults handles GET /api/admin/oneroster/export?classId=1&classId=2
+// It returns a OneRoster zip bundle with line items and results.
+func (c *OneRosterController) ExportResults(ctx *gin.Context) {
+	var classIDs []int
+	for _, value := range ctx.QueryArray("classId") {
+		for _, part := range strings.Split(value, ",") {
+			classID, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+				return
+			}
+			classIDs = append(classIDs, classID)
+		}
+	}
+
+	bundle, err := c.oneRosterService.ExportResults(classIDs)
+	if err != nil {
+		log.Printf("Error exporting OneRoster results: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("oneroster-results-%s.zip", time.Now().Format("20060102-150405"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, "application/zip", bundle)
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// PeerReviewController handles peer review requests
+type PeerReviewController struct {
+	peerReviewService services.PeerReviewService
+	classService      services.ClassService
+}
+
+// NewPeerReviewController creates a new PeerReviewController
+func NewPeerReviewController(peerReviewService services.PeerReviewService, classService services.ClassService) *PeerReviewController {
+	return &PeerReviewController{
+		peerReviewService: peerReviewService,
+		classService:      classService,
+	}
+}
+
+// GetSettings handles GET /api/classes/:id/assignments/:assignmentId/peer-review
+func (c *PeerReviewController) GetSettings(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	settings, err := c.peerReviewService.GetSettings(classID, assignmentID)
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// SaveSettings handles PUT /api/classes/:id/assignments/:assignmentId/peer-review
+func (c *PeerReviewController) SaveSettings(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var settings models.PeerReviewSettings
+	if err := ctx.ShouldBindJSON(&settings); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	saved, err := c.peerReviewService.SaveSettings(classID, assignmentID, settings)
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+// AssignReviewers handles POST /api/classes/:id/assignments/:assignmentId/peer-reviews/assign
+func (c *PeerReviewController) AssignReviewers(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	assigned, err := c.peerReviewService.AssignReviewers(classID, assignmentID)
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"assigned": assigned})
+}
+
+// GetReviews handles GET /api/classes/:id/assignments/:assignmentId/peer-reviews
+// Teachers get every review; students get the reviews they have to write.
+func (c *PeerReviewController) GetReviews(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var reviews []models.PeerReview
+	var err error
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		reviews, err = c.peerReviewService.GetReviewsToWrite(classID, assignmentID, userID.(int))
+	} else {
+		if !requireClassTeacher(ctx, c.classService, classID) {
+			return
+		}
+		reviews, err = c.peerReviewService.GetReviews(classID, assignmentID)
+	}
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+// GetReceivedReviews handles GET /api/classes/:id/assignments/:assignmentId/peer-reviews/received
+func (c *PeerReviewController) GetReceivedReviews(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	reviews, err := c.peerReviewService.GetReceivedReviews(classID, assignmentID, userID.(int))
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+// SubmitReview handles PUT /api/classes/:id/assignments/:assignmentId/peer-reviews/:reviewId
+func (c *PeerReviewController) SubmitReview(ctx *gin.Context) {
+	classID, assignmentID, reviewID, ok := parsePeerReviewParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var review models.PeerReview
+	if err := ctx.ShouldBindJSON(&review); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	saved, err := c.peerReviewService.SubmitReview(classID, assignmentID, reviewID, userID.(int), review)
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+// RateReview handles PUT /api/classes/:id/assignments/:assignmentId/peer-reviews/:reviewId/quality
+func (c *PeerReviewController) RateReview(ctx *gin.Context) {
+	classID, assignmentID, reviewID, ok := parsePeerReviewParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		QualityScore *int `json:"qualityScore" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	review, err := c.peerReviewService.RateReview(classID, assignmentID, reviewID, *request.QualityScore)
+	if err != nil {
+		respondPeerReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+// parsePeerReviewParams parses the class, assignment and review IDs from the URL.
+// It writes an error response and returns false if any of them is invalid.
+func parsePeerReviewParams(ctx *gin.Context) (int, int, int, bool) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	reviewID, err := strconv.Atoi(ctx.Param("reviewId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return 0, 0, 0, false
+	}
+
+	return classID, assignmentID, reviewID, true
+}
+
+// respondPeerReviewError maps a peer review service error to an HTTP response
+func respondPeerReviewError(ctx *gin.Context, err error) {
+	log.Printf("Peer review error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "already been assigned"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+	"github.com/yongdilun/classconnect-backend/utils"
+)
+
+// ProgressReportController handles student progress report requests
+type ProgressReportController struct {
+	progressReportService services.ProgressReportService
+	classService          services.ClassService
+}
+
+// NewProgressReportController creates a new ProgressReportController
+func NewProgressReportController(progressReportService services.ProgressReportService, classService services.ClassService) *ProgressReportController {
+	return &ProgressReportController{
+		progressReportService: progressReportService,
+		classService:          classService,
+	}
+}
+
+// GetClassReport handles GET /api/classes/:id/reports/students/:studentId
+// The report is a PDF unless ?format=json is given.
+func (c *ProgressReportController) GetClassReport(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	report, err := c.progressReportService.GetClassReport(classID, studentID)
+	if err != nil {
+		respondProgressReportError(ctx, err)
+		return
+	}
+
+	respondProgressReport(ctx, report)
+}
+
+// DownloadClassReports handles GET /api/classes/:id/reports
+// It returns a zip with a progress report PDF for every enrolled student.
+func (c *ProgressReportController) DownloadClassReports(ctx *gin.Context) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	bundle, err := c.progressReportService.ClassReportsZip(classID)
+	if err != nil {
+		respondProgressReportError(ctx, err)
+		return
+	}
+
+	filename := fmt.Sprintf("progress-reports-class-%d.zip", classID)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, "application/zip", bundle)
+}
+
+// GetTermReport handles GET /api/terms/:termId/students/:studentId/report
+// Teachers get the classes of the term they teach the student in, admins
+// get every class. The report is a PDF unless ?format=json is given.
+func (c *ProgressReportController) GetTermReport(ctx *gin.Context) {
+	termID, err := strconv.Atoi(ctx.Param("termId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term ID"})
+		return
+	}
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var teacherID *int
+	if role, _ := ctx.Get("userRole"); role != "admin" {
+		userID, _ := ctx.Get("userId")
+		id := userID.(int)
+		teacherID = &id
+	}
+
+	report, err := c.progressReportService.GetTermReport(termID, studentID, teacherID)
+	if err != nil {
+		respondProgressReportError(ctx, err)
+		return
+	}
+
+	respondProgressReport(ctx, report)
+}
+
+// respondProgressReport sends a report as a PDF download, or as JSON
+func respondProgressReport(ctx *gin.Context, report *models.ProgressReport) {
+	if ctx.Query("format") == "json" {
+		ctx.JSON(http.StatusOK, report)
+		return
+	}
+
+	pdf, err := utils.WriteProgressReportPDF(*report)
+	if err != nil {
+		log.Printf("Error rendering progress report: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render progress report"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", utils.ProgressReportFilename(*report)))
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func respondProgressReportError(ctx *gin.Context, err error) {
+	log.Printf("Progress report error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// maxQTIUploadSize limits the size of an uploaded QTI package (50 MB)
+const maxQTIUploadSize = 50 << 20
+
+// QTIController handles QTI import and export requests for quizzes
+type QTIController struct {
+	qtiService   services.QTIService
+	classService services.ClassService
+}
+
+// NewQTIController creates a new QTIController
+func NewQTIController(qtiService services.QTIService, classService services.ClassService) *QTIController {
+	return &QTIController{
+		qtiService:   qtiService,
+		classService: classService,
+	}
+}
+
+// ImportPackage handles POST /api/classes/:id/assignments/:assignmentId/quiz/qti/import
+// The zip package can be sent as a multipart "file" field or as the raw request body.
+func (c *QTIController) ImportPackage(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	upload, closeFn, err := readUpload(ctx, maxQTIUploadSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeFn()
+
+	pkg, err := io.ReadAll(upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+
+	report, err := c.qtiService.ImportPackage(classID, assignmentID, pkg, queryBool(ctx, "dryRun", false))
+	if err != nil {
+		log.Printf("Error importing QTI package: %v", err)
+		switch {
+		case errors.Is(err, services.ErrQuizHasAttempts):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrClassArchived):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "invalid QTI package"):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "not found"):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// ExportPackage handles GET /api/classes/:id/assignments/:assignmentId/quiz/qti/export?version=2.1
+func (c *QTIController) ExportPackage(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	version := ctx.DefaultQuery("version", services.QTIVersion21)
+	pkg, err := c.qtiService.ExportPackage(classID, assignmentID, version)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	filename := fmt.Sprintf("quiz-%d-qti%s.zip", assignmentID, strings.ReplaceAll(version, ".", ""))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, "application/zip", pkg)
+}
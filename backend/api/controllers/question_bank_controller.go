@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// QuestionBankController handles question bank requests
+type QuestionBankController struct {
+	questionBankService services.QuestionBankService
+}
+
+// NewQuestionBankController creates a new QuestionBankController
+func NewQuestionBankController(questionBankService services.QuestionBankService) *QuestionBankController {
+	return &QuestionBankController{
+		questionBankService: questionBankService,
+	}
+}
+
+// bankRequest is the request body for creating or updating a question bank
+type bankRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// GetBanks handles GET /api/question-banks
+func (c *QuestionBankController) GetBanks(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	banks, err := c.questionBankService.GetBanks(userID.(int))
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, banks)
+}
+
+// CreateBank handles POST /api/question-banks
+func (c *QuestionBankController) CreateBank(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request bankRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	bank, err := c.questionBankService.CreateBank(userID.(int), request.Name, request.Description)
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, bank)
+}
+
+// GetBank handles GET /api/question-banks/:bankId?tag=&difficulty=
+func (c *QuestionBankController) GetBank(ctx *gin.Context) {
+	bankID, ok := c.requireBankOwner(ctx)
+	if !ok {
+		return
+	}
+
+	var tags []string
+	for _, value := range ctx.QueryArray("tag") {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+
+	bank, err := c.questionBankService.GetBank(bankID, tags, ctx.Query("difficulty"))
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bank)
+}
+
+// UpdateBank handles PUT /api/question-banks/:bankId
+func (c *QuestionBankController) UpdateBank(ctx *gin.Context) {
+	bankID, ok := c.requireBankOwner(ctx)
+	if !ok {
+		return
+	}
+
+	var request bankRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	bank, err := c.questionBankService.UpdateBank(bankID, request.Name, request.Description)
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bank)
+}
+
+// DeleteBank handles DELETE /api/question-banks/:bankId
+func (c *QuestionBankController) DeleteBank(ctx *gin.Context) {
+	bankID, ok := c.requireBankOwner(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.questionBankService.DeleteBank(bankID); err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Question bank deleted successfully"})
+}
+
+// AddQuestion handles POST /api/question-banks/:bankId/questions
+func (c *QuestionBankController) AddQuestion(ctx *gin.Context) {
+	bankID, ok := c.requireBankOwner(ctx)
+	if !ok {
+		return
+	}
+
+	var question models.BankQuestion
+	if err := ctx.ShouldBindJSON(&question); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	created, err := c.questionBankService.AddQuestion(bankID, question)
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// UpdateQuestion handles PUT /api/question-banks/:bankId/questions/:questionId
+func (c *QuestionBankController) UpdateQuestion(ctx *gin.Context) {
+	bankID, ok := c.requireBankOwner(ctx)
+	if !ok {
+		return
+	}
+
+	questionID, err := strconv.Atoi(ctx.Param("questionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	var question models.BankQuestion
+	if err := ctx.ShouldBindJSON(&question); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	updated, err := c.questionBankService.UpdateQuestion(bankID, questionID, question)
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// DeleteQuestion handles DELETE /api/question-banks/:bankId/questions/:questionId
+func (c *QuestionBankController) DeleteQuestion(ctx *gin.Context) {
+	bankID, ok := c.requireBankOwner(ctx)
+	if !ok {
+		return
+	}
+
+	questionID, err := strconv.Atoi(ctx.Param("questionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	if err := c.questionBankService.DeleteQuestion(bankID, questionID); err != nil {
+		respondQuestionBankError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Question deleted successfully"})
+}
+
+// requireBankOwner parses the bank ID from the URL and checks that the user owns the bank
+// (admins can access every bank). It writes an error response and returns false otherwise.
+func (c *QuestionBankController) requireBankOwner(ctx *gin.Context) (int, bool) {
+	bankID, err := strconv.Atoi(ctx.Param("bankId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question bank ID"})
+		return 0, false
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, false
+	}
+
+	isOwner, err := c.questionBankService.IsBankOwner(userID.(int), bankID)
+	if err != nil {
+		respondQuestionBankError(ctx, err)
+		return 0, false
+	}
+
+	if role, _ := ctx.Get("userRole"); !isOwner && role != "admin" {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Question bank belongs to another teacher"})
+		return 0, false
+	}
+
+	return bankID, true
+}
+
+// respondQuestionBankError maps a question bank service error to an HTTP response
+func respondQuestionBankError(ctx *gin.Context, err error) {
+	log.Printf("Question bank error: %v", err)
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "is used by"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
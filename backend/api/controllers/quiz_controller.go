@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// QuizController handles quiz and quiz attempt requests
+type QuizController struct {
+	quizService  services.QuizService
+	classService services.ClassService
+}
+
+// NewQuizController creates a new QuizController
+func NewQuizController(quizService services.QuizService, classService services.ClassService) *QuizController {
+	return &QuizController{
+		quizService:  quizService,
+		classService: classService,
+	}
+}
+
+// SaveQuiz handles PUT /api/classes/:id/assignments/:assignmentId/quiz
+func (c *QuizController) SaveQuiz(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		models.QuizSettings
+		Questions []models.QuizQuestion `json:"questions" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	quiz, err := c.quizService.SaveQuiz(classID, assignmentID, request.QuizSettings, request.Questions)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quiz)
+}
+
+// GetQuiz handles GET /api/classes/:id/assignments/:assignmentId/quiz.
+// Questions and answer keys are only included for teachers of the class.
+func (c *QuizController) GetQuiz(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	includeQuestions := false
+	if role, _ := ctx.Get("userRole"); role == "admin" {
+		includeQuestions = true
+	} else if role == "teacher" {
+		isTeacher, err := c.classService.IsTeacherInClass(userID.(int), classID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify class membership"})
+			return
+		}
+		includeQuestions = isTeacher
+	}
+
+	quiz, err := c.quizService.GetQuiz(classID, assignmentID, includeQuestions)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quiz)
+}
+
+// StartAttempt handles POST /api/classes/:id/assignments/:assignmentId/quiz/attempts
+func (c *QuizController) StartAttempt(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	attempt, err := c.quizService.StartAttempt(classID, assignmentID, userID.(int))
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempt)
+}
+
+// SaveAnswers handles PUT /api/classes/:id/assignments/:assignmentId/quiz/attempts/:attemptId
+func (c *QuizController) SaveAnswers(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	attemptID, err := strconv.Atoi(ctx.Param("attemptId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attempt ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request struct {
+		Answers map[int]models.QuizAnswer `json:"answers" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	attempt, err := c.quizService.SaveAnswers(classID, assignmentID, attemptID, userID.(int), request.Answers)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempt)
+}
+
+// SubmitAttempt handles POST /api/classes/:id/assignments/:assignmentId/quiz/attempts/:attemptId/submit
+func (c *QuizController) SubmitAttempt(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	attemptID, err := strconv.Atoi(ctx.Param("attemptId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attempt ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// The final answers are optional; answers saved earlier are kept
+	var request struct {
+		Answers map[int]models.QuizAnswer `json:"answers"`
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	attempt, err := c.quizService.SubmitAttempt(classID, assignmentID, attemptID, userID.(int), request.Answers)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempt)
+}
+
+// GetAttempts handles GET /api/classes/:id/assignments/:assignmentId/quiz/attempts.
+// Students see their own attempts; teachers see all attempts or filter by studentId.
+func (c *QuizController) GetAttempts(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var studentID *int
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		id := userID.(int)
+		studentID = &id
+	} else {
+		if !requireClassTeacher(ctx, c.classService, classID) {
+			return
+		}
+		if value := ctx.Query("studentId"); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+				return
+			}
+			studentID = &id
+		}
+	}
+
+	attempts, err := c.quizService.GetAttempts(classID, assignmentID, studentID)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
+}
+
+// SavePools handles PUT /api/classes/:id/assignments/:assignmentId/quiz/pools
+func (c *QuizController) SavePools(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		Pools []models.QuizPool `json:"pools" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// Teachers can only draw from their own banks
+	var ownerID *int
+	if role, _ := ctx.Get("userRole"); role != "admin" {
+		userID, _ := ctx.Get("userId")
+		id := userID.(int)
+		ownerID = &id
+	}
+
+	quiz, err := c.quizService.SavePools(classID, assignmentID, ownerID, request.Pools)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quiz)
+}
+
+// GetStudentQuestions handles GET /api/classes/:id/assignments/:assignmentId/quiz/students/:studentId/questions
+func (c *QuizController) GetStudentQuestions(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("studentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	questions, err := c.quizService.GetStudentQuestions(classID, assignmentID, studentID)
+	if err != nil {
+		respondQuizError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, questions)
+}
+
+// parseQuizParams parses the class and assignment IDs from the URL.
+// It writes an error response and returns false if they are invalid.
+func parseQuizParams(ctx *gin.Context) (int, int, bool) {
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return 0, 0, false
+	}
+
+	assignmentID, err := strconv.Atoi(ctx.Param("assignmentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return 0, 0, false
+	}
+
+	return classID, assignmentID, true
+}
+
+// respondQuizError maps a quiz service error to an HTTP response
+func respondQuizError(ctx *gin.Context, err error) {
+	log.Printf("Quiz error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived),
+		strings.Contains(message, "not enrolled"),
+		strings.Contains(message, "not assigned"),
+		strings.Contains(message, "no attempts left"),
+		strings.Contains(message, "belongs to another teacher"):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case errors.Is(err, services.ErrQuizHasAttempts):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// RegradeController handles regrade request requests
+type RegradeController struct {
+	regradeService services.RegradeService
+	classService   services.ClassService
+}
+
+// NewRegradeController creates a new RegradeController
+func NewRegradeController(regradeService services.RegradeService, classService services.ClassService) *RegradeController {
+	return &RegradeController{
+		regradeService: regradeService,
+		classService:   classService,
+	}
+}
+
+// CreateRequest handles POST /api/classes/:id/assignments/:assignmentId/regrade-requests
+func (c *RegradeController) CreateRequest(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request struct {
+		Reason       string `json:"reason" binding:"required"`
+		CriterionIDs []int  `json:"criterionIds"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	regradeRequest, err := c.regradeService.CreateRequest(classID, assignmentID, userID.(int), request.Reason, request.CriterionIDs)
+	if err != nil {
+		respondRegradeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, regradeRequest)
+}
+
+// GetRequests handles GET /api/classes/:id/assignments/:assignmentId/regrade-requests.
+// Teachers get every request, students their own.
+func (c *RegradeController) GetRequests(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var requests []models.RegradeRequest
+	var err error
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		requests, err = c.regradeService.GetStudentRequests(classID, assignmentID, userID.(int))
+	} else {
+		if !requireClassTeacher(ctx, c.classService, classID) {
+			return
+		}
+		requests, err = c.regradeService.GetRequests(classID, assignmentID, ctx.Query("status"))
+	}
+	if err != nil {
+		respondRegradeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+// AcceptRequest handles PUT /api/classes/:id/assignments/:assignmentId/regrade-requests/:requestId/accept
+func (c *RegradeController) AcceptRequest(ctx *gin.Context) {
+	classID, assignmentID, requestID, teacherID, ok := c.parseResolveParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Grade        *int                 `json:"grade"`
+		RubricScores []models.RubricScore `json:"rubricScores"`
+		Response     string               `json:"response"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	regradeRequest, err := c.regradeService.AcceptRequest(classID, assignmentID, requestID, teacherID, request.Grade, request.RubricScores, request.Response)
+	if err != nil {
+		respondRegradeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, regradeRequest)
+}
+
+// RejectRequest handles PUT /api/classes/:id/assignments/:assignmentId/regrade-requests/:requestId/reject
+func (c *RegradeController) RejectRequest(ctx *gin.Context) {
+	classID, assignmentID, requestID, teacherID, ok := c.parseResolveParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Response string `json:"response" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	regradeRequest, err := c.regradeService.RejectRequest(classID, assignmentID, requestID, teacherID, request.Response)
+	if err != nil {
+		respondRegradeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, regradeRequest)
+}
+
+// parseResolveParams reads the URL of a request to resolve and checks that
+// the user teaches the class
+func (c *RegradeController) parseResolveParams(ctx *gin.Context) (int, int, int, int, bool) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+
+	requestID, err := strconv.Atoi(ctx.Param("requestId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid regrade request ID"})
+		return 0, 0, 0, 0, false
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, 0, 0, 0, false
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return 0, 0, 0, 0, false
+	}
+
+	return classID, assignmentID, requestID, userID.(int), true
+}
+
+func respondRegradeError(ctx *gin.Context, err error) {
+	log.Printf("Regrade request error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "already pending"), strings.Contains(message, "already been resolved"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// maxRosterUploadSize limits the size of an uploaded roster file (5 MB)
+const maxRosterUploadSize = 5 << 20
+
+// RosterController handles bulk roster requests
+type RosterController struct {
+	rosterService services.RosterService
+	classService  services.ClassService
+}
+
+// NewRosterController creates a new RosterController
+func NewRosterController(rosterService services.RosterService, classService services.ClassService) *RosterController {
+	return &RosterController{
+		rosterService: rosterService,
+		classService:  classService,
+	}
+}
+
+// ImportRoster handles POST /api/classes/:id/roster/import
+// The CSV can be sent as a multipart "file" field or as the raw request body.
+// Query parameters: dryRun, createAccounts and sendInvites (defaults to true).
+func (c *RosterController) ImportRoster(ctx *gin.Context) {
+	// Parse class ID from URL
+	classID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	options := services.RosterImportOptions{
+		DryRun:         queryBool(ctx, "dryRun", false),
+		CreateAccounts: queryBool(ctx, "createAccounts", false),
+		SendInvites:    queryBool(ctx, "sendInvites", true),
+	}
+
+	csvData, closeFn, err := readUpload(ctx, maxRosterUploadSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeFn()
+
+	report, err := c.rosterService.ImportRoster(classID, csvData, options)
+	if err != nil {
+		log.Printf("Error importing roster for class %d: %v", classID, err)
+		if errors.Is(err, services.ErrClassArchived) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// errUploadMissing is returned when a request does not contain a file
+var errUploadMissing = errors.New("a file upload is required")
+
+// readUpload returns the file sent either as a multipart "file" field or as the request body
+func readUpload(ctx *gin.Context, maxSize int64) (io.Reader, func(), error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize)
+
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			return nil, nil, errUploadMissing
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, nil, errUploadMissing
+		}
+		return file, func() { file.Close() }, nil
+	}
+
+	if ctx.Request.ContentLength == 0 {
+		return nil, nil, errUploadMissing
+	}
+
+	return ctx.Request.Body, func() {}, nil
+}
+
+// queryBool parses a boolean query parameter, falling back to a default value
+func queryBool(ctx *gin.Context, name string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(ctx.Query(name))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
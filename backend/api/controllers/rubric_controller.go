@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// RubricController handles rubric requests
+type RubricController struct {
+	rubricService services.RubricService
+	classService  services.ClassService
+}
+
+// NewRubricController creates a new RubricController
+func NewRubricController(rubricService services.RubricService, classService services.ClassService) *RubricController {
+	return &RubricController{
+		rubricService: rubricService,
+		classService:  classService,
+	}
+}
+
+// rubricResponse adds the rubric's maximum points to the rubric
+type rubricResponse struct {
+	*models.Rubric
+	MaxPoints int `json:"maxPoints"`
+}
+
+// newRubricResponse creates the response for a rubric
+func newRubricResponse(rubric *models.Rubric) rubricResponse {
+	return rubricResponse{Rubric: rubric, MaxPoints: rubric.MaxPoints()}
+}
+
+// GetRubrics handles GET /api/rubrics
+func (c *RubricController) GetRubrics(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	rubrics, err := c.rubricService.GetRubrics(userID.(int))
+	if err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	responses := make([]rubricResponse, 0, len(rubrics))
+	for i := range rubrics {
+		responses = append(responses, newRubricResponse(&rubrics[i]))
+	}
+
+	ctx.JSON(http.StatusOK, responses)
+}
+
+// CreateRubric handles POST /api/rubrics
+func (c *RubricController) CreateRubric(ctx *gin.Context) {
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var rubric models.Rubric
+	if err := ctx.ShouldBindJSON(&rubric); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	created, err := c.rubricService.CreateRubric(userID.(int), rubric)
+	if err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newRubricResponse(created))
+}
+
+// GetRubric handles GET /api/rubrics/:rubricId
+func (c *RubricController) GetRubric(ctx *gin.Context) {
+	rubricID, ok := c.requireRubricOwner(ctx)
+	if !ok {
+		return
+	}
+
+	rubric, err := c.rubricService.GetRubric(rubricID)
+	if err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRubricResponse(rubric))
+}
+
+// UpdateRubric handles PUT /api/rubrics/:rubricId
+func (c *RubricController) UpdateRubric(ctx *gin.Context) {
+	rubricID, ok := c.requireRubricOwner(ctx)
+	if !ok {
+		return
+	}
+
+	var rubric models.Rubric
+	if err := ctx.ShouldBindJSON(&rubric); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	updated, err := c.rubricService.UpdateRubric(rubricID, rubric)
+	if err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRubricResponse(updated))
+}
+
+// DeleteRubric handles DELETE /api/rubrics/:rubricId
+func (c *RubricController) DeleteRubric(ctx *gin.Context) {
+	rubricID, ok := c.requireRubricOwner(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.rubricService.DeleteRubric(rubricID); err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Rubric deleted successfully"})
+}
+
+// AttachRubric handles PUT /api/classes/:id/assignments/:assignmentId/rubric
+// A null rubricId detaches the rubric.
+func (c *RubricController) AttachRubric(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	var request struct {
+		RubricID *int `json:"rubricId"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// Teachers can only attach their own rubrics
+	if request.RubricID != nil {
+		if role, _ := ctx.Get("userRole"); role != "admin" {
+			userID, _ := ctx.Get("userId")
+			isOwner, err := c.rubricService.IsRubricOwner(userID.(int), *request.RubricID)
+			if err != nil {
+				respondRubricError(ctx, err)
+				return
+			}
+			if !isOwner {
+				ctx.JSON(http.StatusForbidden, gin.H{"error": "Rubric belongs to another teacher"})
+				return
+			}
+		}
+	}
+
+	assignment, err := c.rubricService.AttachRubric(classID, assignmentID, request.RubricID)
+	if err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, assignment)
+}
+
+// GetAssignmentRubric handles GET /api/classes/:id/assignments/:assignmentId/rubric
+func (c *RubricController) GetAssignmentRubric(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	rubric, err := c.rubricService.GetAssignmentRubric(classID, assignmentID)
+	if err != nil {
+		respondRubricError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRubricResponse(rubric))
+}
+
+// requireRubricOwner parses the rubric ID from the URL and checks that the user owns the rubric
+// (admins can access every rubric). It writes an error response and returns false otherwise.
+func (c *RubricController) requireRubricOwner(ctx *gin.Context) (int, bool) {
+	rubricID, err := strconv.Atoi(ctx.Param("rubricId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rubric ID"})
+		return 0, false
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, false
+	}
+
+	isOwner, err := c.rubricService.IsRubricOwner(userID.(int), rubricID)
+	if err != nil {
+		respondRubricError(ctx, err)
+		return 0, false
+	}
+
+	if role, _ := ctx.Get("userRole"); !isOwner && role != "admin" {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Rubric belongs to another teacher"})
+		return 0, false
+	}
+
+	return rubricID, true
+}
+
+// respondRubricError maps a rubric service error to an HTTP response
+func respondRubricError(ctx *gin.Context, err error) {
+	log.Printf("Rubric error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "is used by"), strings.Contains(message, "already been used"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
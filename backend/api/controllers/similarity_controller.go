@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// SimilarityController handles similarity check requests
+type SimilarityController struct {
+	similarityService services.SimilarityService
+	classService      services.ClassService
+}
+
+// NewSimilarityController creates a new SimilarityController
+func NewSimilarityController(similarityService services.SimilarityService, classService services.ClassService) *SimilarityController {
+	return &SimilarityController{
+		similarityService: similarityService,
+		classService:      classService,
+	}
+}
+
+// StartCheck handles POST /api/classes/:id/assignments/:assignmentId/similarity
+func (c *SimilarityController) StartCheck(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	// The options are optional; by default only this term's submissions are compared
+	var request struct {
+		IncludeEarlierTerms bool     `json:"includeEarlierTerms"`
+		MinScore            *float64 `json:"minScore"`
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	userID, _ := ctx.Get("userId")
+	job, err := c.similarityService.StartCheck(classID, assignmentID, userID.(int), request.IncludeEarlierTerms, request.MinScore)
+	if err != nil {
+		respondSimilarityError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetJobs handles GET /api/classes/:id/assignments/:assignmentId/similarity
+func (c *SimilarityController) GetJobs(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	jobs, err := c.similarityService.GetJobs(classID, assignmentID)
+	if err != nil {
+		respondSimilarityError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// GetJob handles GET /api/classes/:id/assignments/:assignmentId/similarity/:jobId
+func (c *SimilarityController) GetJob(ctx *gin.Context) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return
+	}
+
+	jobID, err := strconv.Atoi(ctx.Param("jobId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return
+	}
+
+	job, err := c.similarityService.GetJob(classID, assignmentID, jobID)
+	if err != nil {
+		respondSimilarityError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+func respondSimilarityError(ctx *gin.Context, err error) {
+	log.Printf("Similarity error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// SubmissionCommentController handles the comment threads on submissions
+type SubmissionCommentController struct {
+	commentService          services.SubmissionCommentService
+	classService            services.ClassService
+	anonymousGradingService services.AnonymousGradingService
+}
+
+// NewSubmissionCommentController creates a new SubmissionCommentController
+func NewSubmissionCommentController(commentService services.SubmissionCommentService, classService services.ClassService, anonymousGradingService services.AnonymousGradingService) *SubmissionCommentController {
+	return &SubmissionCommentController{
+		commentService:          commentService,
+		classService:            classService,
+		anonymousGradingService: anonymousGradingService,
+	}
+}
+
+// GetComments handles GET /api/classes/:id/assignments/:assignmentId/submissions/:studentId/comments
+func (c *SubmissionCommentController) GetComments(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	comments, err := c.commentService.GetComments(classID, assignmentID, studentID, userID)
+	if err != nil {
+		respondCommentError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comments)
+}
+
+// AddComment handles POST /api/classes/:id/assignments/:assignmentId/submissions/:studentId/comments
+func (c *SubmissionCommentController) AddComment(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Body        string `json:"body" binding:"required"`
+		ParentID    *int   `json:"parentId"`
+		AnchorStart *int   `json:"anchorStart"`
+		AnchorEnd   *int   `json:"anchorEnd"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	role, _ := ctx.Get("userRole")
+	comment, err := c.commentService.AddComment(classID, assignmentID, studentID, userID, role.(string),
+		request.Body, request.ParentID, request.AnchorStart, request.AnchorEnd)
+	if err != nil {
+		respondCommentError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, comment)
+}
+
+// EditComment handles PUT /api/classes/:id/assignments/:assignmentId/submissions/:studentId/comments/:commentId
+func (c *SubmissionCommentController) EditComment(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	commentID, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var request struct {
+		Body string `json:"body" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	comment, err := c.commentService.EditComment(classID, assignmentID, studentID, commentID, userID, request.Body)
+	if err != nil {
+		respondCommentError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /api/classes/:id/assignments/:assignmentId/submissions/:studentId/comments/:commentId
+func (c *SubmissionCommentController) DeleteComment(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	commentID, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	role, _ := ctx.Get("userRole")
+	if err := c.commentService.DeleteComment(classID, assignmentID, studentID, commentID, userID, role == "student"); err != nil {
+		respondCommentError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// GetCommentHistory handles GET /api/classes/:id/assignments/:assignmentId/submissions/:studentId/comments/:commentId/history
+func (c *SubmissionCommentController) GetCommentHistory(ctx *gin.Context) {
+	classID, assignmentID, studentID, _, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	commentID, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	edits, err := c.commentService.GetCommentHistory(classID, assignmentID, studentID, commentID)
+	if err != nil {
+		respondCommentError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, edits)
+}
+
+// parseParams reads the class, assignment and student of a comment URL and
+// checks that the user is the student or a teacher of the class. Teachers
+// must use the anonymous ID while the assignment is graded anonymously.
+func (c *SubmissionCommentController) parseParams(ctx *gin.Context) (int, int, int, int, bool) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, 0, 0, 0, false
+	}
+
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		studentID, err := strconv.Atoi(ctx.Param("studentId"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+			return 0, 0, 0, 0, false
+		}
+		if userID.(int) != studentID {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Students can only comment on their own submissions"})
+			return 0, 0, 0, 0, false
+		}
+		return classID, assignmentID, studentID, userID.(int), true
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return 0, 0, 0, 0, false
+	}
+
+	studentID, err := c.anonymousGradingService.ResolveStudentID(classID, assignmentID, ctx.Param("studentId"))
+	if err != nil {
+		respondCommentError(ctx, err)
+		return 0, 0, 0, 0, false
+	}
+
+	return classID, assignmentID, studentID, userID.(int), true
+}
+
+// respondCommentError maps a submission comment service error to an HTTP response
+func respondCommentError(ctx *gin.Context, err error) {
+	log.Printf("Submission comment error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrClassArchived):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case errors.Is(err, services.ErrIdentitiesHidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "only the author"), strings.HasPrefix(message, "students can only"):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
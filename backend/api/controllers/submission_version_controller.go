@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// SubmissionVersionController handles requests for earlier versions of submissions
+type SubmissionVersionController struct {
+	versionService          services.SubmissionVersionService
+	anonymousGradingService services.AnonymousGradingService
+	classService            services.ClassService
+}
+
+// NewSubmissionVersionController creates a new SubmissionVersionController
+func NewSubmissionVersionController(versionService services.SubmissionVersionService, anonymousGradingService services.AnonymousGradingService, classService services.ClassService) *SubmissionVersionController {
+	return &SubmissionVersionController{
+		versionService:          versionService,
+		anonymousGradingService: anonymousGradingService,
+		classService:            classService,
+	}
+}
+
+// GetVersions handles GET /api/classes/:id/assignments/:assignmentId/submissions/:studentId/versions
+func (c *SubmissionVersionController) GetVersions(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	versions, err := c.versionService.GetVersions(classID, assignmentID, studentID, userID)
+	if err != nil {
+		respondVersionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, versions)
+}
+
+// GetVersion handles GET /api/classes/:id/assignments/:assignmentId/submissions/:studentId/versions/:version
+func (c *SubmissionVersionController) GetVersion(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	submissionVersion, err := c.versionService.GetVersion(classID, assignmentID, studentID, userID, version)
+	if err != nil {
+		respondVersionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, submissionVersion)
+}
+
+// DiffVersions handles GET /api/classes/:id/assignments/:assignmentId/submissions/:studentId/versions/diff?from=1&to=2
+func (c *SubmissionVersionController) DiffVersions(ctx *gin.Context) {
+	classID, assignmentID, studentID, userID, ok := c.parseParams(ctx)
+	if !ok {
+		return
+	}
+
+	from, err := strconv.Atoi(ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from version"})
+		return
+	}
+	to, err := strconv.Atoi(ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to version"})
+		return
+	}
+
+	diff, err := c.versionService.DiffVersions(classID, assignmentID, studentID, userID, from, to)
+	if err != nil {
+		respondVersionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// parseParams parses the class, assignment and student IDs from the URL and
+// checks that the user is a teacher of the class or the student themselves.
+// Teachers must name the student by anonymous ID while grading is anonymous.
+// It writes an error response and returns false otherwise.
+func (c *SubmissionVersionController) parseParams(ctx *gin.Context) (int, int, int, int, bool) {
+	classID, assignmentID, ok := parseQuizParams(ctx)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, 0, 0, 0, false
+	}
+
+	if role, _ := ctx.Get("userRole"); role == "student" {
+		studentID, err := strconv.Atoi(ctx.Param("studentId"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+			return 0, 0, 0, 0, false
+		}
+		if userID.(int) != studentID {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Students can only view their own submissions"})
+			return 0, 0, 0, 0, false
+		}
+		return classID, assignmentID, studentID, userID.(int), true
+	}
+
+	if !requireClassTeacher(ctx, c.classService, classID) {
+		return 0, 0, 0, 0, false
+	}
+
+	studentID, err := c.anonymousGradingService.ResolveStudentID(classID, assignmentID, ctx.Param("studentId"))
+	if err != nil {
+		respondVersionError(ctx, err)
+		return 0, 0, 0, 0, false
+	}
+
+	return classID, assignmentID, studentID, userID.(int), true
+}
+
+// respondVersionError maps a submission version service error to an HTTP response
+func respondVersionError(ctx *gin.Context, err error) {
+	log.Printf("Submission version error: %v", err)
+	message := err.Error()
+	switch {
+	case errors.Is(err, services.ErrIdentitiesHidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "failed to"):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/api/services"
+)
+
+// TermController handles academic term requests
+type TermController struct {
+	termService services.TermService
+}
+
+// NewTermController creates a new TermController
+func NewTermController(termService services.TermService) *TermController {
+	return &TermController{
+		termService: termService,
+	}
+}
+
+// termRequest is the request body for creating or updating a term
+type termRequest struct {
+	Name      string `json:"name" binding:"required"`
+	StartDate string `json:"startDate" binding:"required"`
+	EndDate   string `json:"endDate" binding:"required"`
+}
+
+// parseDates parses the start and end dates of a term request
+func (r termRequest) parseDates() (time.Time, time.Time, error) {
+	startDate, err := models.ParseTime(r.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endDate, err := models.ParseTime(r.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return startDate, endDate, nil
+}
+
+// GetTerms handles GET /api/terms
+func (c *TermController) GetTerms(ctx *gin.Context) {
+	terms, err := c.termService.GetTerms()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, terms)
+}
+
+// CreateTerm handles POST /api/admin/terms
+func (c *TermController) CreateTerm(ctx *gin.Context) {
+	var req termRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	startDate, endDate, err := req.parseDates()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	term, err := c.termService.CreateTerm(req.Name, startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, term)
+}
+
+// UpdateTerm handles PUT /api/admin/terms/:termId
+func (c *TermController) UpdateTerm(ctx *gin.Context) {
+	termID, err := strconv.Atoi(ctx.Param("termId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term ID"})
+		return
+	}
+
+	var req termRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	startDate, endDate, err := req.parseDates()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	term, err := c.termService.UpdateTerm(termID, req.Name, startDate, endDate)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, term)
+}
+
+// DeleteTerm handles DELETE /api/admin/terms/:termId
+func (c *TermController) DeleteTerm(ctx *gin.Context) {
+	termID, err := strconv.Atoi(ctx.Param("termId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term ID"})
+		return
+	}
+
+	if err := c.termService.DeleteTerm(termID); err != nil {
+		log.Printf("Error deleting term %d: %v", termID, err)
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete term"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Term deleted successfully"})
+}
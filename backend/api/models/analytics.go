@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+)
+
+// Trend intervals accepted by the class analytics
+const (
+	AnalyticsIntervalWeek  = "week"
+	AnalyticsIntervalMonth = "month"
+)
+
+// AssignmentAnalytics summarizes the submissions and grades of one assignment.
+// Only students actively enrolled in the class (or the assignment's section)
+// are counted.
+type AssignmentAnalytics struct {
+	AssignmentID   int       `json:"assignmentId"`
+	Title          string    `json:"title"`
+	DueDate        time.Time `json:"dueDate"`
+	PointsPossible int       `json:"pointsPossible"`
+	Students       int       `json:"students"` // Students expected to submit
+	Submitted      int       `json:"submitted"`
+	SubmissionRate float64   `json:"submissionRate"` // Between 0 and 1
+	OnTime         int       `json:"onTime"`
+	Late           int       `json:"late"`
+	LateRatio      float64   `json:"lateRatio"` // Share of the submissions that were late
+	Graded         int       `json:"graded"`
+	MeanGrade      *float64  `json:"meanGrade,omitempty"`
+	MedianGrade    *float64  `json:"medianGrade,omitempty"`
+}
+
+// HistogramBucket counts the grades from From up to To percent of the
+// assignment's points. The last bucket includes 100%.
+type HistogramBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+// GradeDistribution describes the grades of an assignment
+type GradeDistribution struct {
+	AssignmentID   int               `json:"assignmentId"`
+	PointsPossible int               `json:"pointsPossible"`
+	Graded         int               `json:"graded"`
+	Mean           *float64          `json:"mean,omitempty"`
+	Median         *float64          `json:"median,omitempty"`
+	Min            *int              `json:"min,omitempty"`
+	Max            *int              `json:"max,omitempty"`
+	StdDev         *float64          `json:"stdDev,omitempty"`
+	Histogram      []HistogramBucket `json:"histogram"`
+}
+
+// StudentAnalytics shows how a student is keeping up with a class
+type StudentAnalytics struct {
+	StudentID      int        `json:"studentId"`
+	StudentName    string     `json:"studentName"`
+	Assigned       int        `json:"assigned"` // Published assignments the student is expected to submit
+	Submitted      int        `json:"submitted"`
+	Late           int        `json:"late"`
+	Missing        int        `json:"missing"` // Past due without a submission
+	AveragePercent *float64   `json:"averagePercent,omitempty"`
+	ChatMessages   int        `json:"chatMessages"`
+	ChatShare      float64    `json:"chatShare"` // Share of the students' chat messages, between 0 and 1
+	LastMessageAt  *time.Time `json:"lastMessageAt,omitempty"`
+}
+
+// AnalyticsTrendPoint is the class activity in one week or month
+type AnalyticsTrendPoint struct {
+	PeriodStart    time.Time `json:"periodStart"`
+	Submissions    int       `json:"submissions"`
+	Late           int       `json:"late"`
+	AveragePercent *float64  `json:"averagePercent,omitempty"` // Average grade of the period's submissions
+	ChatMessages   int       `json:"chatMessages"`
+}
+
+// AnalyticsTrends is the class activity over time
+type AnalyticsTrends struct {
+	ClassID  int                   `json:"classId"`
+	Interval string                `json:"interval"`
+	Points   []AnalyticsTrendPoint `json:"points"`
+}
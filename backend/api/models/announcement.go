@@ -14,6 +14,8 @@ type Announcement struct {
 	CreatedDate    time.Time  `json:"createdDate" gorm:"column:created_date"`
 	ScheduledDate  *time.Time `json:"scheduledDate,omitempty" gorm:"column:scheduled_date"`
 	IsPublished    bool       `json:"isPublished" gorm:"column:is_published;default:true"`
+	IsPinned       bool       `json:"isPinned" gorm:"column:is_pinned;not null;default:0"`
+	SectionID      *int       `json:"sectionId,omitempty" gorm:"column:section_id"` // nil targets the whole class
 
 	// Virtual fields (not stored in database)
 	UserName string `json:"userName" gorm:"-"`
@@ -44,6 +46,8 @@ type AnnouncementResponse struct {
 	UpdatedAt      time.Time  `json:"updatedAt"`
 	ScheduledDate  *time.Time `json:"scheduledDate,omitempty"`
 	IsPublished    bool       `json:"isPublished"`
+	IsPinned       bool       `json:"isPinned"`
+	SectionID      *int       `json:"sectionId,omitempty"`
 }
 
 // ToResponse converts an Announcement to an AnnouncementResponse
@@ -75,5 +79,7 @@ func (a *Announcement) ToResponse() AnnouncementResponse {
 		UpdatedAt:      a.UpdatedAt,
 		ScheduledDate:  a.ScheduledDate,
 		IsPublished:    a.IsPublished,
+		IsPinned:       a.IsPinned,
+		SectionID:      a.SectionID,
 	}
 }
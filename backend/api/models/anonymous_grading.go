@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// Grading audit actions stored in grading_audit_log.action
+const (
+	GradingAuditGraded             = "graded"
+	GradingAuditRegraded           = "regraded" // A regrade request was accepted
+	GradingAuditIdentitiesRevealed = "identities_revealed"
+)
+
+// AnonymousIDPrefix starts the anonymous IDs that stand in for student IDs
+// while an assignment is graded anonymously
+const AnonymousIDPrefix = "anon-"
+
+// GradingAuditEntry records a grading action, so that grades given with and
+// without knowing the student can be compared when auditing for bias
+type GradingAuditEntry struct {
+	AuditID        int       `gorm:"column:audit_id;primaryKey;autoIncrement" json:"auditId"`
+	AssignmentID   int       `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	GraderID       int       `gorm:"column:grader_id;not null" json:"graderId"`
+	StudentID      *int      `gorm:"column:student_id" json:"studentId,omitempty"` // Hidden in responses until identities are revealed
+	Action         string    `gorm:"column:action;not null" json:"action"`
+	Grade          *int      `gorm:"column:grade" json:"grade,omitempty"`
+	IdentityHidden bool      `gorm:"column:identity_hidden;not null;default:0" json:"identityHidden"` // Whether the grader saw a pseudonym
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+
+	// Virtual fields (not stored in database)
+	GraderName  string `gorm:"-" json:"graderName,omitempty"`
+	StudentName string `gorm:"-" json:"studentName,omitempty"`
+	AnonymousID string `gorm:"-" json:"anonymousId,omitempty"`
+}
+
+// TableName specifies the table name for the GradingAuditEntry model
+func (GradingAuditEntry) TableName() string {
+	return "grading_audit_log"
+}
+
+// AnonymousGradingStatus tells whether student identities are hidden from
+// the graders of an assignment and what is left before they are revealed
+type AnonymousGradingStatus struct {
+	AssignmentID         int        `json:"assignmentId"`
+	AnonymousGrading     bool       `json:"anonymousGrading"`
+	IdentitiesHidden     bool       `json:"identitiesHidden"`
+	IdentitiesRevealedAt *time.Time `json:"identitiesRevealedAt,omitempty"`
+	Submissions          int        `json:"submissions"`
+	Ungraded             int        `json:"ungraded"`
+	PendingRelease       int        `json:"pendingRelease"`
+}
@@ -16,6 +16,38 @@ type Assignment struct {
 	CreatedBy       int       `json:"createdBy" gorm:"column:created_by"`
 	CreatedAt       time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
 	AllowLateSubmit bool      `json:"allowLateSubmit" gorm:"column:allow_late_submissions;default:true"`
+	SectionID       *int      `json:"sectionId,omitempty" gorm:"column:section_id"` // nil targets the whole class
+	RubricID        *int      `json:"rubricId,omitempty" gorm:"column:rubric_id"`
+
+	// When grades were last released to students, grades stay drafts until then
+	GradesReleasedAt *time.Time `json:"gradesReleasedAt,omitempty" gorm:"column:grades_released_at"`
+
+	// Anonymous grading hides student identities from graders until every
+	// grade has been released
+	AnonymousGrading     bool       `json:"anonymousGrading" gorm:"column:anonymous_grading;default:false"`
+	IdentitiesRevealedAt *time.Time `json:"identitiesRevealedAt,omitempty" gorm:"column:identities_revealed_at"`
+	AnonymityKey         string     `json:"-" gorm:"column:anonymity_key"` // Secret the pseudonyms are derived from
+
+	// Resubmission settings
+	MaxResubmissions          *int `json:"maxResubmissions,omitempty" gorm:"column:max_resubmissions"` // nil means unlimited
+	AllowResubmitAfterGrading bool `json:"allowResubmitAfterGrading" gorm:"column:allow_resubmit_after_grading;default:false"`
+
+	// Group settings
+	GroupMode    string `json:"groupMode" gorm:"column:group_mode;default:'none'"`
+	MaxGroupSize *int   `json:"maxGroupSize,omitempty" gorm:"column:max_group_size"` // nil means no limit
+
+	// Peer review settings, peer review is off when PeerReviewCount is 0
+	PeerReviewCount      int        `json:"peerReviewCount" gorm:"column:peer_review_count;default:0"`
+	PeerReviewPrompts    []string   `json:"peerReviewPrompts,omitempty" gorm:"column:peer_review_prompts;serializer:json"`
+	PeerReviewWeight     int        `json:"peerReviewWeight" gorm:"column:peer_review_weight;default:0"`
+	PeerReviewAssignedAt *time.Time `json:"peerReviewAssignedAt,omitempty" gorm:"column:peer_review_assigned_at"`
+
+	// Quiz settings, only used when AssignmentType is "quiz"
+	AssignmentType   string `json:"assignmentType" gorm:"column:assignment_type;default:'standard'"`
+	TimeLimitMinutes *int   `json:"timeLimitMinutes,omitempty" gorm:"column:time_limit_minutes"`
+	MaxAttempts      *int   `json:"maxAttempts,omitempty" gorm:"column:max_attempts"` // nil means unlimited
+	ShuffleQuestions bool   `json:"shuffleQuestions" gorm:"column:shuffle_questions;default:false"`
+	ShuffleOptions   bool   `json:"shuffleOptions" gorm:"column:shuffle_options;default:false"`
 }
 
 // TableName specifies the table name for the Assignment model
@@ -35,14 +67,71 @@ type AssignmentResponse struct {
 	CreatedBy       int       `json:"createdBy"`
 	CreatedAt       time.Time `json:"createdAt"`
 	AllowLateSubmit bool      `json:"allowLateSubmit"`
+	SectionID       *int      `json:"sectionId,omitempty"`
+	AssignmentType  string    `json:"assignmentType"`
+	RubricID        *int      `json:"rubricId,omitempty"`
+
+	GradesReleasedAt *time.Time `json:"gradesReleasedAt,omitempty"`
+
+	AnonymousGrading     bool       `json:"anonymousGrading"`
+	IdentitiesRevealedAt *time.Time `json:"identitiesRevealedAt,omitempty"`
+
+	MaxResubmissions          *int `json:"maxResubmissions,omitempty"`
+	AllowResubmitAfterGrading bool `json:"allowResubmitAfterGrading"`
+
+	GroupMode    string `json:"groupMode"`
+	MaxGroupSize *int   `json:"maxGroupSize,omitempty"`
+
+	PeerReviewCount int `json:"peerReviewCount"`
 
 	// Optional fields for student view
-	Status string `json:"status,omitempty"`
-	Grade  *int   `json:"grade,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	Grade            *int       `json:"grade,omitempty"`
+	EffectiveDueDate *time.Time `json:"effectiveDueDate,omitempty"` // The student's due date after extensions and accommodations
+}
+
+// IsGroupAssignment reports whether the assignment is submitted by groups
+func (a Assignment) IsGroupAssignment() bool {
+	return a.GroupMode == GroupModeTeacher || a.GroupMode == GroupModeSelf
+}
+
+// IdentitiesHidden reports whether graders see pseudonyms instead of students
+func (a Assignment) IdentitiesHidden() bool {
+	return a.AnonymousGrading && a.IdentitiesRevealedAt == nil
+}
+
+// PeerReviewSettings returns the peer review options of the assignment
+func (a Assignment) PeerReviewSettings() PeerReviewSettings {
+	return PeerReviewSettings{
+		ReviewCount:   a.PeerReviewCount,
+		Prompts:       a.PeerReviewPrompts,
+		QualityWeight: a.PeerReviewWeight,
+		AssignedAt:    a.PeerReviewAssignedAt,
+	}
+}
+
+// QuizSettings returns the quiz options of the assignment
+func (a Assignment) QuizSettings() QuizSettings {
+	return QuizSettings{
+		TimeLimitMinutes: a.TimeLimitMinutes,
+		MaxAttempts:      a.MaxAttempts,
+		ShuffleQuestions: a.ShuffleQuestions,
+		ShuffleOptions:   a.ShuffleOptions,
+	}
 }
 
 // ToResponse converts an Assignment to an AssignmentResponse
 func (a Assignment) ToResponse() AssignmentResponse {
+	assignmentType := a.AssignmentType
+	if assignmentType == "" {
+		assignmentType = AssignmentTypeStandard
+	}
+
+	groupMode := a.GroupMode
+	if groupMode == "" {
+		groupMode = GroupModeNone
+	}
+
 	return AssignmentResponse{
 		AssignmentID:    a.AssignmentID,
 		ClassID:         a.ClassID,
@@ -54,5 +143,21 @@ func (a Assignment) ToResponse() AssignmentResponse {
 		CreatedBy:       a.CreatedBy,
 		CreatedAt:       a.CreatedAt,
 		AllowLateSubmit: a.AllowLateSubmit,
+		SectionID:       a.SectionID,
+		AssignmentType:  assignmentType,
+		RubricID:        a.RubricID,
+
+		GradesReleasedAt: a.GradesReleasedAt,
+
+		AnonymousGrading:     a.AnonymousGrading,
+		IdentitiesRevealedAt: a.IdentitiesRevealedAt,
+
+		MaxResubmissions:          a.MaxResubmissions,
+		AllowResubmitAfterGrading: a.AllowResubmitAfterGrading,
+
+		GroupMode:    groupMode,
+		MaxGroupSize: a.MaxGroupSize,
+
+		PeerReviewCount: a.PeerReviewCount,
 	}
 }
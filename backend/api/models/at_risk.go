@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+)
+
+// At-risk rule types stored in at_risk_rules.rule_type. The threshold of a
+// rule is a number of assignments, a percentage or a number of days.
+const (
+	AtRiskRuleMissedInARow = "missed_in_a_row" // Missed at least Threshold assignments in a row
+	AtRiskRuleAverageBelow = "average_below"   // Average released grade below Threshold percent
+	AtRiskRuleInactiveDays = "inactive_days"   // No submission, chat message or attendance for Threshold days
+	AtRiskRuleAbsenceRate  = "absence_rate"    // Absent from at least Threshold percent of recorded sessions
+)
+
+// NotificationTypeAtRisk is the type of notifications about students newly
+// flagged as at risk
+const NotificationTypeAtRisk = "at_risk"
+
+// IsValidAtRiskRuleType reports whether ruleType is a known at-risk rule type
+func IsValidAtRiskRuleType(ruleType string) bool {
+	switch ruleType {
+	case AtRiskRuleMissedInARow, AtRiskRuleAverageBelow, AtRiskRuleInactiveDays, AtRiskRuleAbsenceRate:
+		return true
+	}
+	return false
+}
+
+// AtRiskRule is a condition that flags a student of a class as at risk
+type AtRiskRule struct {
+	RuleID          int       `gorm:"column:rule_id;primaryKey;autoIncrement" json:"ruleId"`
+	ClassID         int       `gorm:"column:class_id;not null" json:"classId"`
+	Name            string    `gorm:"column:name;not null" json:"name"`
+	RuleType        string    `gorm:"column:rule_type;not null" json:"ruleType"`
+	Threshold       float64   `gorm:"column:threshold;not null" json:"threshold"`
+	IsEnabled       bool      `gorm:"column:is_enabled;not null" json:"isEnabled"`
+	NotifyTeachers  bool      `gorm:"column:notify_teachers;not null" json:"notifyTeachers"`
+	NotifyGuardians bool      `gorm:"column:notify_guardians;not null" json:"notifyGuardians"`
+	CreatedBy       int       `gorm:"column:created_by;not null" json:"createdBy"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for the AtRiskRule model
+func (AtRiskRule) TableName() string {
+	return "at_risk_rules"
+}
+
+// AtRiskFlag records that a rule flagged a student. The flag stays active
+// while the rule keeps matching and is resolved once it no longer does.
+type AtRiskFlag struct {
+	FlagID          int        `gorm:"column:flag_id;primaryKey;autoIncrement" json:"flagId"`
+	ClassID         int        `gorm:"column:class_id;not null" json:"classId"`
+	StudentID       int        `gorm:"column:student_id;not null" json:"studentId"`
+	RuleID          int        `gorm:"column:rule_id;not null" json:"ruleId"`
+	Detail          string     `gorm:"column:detail" json:"detail"` // Why the rule matched, e.g. "Missed 3 assignments in a row"
+	FlaggedAt       time.Time  `gorm:"column:flagged_at;not null" json:"flaggedAt"`
+	LastEvaluatedAt time.Time  `gorm:"column:last_evaluated_at;not null" json:"lastEvaluatedAt"`
+	ResolvedAt      *time.Time `gorm:"column:resolved_at" json:"resolvedAt,omitempty"`
+
+	// Virtual fields (not stored in database)
+	RuleName string `gorm:"-" json:"ruleName,omitempty"`
+	RuleType string `gorm:"-" json:"ruleType,omitempty"`
+}
+
+// TableName specifies the table name for the AtRiskFlag model
+func (AtRiskFlag) TableName() string {
+	return "at_risk_flags"
+}
+
+// AtRiskStudent is a student with active at-risk flags on the dashboard
+type AtRiskStudent struct {
+	StudentID   int          `json:"studentId"`
+	StudentName string       `json:"studentName"`
+	Flags       []AtRiskFlag `json:"flags"`
+}
+
+// AtRiskEvaluation summarizes a run of a class's at-risk rules
+type AtRiskEvaluation struct {
+	ClassID     int       `json:"classId"`
+	Rules       int       `json:"rules"`
+	Flagged     int       `json:"flagged"`  // Active flags after the run
+	NewFlags    int       `json:"newFlags"` // Flags raised by this run
+	Resolved    int       `json:"resolved"` // Flags resolved by this run
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+}
+
+// Guardian is a parent or guardian of a student who can receive at-risk
+// alerts by email. Guardians do not have accounts.
+type Guardian struct {
+	GuardianID     int       `gorm:"column:guardian_id;primaryKey;autoIncrement" json:"guardianId"`
+	StudentID      int       `gorm:"column:student_id;not null" json:"studentId"`
+	Name           string    `gorm:"column:name;not null" json:"name"`
+	Email          string    `gorm:"column:email;not null" json:"email"`
+	Relationship   string    `gorm:"column:relationship" json:"relationship,omitempty"`
+	ReceivesAlerts bool      `gorm:"column:receives_alerts;not null" json:"receivesAlerts"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the Guardian model
+func (Guardian) TableName() string {
+	return "student_guardians"
+}
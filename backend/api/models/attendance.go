@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+)
+
+// Attendance statuses stored in attendance_records.status
+const (
+	AttendanceStatusPresent = "present"
+	AttendanceStatusAbsent  = "absent"
+	AttendanceStatusLate    = "late"
+	AttendanceStatusExcused = "excused"
+)
+
+// IsValidAttendanceStatus reports whether status is a known attendance status
+func IsValidAttendanceStatus(status string) bool {
+	switch status {
+	case AttendanceStatusPresent, AttendanceStatusAbsent, AttendanceStatusLate, AttendanceStatusExcused:
+		return true
+	}
+	return false
+}
+
+// ClassSchedule is a weekly meeting time of a class. Creating a schedule
+// generates a ClassSession for every matching day between StartDate and EndDate.
+type ClassSchedule struct {
+	ScheduleID int       `gorm:"column:schedule_id;primaryKey;autoIncrement" json:"scheduleId"`
+	ClassID    int       `gorm:"column:class_id;not null" json:"classId"`
+	DayOfWeek  int       `gorm:"column:day_of_week;not null" json:"dayOfWeek"` // 0 = Sunday
+	StartTime  string    `gorm:"column:start_time;not null" json:"startTime"`  // HH:MM
+	EndTime    string    `gorm:"column:end_time;not null" json:"endTime"`      // HH:MM
+	StartDate  time.Time `gorm:"column:start_date;not null" json:"startDate"`
+	EndDate    time.Time `gorm:"column:end_date;not null" json:"endDate"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the ClassSchedule model
+func (ClassSchedule) TableName() string {
+	return "class_schedules"
+}
+
+// ClassSession is a single meeting of a class that attendance is taken for
+type ClassSession struct {
+	SessionID     int       `gorm:"column:session_id;primaryKey;autoIncrement" json:"sessionId"`
+	ClassID       int       `gorm:"column:class_id;not null" json:"classId"`
+	ScheduleID    *int      `gorm:"column:schedule_id" json:"scheduleId,omitempty"` // nil for one-off sessions
+	StartTime     time.Time `gorm:"column:start_time;not null" json:"startTime"`
+	EndTime       time.Time `gorm:"column:end_time;not null" json:"endTime"`
+	CheckInSecret string    `gorm:"column:check_in_secret;not null" json:"-"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the ClassSession model
+func (ClassSession) TableName() string {
+	return "class_sessions"
+}
+
+// AttendanceRecord is the attendance of one student at one session
+type AttendanceRecord struct {
+	RecordID    int        `gorm:"column:record_id;primaryKey;autoIncrement" json:"recordId"`
+	SessionID   int        `gorm:"column:session_id;not null" json:"sessionId"`
+	StudentID   int        `gorm:"column:student_id;not null" json:"studentId"`
+	Status      string     `gorm:"column:status;not null" json:"status"`
+	Note        string     `gorm:"column:note" json:"note,omitempty"`
+	CheckedInAt *time.Time `gorm:"column:checked_in_at" json:"checkedInAt,omitempty"` // Set when the student checked in themselves
+	MarkedBy    *int       `gorm:"column:marked_by" json:"markedBy,omitempty"`        // Teacher who last changed the record
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for the AttendanceRecord model
+func (AttendanceRecord) TableName() string {
+	return "attendance_records"
+}
+
+// SessionAttendanceResponse is a student's row on a session's attendance sheet
+type SessionAttendanceResponse struct {
+	StudentID   int        `json:"studentId"`
+	FirstName   string     `json:"firstName"`
+	LastName    string     `json:"lastName"`
+	Status      string     `json:"status,omitempty"` // Empty when attendance has not been taken
+	Note        string     `json:"note,omitempty"`
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty"`
+}
+
+// AttendanceSummary counts a student's attendance over the sessions held so far
+type AttendanceSummary struct {
+	StudentID      int     `json:"studentId"`
+	FirstName      string  `json:"firstName"`
+	LastName       string  `json:"lastName"`
+	Sessions       int     `json:"sessions"`
+	Present        int     `json:"present"`
+	Late           int     `json:"late"`
+	Absent         int     `json:"absent"`
+	Excused        int     `json:"excused"`
+	Unmarked       int     `json:"unmarked"`
+	AttendanceRate float64 `json:"attendanceRate"` // Present or late, out of the sessions not excused
+}
+
+// Add counts one session with the given status
+func (s *AttendanceSummary) Add(status string) {
+	s.Sessions++
+	switch status {
+	case AttendanceStatusPresent:
+		s.Present++
+	case AttendanceStatusLate:
+		s.Late++
+	case AttendanceStatusAbsent:
+		s.Absent++
+	case AttendanceStatusExcused:
+		s.Excused++
+	default:
+		s.Unmarked++
+	}
+
+	if counted := s.Sessions - s.Excused; counted > 0 {
+		s.AttendanceRate = float64(s.Present+s.Late) / float64(counted)
+	} else {
+		s.AttendanceRate = 0
+	}
+}
+
+// StudentAttendanceReport lists a student's attendance at each session of a class
+type StudentAttendanceReport struct {
+	ClassID  int                        `json:"classId"`
+	Summary  AttendanceSummary          `json:"summary"`
+	Sessions []StudentSessionAttendance `json:"sessions"`
+}
+
+// StudentSessionAttendance is a student's attendance at a single session
+type StudentSessionAttendance struct {
+	SessionID   int        `json:"sessionId"`
+	StartTime   time.Time  `json:"startTime"`
+	EndTime     time.Time  `json:"endTime"`
+	Status      string     `json:"status,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty"`
+}
+
+// ClassAttendanceReport summarizes attendance for every student in a class
+type ClassAttendanceReport struct {
+	ClassID  int                 `json:"classId"`
+	Sessions int                 `json:"sessions"`
+	Students []AttendanceSummary `json:"students"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Calendar event types
+const (
+	CalendarEventAssignment   = "assignment"
+	CalendarEventAnnouncement = "announcement"
+	CalendarEventSession      = "session"
+)
+
+// CalendarEvent is a dated item from one of a user's classes
+type CalendarEvent struct {
+	ID          string    `json:"id"` // Unique across event types, e.g. "assignment-12"
+	Type        string    `json:"type"`
+	ClassID     int       `json:"classId"`
+	ClassName   string    `json:"className"`
+	ItemID      int       `json:"itemId"` // Assignment, announcement or session ID
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// CalendarFeedToken is the secret that gives access to a user's iCalendar feed
+type CalendarFeedToken struct {
+	TokenID   int       `gorm:"column:token_id;primaryKey;autoIncrement" json:"-"`
+	UserID    int       `gorm:"column:user_id;not null;unique" json:"userId"`
+	Token     string    `gorm:"column:token;not null;unique" json:"token"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the CalendarFeedToken model
+func (CalendarFeedToken) TableName() string {
+	return "calendar_feed_tokens"
+}
@@ -16,6 +16,13 @@ type Class struct {
 	ThemeColor  string    `gorm:"column:theme_color" json:"themeColor,omitempty"`
 	CreatorID   int       `gorm:"column:creator_id;not null" json:"creatorId"`
 	Creator     User      `gorm:"foreignKey:CreatorID;references:UserID" json:"creator,omitempty"`
+
+	// Enrollment settings
+	RequiresApproval bool `gorm:"column:requires_approval;not null;default:0" json:"requiresApproval"`
+	MaxCapacity      *int `gorm:"column:max_capacity" json:"maxCapacity,omitempty"` // nil means unlimited
+
+	// Academic term the class belongs to
+	TermID *int `gorm:"column:term_id" json:"termId,omitempty"`
 }
 
 // TableName specifies the table name for Class model
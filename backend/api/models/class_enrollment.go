@@ -4,6 +4,15 @@ import (
 	"time"
 )
 
+// Enrollment statuses stored in class_enrollments.status
+const (
+	EnrollmentStatusActive     = "active"
+	EnrollmentStatusPending    = "pending"
+	EnrollmentStatusWaitlisted = "waitlisted"
+	EnrollmentStatusRejected   = "rejected"
+	EnrollmentStatusRemoved    = "removed"
+)
+
 // ClassEnrollment represents the class_enrollments table
 type ClassEnrollment struct {
 	EnrollmentID   int       `gorm:"column:enrollment_id;primaryKey;autoIncrement" json:"enrollmentId"`
@@ -11,6 +20,8 @@ type ClassEnrollment struct {
 	ClassID        int       `gorm:"column:class_id;not null" json:"classId"`
 	EnrollmentDate time.Time `gorm:"column:enrollment_date;not null;default:CURRENT_TIMESTAMP" json:"enrollmentDate"`
 	IsActive       bool      `gorm:"column:is_active;not null;default:1" json:"isActive"`
+	Status         string    `gorm:"column:status;not null;default:'active'" json:"status"`
+	SectionID      *int      `gorm:"column:section_id" json:"sectionId,omitempty"`
 	User           User      `gorm:"foreignKey:UserID;references:UserID" json:"user,omitempty"`
 	Class          Class     `gorm:"foreignKey:ClassID;references:ClassID" json:"class,omitempty"`
 }
@@ -19,3 +30,17 @@ type ClassEnrollment struct {
 func (ClassEnrollment) TableName() string {
 	return "class_enrollments"
 }
+
+// EnrollmentResponse represents the response format for enrollment requests
+type EnrollmentResponse struct {
+	EnrollmentID     int       `json:"enrollmentId"`
+	ClassID          int       `json:"classId"`
+	StudentID        int       `json:"studentId"`
+	FirstName        string    `json:"firstName"`
+	LastName         string    `json:"lastName"`
+	Email            string    `json:"email"`
+	Status           string    `json:"status"`
+	EnrollmentDate   time.Time `json:"enrollmentDate"`
+	SectionID        *int      `json:"sectionId,omitempty"`
+	WaitlistPosition int       `json:"waitlistPosition,omitempty"`
+}
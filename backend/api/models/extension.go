@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// AssignmentExtension moves the due date of one assignment for one student
+type AssignmentExtension struct {
+	ExtensionID  int       `gorm:"column:extension_id;primaryKey;autoIncrement" json:"extensionId"`
+	AssignmentID int       `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	StudentID    int       `gorm:"column:student_id;not null" json:"studentId"`
+	DueDate      time.Time `gorm:"column:due_date;not null" json:"dueDate"`
+	Reason       string    `gorm:"column:reason" json:"reason,omitempty"`
+	GrantedBy    int       `gorm:"column:granted_by;not null" json:"grantedBy"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the AssignmentExtension model
+func (AssignmentExtension) TableName() string {
+	return "assignment_extensions"
+}
+
+// Accommodation is a standing adjustment for a student in a class that
+// applies to every assignment: extra days on due dates and extra time on
+// timed quizzes
+type Accommodation struct {
+	AccommodationID  int       `gorm:"column:accommodation_id;primaryKey;autoIncrement" json:"accommodationId"`
+	ClassID          int       `gorm:"column:class_id;not null" json:"classId"`
+	StudentID        int       `gorm:"column:student_id;not null" json:"studentId"`
+	ExtraDays        int       `gorm:"column:extra_days;not null;default:0" json:"extraDays"`
+	ExtraTimePercent int       `gorm:"column:extra_time_percent;not null;default:0" json:"extraTimePercent"` // 50 gives 50% more time on quizzes
+	Note             string    `gorm:"column:note" json:"note,omitempty"`
+	CreatedBy        int       `gorm:"column:created_by;not null" json:"createdBy"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for the Accommodation model
+func (Accommodation) TableName() string {
+	return "class_accommodations"
+}
+
+// ApplyToDueDate returns the due date moved by the accommodation's extra days
+func (a Accommodation) ApplyToDueDate(dueDate time.Time) time.Time {
+	if dueDate.IsZero() {
+		return dueDate
+	}
+	return dueDate.AddDate(0, 0, a.ExtraDays)
+}
+
+// ApplyToTimeLimit returns the time limit extended by the accommodation's extra time
+func (a Accommodation) ApplyToTimeLimit(limit time.Duration) time.Duration {
+	return limit + limit*time.Duration(a.ExtraTimePercent)/100
+}
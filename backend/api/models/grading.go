@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// GradeRelease summarizes the release of an assignment's grades: graded
+// submissions are either released to their students or pending release
+type GradeRelease struct {
+	AssignmentID int        `json:"assignmentId"`
+	Graded       int        `json:"graded"`
+	Released     int        `json:"released"`
+	Pending      int        `json:"pending"`
+	ReleasedNow  int        `json:"releasedNow,omitempty"` // Grades released by the current request
+	ReleasedAt   *time.Time `json:"releasedAt,omitempty"`
+}
+
+// Row statuses reported by a grade import
+const (
+	GradeRowGraded    = "graded"
+	GradeRowUnchanged = "unchanged"
+	GradeRowSkipped   = "skipped"
+	GradeRowError     = "error"
+)
+
+// GradeImportRow is the outcome of a single row of a grades CSV
+type GradeImportRow struct {
+	Row           int    `json:"row"`
+	StudentID     int    `json:"studentId,omitempty"`
+	AnonymousID   string `json:"anonymousId,omitempty"` // Set instead of the student ID while identities are hidden
+	Email         string `json:"email,omitempty"`
+	StudentName   string `json:"studentName,omitempty"`
+	Grade         *int   `json:"grade,omitempty"`
+	PreviousGrade *int   `json:"previousGrade,omitempty"`
+	Feedback      string `json:"feedback,omitempty"`
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+}
+
+// GradeImportReport summarizes a grade import. Grades are applied only when
+// no row has an error, and never in a dry run.
+type GradeImportReport struct {
+	AssignmentID int              `json:"assignmentId"`
+	DryRun       bool             `json:"dryRun"`
+	Applied      bool             `json:"applied"`
+	Graded       int              `json:"graded"`
+	Unchanged    int              `json:"unchanged"`
+	Skipped      int              `json:"skipped"`
+	Errors       int              `json:"errors"`
+	Rows         []GradeImportRow `json:"rows"`
+}
+
+// AddRow appends a row to the report and updates the totals
+func (r *GradeImportReport) AddRow(row GradeImportRow) {
+	switch row.Status {
+	case GradeRowGraded:
+		r.Graded++
+	case GradeRowUnchanged:
+		r.Unchanged++
+	case GradeRowSkipped:
+		r.Skipped++
+	case GradeRowError:
+		r.Errors++
+	}
+	r.Rows = append(r.Rows, row)
+}
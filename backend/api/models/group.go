@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Group modes stored in assignments.group_mode
+const (
+	GroupModeNone    = "none"    // Individual work
+	GroupModeTeacher = "teacher" // Groups are set up by the teacher
+	GroupModeSelf    = "self"    // Students form and join groups themselves
+)
+
+// IsValidGroupMode reports whether mode is a known group mode
+func IsValidGroupMode(mode string) bool {
+	switch mode {
+	case GroupModeNone, GroupModeTeacher, GroupModeSelf:
+		return true
+	}
+	return false
+}
+
+// AssignmentGroup is a team of students that submits one assignment together
+type AssignmentGroup struct {
+	GroupID      int       `gorm:"column:group_id;primaryKey;autoIncrement" json:"groupId"`
+	AssignmentID int       `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	Name         string    `gorm:"column:name;not null" json:"name"`
+	CreatedBy    int       `gorm:"column:created_by;not null" json:"createdBy"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+
+	Members []AssignmentGroupMember `gorm:"-" json:"members"`
+}
+
+// TableName specifies the table name for the AssignmentGroup model
+func (AssignmentGroup) TableName() string {
+	return "assignment_groups"
+}
+
+// MemberIDs returns the user IDs of the group's members
+func (g AssignmentGroup) MemberIDs() []int {
+	ids := make([]int, 0, len(g.Members))
+	for _, member := range g.Members {
+		ids = append(ids, member.StudentID)
+	}
+	return ids
+}
+
+// AssignmentGroupMember is a student's membership in a group. A student is
+// in at most one group per assignment.
+type AssignmentGroupMember struct {
+	MemberID     int       `gorm:"column:member_id;primaryKey;autoIncrement" json:"-"`
+	GroupID      int       `gorm:"column:group_id;not null" json:"-"`
+	AssignmentID int       `gorm:"column:assignment_id;not null" json:"-"`
+	StudentID    int       `gorm:"column:student_id;not null" json:"studentId"`
+	JoinedAt     time.Time `gorm:"column:joined_at;autoCreateTime" json:"joinedAt"`
+
+	StudentName string `gorm:"-" json:"studentName"`
+}
+
+// TableName specifies the table name for the AssignmentGroupMember model
+func (AssignmentGroupMember) TableName() string {
+	return "assignment_group_members"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types stored in notifications.type
+const (
+	NotificationTypeGradeReleased = "grade_released"
+)
+
+// Notification is an in-app message to a user about something that happened
+// in one of their classes
+type Notification struct {
+	NotificationID int        `gorm:"column:notification_id;primaryKey;autoIncrement" json:"notificationId"`
+	UserID         int        `gorm:"column:user_id;not null" json:"userId"`
+	Type           string     `gorm:"column:type;not null" json:"type"`
+	Title          string     `gorm:"column:title;not null" json:"title"`
+	Message        string     `gorm:"column:message" json:"message"`
+	ClassID        *int       `gorm:"column:class_id" json:"classId,omitempty"`
+	AssignmentID   *int       `gorm:"column:assignment_id" json:"assignmentId,omitempty"`
+	IsRead         bool       `gorm:"column:is_read;not null;default:0" json:"isRead"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	ReadAt         *time.Time `gorm:"column:read_at" json:"readAt,omitempty"`
+}
+
+// TableName specifies the table name for the Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// OneRoster entity types stored in oneroster_mappings.entity_type
+const (
+	OneRosterEntityClass      = "class"
+	OneRosterEntityUser       = "user"
+	OneRosterEntityEnrollment = "enrollment"
+	OneRosterEntityLineItem   = "lineItem"
+	OneRosterEntityTerm       = "term"
+)
+
+// OneRosterMapping links a OneRoster sourcedId to a local record so that
+// repeated imports update existing rows instead of creating duplicates
+type OneRosterMapping struct {
+	MappingID  int       `gorm:"column:mapping_id;primaryKey;autoIncrement" json:"mappingId"`
+	EntityType string    `gorm:"column:entity_type;not null" json:"entityType"`
+	SourcedID  string    `gorm:"column:sourced_id;not null" json:"sourcedId"`
+	LocalID    int       `gorm:"column:local_id;not null" json:"localId"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for the OneRosterMapping model
+func (OneRosterMapping) TableName() string {
+	return "oneroster_mappings"
+}
+
+// OneRosterFileReport summarizes the import of a single OneRoster CSV file
+type OneRosterFileReport struct {
+	File     string   `json:"file"`
+	Rows     int      `json:"rows"`
+	Created  int      `json:"created"`
+	Updated  int      `json:"updated"`
+	Skipped  int      `json:"skipped"`
+	Errors   int      `json:"errors"`
+	Messages []string `json:"messages,omitempty"`
+}
+
+// OneRosterImportReport summarizes a OneRoster bundle import
+type OneRosterImportReport struct {
+	DryRun bool                   `json:"dryRun"`
+	Files  []*OneRosterFileReport `json:"files"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+)
+
+// Peer review statuses stored in peer_reviews.status
+const (
+	PeerReviewStatusAssigned  = "assigned"
+	PeerReviewStatusCompleted = "completed"
+)
+
+// PeerReviewSettings are the peer review options of an assignment
+type PeerReviewSettings struct {
+	ReviewCount   int        `json:"reviewCount"`          // Reviews per submission, 0 turns peer review off
+	Prompts       []string   `json:"prompts,omitempty"`    // Questions reviewers answer when the assignment has no rubric
+	QualityWeight int        `json:"qualityWeight"`        // Percentage of the reviewer's grade that comes from review quality
+	AssignedAt    *time.Time `json:"assignedAt,omitempty"` // When reviewers were assigned
+}
+
+// PeerReviewAnswer is a reviewer's answer to one prompt
+type PeerReviewAnswer struct {
+	Prompt string `json:"prompt"`
+	Answer string `json:"answer"`
+}
+
+// PeerReviewScore is the level a reviewer picked for one rubric criterion
+type PeerReviewScore struct {
+	CriterionID int    `json:"criterionId"`
+	LevelID     int    `json:"levelId"`
+	Points      int    `json:"points"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// PeerReview is one student's review of a classmate's submission. Reviews
+// are anonymous: students never see who wrote or received a review.
+type PeerReview struct {
+	ReviewID     int                `gorm:"column:review_id;primaryKey;autoIncrement" json:"reviewId"`
+	AssignmentID int                `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	SubmissionID int                `gorm:"column:submission_id;not null" json:"submissionId"`
+	ReviewerID   int                `gorm:"column:reviewer_id;not null" json:"reviewerId"`
+	Status       string             `gorm:"column:status;not null;default:'assigned'" json:"status"`
+	Answers      []PeerReviewAnswer `gorm:"column:answers;serializer:json" json:"answers,omitempty"`
+	RubricScores []PeerReviewScore  `gorm:"column:rubric_scores;serializer:json" json:"rubricScores,omitempty"`
+	Points       *int               `gorm:"column:points" json:"points,omitempty"` // Rubric total scaled to the assignment's points
+	Comment      string             `gorm:"column:comment" json:"comment,omitempty"`
+	QualityScore *int               `gorm:"column:quality_score" json:"qualityScore,omitempty"` // 0-100, set by the teacher
+	AssignedAt   time.Time          `gorm:"column:assigned_at;not null" json:"assignedAt"`
+	CompletedAt  *time.Time         `gorm:"column:completed_at" json:"completedAt,omitempty"`
+
+	// Submission under review, only filled in for the reviewer
+	Content string `gorm:"-" json:"content,omitempty"`
+	FileURL string `gorm:"-" json:"fileURL,omitempty"`
+}
+
+// TableName specifies the table name for the PeerReview model
+func (PeerReview) TableName() string {
+	return "peer_reviews"
+}
+
+// Anonymous returns a copy of the review without the reviewer and the
+// reviewed submission, for showing it to students
+func (r PeerReview) Anonymous() PeerReview {
+	r.ReviewerID = 0
+	r.SubmissionID = 0
+	r.QualityScore = nil
+	return r
+}
+
+// PeerReviewSummary describes a student's part in the peer review of an assignment
+type PeerReviewSummary struct {
+	Assigned       int      `json:"assigned"`                 // Reviews the student has to write
+	Completed      int      `json:"completed"`                // Reviews the student has written
+	Received       int      `json:"received"`                 // Completed reviews of the student's submission
+	Unrated        int      `json:"unrated"`                  // Written reviews the teacher has not rated yet
+	AverageQuality *float64 `json:"averageQuality,omitempty"` // Quality of the student's rated and unwritten reviews, 0-100
+}
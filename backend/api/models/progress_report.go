@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// Statuses of an assignment on a progress report
+const (
+	ReportAssignmentGraded    = "graded"
+	ReportAssignmentSubmitted = "submitted" // Not graded yet, or the grade is not released
+	ReportAssignmentMissing   = "missing"
+	ReportAssignmentUpcoming  = "upcoming"
+)
+
+// ProgressReport is a student's progress in a class, or in their classes of
+// a term. Only released grades and feedback are included.
+type ProgressReport struct {
+	StudentID   int             `json:"studentId"`
+	StudentName string          `json:"studentName"`
+	Title       string          `json:"title"` // The class name, or the term name for term reports
+	TermName    string          `json:"termName,omitempty"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Classes     []ClassProgress `json:"classes"`
+}
+
+// ClassProgress is the part of a progress report about one class
+type ClassProgress struct {
+	ClassID        int                `json:"classId"`
+	ClassName      string             `json:"className"`
+	Teachers       []string           `json:"teachers"`
+	Assigned       int                `json:"assigned"`
+	Submitted      int                `json:"submitted"`
+	Late           int                `json:"late"`
+	Missing        int                `json:"missing"`
+	AveragePercent *float64           `json:"averagePercent,omitempty"` // Average of the released grades
+	Assignments    []ReportAssignment `json:"assignments"`
+	Attendance     ReportAttendance   `json:"attendance"`
+	Comments       []ReportComment    `json:"comments"`
+}
+
+// ReportAssignment is an assignment on a progress report
+type ReportAssignment struct {
+	AssignmentID   int        `json:"assignmentId"`
+	Title          string     `json:"title"`
+	DueDate        time.Time  `json:"dueDate"` // The student's own due date, after extensions and accommodations
+	PointsPossible int        `json:"pointsPossible"`
+	Status         string     `json:"status"`
+	SubmittedAt    *time.Time `json:"submittedAt,omitempty"`
+	IsLate         bool       `json:"isLate"`
+	Grade          *int       `json:"grade,omitempty"`
+}
+
+// ReportAttendance counts a student's attendance records in a class
+type ReportAttendance struct {
+	Sessions int `json:"sessions"`
+	Present  int `json:"present"`
+	Late     int `json:"late"`
+	Absent   int `json:"absent"`
+	Excused  int `json:"excused"`
+}
+
+// ReportComment is a teacher's feedback on a graded submission or a
+// teacher's comment in a submission's thread
+type ReportComment struct {
+	AssignmentTitle string    `json:"assignmentTitle"`
+	Author          string    `json:"author"`
+	Body            string    `json:"body"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
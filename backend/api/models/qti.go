@@ -0,0 +1,27 @@
+package models
+
+// Outcome of converting a single QTI item
+const (
+	QTIItemImported  = "imported"
+	QTIItemConverted = "converted" // imported, but parts of the item were changed or dropped
+	QTIItemSkipped   = "skipped"
+)
+
+// QTIItemReport describes how a single QTI item was converted
+type QTIItemReport struct {
+	File         string   `json:"file"`
+	Identifier   string   `json:"identifier,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	Status       string   `json:"status"`
+	QuestionType string   `json:"questionType,omitempty"`
+	Messages     []string `json:"messages,omitempty"`
+}
+
+// QTIImportReport summarizes the import of a QTI package into a quiz
+type QTIImportReport struct {
+	DryRun   bool            `json:"dryRun"`
+	Version  string          `json:"version"`
+	Imported int             `json:"imported"`
+	Skipped  int             `json:"skipped"`
+	Items    []QTIItemReport `json:"items"`
+}
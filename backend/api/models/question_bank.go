@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+// Question difficulties stored in bank_questions.difficulty
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// QuestionBank is a teacher's collection of reusable quiz questions
+type QuestionBank struct {
+	BankID        int            `gorm:"column:bank_id;primaryKey;autoIncrement" json:"bankId"`
+	OwnerID       int            `gorm:"column:owner_id;not null" json:"ownerId"`
+	Name          string         `gorm:"column:name;not null" json:"name"`
+	Description   string         `gorm:"column:description" json:"description"`
+	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+	QuestionCount int            `gorm:"-" json:"questionCount"`
+	Questions     []BankQuestion `gorm:"-" json:"questions,omitempty"`
+}
+
+// TableName specifies the table name for the QuestionBank model
+func (QuestionBank) TableName() string {
+	return "question_banks"
+}
+
+// BankQuestion is a question in a question bank
+type BankQuestion struct {
+	BankQuestionID int          `gorm:"column:bank_question_id;primaryKey;autoIncrement" json:"bankQuestionId"`
+	BankID         int          `gorm:"column:bank_id;not null" json:"bankId"`
+	QuestionType   string       `gorm:"column:question_type;not null" json:"questionType"`
+	Prompt         string       `gorm:"column:prompt;not null" json:"prompt"`
+	Points         float64      `gorm:"column:points;not null" json:"points"`
+	Options        []QuizOption `gorm:"column:options;serializer:json" json:"options,omitempty"`
+	CorrectAnswers []string     `gorm:"column:correct_answers;serializer:json" json:"correctAnswers,omitempty"`
+	Tolerance      float64      `gorm:"column:tolerance;not null;default:0" json:"tolerance,omitempty"`
+	CaseSensitive  bool         `gorm:"column:case_sensitive;not null;default:0" json:"caseSensitive,omitempty"`
+	Tags           []string     `gorm:"column:tags;serializer:json" json:"tags"`
+	Difficulty     string       `gorm:"column:difficulty;not null;default:'medium'" json:"difficulty"`
+	CreatedAt      time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time    `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for the BankQuestion model
+func (BankQuestion) TableName() string {
+	return "bank_questions"
+}
+
+// HasTags reports whether the question has all of the given tags
+func (q BankQuestion) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, own := range q.Tags {
+			if own == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// QuizQuestion returns the question as a quiz question
+func (q BankQuestion) QuizQuestion() QuizQuestion {
+	return QuizQuestion{
+		QuestionType:   q.QuestionType,
+		Prompt:         q.Prompt,
+		Points:         q.Points,
+		Options:        q.Options,
+		CorrectAnswers: q.CorrectAnswers,
+		Tolerance:      q.Tolerance,
+		CaseSensitive:  q.CaseSensitive,
+	}
+}
+
+// QuizPool draws random questions from a question bank into a quiz.
+// Each student gets their own draw the first time they start the quiz.
+type QuizPool struct {
+	PoolID            int      `gorm:"column:pool_id;primaryKey;autoIncrement" json:"poolId"`
+	AssignmentID      int      `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	BankID            int      `gorm:"column:bank_id;not null" json:"bankId"`
+	DrawCount         int      `gorm:"column:draw_count;not null" json:"drawCount"`
+	Tags              []string `gorm:"column:tags;serializer:json" json:"tags,omitempty"`             // Only draw questions with all of these tags
+	Difficulty        string   `gorm:"column:difficulty" json:"difficulty,omitempty"`                 // Only draw questions of this difficulty
+	PointsPerQuestion *float64 `gorm:"column:points_per_question" json:"pointsPerQuestion,omitempty"` // Overrides the points of drawn questions
+}
+
+// TableName specifies the table name for the QuizPool model
+func (QuizPool) TableName() string {
+	return "quiz_pools"
+}
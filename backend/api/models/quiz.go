@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+)
+
+// Assignment types stored in assignments.assignment_type
+const (
+	AssignmentTypeStandard = "standard"
+	AssignmentTypeQuiz     = "quiz"
+)
+
+// Quiz question types stored in quiz_questions.question_type
+const (
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeMultiSelect    = "multi_select"
+	QuestionTypeTrueFalse      = "true_false"
+	QuestionTypeNumeric        = "numeric"
+	QuestionTypeShortAnswer    = "short_answer"
+)
+
+// QuizOption is a choice of a multiple-choice or multi-select question
+type QuizOption struct {
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Correct bool   `json:"correct,omitempty"`
+}
+
+// QuizQuestion is a question of a quiz assignment
+type QuizQuestion struct {
+	QuestionID     int          `gorm:"column:question_id;primaryKey;autoIncrement" json:"questionId"`
+	AssignmentID   int          `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	Position       int          `gorm:"column:position;not null" json:"position"`
+	QuestionType   string       `gorm:"column:question_type;not null" json:"questionType"`
+	Prompt         string       `gorm:"column:prompt;not null" json:"prompt"`
+	Points         float64      `gorm:"column:points;not null" json:"points"`
+	Options        []QuizOption `gorm:"column:options;serializer:json" json:"options,omitempty"`
+	CorrectAnswers []string     `gorm:"column:correct_answers;serializer:json" json:"correctAnswers,omitempty"` // For true/false, numeric and short-answer questions
+	Tolerance      float64      `gorm:"column:tolerance;not null;default:0" json:"tolerance,omitempty"`         // Allowed difference for numeric questions
+	CaseSensitive  bool         `gorm:"column:case_sensitive;not null;default:0" json:"caseSensitive,omitempty"`
+
+	// Set on questions drawn from a question bank for a single student
+	StudentID      *int `gorm:"column:student_id" json:"studentId,omitempty"`
+	PoolID         *int `gorm:"column:pool_id" json:"poolId,omitempty"`
+	BankQuestionID *int `gorm:"column:bank_question_id" json:"bankQuestionId,omitempty"`
+}
+
+// TableName specifies the table name for the QuizQuestion model
+func (QuizQuestion) TableName() string {
+	return "quiz_questions"
+}
+
+// ForStudent returns a copy of the question without its answer key
+func (q QuizQuestion) ForStudent() QuizQuestion {
+	stripped := QuizQuestion{
+		QuestionID:   q.QuestionID,
+		AssignmentID: q.AssignmentID,
+		Position:     q.Position,
+		QuestionType: q.QuestionType,
+		Prompt:       q.Prompt,
+		Points:       q.Points,
+	}
+	for _, option := range q.Options {
+		stripped.Options = append(stripped.Options, QuizOption{ID: option.ID, Text: option.Text})
+	}
+	return stripped
+}
+
+// QuizAnswer is a student's answer to a question. Choice questions use
+// Selected (option IDs), all other question types use Value.
+type QuizAnswer struct {
+	Selected []string `json:"selected,omitempty"`
+	Value    string   `json:"value,omitempty"`
+}
+
+// QuizAttempt is one attempt of a student at a quiz
+type QuizAttempt struct {
+	AttemptID     int                `gorm:"column:attempt_id;primaryKey;autoIncrement" json:"attemptId"`
+	AssignmentID  int                `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	StudentID     int                `gorm:"column:student_id;not null" json:"studentId"`
+	AttemptNumber int                `gorm:"column:attempt_number;not null" json:"attemptNumber"`
+	StartedAt     time.Time          `gorm:"column:started_at;not null" json:"startedAt"`
+	ExpiresAt     *time.Time         `gorm:"column:expires_at" json:"expiresAt,omitempty"` // nil when the quiz has no time limit
+	SubmittedAt   *time.Time         `gorm:"column:submitted_at" json:"submittedAt,omitempty"`
+	QuestionOrder []int              `gorm:"column:question_order;serializer:json" json:"-"`
+	OptionOrder   map[int][]string   `gorm:"column:option_order;serializer:json" json:"-"`
+	Answers       map[int]QuizAnswer `gorm:"column:answers;serializer:json" json:"answers"`
+	Results       map[int]float64    `gorm:"column:results;serializer:json" json:"results,omitempty"` // Points earned per question
+	Score         *float64           `gorm:"column:score" json:"score,omitempty"`
+	MaxScore      float64            `gorm:"column:max_score;not null" json:"maxScore"`
+
+	// Questions in the order shown to the student, without answer keys
+	Questions []QuizQuestion `gorm:"-" json:"questions,omitempty"`
+}
+
+// TableName specifies the table name for the QuizAttempt model
+func (QuizAttempt) TableName() string {
+	return "quiz_attempts"
+}
+
+// QuizSettings are the quiz options of an assignment
+type QuizSettings struct {
+	TimeLimitMinutes *int `json:"timeLimitMinutes,omitempty"`
+	MaxAttempts      *int `json:"maxAttempts,omitempty"`
+	ShuffleQuestions bool `json:"shuffleQuestions"`
+	ShuffleOptions   bool `json:"shuffleOptions"`
+}
+
+// QuizResponse describes a quiz. Questions are only included for teachers.
+// QuestionCount includes the questions drawn from pools; TotalPoints only
+// includes pools with a fixed number of points per question.
+type QuizResponse struct {
+	AssignmentID  int            `json:"assignmentId"`
+	Settings      QuizSettings   `json:"settings"`
+	QuestionCount int            `json:"questionCount"`
+	TotalPoints   float64        `json:"totalPoints"`
+	Questions     []QuizQuestion `json:"questions,omitempty"`
+	Pools         []QuizPool     `json:"pools,omitempty"`
+}
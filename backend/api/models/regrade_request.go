@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Regrade request statuses stored in regrade_requests.status
+const (
+	RegradeRequestPending  = "pending"
+	RegradeRequestAccepted = "accepted"
+	RegradeRequestRejected = "rejected"
+)
+
+// Notification types about regrade requests
+const (
+	NotificationTypeRegradeRequested = "regrade_requested"
+	NotificationTypeRegradeResolved  = "regrade_resolved"
+)
+
+// RegradeRequest is a student's request to have the grade of their submission
+// reviewed. It keeps the grade the student disputed and, once accepted, the
+// grade that replaced it.
+type RegradeRequest struct {
+	RequestID     int        `gorm:"column:request_id;primaryKey;autoIncrement" json:"requestId"`
+	AssignmentID  int        `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	SubmissionID  int        `gorm:"column:submission_id;not null" json:"submissionId"`
+	StudentID     int        `gorm:"column:student_id;not null" json:"studentId"` // Hidden from teachers while the assignment is graded anonymously
+	Reason        string     `gorm:"column:reason;not null" json:"reason"`
+	CriterionIDs  []int      `gorm:"column:criterion_ids;serializer:json" json:"criterionIds,omitempty"` // Rubric criteria the student disputes
+	Status        string     `gorm:"column:status;not null" json:"status"`
+	OriginalGrade int        `gorm:"column:original_grade;not null" json:"originalGrade"` // The grade when the request was opened
+	NewGrade      *int       `gorm:"column:new_grade" json:"newGrade,omitempty"`          // Set when the request is accepted
+	Response      string     `gorm:"column:response" json:"response,omitempty"`           // The teacher's explanation
+	ResolvedBy    *int       `gorm:"column:resolved_by" json:"resolvedBy,omitempty"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	ResolvedAt    *time.Time `gorm:"column:resolved_at" json:"resolvedAt,omitempty"`
+
+	// Virtual fields (not stored in database)
+	StudentName  string `gorm:"-" json:"studentName,omitempty"`
+	AnonymousID  string `gorm:"-" json:"anonymousId,omitempty"`
+	ResolverName string `gorm:"-" json:"resolverName,omitempty"`
+}
+
+// TableName specifies the table name for the RegradeRequest model
+func (RegradeRequest) TableName() string {
+	return "regrade_requests"
+}
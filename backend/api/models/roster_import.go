@@ -0,0 +1,50 @@
+package models
+
+// Row statuses reported by a roster import
+const (
+	RosterRowCreated    = "created"
+	RosterRowEnrolled   = "enrolled"
+	RosterRowWaitlisted = "waitlisted"
+	RosterRowSkipped    = "skipped"
+	RosterRowError      = "error"
+)
+
+// RosterImportRow is the outcome of a single row of a roster CSV
+type RosterImportRow struct {
+	Row       int    `json:"row"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	UserID    int    `json:"userId,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// RosterImportReport summarizes a roster import
+type RosterImportReport struct {
+	ClassID    int               `json:"classId"`
+	DryRun     bool              `json:"dryRun"`
+	Created    int               `json:"created"`
+	Enrolled   int               `json:"enrolled"`
+	Waitlisted int               `json:"waitlisted"`
+	Skipped    int               `json:"skipped"`
+	Errors     int               `json:"errors"`
+	Rows       []RosterImportRow `json:"rows"`
+}
+
+// AddRow appends a row to the report and updates the totals
+func (r *RosterImportReport) AddRow(row RosterImportRow) {
+	switch row.Status {
+	case RosterRowCreated:
+		r.Created++
+	case RosterRowEnrolled:
+		r.Enrolled++
+	case RosterRowWaitlisted:
+		r.Waitlisted++
+	case RosterRowSkipped:
+		r.Skipped++
+	case RosterRowError:
+		r.Errors++
+	}
+	r.Rows = append(r.Rows, row)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+)
+
+// Rubric is a teacher's grading rubric. It can be attached to any number of assignments.
+type Rubric struct {
+	RubricID  int               `gorm:"column:rubric_id;primaryKey;autoIncrement" json:"rubricId"`
+	OwnerID   int               `gorm:"column:owner_id;not null" json:"ownerId"`
+	Title     string            `gorm:"column:title;not null" json:"title"`
+	CreatedAt time.Time         `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time         `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+	Criteria  []RubricCriterion `gorm:"-" json:"criteria"`
+}
+
+// TableName specifies the table name for the Rubric model
+func (Rubric) TableName() string {
+	return "rubrics"
+}
+
+// MaxPoints returns the highest total a submission can score on the rubric
+func (r Rubric) MaxPoints() int {
+	total := 0
+	for _, criterion := range r.Criteria {
+		total += criterion.MaxPoints()
+	}
+	return total
+}
+
+// RubricCriterion is a criterion of a rubric, scored by choosing one of its levels
+type RubricCriterion struct {
+	CriterionID int           `gorm:"column:criterion_id;primaryKey;autoIncrement" json:"criterionId"`
+	RubricID    int           `gorm:"column:rubric_id;not null" json:"rubricId"`
+	Position    int           `gorm:"column:position;not null" json:"position"`
+	Title       string        `gorm:"column:title;not null" json:"title"`
+	Description string        `gorm:"column:description" json:"description"`
+	Levels      []RubricLevel `gorm:"-" json:"levels"`
+}
+
+// TableName specifies the table name for the RubricCriterion model
+func (RubricCriterion) TableName() string {
+	return "rubric_criteria"
+}
+
+// MaxPoints returns the points of the criterion's highest level
+func (c RubricCriterion) MaxPoints() int {
+	max := 0
+	for _, level := range c.Levels {
+		if level.Points > max {
+			max = level.Points
+		}
+	}
+	return max
+}
+
+// RubricLevel is a performance level of a rubric criterion
+type RubricLevel struct {
+	LevelID     int    `gorm:"column:level_id;primaryKey;autoIncrement" json:"levelId"`
+	CriterionID int    `gorm:"column:criterion_id;not null" json:"criterionId"`
+	Position    int    `gorm:"column:position;not null" json:"position"`
+	Title       string `gorm:"column:title;not null" json:"title"`
+	Description string `gorm:"column:description" json:"description"`
+	Points      int    `gorm:"column:points;not null" json:"points"`
+}
+
+// TableName specifies the table name for the RubricLevel model
+func (RubricLevel) TableName() string {
+	return "rubric_levels"
+}
+
+// RubricScore is the level a submission was given for one rubric criterion
+type RubricScore struct {
+	ScoreID      int       `gorm:"column:score_id;primaryKey;autoIncrement" json:"scoreId"`
+	SubmissionID int       `gorm:"column:submission_id;not null" json:"submissionId"`
+	CriterionID  int       `gorm:"column:criterion_id;not null" json:"criterionId"`
+	LevelID      int       `gorm:"column:level_id;not null" json:"levelId"`
+	Points       int       `gorm:"column:points;not null" json:"points"`
+	Comment      string    `gorm:"column:comment" json:"comment"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the RubricScore model
+func (RubricScore) TableName() string {
+	return "rubric_scores"
+}
+
+// RubricScoreResponse describes the score for one criterion in a submission response
+type RubricScoreResponse struct {
+	CriterionID int    `json:"criterionId"`
+	Criterion   string `json:"criterion"`
+	LevelID     int    `json:"levelId"`
+	Level       string `json:"level"`
+	Points      int    `json:"points"`
+	MaxPoints   int    `json:"maxPoints"`
+	Comment     string `json:"comment,omitempty"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+// Similarity job statuses stored in similarity_jobs.status
+const (
+	SimilarityJobQueued    = "queued"
+	SimilarityJobRunning   = "running"
+	SimilarityJobCompleted = "completed"
+	SimilarityJobFailed    = "failed"
+)
+
+// SimilarityJob is a request to compare the submissions of an assignment with
+// each other and, optionally, with the submissions of the same assignment in
+// earlier terms. Jobs run in the background on the server.
+type SimilarityJob struct {
+	JobID               int        `gorm:"column:job_id;primaryKey;autoIncrement" json:"jobId"`
+	AssignmentID        int        `gorm:"column:assignment_id;not null" json:"assignmentId"`
+	IncludeEarlierTerms bool       `gorm:"column:include_earlier_terms;not null;default:0" json:"includeEarlierTerms"`
+	MinScore            float64    `gorm:"column:min_score;not null" json:"minScore"` // Pairs below this score are not stored
+	Status              string     `gorm:"column:status;not null" json:"status"`
+	Error               string     `gorm:"column:error" json:"error,omitempty"`
+	Warnings            []string   `gorm:"column:warnings;serializer:json" json:"warnings,omitempty"` // Files whose text could not be read
+	SubmissionCount     int        `gorm:"column:submission_count;not null;default:0" json:"submissionCount"`
+	PairCount           int        `gorm:"column:pair_count;not null;default:0" json:"pairCount"`
+	RequestedBy         int        `gorm:"column:requested_by;not null" json:"requestedBy"`
+	CreatedAt           time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	StartedAt           *time.Time `gorm:"column:started_at" json:"startedAt,omitempty"`
+	CompletedAt         *time.Time `gorm:"column:completed_at" json:"completedAt,omitempty"`
+
+	Pairs []SimilarityPair `gorm:"-" json:"pairs,omitempty"`
+}
+
+// TableName specifies the table name for the SimilarityJob model
+func (SimilarityJob) TableName() string {
+	return "similarity_jobs"
+}
+
+// SimilaritySpan is a passage found in both submissions of a pair. Offsets
+// count characters in the text that was compared: the submission's content
+// followed by the text of its file.
+type SimilaritySpan struct {
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	Text       string `json:"text"`
+	OtherStart int    `json:"otherStart"`
+	OtherEnd   int    `json:"otherEnd"`
+	OtherText  string `json:"otherText"`
+}
+
+// SimilarityPair is the similarity between a submission of the checked
+// assignment and another submission, either of the same assignment or of an
+// earlier term's copy of it
+type SimilarityPair struct {
+	PairID            int              `gorm:"column:pair_id;primaryKey;autoIncrement" json:"pairId"`
+	JobID             int              `gorm:"column:job_id;not null" json:"jobId"`
+	SubmissionID      int              `gorm:"column:submission_id;not null" json:"submissionId"`
+	StudentID         int              `gorm:"column:student_id;not null" json:"studentId,omitempty"`
+	OtherSubmissionID int              `gorm:"column:other_submission_id;not null" json:"otherSubmissionId"`
+	OtherStudentID    int              `gorm:"column:other_student_id;not null" json:"otherStudentId,omitempty"`
+	OtherAssignmentID int              `gorm:"column:other_assignment_id;not null" json:"otherAssignmentId"` // Differs from the job's assignment for earlier terms
+	Score             float64          `gorm:"column:score;not null" json:"score"`                           // The higher of the two shares, between 0 and 1
+	SubmissionShare   float64          `gorm:"column:submission_share;not null" json:"submissionShare"`      // Share of the submission found in the other one
+	OtherShare        float64          `gorm:"column:other_share;not null" json:"otherShare"`                // Share of the other submission found in this one
+	Spans             []SimilaritySpan `gorm:"column:spans;serializer:json" json:"spans,omitempty"`
+
+	// Virtual fields (not stored in database)
+	StudentName      string `gorm:"-" json:"studentName"`
+	OtherStudentName string `gorm:"-" json:"otherStudentName"`
+	AnonymousID      string `gorm:"-" json:"anonymousId,omitempty"` // Set instead of the student IDs while grading is anonymous
+	OtherAnonymousID string `gorm:"-" json:"otherAnonymousId,omitempty"`
+}
+
+// TableName specifies the table name for the SimilarityPair model
+func (SimilarityPair) TableName() string {
+	return "similarity_pairs"
+}
@@ -4,6 +4,10 @@ import (
 	"time"
 )
 
+// SubmissionStatusPendingRelease is the status students see for work that
+// has been graded while the grade is not released yet
+const SubmissionStatusPendingRelease = "graded_pending_release"
+
 // Submission represents a student's submission for an assignment
 type Submission struct {
 	SubmissionID   int        `json:"submissionId" gorm:"column:submission_id;primaryKey;autoIncrement"`
@@ -18,6 +22,8 @@ type Submission struct {
 	Feedback       string     `json:"feedback" gorm:"column:feedback"`
 	GradedBy       *int       `json:"gradedBy" gorm:"column:graded_by"`
 	GradedDate     *time.Time `json:"gradedDate" gorm:"column:graded_date"`
+	GradeReleased  bool       `json:"gradeReleased" gorm:"column:grade_released;default:true"` // Draft grades are hidden from the student until released
+	GroupID        *int       `json:"groupId,omitempty" gorm:"column:group_id"`                // Set when the work was submitted for a group
 
 	// Virtual fields (not stored in database)
 	StudentName string      `json:"studentName" gorm:"-"`
@@ -35,6 +41,7 @@ type SubmissionResponse struct {
 	AssignmentID   int        `json:"assignmentId"`
 	StudentID      int        `json:"studentId"`
 	StudentName    string     `json:"studentName"`
+	AnonymousID    string     `json:"anonymousId,omitempty"` // Set instead of the student ID while grading is anonymous
 	Content        string     `json:"content"`
 	FileURL        string     `json:"fileURL,omitempty"`
 	SubmissionDate time.Time  `json:"submissionDate"`
@@ -44,6 +51,16 @@ type SubmissionResponse struct {
 	Status         string     `json:"status"`
 	GradedBy       *int       `json:"gradedBy,omitempty"`
 	GradedDate     *time.Time `json:"gradedDate,omitempty"`
+	GradeReleased  bool       `json:"gradeReleased"`
+	Version        int        `json:"version,omitempty"` // Number of the latest version
+	GroupID        *int       `json:"groupId,omitempty"`
+
+	// Per-criterion scores when the submission was graded with a rubric
+	RubricScores []RubricScoreResponse `json:"rubricScores,omitempty"`
+
+	// Peer review progress, and the grade including review quality when it counts
+	PeerReviews   *PeerReviewSummary `json:"peerReviews,omitempty"`
+	AdjustedGrade *int               `json:"adjustedGrade,omitempty"`
 
 	// Optional assignment details
 	Assignment *AssignmentResponse `json:"assignment,omitempty"`
@@ -65,6 +82,8 @@ func (s Submission) ToResponse() SubmissionResponse {
 		Status:         s.Status,
 		GradedBy:       s.GradedBy,
 		GradedDate:     s.GradedDate,
+		GradeReleased:  s.GradeReleased,
+		GroupID:        s.GroupID,
 	}
 
 	// Include assignment details if available
@@ -75,3 +94,19 @@ func (s Submission) ToResponse() SubmissionResponse {
 
 	return response
 }
+
+// HideUnreleasedGrade removes a draft grade and everything that reveals it
+// from the response, for showing the submission to its student
+func (r *SubmissionResponse) HideUnreleasedGrade() {
+	if r.Grade == nil || r.GradeReleased {
+		return
+	}
+
+	r.Grade = nil
+	r.Feedback = ""
+	r.GradedBy = nil
+	r.GradedDate = nil
+	r.RubricScores = nil
+	r.AdjustedGrade = nil
+	r.Status = SubmissionStatusPendingRelease
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationTypeSubmissionComment is the type of notifications about new
+// comments on a submission
+const NotificationTypeSubmissionComment = "submission_comment"
+
+// SubmissionComment is a comment in the feedback thread of a submission,
+// written by the student or a teacher. Top-level comments can be anchored
+// to a range of characters in the submission's content.
+type SubmissionComment struct {
+	CommentID    int        `gorm:"column:comment_id;primaryKey;autoIncrement" json:"commentId"`
+	SubmissionID int        `gorm:"column:submission_id;not null" json:"submissionId"`
+	ParentID     *int       `gorm:"column:parent_id" json:"parentId,omitempty"` // Set for replies
+	AuthorID     int        `gorm:"column:author_id;not null" json:"authorId"`
+	AuthorRole   string     `gorm:"column:author_role;not null" json:"authorRole"`
+	Body         string     `gorm:"column:body;not null" json:"body"`
+	AnchorStart  *int       `gorm:"column:anchor_start" json:"anchorStart,omitempty"` // Character offsets in the content of Version
+	AnchorEnd    *int       `gorm:"column:anchor_end" json:"anchorEnd,omitempty"`
+	AnchorText   string     `gorm:"column:anchor_text" json:"anchorText,omitempty"` // The anchored text when the comment was written
+	Version      int        `gorm:"column:version;not null" json:"version"`         // Submission version the comment was written on
+	IsDeleted    bool       `gorm:"column:is_deleted;not null;default:0" json:"isDeleted"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	EditedAt     *time.Time `gorm:"column:edited_at" json:"editedAt,omitempty"`
+
+	// Virtual fields (not stored in database)
+	AuthorName string              `gorm:"-" json:"authorName"`
+	EditCount  int                 `gorm:"-" json:"editCount"`
+	Replies    []SubmissionComment `gorm:"-" json:"replies,omitempty"`
+}
+
+// TableName specifies the table name for the SubmissionComment model
+func (SubmissionComment) TableName() string {
+	return "submission_comments"
+}
+
+// SubmissionCommentEdit is an earlier text of an edited comment
+type SubmissionCommentEdit struct {
+	EditID    int       `gorm:"column:edit_id;primaryKey;autoIncrement" json:"editId"`
+	CommentID int       `gorm:"column:comment_id;not null" json:"commentId"`
+	Body      string    `gorm:"column:body;not null" json:"body"` // The text before the edit
+	EditedAt  time.Time `gorm:"column:edited_at;not null" json:"editedAt"`
+}
+
+// TableName specifies the table name for the SubmissionCommentEdit model
+func (SubmissionCommentEdit) TableName() string {
+	return "submission_comment_edits"
+}
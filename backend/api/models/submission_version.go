@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// SubmissionVersion is an immutable copy of one submission attempt. The
+// submissions row always holds the latest version.
+type SubmissionVersion struct {
+	VersionID     int       `gorm:"column:version_id;primaryKey;autoIncrement" json:"versionId"`
+	SubmissionID  int       `gorm:"column:submission_id;not null" json:"submissionId"`
+	VersionNumber int       `gorm:"column:version_number;not null" json:"version"`
+	Content       string    `gorm:"column:content" json:"content"`
+	FileURL       string    `gorm:"column:file_url" json:"fileURL,omitempty"`
+	SubmittedAt   time.Time `gorm:"column:submitted_at;not null" json:"submittedAt"`
+	IsLate        bool      `gorm:"column:is_late;not null;default:0" json:"isLate"`
+
+	// Virtual fields (not stored in database)
+	StudentID   int    `gorm:"-" json:"studentId,omitempty"`
+	AnonymousID string `gorm:"-" json:"anonymousId,omitempty"` // Set instead of the student ID while grading is anonymous
+	StudentName string `gorm:"-" json:"studentName,omitempty"`
+}
+
+// TableName specifies the table name for the SubmissionVersion model
+func (SubmissionVersion) TableName() string {
+	return "submission_versions"
+}
+
+// SubmissionVersionDiff is a line-by-line comparison of the content of two
+// submission versions
+type SubmissionVersionDiff struct {
+	SubmissionID int        `json:"submissionId"`
+	StudentID    int        `json:"studentId,omitempty"`
+	AnonymousID  string     `json:"anonymousId,omitempty"` // Set instead of the student ID while grading is anonymous
+	StudentName  string     `json:"studentName,omitempty"`
+	From         int        `json:"from"`
+	To           int        `json:"to"`
+	FileChanged  bool       `json:"fileChanged"`
+	FromFileURL  string     `json:"fromFileURL,omitempty"`
+	ToFileURL    string     `json:"toFileURL,omitempty"`
+	Added        int        `json:"added"`
+	Removed      int        `json:"removed"`
+	TooLarge     bool       `json:"tooLarge,omitempty"` // The versions are too large to compare line by line
+	Lines        []DiffLine `json:"lines"`
+}
+
+// Diff line operations
+const (
+	DiffEqual   = "equal"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+)
+
+// DiffLine is one line of a line-based diff. FromLine and ToLine are the
+// 1-based line numbers in the old and new text; zero when the line is not
+// present on that side.
+type DiffLine struct {
+	Op       string `json:"op"`
+	Text     string `json:"text"`
+	FromLine int    `json:"fromLine,omitempty"`
+	ToLine   int    `json:"toLine,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Term represents an academic term, such as a semester or school year
+type Term struct {
+	TermID     int       `gorm:"column:term_id;primaryKey;autoIncrement" json:"termId"`
+	Name       string    `gorm:"column:name;not null" json:"name"`
+	StartDate  time.Time `gorm:"column:start_date;not null" json:"startDate"`
+	EndDate    time.Time `gorm:"column:end_date;not null" json:"endDate"`
+	IsArchived bool      `gorm:"column:is_archived;not null;default:0" json:"isArchived"` // Set once the term's classes have been auto-archived
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the Term model
+func (Term) TableName() string {
+	return "terms"
+}
+
+// ClassSection represents a section within a class, such as a period or lab group
+type ClassSection struct {
+	SectionID int       `gorm:"column:section_id;primaryKey;autoIncrement" json:"sectionId"`
+	ClassID   int       `gorm:"column:class_id;not null" json:"classId"`
+	Name      string    `gorm:"column:name;not null" json:"name"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for the ClassSection model
+func (ClassSection) TableName() string {
+	return "class_sections"
+}
@@ -15,9 +15,31 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 	authController := controllers.NewAuthController(serviceFactory)
 	classController := controllers.NewClassController(serviceFactory.ClassService())
 	chatController := controllers.NewChatController(serviceFactory.ChatService())
-	assignmentController := controllers.NewAssignmentController(serviceFactory.AssignmentService())
-	announcementController := controllers.NewAnnouncementController(serviceFactory.AnnouncementService())
+	assignmentController := controllers.NewAssignmentController(serviceFactory.AssignmentService(), serviceFactory.AnonymousGradingService())
+	announcementController := controllers.NewAnnouncementController(serviceFactory.AnnouncementService(), serviceFactory.ClassService())
 	userController := controllers.NewUserController(serviceFactory.UserService())
+	rosterController := controllers.NewRosterController(serviceFactory.RosterService(), serviceFactory.ClassService())
+	oneRosterController := controllers.NewOneRosterController(serviceFactory.OneRosterService())
+	termController := controllers.NewTermController(serviceFactory.TermService())
+	attendanceController := controllers.NewAttendanceController(serviceFactory.AttendanceService(), serviceFactory.ClassService())
+	calendarController := controllers.NewCalendarController(serviceFactory.CalendarService())
+	quizController := controllers.NewQuizController(serviceFactory.QuizService(), serviceFactory.ClassService())
+	qtiController := controllers.NewQTIController(serviceFactory.QTIService(), serviceFactory.ClassService())
+	questionBankController := controllers.NewQuestionBankController(serviceFactory.QuestionBankService())
+	rubricController := controllers.NewRubricController(serviceFactory.RubricService(), serviceFactory.ClassService())
+	submissionVersionController := controllers.NewSubmissionVersionController(serviceFactory.SubmissionVersionService(), serviceFactory.AnonymousGradingService(), serviceFactory.ClassService())
+	extensionController := controllers.NewExtensionController(serviceFactory.ExtensionService(), serviceFactory.ClassService())
+	groupController := controllers.NewGroupController(serviceFactory.GroupService(), serviceFactory.ClassService())
+	peerReviewController := controllers.NewPeerReviewController(serviceFactory.PeerReviewService(), serviceFactory.ClassService())
+	similarityController := controllers.NewSimilarityController(serviceFactory.SimilarityService(), serviceFactory.ClassService())
+	gradingController := controllers.NewGradingController(serviceFactory.GradingService(), serviceFactory.ClassService())
+	notificationController := controllers.NewNotificationController(serviceFactory.NotificationService())
+	anonymousGradingController := controllers.NewAnonymousGradingController(serviceFactory.AnonymousGradingService(), serviceFactory.ClassService())
+	submissionCommentController := controllers.NewSubmissionCommentController(serviceFactory.SubmissionCommentService(), serviceFactory.ClassService(), serviceFactory.AnonymousGradingService())
+	regradeController := controllers.NewRegradeController(serviceFactory.RegradeService(), serviceFactory.ClassService())
+	analyticsController := controllers.NewAnalyticsController(serviceFactory.AnalyticsService(), serviceFactory.ClassService())
+	atRiskController := controllers.NewAtRiskController(serviceFactory.AtRiskService(), serviceFactory.GuardianService(), serviceFactory.ClassService())
+	progressReportController := controllers.NewProgressReportController(serviceFactory.ProgressReportService(), serviceFactory.ClassService())
 
 	// Add a simple test endpoint that always returns success
 	router.GET("/api/test-simple", func(c *gin.Context) {
@@ -54,6 +76,9 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 			auth.POST("/forgot-password", authController.ForgotPassword())
 			auth.POST("/reset-password", authController.ResetPassword())
 		}
+
+		// iCalendar feed, authenticated by the secret token in the URL
+		public.GET("/calendar/feed/:token", calendarController.GetFeed)
 	}
 
 	// Protected routes
@@ -64,6 +89,19 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 		protected.GET("/users/me", authController.GetCurrentUser())
 		protected.GET("/users/:id", userController.GetUser)
 
+		// Academic terms
+		protected.GET("/terms", termController.GetTerms)
+
+		// Calendar
+		protected.GET("/calendar", calendarController.GetEvents)
+		protected.GET("/calendar/feed", calendarController.GetFeedURL)
+		protected.POST("/calendar/feed/reset", calendarController.ResetFeedURL)
+
+		// Notifications
+		protected.GET("/notifications", notificationController.GetNotifications)
+		protected.PUT("/notifications/read", notificationController.MarkAllRead)
+		protected.PUT("/notifications/:notificationId/read", notificationController.MarkRead)
+
 		// Teacher-specific routes
 		teachers := protected.Group("/")
 		teachers.Use(middlewares.RoleMiddleware("teacher", "admin"))
@@ -73,10 +111,73 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 			teachers.GET("/classes/teacher/:teacherId", classController.GetTeacherClasses)
 			teachers.PUT("/classes/:id", classController.UpdateClass)
 			teachers.DELETE("/classes/:id", classController.DeleteClass)
+			teachers.POST("/classes/:id/archive", classController.ArchiveClass)
+			teachers.POST("/classes/:id/unarchive", classController.UnarchiveClass)
+			teachers.POST("/classes/:id/clone", classController.CloneClass)
 			teachers.POST("/classes/:id/teachers", classController.AddTeacherToClass)
 			teachers.DELETE("/classes/:id/teachers/:teacherId", classController.RemoveTeacherFromClass)
 			teachers.GET("/classes/:id/students", classController.GetClassStudents)
 			teachers.DELETE("/classes/:id/students/:studentId", classController.RemoveStudentFromClass)
+
+			// Enrollment approval and waitlist management
+			teachers.PUT("/classes/:id/enrollment-settings", classController.UpdateEnrollmentSettings)
+			teachers.GET("/classes/:id/enrollments", classController.GetEnrollments)
+			teachers.POST("/classes/:id/enrollments/:studentId/approve", classController.ApproveEnrollment)
+			teachers.POST("/classes/:id/enrollments/:studentId/reject", classController.RejectEnrollment)
+
+			// Bulk roster import
+			teachers.POST("/classes/:id/roster/import", rosterController.ImportRoster)
+
+			// Terms and sections
+			teachers.PUT("/classes/:id/term", classController.SetClassTerm)
+			teachers.POST("/classes/:id/sections", classController.CreateSection)
+			teachers.DELETE("/classes/:id/sections/:sectionId", classController.DeleteSection)
+			teachers.PUT("/classes/:id/students/:studentId/section", classController.SetStudentSection)
+
+			// Standing accommodations (extra days and extra quiz time)
+			teachers.GET("/classes/:id/accommodations", extensionController.GetAccommodations)
+			teachers.PUT("/classes/:id/accommodations/:studentId", extensionController.SetAccommodation)
+			teachers.DELETE("/classes/:id/accommodations/:studentId", extensionController.DeleteAccommodation)
+
+			// Class analytics
+			teachers.GET("/classes/:id/analytics/assignments", analyticsController.GetAssignmentAnalytics)
+			teachers.GET("/classes/:id/analytics/assignments/:assignmentId/grades", analyticsController.GetGradeDistribution)
+			teachers.GET("/classes/:id/analytics/students", analyticsController.GetStudentAnalytics)
+			teachers.GET("/classes/:id/analytics/trends", analyticsController.GetTrends)
+
+			// At-risk rules, the students they flag and guardians who get alerts
+			teachers.GET("/classes/:id/at-risk", atRiskController.GetAtRiskStudents)
+			teachers.POST("/classes/:id/at-risk/evaluate", atRiskController.EvaluateClass)
+			teachers.GET("/classes/:id/at-risk/rules", atRiskController.GetRules)
+			teachers.POST("/classes/:id/at-risk/rules", atRiskController.CreateRule)
+			teachers.PUT("/classes/:id/at-risk/rules/:ruleId", atRiskController.UpdateRule)
+			teachers.DELETE("/classes/:id/at-risk/rules/:ruleId", atRiskController.DeleteRule)
+			teachers.GET("/students/:studentId/guardians", atRiskController.GetGuardians)
+			teachers.POST("/students/:studentId/guardians", atRiskController.AddGuardian)
+			teachers.PUT("/students/:studentId/guardians/:guardianId", atRiskController.UpdateGuardian)
+			teachers.DELETE("/students/:studentId/guardians/:guardianId", atRiskController.DeleteGuardian)
+
+			// Student progress reports
+			teachers.GET("/classes/:id/reports", progressReportController.DownloadClassReports)
+			teachers.GET("/classes/:id/reports/students/:studentId", progressReportController.GetClassReport)
+			teachers.GET("/terms/:termId/students/:studentId/report", progressReportController.GetTermReport)
+
+			// Question banks
+			teachers.GET("/question-banks", questionBankController.GetBanks)
+			teachers.POST("/question-banks", questionBankController.CreateBank)
+			teachers.GET("/question-banks/:bankId", questionBankController.GetBank)
+			teachers.PUT("/question-banks/:bankId", questionBankController.UpdateBank)
+			teachers.DELETE("/question-banks/:bankId", questionBankController.DeleteBank)
+			teachers.POST("/question-banks/:bankId/questions", questionBankController.AddQuestion)
+			teachers.PUT("/question-banks/:bankId/questions/:questionId", questionBankController.UpdateQuestion)
+			teachers.DELETE("/question-banks/:bankId/questions/:questionId", questionBankController.DeleteQuestion)
+
+			// Rubrics
+			teachers.GET("/rubrics", rubricController.GetRubrics)
+			teachers.POST("/rubrics", rubricController.CreateRubric)
+			teachers.GET("/rubrics/:rubricId", rubricController.GetRubric)
+			teachers.PUT("/rubrics/:rubricId", rubricController.UpdateRubric)
+			teachers.DELETE("/rubrics/:rubricId", rubricController.DeleteRubric)
 		}
 
 		// Student-specific routes
@@ -87,6 +188,7 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 			students.GET("/classes/student/:studentId", classController.GetStudentClasses)
 			students.POST("/classes/join", classController.JoinClass)
 			students.GET("/classes/:id", classController.GetClass)
+			students.GET("/classes/:id/sections", classController.GetSections)
 		}
 
 		// Class chat routes (accessible to both teachers and students)
@@ -112,6 +214,8 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 			announcements.PUT("/classes/:id/announcements/:announcementId", middlewares.RoleMiddleware("teacher", "admin"), announcementController.UpdateAnnouncement)
 			// Delete an announcement (teacher only)
 			announcements.DELETE("/classes/:id/announcements/:announcementId", middlewares.RoleMiddleware("teacher", "admin"), announcementController.DeleteAnnouncement)
+			// Pin or unpin an announcement (teacher only)
+			announcements.PUT("/classes/:id/announcements/:announcementId/pin", middlewares.RoleMiddleware("teacher", "admin"), announcementController.PinAnnouncement)
 		}
 
 		// Assignment routes (accessible to both teachers and students)
@@ -134,13 +238,115 @@ func SetupRoutes(router *gin.Engine, serviceFactory services.ServiceFactory) {
 			assignments.GET("/classes/:id/assignments/:assignmentId/submissions", middlewares.RoleMiddleware("teacher", "admin"), assignmentController.GetAssignmentSubmissions)
 			// Grade a submission (teacher only)
 			assignments.PUT("/classes/:id/assignments/:assignmentId/submissions/:studentId", middlewares.RoleMiddleware("teacher", "admin"), assignmentController.GradeSubmission)
+			// List, view and compare earlier versions of a submission
+			assignments.GET("/classes/:id/assignments/:assignmentId/submissions/:studentId/versions", submissionVersionController.GetVersions)
+			assignments.GET("/classes/:id/assignments/:assignmentId/submissions/:studentId/versions/diff", submissionVersionController.DiffVersions)
+			assignments.GET("/classes/:id/assignments/:assignmentId/submissions/:studentId/versions/:version", submissionVersionController.GetVersion)
+			// Comment threads between the student and the teachers on a submission
+			assignments.GET("/classes/:id/assignments/:assignmentId/submissions/:studentId/comments", submissionCommentController.GetComments)
+			assignments.POST("/classes/:id/assignments/:assignmentId/submissions/:studentId/comments", submissionCommentController.AddComment)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/submissions/:studentId/comments/:commentId", submissionCommentController.EditComment)
+			assignments.DELETE("/classes/:id/assignments/:assignmentId/submissions/:studentId/comments/:commentId", submissionCommentController.DeleteComment)
+			assignments.GET("/classes/:id/assignments/:assignmentId/submissions/:studentId/comments/:commentId/history", submissionCommentController.GetCommentHistory)
+			// Per-student due date extensions (teacher only)
+			assignments.GET("/classes/:id/assignments/:assignmentId/extensions", middlewares.RoleMiddleware("teacher", "admin"), extensionController.GetExtensions)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/extensions/:studentId", middlewares.RoleMiddleware("teacher", "admin"), extensionController.GrantExtension)
+			assignments.DELETE("/classes/:id/assignments/:assignmentId/extensions/:studentId", middlewares.RoleMiddleware("teacher", "admin"), extensionController.RevokeExtension)
+			// Groups of group assignments
+			assignments.GET("/classes/:id/assignments/:assignmentId/groups", groupController.GetGroups)
+			assignments.POST("/classes/:id/assignments/:assignmentId/groups", groupController.CreateGroup)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/groups/:groupId/members", middlewares.RoleMiddleware("teacher", "admin"), groupController.SetMembers)
+			assignments.DELETE("/classes/:id/assignments/:assignmentId/groups/:groupId", middlewares.RoleMiddleware("teacher", "admin"), groupController.DeleteGroup)
+			assignments.POST("/classes/:id/assignments/:assignmentId/groups/:groupId/join", middlewares.RoleMiddleware("student"), groupController.JoinGroup)
+			assignments.POST("/classes/:id/assignments/:assignmentId/groups/:groupId/leave", middlewares.RoleMiddleware("student"), groupController.LeaveGroup)
+			// Give every member of a group the same grade (teacher only)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/groups/:groupId/grade", middlewares.RoleMiddleware("teacher", "admin"), groupController.GradeGroup)
+			// Peer review settings (changes are teacher only)
+			assignments.GET("/classes/:id/assignments/:assignmentId/peer-review", peerReviewController.GetSettings)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/peer-review", middlewares.RoleMiddleware("teacher", "admin"), peerReviewController.SaveSettings)
+			// Assign reviewers after the due date (teacher only)
+			assignments.POST("/classes/:id/assignments/:assignmentId/peer-reviews/assign", middlewares.RoleMiddleware("teacher", "admin"), peerReviewController.AssignReviewers)
+			// List all reviews (teachers) or the reviews to write (students)
+			assignments.GET("/classes/:id/assignments/:assignmentId/peer-reviews", peerReviewController.GetReviews)
+			// Reviews of the student's own submission (student only)
+			assignments.GET("/classes/:id/assignments/:assignmentId/peer-reviews/received", middlewares.RoleMiddleware("student"), peerReviewController.GetReceivedReviews)
+			// Write a review (student only)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/peer-reviews/:reviewId", middlewares.RoleMiddleware("student"), peerReviewController.SubmitReview)
+			// Rate the quality of a review (teacher only)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/peer-reviews/:reviewId/quality", middlewares.RoleMiddleware("teacher", "admin"), peerReviewController.RateReview)
+			// Release draft grades to students (teacher only)
+			assignments.GET("/classes/:id/assignments/:assignmentId/grades/release", middlewares.RoleMiddleware("teacher", "admin"), gradingController.GetGradeRelease)
+			assignments.POST("/classes/:id/assignments/:assignmentId/grades/release", middlewares.RoleMiddleware("teacher", "admin"), gradingController.ReleaseGrades)
+			// Grade many submissions from a CSV file (teacher only)
+			assignments.POST("/classes/:id/assignments/:assignmentId/grades/import", middlewares.RoleMiddleware("teacher", "admin"), gradingController.ImportGrades)
+			// Regrade requests: students open them, teachers accept or reject them
+			assignments.POST("/classes/:id/assignments/:assignmentId/regrade-requests", middlewares.RoleMiddleware("student"), regradeController.CreateRequest)
+			assignments.GET("/classes/:id/assignments/:assignmentId/regrade-requests", regradeController.GetRequests)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/regrade-requests/:requestId/accept", middlewares.RoleMiddleware("teacher", "admin"), regradeController.AcceptRequest)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/regrade-requests/:requestId/reject", middlewares.RoleMiddleware("teacher", "admin"), regradeController.RejectRequest)
+			// Anonymous grading status and the grading audit log (teacher only)
+			assignments.GET("/classes/:id/assignments/:assignmentId/anonymous-grading", middlewares.RoleMiddleware("teacher", "admin"), anonymousGradingController.GetStatus)
+			assignments.GET("/classes/:id/assignments/:assignmentId/anonymous-grading/audit", middlewares.RoleMiddleware("teacher", "admin"), anonymousGradingController.GetAuditLog)
+			// Similarity checks of the submissions (teacher only)
+			assignments.POST("/classes/:id/assignments/:assignmentId/similarity", middlewares.RoleMiddleware("teacher", "admin"), similarityController.StartCheck)
+			assignments.GET("/classes/:id/assignments/:assignmentId/similarity", middlewares.RoleMiddleware("teacher", "admin"), similarityController.GetJobs)
+			assignments.GET("/classes/:id/assignments/:assignmentId/similarity/:jobId", middlewares.RoleMiddleware("teacher", "admin"), similarityController.GetJob)
+			// Get the rubric of an assignment
+			assignments.GET("/classes/:id/assignments/:assignmentId/rubric", rubricController.GetAssignmentRubric)
+			// Attach or detach a rubric (teacher only)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/rubric", middlewares.RoleMiddleware("teacher", "admin"), rubricController.AttachRubric)
+
+			// Set up quiz questions and settings (teacher only)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/quiz", middlewares.RoleMiddleware("teacher", "admin"), quizController.SaveQuiz)
+			// Get quiz settings, with questions for teachers
+			assignments.GET("/classes/:id/assignments/:assignmentId/quiz", quizController.GetQuiz)
+			// List quiz attempts
+			assignments.GET("/classes/:id/assignments/:assignmentId/quiz/attempts", quizController.GetAttempts)
+			// Start, save and submit quiz attempts (student only)
+			assignments.POST("/classes/:id/assignments/:assignmentId/quiz/attempts", middlewares.RoleMiddleware("student"), quizController.StartAttempt)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/quiz/attempts/:attemptId", middlewares.RoleMiddleware("student"), quizController.SaveAnswers)
+			assignments.POST("/classes/:id/assignments/:assignmentId/quiz/attempts/:attemptId/submit", middlewares.RoleMiddleware("student"), quizController.SubmitAttempt)
+			// Draw random questions from question banks (teacher only)
+			assignments.PUT("/classes/:id/assignments/:assignmentId/quiz/pools", middlewares.RoleMiddleware("teacher", "admin"), quizController.SavePools)
+			assignments.GET("/classes/:id/assignments/:assignmentId/quiz/students/:studentId/questions", middlewares.RoleMiddleware("teacher", "admin"), quizController.GetStudentQuestions)
+			// QTI package import and export (teacher only)
+			assignments.POST("/classes/:id/assignments/:assignmentId/quiz/qti/import", middlewares.RoleMiddleware("teacher", "admin"), qtiController.ImportPackage)
+			assignments.GET("/classes/:id/assignments/:assignmentId/quiz/qti/export", middlewares.RoleMiddleware("teacher", "admin"), qtiController.ExportPackage)
+		}
+
+		// Attendance routes (accessible to both teachers and students)
+		attendance := protected.Group("/")
+		attendance.Use(middlewares.RoleMiddleware("student", "teacher", "admin"))
+		{
+			// Meeting schedules and sessions
+			attendance.GET("/classes/:id/schedules", attendanceController.GetSchedules)
+			attendance.POST("/classes/:id/schedules", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.CreateSchedule)
+			attendance.DELETE("/classes/:id/schedules/:scheduleId", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.DeleteSchedule)
+			attendance.GET("/classes/:id/sessions", attendanceController.GetSessions)
+			attendance.POST("/classes/:id/sessions", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.CreateSession)
+			// Take attendance (teacher only)
+			attendance.GET("/classes/:id/sessions/:sessionId/attendance", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.GetSessionAttendance)
+			attendance.PUT("/classes/:id/sessions/:sessionId/attendance", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.MarkAttendance)
+			attendance.GET("/classes/:id/sessions/:sessionId/check-in-code", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.GetCheckInCode)
+			// Self check-in (student only)
+			attendance.POST("/classes/:id/sessions/:sessionId/check-in", middlewares.RoleMiddleware("student"), attendanceController.CheckIn)
+			// Reports
+			attendance.GET("/classes/:id/attendance/report", middlewares.RoleMiddleware("teacher", "admin"), attendanceController.GetClassReport)
+			attendance.GET("/classes/:id/attendance/students/:studentId", attendanceController.GetStudentReport)
 		}
 
 		// Admin-specific routes
 		admins := protected.Group("/")
 		admins.Use(middlewares.RoleMiddleware("admin"))
 		{
-			// Admin routes will be added here
+			// OneRoster SIS integration
+			admins.POST("/admin/oneroster/import", oneRosterController.ImportBundle)
+			admins.GET("/admin/oneroster/export", oneRosterController.ExportResults)
+
+			// Academic terms
+			admins.POST("/admin/terms", termController.CreateTerm)
+			admins.PUT("/admin/terms/:termId", termController.UpdateTerm)
+			admins.DELETE("/admin/terms/:termId", termController.DeleteTerm)
 		}
 	}
 }
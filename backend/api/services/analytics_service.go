@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// maxTrendPoints limits the number of weeks or months in a trend
+const maxTrendPoints = 260
+
+// enrolledSubmissionsFrom joins the assignments of a class with the
+// submissions of the students who are actively enrolled in the class, or in
+// the section the assignment targets
+const enrolledSubmissionsFrom = `
+	FROM assignments a
+	JOIN class_enrollments ce ON ce.class_id = a.class_id AND ce.is_active = 1 AND ce.status = 'active'
+		AND (a.section_id IS NULL OR ce.section_id = a.section_id)
+	JOIN submissions s ON s.assignment_id = a.assignment_id AND s.user_id = ce.user_id`
+
+// trendPeriods are the SQL expressions truncating a date column to the
+// start of its period. Weeks start on Monday, like day 0 (1900-01-01).
+var trendPeriods = map[string]string{
+	models.AnalyticsIntervalWeek:  "DATEADD(day, DATEDIFF(day, 0, %[1]s) / 7 * 7, 0)",
+	models.AnalyticsIntervalMonth: "DATEADD(month, DATEDIFF(month, 0, %[1]s), 0)",
+}
+
+// AnalyticsService computes class analytics with aggregate queries
+type AnalyticsService interface {
+	Service
+	GetAssignmentAnalytics(classID int) ([]models.AssignmentAnalytics, error)
+	GetGradeDistribution(classID, assignmentID int) (*models.GradeDistribution, error)
+	GetStudentAnalytics(classID int) ([]models.StudentAnalytics, error)
+	GetTrends(classID int, interval string, from, to *time.Time) (*models.AnalyticsTrends, error)
+}
+
+// AnalyticsServiceImpl implements AnalyticsService
+type AnalyticsServiceImpl struct {
+	*BaseService
+}
+
+// NewAnalyticsService creates a new AnalyticsService
+func NewAnalyticsService(db *gorm.DB) AnalyticsService {
+	return &AnalyticsServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetAssignmentAnalytics returns the submission rate, late ratio and grade
+// summary of every published assignment of a class, by due date
+func (s *AnalyticsServiceImpl) GetAssignmentAnalytics(classID int) ([]models.AssignmentAnalytics, error) {
+	if err := s.checkClass(classID); err != nil {
+		return nil, err
+	}
+
+	var analytics []models.AssignmentAnalytics
+	if err := s.db.Raw(`
+		SELECT a.assignment_id, a.title, a.due_date, a.points_possible,
+			COUNT(ce.user_id) AS students,
+			COUNT(s.submission_id) AS submitted,
+			COALESCE(SUM(CASE WHEN s.is_late = 1 THEN 1 ELSE 0 END), 0) AS late,
+			COUNT(s.grade) AS graded,
+			AVG(CAST(s.grade AS FLOAT)) AS mean_grade
+		FROM assignments a
+		LEFT JOIN class_enrollments ce ON ce.class_id = a.class_id AND ce.is_active = 1 AND ce.status = 'active'
+			AND (a.section_id IS NULL OR ce.section_id = a.section_id)
+		LEFT JOIN submissions s ON s.assignment_id = a.assignment_id AND s.user_id = ce.user_id
+		WHERE a.class_id = ? AND a.is_published = 1
+		GROUP BY a.assignment_id, a.title, a.due_date, a.points_possible
+		ORDER BY a.due_date, a.assignment_id
+	`, classID).Scan(&analytics).Error; err != nil {
+		return nil, fmt.Errorf("failed to get assignment analytics: %w", err)
+	}
+
+	var medians []struct {
+		AssignmentID int
+		MedianGrade  float64
+	}
+	if err := s.db.Raw(`
+		SELECT DISTINCT a.assignment_id,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.grade) OVER (PARTITION BY a.assignment_id) AS median_grade
+		`+enrolledSubmissionsFrom+`
+		WHERE a.class_id = ? AND a.is_published = 1 AND s.grade IS NOT NULL
+	`, classID).Scan(&medians).Error; err != nil {
+		return nil, fmt.Errorf("failed to get median grades: %w", err)
+	}
+	medianByAssignment := make(map[int]float64, len(medians))
+	for _, median := range medians {
+		medianByAssignment[median.AssignmentID] = median.MedianGrade
+	}
+
+	for i := range analytics {
+		a := &analytics[i]
+		a.OnTime = a.Submitted - a.Late
+		a.SubmissionRate = ratio(a.Submitted, a.Students)
+		a.LateRatio = ratio(a.Late, a.Submitted)
+		if median, ok := medianByAssignment[a.AssignmentID]; ok {
+			a.MedianGrade = &median
+		}
+	}
+
+	return analytics, nil
+}
+
+// GetGradeDistribution returns the grade statistics of an assignment and a
+// histogram of the grades in steps of 10% of its points
+func (s *AnalyticsServiceImpl) GetGradeDistribution(classID, assignmentID int) (*models.GradeDistribution, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	distribution := models.GradeDistribution{
+		AssignmentID:   assignmentID,
+		PointsPossible: assignment.PointsPossible,
+		Histogram:      make([]models.HistogramBucket, 10),
+	}
+	for i := range distribution.Histogram {
+		distribution.Histogram[i] = models.HistogramBucket{From: i * 10, To: (i + 1) * 10}
+	}
+
+	var stats struct {
+		Graded   int
+		Mean     *float64
+		MinGrade *int
+		MaxGrade *int
+		StdDev   *float64
+	}
+	if err := s.db.Raw(`
+		SELECT COUNT(s.grade) AS graded,
+			AVG(CAST(s.grade AS FLOAT)) AS mean,
+			MIN(s.grade) AS min_grade,
+			MAX(s.grade) AS max_grade,
+			STDEVP(CAST(s.grade AS FLOAT)) AS std_dev
+		`+enrolledSubmissionsFrom+`
+		WHERE a.assignment_id = ? AND s.grade IS NOT NULL
+	`, assignmentID).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get grade statistics: %w", err)
+	}
+	if stats.Graded == 0 {
+		return &distribution, nil
+	}
+	distribution.Graded = stats.Graded
+	distribution.Mean = stats.Mean
+	distribution.Min = stats.MinGrade
+	distribution.Max = stats.MaxGrade
+	distribution.StdDev = stats.StdDev
+
+	var medians []float64
+	if err := s.db.Raw(`
+		SELECT TOP 1 PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.grade) OVER () AS median
+		`+enrolledSubmissionsFrom+`
+		WHERE a.assignment_id = ? AND s.grade IS NOT NULL
+	`, assignmentID).Scan(&medians).Error; err != nil {
+		return nil, fmt.Errorf("failed to get median grade: %w", err)
+	}
+	if len(medians) > 0 {
+		distribution.Median = &medians[0]
+	}
+
+	if assignment.PointsPossible > 0 {
+		var buckets []struct {
+			Bucket int
+			Count  int
+		}
+		if err := s.db.Raw(`
+			SELECT bucket, COUNT(*) AS count
+			FROM (
+				SELECT CASE WHEN s.grade >= a.points_possible THEN 9 ELSE s.grade * 10 / a.points_possible END AS bucket
+				`+enrolledSubmissionsFrom+`
+				WHERE a.assignment_id = ? AND s.grade IS NOT NULL
+			) graded
+			GROUP BY bucket
+		`, assignmentID).Scan(&buckets).Error; err != nil {
+			return nil, fmt.Errorf("failed to get grade histogram: %w", err)
+		}
+		for _, bucket := range buckets {
+			if bucket.Bucket >= 0 && bucket.Bucket < len(distribution.Histogram) {
+				distribution.Histogram[bucket.Bucket].Count = bucket.Count
+			}
+		}
+	}
+
+	return &distribution, nil
+}
+
+// GetStudentAnalytics returns how every actively enrolled student is keeping
+// up with the class: submissions, late and missing work, average grade and
+// chat participation
+func (s *AnalyticsServiceImpl) GetStudentAnalytics(classID int) ([]models.StudentAnalytics, error) {
+	if err := s.checkClass(classID); err != nil {
+		return nil, err
+	}
+
+	var students []models.StudentAnalytics
+	if err := s.db.Raw(`
+		SELECT ce.user_id AS student_id,
+			COALESCE(sp.first_name + ' ' + sp.last_name, '') AS student_name,
+			COUNT(a.assignment_id) AS assigned,
+			COUNT(s.submission_id) AS submitted,
+			COALESCE(SUM(CASE WHEN s.is_late = 1 THEN 1 ELSE 0 END), 0) AS late,
+			COALESCE(SUM(CASE WHEN s.submission_id IS NULL AND a.due_date < ? THEN 1 ELSE 0 END), 0) AS missing,
+			AVG(CASE WHEN s.grade IS NOT NULL AND a.points_possible > 0 THEN s.grade * 100.0 / a.points_possible END) AS average_percent
+		FROM class_enrollments ce
+		LEFT JOIN student_profiles sp ON sp.user_id = ce.user_id
+		LEFT JOIN assignments a ON a.class_id = ce.class_id AND a.is_published = 1
+			AND (a.section_id IS NULL OR a.section_id = ce.section_id)
+		LEFT JOIN submissions s ON s.assignment_id = a.assignment_id AND s.user_id = ce.user_id
+		WHERE ce.class_id = ? AND ce.is_active = 1 AND ce.status = 'active'
+		GROUP BY ce.user_id, sp.first_name, sp.last_name
+		ORDER BY sp.last_name, sp.first_name, ce.user_id
+	`, time.Now(), classID).Scan(&students).Error; err != nil {
+		return nil, fmt.Errorf("failed to get student analytics: %w", err)
+	}
+
+	var chats []struct {
+		StudentID     int
+		ChatMessages  int
+		LastMessageAt time.Time
+	}
+	if err := s.db.Raw(`
+		SELECT m.user_id AS student_id, COUNT(*) AS chat_messages, MAX(m.timestamp) AS last_message_at
+		FROM chat_messages m
+		JOIN class_enrollments ce ON ce.class_id = m.class_id AND ce.user_id = m.user_id
+			AND ce.is_active = 1 AND ce.status = 'active'
+		WHERE m.class_id = ? AND m.is_deleted = 0
+		GROUP BY m.user_id
+	`, classID).Scan(&chats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get chat participation: %w", err)
+	}
+
+	total := 0
+	chatByStudent := make(map[int]int, len(chats))
+	for i, chat := range chats {
+		total += chat.ChatMessages
+		chatByStudent[chat.StudentID] = i
+	}
+	for i := range students {
+		student := &students[i]
+		if student.StudentName == "" {
+			student.StudentName = fmt.Sprintf("Student #%d", student.StudentID)
+		}
+		if j, ok := chatByStudent[student.StudentID]; ok {
+			student.ChatMessages = chats[j].ChatMessages
+			student.ChatShare = ratio(chats[j].ChatMessages, total)
+			student.LastMessageAt = &chats[j].LastMessageAt
+		}
+	}
+
+	return students, nil
+}
+
+// GetTrends returns the submissions, late work, average grades and chat
+// messages of a class per week or month. Without a range it covers the last
+// 12 periods; periods without activity are included with zero counts.
+func (s *AnalyticsServiceImpl) GetTrends(classID int, interval string, from, to *time.Time) (*models.AnalyticsTrends, error) {
+	if err := s.checkClass(classID); err != nil {
+		return nil, err
+	}
+
+	if interval == "" {
+		interval = models.AnalyticsIntervalWeek
+	}
+	period, ok := trendPeriods[interval]
+	if !ok {
+		return nil, fmt.Errorf("invalid interval %q, use week or month", interval)
+	}
+
+	end := time.Now().UTC()
+	if to != nil {
+		end = to.UTC()
+	}
+	start := nextTrendPeriod(trendPeriodStart(end, interval), interval, -11)
+	if from != nil {
+		start = trendPeriodStart(from.UTC(), interval)
+	}
+	if !start.Before(end) {
+		return nil, errors.New("from must be before to")
+	}
+
+	trends := models.AnalyticsTrends{ClassID: classID, Interval: interval}
+	index := make(map[string]int)
+	for t := start; t.Before(end); t = nextTrendPeriod(t, interval, 1) {
+		if len(trends.Points) == maxTrendPoints {
+			return nil, fmt.Errorf("the range cannot cover more than %d periods", maxTrendPoints)
+		}
+		index[t.Format("2006-01-02")] = len(trends.Points)
+		trends.Points = append(trends.Points, models.AnalyticsTrendPoint{PeriodStart: t})
+	}
+
+	submissionPeriod := fmt.Sprintf(period, "s.submission_date")
+	var submissions []models.AnalyticsTrendPoint
+	if err := s.db.Raw(`
+		SELECT `+submissionPeriod+` AS period_start,
+			COUNT(*) AS submissions,
+			COALESCE(SUM(CASE WHEN s.is_late = 1 THEN 1 ELSE 0 END), 0) AS late,
+			AVG(CASE WHEN s.grade IS NOT NULL AND a.points_possible > 0 THEN s.grade * 100.0 / a.points_possible END) AS average_percent
+		`+enrolledSubmissionsFrom+`
+		WHERE a.class_id = ? AND a.is_published = 1 AND s.submission_date >= ? AND s.submission_date < ?
+		GROUP BY `+submissionPeriod, classID, start, end).Scan(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get submission trends: %w", err)
+	}
+	for _, point := range submissions {
+		if i, ok := index[point.PeriodStart.Format("2006-01-02")]; ok {
+			trends.Points[i].Submissions = point.Submissions
+			trends.Points[i].Late = point.Late
+			trends.Points[i].AveragePercent = point.AveragePercent
+		}
+	}
+
+	chatPeriod := fmt.Sprintf(period, "timestamp")
+	var chats []models.AnalyticsTrendPoint
+	if err := s.db.Raw(`
+		SELECT `+chatPeriod+` AS period_start, COUNT(*) AS chat_messages
+		FROM chat_messages
+		WHERE class_id = ? AND is_deleted = 0 AND timestamp >= ? AND timestamp < ?
+		GROUP BY `+chatPeriod, classID, start, end).Scan(&chats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get chat trends: %w", err)
+	}
+	for _, point := range chats {
+		if i, ok := index[point.PeriodStart.Format("2006-01-02")]; ok {
+			trends.Points[i].ChatMessages = point.ChatMessages
+		}
+	}
+
+	return &trends, nil
+}
+
+func (s *AnalyticsServiceImpl) checkClass(classID int) error {
+	var class models.Class
+	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
+		return fmt.Errorf("class not found: %w", err)
+	}
+	return nil
+}
+
+// trendPeriodStart truncates a time to the start of its week (Monday) or month,
+// matching the SQL expressions in trendPeriods
+func trendPeriodStart(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == models.AnalyticsIntervalMonth {
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// nextTrendPeriod moves a period start by a number of weeks or months
+func nextTrendPeriod(t time.Time, interval string, periods int) time.Time {
+	if interval == models.AnalyticsIntervalMonth {
+		return t.AddDate(0, periods, 0)
+	}
+	return t.AddDate(0, 0, 7*periods)
+}
+
+// ratio divides two counts, returning 0 when there is nothing to divide
+func ratio(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
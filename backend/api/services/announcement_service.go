@@ -13,10 +13,13 @@ import (
 type AnnouncementService interface {
 	Service
 	GetAnnouncements(classID int) ([]models.AnnouncementResponse, error)
+	GetStudentAnnouncements(classID, studentID int) ([]models.AnnouncementResponse, error)
 	GetAnnouncement(announcementID int) (models.AnnouncementResponse, error)
-	CreateAnnouncement(classID, userID int, content string, title string) (models.AnnouncementResponse, error)
+	GetStudentAnnouncement(classID, announcementID, studentID int) (models.AnnouncementResponse, error)
+	CreateAnnouncement(classID, userID int, content string, title string, sectionID *int) (models.AnnouncementResponse, error)
 	UpdateAnnouncement(announcementID, userID int, content string, title string) (models.AnnouncementResponse, error)
 	DeleteAnnouncement(announcementID, userID int) error
+	SetAnnouncementPinned(classID, announcementID int, pinned bool) (models.AnnouncementResponse, error)
 }
 
 // AnnouncementServiceImpl implements AnnouncementService
@@ -33,6 +36,17 @@ func NewAnnouncementService(db *gorm.DB) AnnouncementService {
 
 // GetAnnouncements retrieves all announcements for a class
 func (s *AnnouncementServiceImpl) GetAnnouncements(classID int) ([]models.AnnouncementResponse, error) {
+	return s.getAnnouncements(classID, s.db)
+}
+
+// GetStudentAnnouncements retrieves the announcements a student can see in a class:
+// those for the whole class and those for the student's section
+func (s *AnnouncementServiceImpl) GetStudentAnnouncements(classID, studentID int) ([]models.AnnouncementResponse, error) {
+	return s.getAnnouncements(classID, s.db.Scopes(studentSectionScope(classID, studentID)))
+}
+
+// getAnnouncements retrieves the announcements of a class matching the given query
+func (s *AnnouncementServiceImpl) getAnnouncements(classID int, query *gorm.DB) ([]models.AnnouncementResponse, error) {
 	// Check if class exists
 	var class models.Class
 	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
@@ -41,8 +55,9 @@ func (s *AnnouncementServiceImpl) GetAnnouncements(classID int) ([]models.Announ
 
 	// Get announcements
 	var announcements []models.Announcement
-	if err := s.db.Table("announcements").
+	if err := query.Table("announcements").
 		Where("class_id = ?", classID).
+		Order("announcements.is_pinned DESC").
 		Order("announcements.created_date DESC").
 		Find(&announcements).Error; err != nil {
 		return nil, fmt.Errorf("failed to get announcements: %w", err)
@@ -70,8 +85,20 @@ func (s *AnnouncementServiceImpl) GetAnnouncements(classID int) ([]models.Announ
 
 // GetAnnouncement retrieves a specific announcement
 func (s *AnnouncementServiceImpl) GetAnnouncement(announcementID int) (models.AnnouncementResponse, error) {
+	return s.getAnnouncement(s.db.Where("announcement_id = ?", announcementID))
+}
+
+// GetStudentAnnouncement retrieves an announcement of a class the student can
+// see: one for the whole class or for the student's section
+func (s *AnnouncementServiceImpl) GetStudentAnnouncement(classID, announcementID, studentID int) (models.AnnouncementResponse, error) {
+	return s.getAnnouncement(s.db.Where("announcement_id = ? AND class_id = ?", announcementID, classID).
+		Scopes(studentSectionScope(classID, studentID)))
+}
+
+// getAnnouncement retrieves the announcement matching the given query
+func (s *AnnouncementServiceImpl) getAnnouncement(query *gorm.DB) (models.AnnouncementResponse, error) {
 	var announcement models.Announcement
-	if err := s.db.Where("announcement_id = ?", announcementID).First(&announcement).Error; err != nil {
+	if err := query.First(&announcement).Error; err != nil {
 		return models.AnnouncementResponse{}, fmt.Errorf("announcement not found: %w", err)
 	}
 
@@ -90,19 +117,29 @@ func (s *AnnouncementServiceImpl) GetAnnouncement(announcementID int) (models.An
 }
 
 // CreateAnnouncement creates a new announcement
-func (s *AnnouncementServiceImpl) CreateAnnouncement(classID, userID int, content string, title string) (models.AnnouncementResponse, error) {
+func (s *AnnouncementServiceImpl) CreateAnnouncement(classID, userID int, content string, title string, sectionID *int) (models.AnnouncementResponse, error) {
 	// Check if class exists
 	var class models.Class
 	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
 		return models.AnnouncementResponse{}, fmt.Errorf("class not found: %w", err)
 	}
 
+	// Archived classes are read-only
+	if class.IsArchived {
+		return models.AnnouncementResponse{}, ErrClassArchived
+	}
+
 	// Check if user exists
 	var user models.User
 	if err := s.db.Where("user_id = ?", userID).First(&user).Error; err != nil {
 		return models.AnnouncementResponse{}, fmt.Errorf("user not found: %w", err)
 	}
 
+	// Check that the target section belongs to this class
+	if err := validateSection(s.db, classID, sectionID); err != nil {
+		return models.AnnouncementResponse{}, err
+	}
+
 	// Create announcement
 	announcement := models.Announcement{
 		ClassID:     classID,
@@ -111,6 +148,7 @@ func (s *AnnouncementServiceImpl) CreateAnnouncement(classID, userID int, conten
 		Content:     content,
 		CreatedDate: time.Now(),
 		IsPublished: true,
+		SectionID:   sectionID,
 	}
 
 	// Set virtual fields for compatibility
@@ -142,6 +180,11 @@ func (s *AnnouncementServiceImpl) UpdateAnnouncement(announcementID, userID int,
 		return models.AnnouncementResponse{}, errors.New("user is not authorized to update this announcement")
 	}
 
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, announcement.ClassID); err != nil {
+		return models.AnnouncementResponse{}, err
+	}
+
 	// Update announcement
 	announcement.Title = title
 	announcement.Content = content
@@ -180,6 +223,11 @@ func (s *AnnouncementServiceImpl) DeleteAnnouncement(announcementID, userID int)
 		return errors.New("user is not authorized to delete this announcement")
 	}
 
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, announcement.ClassID); err != nil {
+		return err
+	}
+
 	// Delete the announcement
 	if err := s.db.Delete(&announcement).Error; err != nil {
 		return fmt.Errorf("failed to delete announcement: %w", err)
@@ -187,3 +235,37 @@ func (s *AnnouncementServiceImpl) DeleteAnnouncement(announcementID, userID int)
 
 	return nil
 }
+
+// SetAnnouncementPinned pins or unpins an announcement.
+// Pinned announcements are listed first and can be carried over when a class is cloned.
+func (s *AnnouncementServiceImpl) SetAnnouncementPinned(classID, announcementID int, pinned bool) (models.AnnouncementResponse, error) {
+	// Get the announcement
+	var announcement models.Announcement
+	if err := s.db.Where("announcement_id = ? AND class_id = ?", announcementID, classID).First(&announcement).Error; err != nil {
+		return models.AnnouncementResponse{}, fmt.Errorf("announcement not found: %w", err)
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return models.AnnouncementResponse{}, err
+	}
+
+	if err := s.db.Model(&models.Announcement{}).
+		Where("announcement_id = ?", announcementID).
+		Update("is_pinned", pinned).Error; err != nil {
+		return models.AnnouncementResponse{}, fmt.Errorf("failed to update announcement: %w", err)
+	}
+	announcement.IsPinned = pinned
+
+	// Get user info
+	var user models.User
+	if err := s.db.Where("user_id = ?", announcement.CreatedBy).First(&user).Error; err != nil {
+		announcement.UserName = "Unknown User"
+		announcement.UserRole = "unknown"
+	} else {
+		announcement.UserName = user.FirstName + " " + user.LastName
+		announcement.UserRole = user.UserRole
+	}
+
+	return announcement.ToResponse(), nil
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ErrIdentitiesHidden is returned when a grader asks for a student by ID
+// while the assignment is graded anonymously
+var ErrIdentitiesHidden = errors.New("student identities are hidden while this assignment is graded anonymously, use the anonymous ID")
+
+// AnonymousGradingService hides student identities from graders of
+// anonymously graded assignments and keeps the grading audit log
+type AnonymousGradingService interface {
+	Service
+	GetStatus(classID, assignmentID int) (*models.AnonymousGradingStatus, error)
+	AnonymizeSubmissions(classID, assignmentID int, responses []models.SubmissionResponse) error
+	ResolveStudentID(classID, assignmentID int, studentParam string) (int, error)
+	GetAuditLog(classID, assignmentID int) ([]models.GradingAuditEntry, error)
+}
+
+// AnonymousGradingServiceImpl implements AnonymousGradingService
+type AnonymousGradingServiceImpl struct {
+	*BaseService
+}
+
+// NewAnonymousGradingService creates a new AnonymousGradingService
+func NewAnonymousGradingService(db *gorm.DB) AnonymousGradingService {
+	return &AnonymousGradingServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetStatus returns whether identities are hidden and how many submissions
+// still need a grade or a release before they are revealed
+func (s *AnonymousGradingServiceImpl) GetStatus(classID, assignmentID int) (*models.AnonymousGradingStatus, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.AnonymousGradingStatus{
+		AssignmentID:         assignment.AssignmentID,
+		AnonymousGrading:     assignment.AnonymousGrading,
+		IdentitiesHidden:     assignment.IdentitiesHidden(),
+		IdentitiesRevealedAt: assignment.IdentitiesRevealedAt,
+	}
+
+	var counts []struct {
+		Graded        bool
+		GradeReleased bool
+		Count         int
+	}
+	if err := s.db.Model(&models.Submission{}).
+		Select("CASE WHEN grade IS NULL THEN 0 ELSE 1 END AS graded, grade_released, COUNT(*) AS count").
+		Where("assignment_id = ?", assignmentID).
+		Group("CASE WHEN grade IS NULL THEN 0 ELSE 1 END, grade_released").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count submissions: %w", err)
+	}
+	for _, count := range counts {
+		status.Submissions += count.Count
+		switch {
+		case !count.Graded:
+			status.Ungraded += count.Count
+		case !count.GradeReleased:
+			status.PendingRelease += count.Count
+		}
+	}
+
+	return &status, nil
+}
+
+// AnonymizeSubmissions replaces the student IDs and names of submissions
+// with pseudonyms while the assignment's identities are hidden. Pseudonyms
+// are stable: a student keeps theirs for the whole assignment.
+func (s *AnonymousGradingServiceImpl) AnonymizeSubmissions(classID, assignmentID int, responses []models.SubmissionResponse) error {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return err
+	}
+	if !assignment.IdentitiesHidden() {
+		return nil
+	}
+
+	key, err := anonymityKey(s.db, assignment)
+	if err != nil {
+		return err
+	}
+
+	for i := range responses {
+		responses[i].AnonymousID, responses[i].StudentName = studentPseudonym(key, responses[i].StudentID)
+		responses[i].StudentID = 0
+	}
+
+	return nil
+}
+
+// ResolveStudentID turns the student of a submission URL into a student ID.
+// Graders of anonymously graded assignments must use the anonymous ID until
+// identities are revealed; afterwards both forms are accepted.
+func (s *AnonymousGradingServiceImpl) ResolveStudentID(classID, assignmentID int, studentParam string) (int, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !strings.HasPrefix(studentParam, models.AnonymousIDPrefix) {
+		studentID, err := strconv.Atoi(studentParam)
+		if err != nil {
+			return 0, errors.New("invalid student ID")
+		}
+		if assignment.IdentitiesHidden() {
+			return 0, ErrIdentitiesHidden
+		}
+		return studentID, nil
+	}
+
+	if !assignment.AnonymousGrading {
+		return 0, errors.New("assignment is not graded anonymously")
+	}
+
+	key, err := anonymityKey(s.db, assignment)
+	if err != nil {
+		return 0, err
+	}
+
+	// Pseudonyms cannot be reversed, so compare them with those of every
+	// student who is enrolled or has submitted
+	var studentIDs []int
+	if err := s.db.Model(&models.ClassEnrollment{}).Where("class_id = ?", classID).
+		Pluck("user_id", &studentIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get students: %w", err)
+	}
+	var submitterIDs []int
+	if err := s.db.Model(&models.Submission{}).Where("assignment_id = ?", assignmentID).
+		Pluck("user_id", &submitterIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get students: %w", err)
+	}
+
+	for _, studentID := range append(studentIDs, submitterIDs...) {
+		if anonymousID, _ := studentPseudonym(key, studentID); anonymousID == studentParam {
+			return studentID, nil
+		}
+	}
+
+	return 0, errors.New("student not found")
+}
+
+// GetAuditLog returns the grading audit log of an assignment, oldest first.
+// Students are shown by pseudonym until identities are revealed.
+func (s *AnonymousGradingServiceImpl) GetAuditLog(classID, assignmentID int) ([]models.GradingAuditEntry, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.GradingAuditEntry
+	if err := s.db.Where("assignment_id = ?", assignmentID).Order("created_at, audit_id").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get grading audit log: %w", err)
+	}
+
+	var key string
+	if assignment.IdentitiesHidden() {
+		if key, err = anonymityKey(s.db, assignment); err != nil {
+			return nil, err
+		}
+	}
+
+	graders := make(map[int]string)
+	for i := range entries {
+		if _, ok := graders[entries[i].GraderID]; !ok {
+			var teacher models.TeacherProfile
+			if err := s.db.Where("user_id = ?", entries[i].GraderID).First(&teacher).Error; err == nil {
+				graders[entries[i].GraderID] = fmt.Sprintf("%s %s", teacher.FirstName, teacher.LastName)
+			}
+		}
+		entries[i].GraderName = graders[entries[i].GraderID]
+
+		if entries[i].StudentID == nil {
+			continue
+		}
+		if key != "" {
+			entries[i].AnonymousID, entries[i].StudentName = studentPseudonym(key, *entries[i].StudentID)
+			entries[i].StudentID = nil
+		} else {
+			entries[i].StudentName = studentDisplayName(s.db, *entries[i].StudentID)
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *AnonymousGradingServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// anonymityKey returns the secret the assignment's pseudonyms are derived
+// from, creating it when the assignment was saved without one
+func anonymityKey(db *gorm.DB, assignment *models.Assignment) (string, error) {
+	if assignment.AnonymityKey != "" {
+		return assignment.AnonymityKey, nil
+	}
+
+	key, err := newAnonymityKey()
+	if err != nil {
+		return "", err
+	}
+
+	// Only set the key when no concurrent request has set it already
+	if err := db.Model(&models.Assignment{}).
+		Where("assignment_id = ? AND (anonymity_key IS NULL OR anonymity_key = '')", assignment.AssignmentID).
+		Update("anonymity_key", key).Error; err != nil {
+		return "", fmt.Errorf("failed to save anonymity key: %w", err)
+	}
+	var stored models.Assignment
+	if err := db.Select("assignment_id", "anonymity_key").Where("assignment_id = ?", assignment.AssignmentID).
+		First(&stored).Error; err != nil {
+		return "", fmt.Errorf("failed to get anonymity key: %w", err)
+	}
+	assignment.AnonymityKey = stored.AnonymityKey
+
+	return assignment.AnonymityKey, nil
+}
+
+// newAnonymityKey generates the secret of an assignment's pseudonyms
+func newAnonymityKey() (string, error) {
+	key, err := utils.GenerateSecureRandomString(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate anonymity key: %w", err)
+	}
+	return key, nil
+}
+
+// studentPseudonym returns the anonymous ID and display name of a student
+// for an assignment with the given anonymity key
+func studentPseudonym(key string, studentID int) (string, string) {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strconv.Itoa(studentID)))
+	code := hex.EncodeToString(mac.Sum(nil)[:4])
+	return models.AnonymousIDPrefix + code, "Student " + strings.ToUpper(code)
+}
+
+// logGradingAudit records that a grader graded or regraded a student's
+// submission, noting whether the student's identity was hidden at the time
+func logGradingAudit(db *gorm.DB, assignment *models.Assignment, graderID, studentID int, action string, grade *int) error {
+	entry := models.GradingAuditEntry{
+		AssignmentID:   assignment.AssignmentID,
+		GraderID:       graderID,
+		StudentID:      &studentID,
+		Action:         action,
+		Grade:          grade,
+		IdentityHidden: assignment.IdentitiesHidden(),
+	}
+	return db.Create(&entry).Error
+}
+
+// revealIdentitiesIfComplete reveals the students of an anonymously graded
+// assignment once every submission has a released grade
+func revealIdentitiesIfComplete(db *gorm.DB, assignment *models.Assignment, userID int) error {
+	if !assignment.IdentitiesHidden() {
+		return nil
+	}
+
+	var remaining int64
+	if err := db.Model(&models.Submission{}).
+		Where("assignment_id = ? AND (grade IS NULL OR grade_released = ?)", assignment.AssignmentID, false).
+		Count(&remaining).Error; err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return nil
+	}
+
+	revealedAt := time.Now()
+	if err := db.Model(assignment).Update("identities_revealed_at", revealedAt).Error; err != nil {
+		return err
+	}
+	assignment.IdentitiesRevealedAt = &revealedAt
+
+	entry := models.GradingAuditEntry{
+		AssignmentID: assignment.AssignmentID,
+		GraderID:     userID,
+		Action:       models.GradingAuditIdentitiesRevealed,
+	}
+	return db.Create(&entry).Error
+}
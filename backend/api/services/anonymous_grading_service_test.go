@@ -0,0 +1,65 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+)
+
+func TestStudentPseudonym(t *testing.T) {
+	// The code is the first 4 bytes of HMAC-SHA256(key, student ID) in hex
+	tests := []struct {
+		key       string
+		studentID int
+		wantID    string
+		wantName  string
+	}{
+		{"assignment-key", 1, models.AnonymousIDPrefix + "50508709", "Student 50508709"},
+		{"assignment-key", 2, models.AnonymousIDPrefix + "b8eba939", "Student B8EBA939"},
+		{"other-key", 1, models.AnonymousIDPrefix + "477510ef", "Student 477510EF"},
+		{"", 0, models.AnonymousIDPrefix + "9979e4c3", "Student 9979E4C3"},
+	}
+
+	for _, tt := range tests {
+		id, name := studentPseudonym(tt.key, tt.studentID)
+		if id != tt.wantID || name != tt.wantName {
+			t.Errorf("studentPseudonym(%q, %d) = %q, %q, want %q, %q", tt.key, tt.studentID, id, name, tt.wantID, tt.wantName)
+		}
+	}
+}
+
+func TestStudentPseudonymsAreDistinct(t *testing.T) {
+	pattern := regexp.MustCompile("^" + regexp.QuoteMeta(models.AnonymousIDPrefix) + "[0-9a-f]{8}$")
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"generated key", "k3Jd9sLq0Pz7Xv2Nw8Tc5Yb1Rm6Fh4Ga"},
+		{"short key", "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[string]int)
+			for studentID := 1; studentID <= 500; studentID++ {
+				id, _ := studentPseudonym(tt.key, studentID)
+				if !pattern.MatchString(id) {
+					t.Fatalf("pseudonym %q of student %d has the wrong format", id, studentID)
+				}
+				if other, ok := seen[id]; ok {
+					t.Fatalf("students %d and %d share pseudonym %q", other, studentID, id)
+				}
+				seen[id] = studentID
+
+				if again, _ := studentPseudonym(tt.key, studentID); again != id {
+					t.Fatalf("pseudonym of student %d is not stable: %q then %q", studentID, id, again)
+				}
+				if otherKey, _ := studentPseudonym(tt.key+"x", studentID); otherKey == id {
+					t.Errorf("student %d has pseudonym %q under two keys", studentID, id)
+				}
+			}
+		})
+	}
+}
@@ -14,13 +14,16 @@ import (
 type AssignmentService interface {
 	Service
 	GetAssignments(classID int) ([]models.AssignmentResponse, error)
+	GetStudentAssignments(classID, studentID int) ([]models.AssignmentResponse, error)
 	GetAssignment(classID, assignmentID int) (models.AssignmentResponse, error)
+	GetStudentAssignment(classID, assignmentID, studentID int) (models.AssignmentResponse, error)
 	CreateAssignment(classID, teacherID int, assignment models.Assignment) (models.AssignmentResponse, error)
 	UpdateAssignment(classID, assignmentID int, assignment models.Assignment) (models.AssignmentResponse, error)
 	DeleteAssignment(classID, assignmentID int) error
 	SubmitAssignment(classID, assignmentID, studentID int, content string, fileURL string) (models.SubmissionResponse, error)
 	GetSubmission(classID, assignmentID, studentID int) (models.SubmissionResponse, error)
 	GradeSubmission(classID, assignmentID, studentID, teacherID int, grade int, feedback string) (models.SubmissionResponse, error)
+	GradeSubmissionWithRubric(classID, assignmentID, studentID, teacherID int, scores []models.RubricScore, feedback string) (models.SubmissionResponse, error)
 	GetAssignmentSubmissions(classID, assignmentID int) ([]models.SubmissionResponse, error)
 }
 
@@ -59,6 +62,42 @@ func (s *AssignmentServiceImpl) GetAssignments(classID int) ([]models.Assignment
 	return responses, nil
 }
 
+// GetStudentAssignments retrieves the assignments a student can see in a class:
+// those for the whole class and those for the student's section
+func (s *AssignmentServiceImpl) GetStudentAssignments(classID, studentID int) ([]models.AssignmentResponse, error) {
+	// Check if class exists
+	var class models.Class
+	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+
+	var assignments []models.Assignment
+	if err := s.db.Where("class_id = ?", classID).
+		Scopes(studentSectionScope(classID, studentID)).
+		Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+
+	dueDates, err := studentDueDates(s.db, classID, studentID, assignments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due dates: %w", err)
+	}
+
+	responses := make([]models.AssignmentResponse, 0, len(assignments))
+	for _, assignment := range assignments {
+		response := assignment.ToResponse()
+
+		// Show the student's own due date when an extension or accommodation moves it
+		if dueDate := dueDates[assignment.AssignmentID]; !dueDate.Equal(assignment.DueDate) {
+			response.EffectiveDueDate = &dueDate
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
 // GetAssignment retrieves a specific assignment
 func (s *AssignmentServiceImpl) GetAssignment(classID, assignmentID int) (models.AssignmentResponse, error) {
 	// Check if class exists
@@ -79,6 +118,29 @@ func (s *AssignmentServiceImpl) GetAssignment(classID, assignmentID int) (models
 	return response, nil
 }
 
+// GetStudentAssignment retrieves an assignment the student can see: one for
+// the whole class or for the student's section
+func (s *AssignmentServiceImpl) GetStudentAssignment(classID, assignmentID, studentID int) (models.AssignmentResponse, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).
+		Scopes(studentSectionScope(classID, studentID)).
+		First(&assignment).Error; err != nil {
+		return models.AssignmentResponse{}, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	dueDates, err := studentDueDates(s.db, classID, studentID, []models.Assignment{assignment})
+	if err != nil {
+		return models.AssignmentResponse{}, fmt.Errorf("failed to get due dates: %w", err)
+	}
+
+	response := assignment.ToResponse()
+	if dueDate := dueDates[assignment.AssignmentID]; !dueDate.Equal(assignment.DueDate) {
+		response.EffectiveDueDate = &dueDate
+	}
+
+	return response, nil
+}
+
 // CreateAssignment creates a new assignment
 func (s *AssignmentServiceImpl) CreateAssignment(classID, teacherID int, assignment models.Assignment) (models.AssignmentResponse, error) {
 	// Check if class exists
@@ -87,15 +149,38 @@ func (s *AssignmentServiceImpl) CreateAssignment(classID, teacherID int, assignm
 		return models.AssignmentResponse{}, fmt.Errorf("class not found: %w", err)
 	}
 
+	// Archived classes are read-only
+	if class.IsArchived {
+		return models.AssignmentResponse{}, ErrClassArchived
+	}
+
 	// Check if user is a teacher for this class
 	var classTeacher models.ClassTeacher
 	if err := s.db.Where("class_id = ? AND user_id = ?", classID, teacherID).First(&classTeacher).Error; err != nil {
 		return models.AssignmentResponse{}, errors.New("user is not a teacher for this class")
 	}
 
+	// Check that the target section belongs to this class
+	if err := validateSection(s.db, classID, assignment.SectionID); err != nil {
+		return models.AssignmentResponse{}, err
+	}
+
 	// Set class ID and created_by
 	assignment.ClassID = classID
 	assignment.CreatedBy = teacherID
+	if assignment.AssignmentType == "" {
+		assignment.AssignmentType = models.AssignmentTypeStandard
+	}
+	if assignment.GroupMode == "" {
+		assignment.GroupMode = models.GroupModeNone
+	}
+	if assignment.AnonymousGrading {
+		key, err := newAnonymityKey()
+		if err != nil {
+			return models.AssignmentResponse{}, err
+		}
+		assignment.AnonymityKey = key
+	}
 
 	// Create the assignment
 	if err := s.db.Create(&assignment).Error; err != nil {
@@ -113,6 +198,11 @@ func (s *AssignmentServiceImpl) UpdateAssignment(classID, assignmentID int, assi
 		return models.AssignmentResponse{}, fmt.Errorf("assignment not found: %w", err)
 	}
 
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return models.AssignmentResponse{}, err
+	}
+
 	// Store original due date to check if it changed
 	originalDueDate := existingAssignment.DueDate
 
@@ -122,6 +212,33 @@ func (s *AssignmentServiceImpl) UpdateAssignment(classID, assignmentID int, assi
 	existingAssignment.DueDate = assignment.DueDate
 	existingAssignment.PointsPossible = assignment.PointsPossible
 	existingAssignment.IsPublished = assignment.IsPublished
+	existingAssignment.SectionID = assignment.SectionID
+	existingAssignment.MaxResubmissions = assignment.MaxResubmissions
+	existingAssignment.AllowResubmitAfterGrading = assignment.AllowResubmitAfterGrading
+	existingAssignment.GroupMode = assignment.GroupMode
+	existingAssignment.MaxGroupSize = assignment.MaxGroupSize
+
+	// Identities stay hidden until every grade is released, and turning
+	// anonymous grading on again hides them again
+	if existingAssignment.IdentitiesHidden() && !assignment.AnonymousGrading {
+		return models.AssignmentResponse{}, errors.New("anonymous grading cannot be turned off before all grades are released")
+	}
+	if assignment.AnonymousGrading && !existingAssignment.AnonymousGrading {
+		existingAssignment.IdentitiesRevealedAt = nil
+		if existingAssignment.AnonymityKey == "" {
+			key, err := newAnonymityKey()
+			if err != nil {
+				return models.AssignmentResponse{}, err
+			}
+			existingAssignment.AnonymityKey = key
+		}
+	}
+	existingAssignment.AnonymousGrading = assignment.AnonymousGrading
+
+	// Check that the target section belongs to this class
+	if err := validateSection(s.db, classID, existingAssignment.SectionID); err != nil {
+		return models.AssignmentResponse{}, err
+	}
 
 	// Preserve the created_by field if it's set in the update
 	if assignment.CreatedBy > 0 {
@@ -133,33 +250,12 @@ func (s *AssignmentServiceImpl) UpdateAssignment(classID, assignmentID int, assi
 		return models.AssignmentResponse{}, fmt.Errorf("failed to update assignment: %w", err)
 	}
 
-	// If due date changed, update is_late flag for all submissions
+	// If due date changed, update is_late flag for all submissions against
+	// each student's due date, including extensions and accommodations
 	dueDateChanged := !originalDueDate.Equal(existingAssignment.DueDate)
 	if dueDateChanged {
-		var submissions []models.Submission
-		if err := s.db.Where("assignment_id = ?", assignmentID).Find(&submissions).Error; err != nil {
-			log.Printf("Warning: Failed to fetch submissions when updating assignment: %v", err)
-		} else {
-			for _, submission := range submissions {
-				// Check if the submission should be marked as late based on the new due date
-				isLate := false
-				if !existingAssignment.DueDate.IsZero() && !submission.SubmissionDate.IsZero() && 
-					submission.SubmissionDate.After(existingAssignment.DueDate) {
-					isLate = true
-				}
-				
-				// Only update if the is_late status changed
-				if submission.IsLate != isLate {
-					// Update the is_late flag
-					if err := s.db.Model(&models.Submission{}).
-						Where("submission_id = ?", submission.SubmissionID).
-						Update("is_late", isLate).Error; err != nil {
-						log.Printf("Warning: Failed to update is_late flag for submission %d: %v", submission.SubmissionID, err)
-					} else {
-						log.Printf("Updated is_late status to %v for submission %d", isLate, submission.SubmissionID)
-					}
-				}
-			}
+		if err := recalculateLateFlags(s.db, &existingAssignment, nil); err != nil {
+			log.Printf("Warning: Failed to update is_late flags when updating assignment: %v", err)
 		}
 	}
 
@@ -174,6 +270,11 @@ func (s *AssignmentServiceImpl) DeleteAssignment(classID, assignmentID int) erro
 		return fmt.Errorf("assignment not found: %w", err)
 	}
 
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
 	// Delete the assignment
 	if err := s.db.Delete(&assignment).Error; err != nil {
 		return fmt.Errorf("failed to delete assignment: %w", err)
@@ -190,35 +291,98 @@ func (s *AssignmentServiceImpl) SubmitAssignment(classID, assignmentID, studentI
 		return models.SubmissionResponse{}, fmt.Errorf("assignment not found: %w", err)
 	}
 
-	// Check if student is enrolled in the class
-	var classEnrollment models.ClassEnrollment
-	if err := s.db.Where("class_id = ? AND user_id = ?", classID, studentID).First(&classEnrollment).Error; err != nil {
-		return models.SubmissionResponse{}, errors.New("student is not enrolled in this class")
+	if err := checkCanSubmit(s.db, &assignment, studentID); err != nil {
+		return models.SubmissionResponse{}, err
 	}
 
-	// Check if the due date has passed to determine if submission is late
-	isLate := false
-	if !assignment.DueDate.IsZero() && time.Now().After(assignment.DueDate) {
-		isLate = true
+	// Quizzes are submitted through quiz attempts
+	if assignment.AssignmentType == models.AssignmentTypeQuiz {
+		return models.SubmissionResponse{}, errors.New("quiz assignments must be submitted as quiz attempts")
 	}
 
+	// Group assignments are submitted once for every member of the group
+	if assignment.IsGroupAssignment() {
+		submission, version, err := submitGroupWork(s.db, &assignment, studentID, content, fileURL)
+		if err != nil {
+			return models.SubmissionResponse{}, err
+		}
+
+		submission.StudentName = studentDisplayName(s.db, studentID)
+		submission.Assignment = &assignment
+		response := submission.ToResponse()
+		response.Version = version
+
+		return response, nil
+	}
+
+	// Check if the student's due date, including extensions and accommodations,
+	// has passed to determine if submission is late
+	dueDate, err := effectiveDueDate(s.db, &assignment, studentID)
+	if err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("failed to get due date: %w", err)
+	}
+	isLate := isLateFor(dueDate, time.Now())
+
 	// Check if submission already exists
 	var existingSubmission models.Submission
 	result := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&existingSubmission)
 
 	if result.Error == nil {
-		// Update existing submission
-		existingSubmission.Content = content
-		existingSubmission.SubmissionDate = time.Now()
-		existingSubmission.Status = "submitted"
-		existingSubmission.IsLate = isLate
-
-		// Update file URL if provided
-		if fileURL != "" {
-			existingSubmission.FileURL = fileURL
+		// Graded work can only be resubmitted when the teacher allows it
+		if existingSubmission.Grade != nil && !assignment.AllowResubmitAfterGrading {
+			return models.SubmissionResponse{}, errors.New("submission has already been graded and resubmissions are not allowed")
+		}
+
+		storedVersions, err := countSubmissionVersions(s.db, existingSubmission.SubmissionID)
+		if err != nil {
+			return models.SubmissionResponse{}, fmt.Errorf("failed to count submission versions: %w", err)
+		}
+
+		// The first version is the original submission, the rest are resubmissions
+		resubmissions := max(storedVersions, 1) - 1
+		if assignment.MaxResubmissions != nil && resubmissions >= *assignment.MaxResubmissions {
+			return models.SubmissionResponse{}, fmt.Errorf("resubmission limit reached: %d resubmission(s) allowed", *assignment.MaxResubmissions)
 		}
 
-		if err := s.db.Save(&existingSubmission).Error; err != nil {
+		// Update existing submission, keeping the previous work as a version
+		var version int
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			// Submissions made before versions were recorded keep their work as version 1
+			if storedVersions == 0 {
+				if _, err := recordSubmissionVersion(tx, &existingSubmission); err != nil {
+					return err
+				}
+			}
+
+			existingSubmission.Content = content
+			existingSubmission.SubmissionDate = time.Now()
+			existingSubmission.Status = "submitted"
+			existingSubmission.IsLate = isLate
+
+			// Resubmitted graded work needs a new grade
+			if existingSubmission.Grade != nil {
+				existingSubmission.Grade = nil
+				existingSubmission.GradedBy = nil
+				existingSubmission.GradedDate = nil
+				existingSubmission.GradeReleased = false
+				if err := tx.Where("submission_id = ?", existingSubmission.SubmissionID).Delete(&models.RubricScore{}).Error; err != nil {
+					return err
+				}
+			}
+
+			// Update file URL if provided
+			if fileURL != "" {
+				existingSubmission.FileURL = fileURL
+			}
+
+			if err := tx.Save(&existingSubmission).Error; err != nil {
+				return err
+			}
+
+			version, err = recordSubmissionVersion(tx, &existingSubmission)
+			return err
+		})
+		if err != nil {
 			return models.SubmissionResponse{}, fmt.Errorf("failed to update submission: %w", err)
 		}
 
@@ -233,6 +397,7 @@ func (s *AssignmentServiceImpl) SubmitAssignment(classID, assignmentID, studentI
 
 		// Create the response with assignment data
 		response := existingSubmission.ToResponse()
+		response.Version = version
 
 		// Log that we're including assignment data
 		log.Printf("Including assignment data in submission response: %s", assignment.Title)
@@ -251,7 +416,17 @@ func (s *AssignmentServiceImpl) SubmitAssignment(classID, assignmentID, studentI
 		Status:         "submitted",
 	}
 
-	if err := s.db.Create(&submission).Error; err != nil {
+	var version int
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&submission).Error; err != nil {
+			return err
+		}
+
+		var err error
+		version, err = recordSubmissionVersion(tx, &submission)
+		return err
+	})
+	if err != nil {
 		return models.SubmissionResponse{}, fmt.Errorf("failed to create submission: %w", err)
 	}
 
@@ -266,6 +441,7 @@ func (s *AssignmentServiceImpl) SubmitAssignment(classID, assignmentID, studentI
 
 	// Create the response with assignment data
 	response := submission.ToResponse()
+	response.Version = version
 
 	// Log that we're including assignment data
 	log.Printf("Including assignment data in submission response: %s", assignment.Title)
@@ -273,6 +449,29 @@ func (s *AssignmentServiceImpl) SubmitAssignment(classID, assignmentID, studentI
 	return response, nil
 }
 
+// checkCanSubmit checks that a student may submit work for an assignment:
+// the class is not archived, the student is enrolled and, for section
+// assignments, the student is in that section
+func checkCanSubmit(db *gorm.DB, assignment *models.Assignment, studentID int) error {
+	// Archived classes are read-only
+	if err := ensureClassWritable(db, assignment.ClassID); err != nil {
+		return err
+	}
+
+	// Check if student is enrolled in the class
+	var classEnrollment models.ClassEnrollment
+	if err := db.Where("class_id = ? AND user_id = ? AND is_active = ?", assignment.ClassID, studentID, true).First(&classEnrollment).Error; err != nil {
+		return errors.New("student is not enrolled in this class")
+	}
+
+	// Section assignments only accept submissions from that section
+	if assignment.SectionID != nil && (classEnrollment.SectionID == nil || *classEnrollment.SectionID != *assignment.SectionID) {
+		return errors.New("assignment is not assigned to the student's section")
+	}
+
+	return nil
+}
+
 // GetSubmission retrieves a submission
 func (s *AssignmentServiceImpl) GetSubmission(classID, assignmentID, studentID int) (models.SubmissionResponse, error) {
 	// Check if assignment exists
@@ -328,6 +527,24 @@ func (s *AssignmentServiceImpl) GetSubmission(classID, assignmentID, studentID i
 	// Create the response with assignment data
 	response := submission.ToResponse()
 
+	scores, err := loadRubricScores(s.db, []int{submission.SubmissionID})
+	if err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("failed to get rubric scores: %w", err)
+	}
+	response.RubricScores = scores[submission.SubmissionID]
+
+	versions, err := countSubmissionVersions(s.db, submission.SubmissionID)
+	if err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("failed to count submission versions: %w", err)
+	}
+	response.Version = max(versions, 1)
+
+	responses := []models.SubmissionResponse{response}
+	if err := withPeerReviewSummaries(s.db, &assignment, responses); err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("failed to get peer reviews: %w", err)
+	}
+	response = responses[0]
+
 	// Log that we're including assignment data
 	log.Printf("Including assignment data in submission response: %s", assignment.Title)
 
@@ -373,6 +590,9 @@ func (s *AssignmentServiceImpl) GetAssignmentSubmissions(classID, assignmentID i
 	for _, submission := range submissions {
 		responses = append(responses, submission.ToResponse())
 	}
+	if err := withRubricScores(s.db, responses); err != nil {
+		return nil, fmt.Errorf("failed to get rubric scores: %w", err)
+	}
 
 	// Also get students enrolled in the class who haven't submitted yet
 	type EnrolledStudent struct {
@@ -418,10 +638,15 @@ func (s *AssignmentServiceImpl) GetAssignmentSubmissions(classID, assignmentID i
 		}
 	}
 
+	if err := withPeerReviewSummaries(s.db, &assignment, responses); err != nil {
+		return nil, fmt.Errorf("failed to get peer reviews: %w", err)
+	}
+
 	return responses, nil
 }
 
-// GradeSubmission grades a submission
+// GradeSubmission grades a submission. The grade is a draft, hidden from the
+// student, until the assignment's grades are released.
 func (s *AssignmentServiceImpl) GradeSubmission(classID, assignmentID, studentID, teacherID int, grade int, feedback string) (models.SubmissionResponse, error) {
 	// Check if assignment exists
 	var assignment models.Assignment
@@ -435,6 +660,11 @@ func (s *AssignmentServiceImpl) GradeSubmission(classID, assignmentID, studentID
 		return models.SubmissionResponse{}, errors.New("user is not a teacher for this class")
 	}
 
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return models.SubmissionResponse{}, err
+	}
+
 	// Get the submission
 	var submission models.Submission
 	if err := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&submission).Error; err != nil {
@@ -451,8 +681,19 @@ func (s *AssignmentServiceImpl) GradeSubmission(classID, assignmentID, studentID
 	submission.Status = "graded"
 	submission.GradedBy = &gradedBy
 	submission.GradedDate = &gradedDate
+	submission.GradeReleased = false
 
-	if err := s.db.Save(&submission).Error; err != nil {
+	// A single grade replaces any earlier rubric scores
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("submission_id = ?", submission.SubmissionID).Delete(&models.RubricScore{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&submission).Error; err != nil {
+			return err
+		}
+		return logGradingAudit(tx, &assignment, teacherID, studentID, models.GradingAuditGraded, submission.Grade)
+	})
+	if err != nil {
 		return models.SubmissionResponse{}, fmt.Errorf("failed to update submission: %w", err)
 	}
 
@@ -464,3 +705,87 @@ func (s *AssignmentServiceImpl) GradeSubmission(classID, assignmentID, studentID
 
 	return submission.ToResponse(), nil
 }
+
+// GradeSubmissionWithRubric grades a submission by scoring each criterion of the
+// assignment's rubric. The total is stored as the submission's draft grade.
+func (s *AssignmentServiceImpl) GradeSubmissionWithRubric(classID, assignmentID, studentID, teacherID int, scores []models.RubricScore, feedback string) (models.SubmissionResponse, error) {
+	// Check if assignment exists
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("assignment not found: %w", err)
+	}
+	if assignment.RubricID == nil {
+		return models.SubmissionResponse{}, errors.New("assignment has no rubric")
+	}
+
+	// Check if teacher is assigned to the class
+	var classTeacher models.ClassTeacher
+	if err := s.db.Where("class_id = ? AND user_id = ?", classID, teacherID).First(&classTeacher).Error; err != nil {
+		return models.SubmissionResponse{}, errors.New("user is not a teacher for this class")
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return models.SubmissionResponse{}, err
+	}
+
+	// Get the submission
+	var submission models.Submission
+	if err := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&submission).Error; err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("submission not found: %w", err)
+	}
+
+	rubric, err := loadRubric(s.db, *assignment.RubricID)
+	if err != nil {
+		return models.SubmissionResponse{}, err
+	}
+
+	grade, err := scoreWithRubric(rubric, assignment.PointsPossible, scores)
+	if err != nil {
+		return models.SubmissionResponse{}, err
+	}
+
+	gradedBy := teacherID
+	gradedDate := time.Now()
+	submission.Grade = &grade
+	submission.Feedback = feedback
+	submission.Status = "graded"
+	submission.GradedBy = &gradedBy
+	submission.GradedDate = &gradedDate
+	submission.GradeReleased = false
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("submission_id = ?", submission.SubmissionID).Delete(&models.RubricScore{}).Error; err != nil {
+			return err
+		}
+		for i := range scores {
+			scores[i].ScoreID = 0
+			scores[i].SubmissionID = submission.SubmissionID
+			if err := tx.Create(&scores[i]).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&submission).Error; err != nil {
+			return err
+		}
+		return logGradingAudit(tx, &assignment, teacherID, studentID, models.GradingAuditGraded, submission.Grade)
+	})
+	if err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("failed to update submission: %w", err)
+	}
+
+	// Get student name
+	var student models.StudentProfile
+	if err := s.db.Where("user_id = ?", studentID).First(&student).Error; err == nil {
+		submission.StudentName = fmt.Sprintf("%s %s", student.FirstName, student.LastName)
+	}
+
+	response := submission.ToResponse()
+	rubricScores, err := loadRubricScores(s.db, []int{submission.SubmissionID})
+	if err != nil {
+		return models.SubmissionResponse{}, fmt.Errorf("failed to get rubric scores: %w", err)
+	}
+	response.RubricScores = rubricScores[submission.SubmissionID]
+
+	return response, nil
+}
@@ -0,0 +1,713 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// AtRiskService flags students who are falling behind using configurable
+// rules per class, and alerts their teachers and guardians
+type AtRiskService interface {
+	Service
+	GetRules(classID int) ([]models.AtRiskRule, error)
+	CreateRule(classID, userID int, rule models.AtRiskRule) (*models.AtRiskRule, error)
+	UpdateRule(classID, ruleID int, rule models.AtRiskRule) (*models.AtRiskRule, error)
+	DeleteRule(classID, ruleID int) error
+	GetAtRiskStudents(classID int) ([]models.AtRiskStudent, error)
+	EvaluateClass(classID int) (*models.AtRiskEvaluation, error)
+	EvaluateAllClasses() (int, error)
+}
+
+// AtRiskServiceImpl implements AtRiskService
+type AtRiskServiceImpl struct {
+	*BaseService
+}
+
+// NewAtRiskService creates a new AtRiskService
+func NewAtRiskService(db *gorm.DB) AtRiskService {
+	return &AtRiskServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetRules returns the at-risk rules of a class
+func (s *AtRiskServiceImpl) GetRules(classID int) ([]models.AtRiskRule, error) {
+	if _, err := s.getClass(classID); err != nil {
+		return nil, err
+	}
+
+	var rules []models.AtRiskRule
+	if err := s.db.Where("class_id = ?", classID).Order("rule_id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get at-risk rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateRule adds an at-risk rule to a class. Rules without a name are named
+// after their condition.
+func (s *AtRiskServiceImpl) CreateRule(classID, userID int, rule models.AtRiskRule) (*models.AtRiskRule, error) {
+	if _, err := s.getClass(classID); err != nil {
+		return nil, err
+	}
+	if err := validateAtRiskRule(&rule); err != nil {
+		return nil, err
+	}
+
+	rule.RuleID = 0
+	rule.ClassID = classID
+	rule.CreatedBy = userID
+	if err := s.db.Create(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create at-risk rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// UpdateRule replaces the condition and alert settings of a rule. Flags the
+// rule raised are re-checked at the next evaluation.
+func (s *AtRiskServiceImpl) UpdateRule(classID, ruleID int, rule models.AtRiskRule) (*models.AtRiskRule, error) {
+	existing, err := s.getRule(classID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAtRiskRule(&rule); err != nil {
+		return nil, err
+	}
+
+	existing.Name = rule.Name
+	existing.RuleType = rule.RuleType
+	existing.Threshold = rule.Threshold
+	existing.IsEnabled = rule.IsEnabled
+	existing.NotifyTeachers = rule.NotifyTeachers
+	existing.NotifyGuardians = rule.NotifyGuardians
+	if err := s.db.Save(existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to update at-risk rule: %w", err)
+	}
+	return existing, nil
+}
+
+// DeleteRule removes a rule together with the flags it raised
+func (s *AtRiskServiceImpl) DeleteRule(classID, ruleID int) error {
+	rule, err := s.getRule(classID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", rule.RuleID).Delete(&models.AtRiskFlag{}).Error; err != nil {
+			return fmt.Errorf("failed to delete at-risk flags: %w", err)
+		}
+		if err := tx.Delete(rule).Error; err != nil {
+			return fmt.Errorf("failed to delete at-risk rule: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetAtRiskStudents returns the students of a class with active flags, the
+// most flagged first
+func (s *AtRiskServiceImpl) GetAtRiskStudents(classID int) ([]models.AtRiskStudent, error) {
+	if _, err := s.getClass(classID); err != nil {
+		return nil, err
+	}
+
+	var flags []models.AtRiskFlag
+	if err := s.db.Where("class_id = ? AND resolved_at IS NULL", classID).
+		Order("flagged_at, flag_id").Find(&flags).Error; err != nil {
+		return nil, fmt.Errorf("failed to get at-risk flags: %w", err)
+	}
+
+	var rules []models.AtRiskRule
+	if err := s.db.Where("class_id = ?", classID).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get at-risk rules: %w", err)
+	}
+	ruleByID := make(map[int]models.AtRiskRule, len(rules))
+	for _, rule := range rules {
+		ruleByID[rule.RuleID] = rule
+	}
+
+	roster, err := atRiskRoster(s.db, classID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(roster))
+	for _, student := range roster {
+		names[student.UserID] = student.StudentName
+	}
+
+	var students []models.AtRiskStudent
+	index := make(map[int]int)
+	for _, flag := range flags {
+		rule := ruleByID[flag.RuleID]
+		flag.RuleName = rule.Name
+		flag.RuleType = rule.RuleType
+
+		i, ok := index[flag.StudentID]
+		if !ok {
+			name := names[flag.StudentID]
+			if name == "" {
+				name = studentDisplayName(s.db, flag.StudentID)
+			}
+			i = len(students)
+			index[flag.StudentID] = i
+			students = append(students, models.AtRiskStudent{StudentID: flag.StudentID, StudentName: name})
+		}
+		students[i].Flags = append(students[i].Flags, flag)
+	}
+
+	sort.SliceStable(students, func(i, j int) bool {
+		if len(students[i].Flags) != len(students[j].Flags) {
+			return len(students[i].Flags) > len(students[j].Flags)
+		}
+		return students[i].StudentName < students[j].StudentName
+	})
+
+	if students == nil {
+		students = []models.AtRiskStudent{}
+	}
+	return students, nil
+}
+
+// EvaluateClass runs the enabled rules of a class against its actively
+// enrolled students. New flags notify the class's teachers and the student's
+// guardians when the rule asks for it; flags that no longer match are
+// resolved. Students are alerted about only once per flag.
+func (s *AtRiskServiceImpl) EvaluateClass(classID int) (*models.AtRiskEvaluation, error) {
+	class, err := s.getClass(classID)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateAtRiskRules(s.db, class)
+}
+
+// EvaluateAllClasses evaluates the rules of every class that is not
+// archived and has enabled rules. It returns the number of classes evaluated.
+func (s *AtRiskServiceImpl) EvaluateAllClasses() (int, error) {
+	var classes []models.Class
+	if err := s.db.Where("is_archived = ? AND class_id IN (?)", false,
+		s.db.Model(&models.AtRiskRule{}).Select("class_id").Where("is_enabled = ?", true)).
+		Find(&classes).Error; err != nil {
+		return 0, fmt.Errorf("failed to get classes with at-risk rules: %w", err)
+	}
+
+	evaluated := 0
+	for i := range classes {
+		if _, err := evaluateAtRiskRules(s.db, &classes[i]); err != nil {
+			log.Printf("Failed to evaluate at-risk rules of class %d: %v", classes[i].ClassID, err)
+			continue
+		}
+		evaluated++
+	}
+	return evaluated, nil
+}
+
+func (s *AtRiskServiceImpl) getClass(classID int) (*models.Class, error) {
+	var class models.Class
+	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+	return &class, nil
+}
+
+func (s *AtRiskServiceImpl) getRule(classID, ruleID int) (*models.AtRiskRule, error) {
+	var rule models.AtRiskRule
+	if err := s.db.Where("rule_id = ? AND class_id = ?", ruleID, classID).First(&rule).Error; err != nil {
+		return nil, fmt.Errorf("at-risk rule not found: %w", err)
+	}
+	return &rule, nil
+}
+
+// validateAtRiskRule checks the type and threshold of a rule and names
+// unnamed rules after their condition
+func validateAtRiskRule(rule *models.AtRiskRule) error {
+	if !models.IsValidAtRiskRuleType(rule.RuleType) {
+		return fmt.Errorf("invalid rule type %q", rule.RuleType)
+	}
+
+	switch rule.RuleType {
+	case models.AtRiskRuleMissedInARow, models.AtRiskRuleInactiveDays:
+		if rule.Threshold < 1 || rule.Threshold != math.Trunc(rule.Threshold) {
+			return errors.New("threshold must be a whole number of at least 1")
+		}
+	case models.AtRiskRuleAverageBelow, models.AtRiskRuleAbsenceRate:
+		if rule.Threshold <= 0 || rule.Threshold > 100 {
+			return errors.New("threshold must be a percentage above 0 and at most 100")
+		}
+	}
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		switch rule.RuleType {
+		case models.AtRiskRuleMissedInARow:
+			rule.Name = fmt.Sprintf("Missed %.0f assignments in a row", rule.Threshold)
+		case models.AtRiskRuleAverageBelow:
+			rule.Name = fmt.Sprintf("Average below %g%%", rule.Threshold)
+		case models.AtRiskRuleInactiveDays:
+			rule.Name = fmt.Sprintf("No activity for %.0f days", rule.Threshold)
+		case models.AtRiskRuleAbsenceRate:
+			rule.Name = fmt.Sprintf("Absent from %g%% of sessions", rule.Threshold)
+		}
+	}
+	return nil
+}
+
+// atRiskStudent is an actively enrolled student the rules are evaluated for
+type atRiskStudent struct {
+	UserID         int
+	SectionID      *int
+	EnrollmentDate time.Time
+	StudentName    string
+}
+
+// atRiskRoster returns the actively enrolled students of a class
+func atRiskRoster(db *gorm.DB, classID int) ([]atRiskStudent, error) {
+	var roster []atRiskStudent
+	if err := db.Raw(`
+		SELECT ce.user_id, ce.section_id, ce.enrollment_date,
+			COALESCE(sp.first_name + ' ' + sp.last_name, '') AS student_name
+		FROM class_enrollments ce
+		LEFT JOIN student_profiles sp ON sp.user_id = ce.user_id
+		WHERE ce.class_id = ? AND ce.is_active = 1 AND ce.status = 'active'
+	`, classID).Scan(&roster).Error; err != nil {
+		return nil, fmt.Errorf("failed to get class roster: %w", err)
+	}
+	for i := range roster {
+		if roster[i].StudentName == "" {
+			roster[i].StudentName = fmt.Sprintf("Student #%d", roster[i].UserID)
+		}
+	}
+	return roster, nil
+}
+
+// evaluateAtRiskRules runs the enabled rules of a class and updates its flags
+func evaluateAtRiskRules(db *gorm.DB, class *models.Class) (*models.AtRiskEvaluation, error) {
+	now := time.Now()
+
+	var rules []models.AtRiskRule
+	if err := db.Where("class_id = ? AND is_enabled = ?", class.ClassID, true).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get at-risk rules: %w", err)
+	}
+
+	roster, err := atRiskRoster(db, class.ClassID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each rule maps the students it matches to the reason
+	metrics := atRiskMetrics{db: db, classID: class.ClassID, roster: roster, now: now}
+	matches := make(map[int]map[int]string, len(rules))
+	for _, rule := range rules {
+		matched, err := metrics.match(rule)
+		if err != nil {
+			return nil, err
+		}
+		matches[rule.RuleID] = matched
+	}
+
+	var active []models.AtRiskFlag
+	if err := db.Where("class_id = ? AND resolved_at IS NULL", class.ClassID).Find(&active).Error; err != nil {
+		return nil, fmt.Errorf("failed to get at-risk flags: %w", err)
+	}
+
+	evaluation := models.AtRiskEvaluation{ClassID: class.ClassID, Rules: len(rules), EvaluatedAt: now}
+	var newFlags []models.AtRiskFlag
+	err = db.Transaction(func(tx *gorm.DB) error {
+		flagged := make(map[[2]int]bool, len(active))
+		for i := range active {
+			flag := &active[i]
+			detail, ok := matches[flag.RuleID][flag.StudentID]
+			if !ok {
+				if err := tx.Model(flag).Updates(map[string]interface{}{"resolved_at": now, "last_evaluated_at": now}).Error; err != nil {
+					return err
+				}
+				evaluation.Resolved++
+				continue
+			}
+			if err := tx.Model(flag).Updates(map[string]interface{}{"detail": detail, "last_evaluated_at": now}).Error; err != nil {
+				return err
+			}
+			flagged[[2]int{flag.RuleID, flag.StudentID}] = true
+			evaluation.Flagged++
+		}
+
+		for _, rule := range rules {
+			for studentID, detail := range matches[rule.RuleID] {
+				if flagged[[2]int{rule.RuleID, studentID}] {
+					continue
+				}
+				flag := models.AtRiskFlag{
+					ClassID:         class.ClassID,
+					StudentID:       studentID,
+					RuleID:          rule.RuleID,
+					Detail:          detail,
+					FlaggedAt:       now,
+					LastEvaluatedAt: now,
+				}
+				if err := tx.Create(&flag).Error; err != nil {
+					return err
+				}
+				newFlags = append(newFlags, flag)
+				evaluation.Flagged++
+				evaluation.NewFlags++
+			}
+		}
+
+		return notifyTeachersOfAtRisk(tx, class, rules, roster, newFlags)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update at-risk flags: %w", err)
+	}
+
+	// Emails are sent after the flags are saved, so a mail failure does not
+	// raise the same alert again at the next run
+	emailGuardiansOfAtRisk(db, class, rules, roster, newFlags)
+
+	return &evaluation, nil
+}
+
+// notifyTeachersOfAtRisk creates in-app notifications for the teachers of a
+// class about new flags of rules that notify teachers
+func notifyTeachersOfAtRisk(tx *gorm.DB, class *models.Class, rules []models.AtRiskRule, roster []atRiskStudent, flags []models.AtRiskFlag) error {
+	ruleByID := make(map[int]models.AtRiskRule, len(rules))
+	for _, rule := range rules {
+		ruleByID[rule.RuleID] = rule
+	}
+	names := make(map[int]string, len(roster))
+	for _, student := range roster {
+		names[student.UserID] = student.StudentName
+	}
+
+	var teacherIDs []int
+	teachersLoaded := false
+	var notifications []models.Notification
+	for _, flag := range flags {
+		if !ruleByID[flag.RuleID].NotifyTeachers {
+			continue
+		}
+		if !teachersLoaded {
+			if err := tx.Model(&models.ClassTeacher{}).Where("class_id = ?", class.ClassID).
+				Pluck("user_id", &teacherIDs).Error; err != nil {
+				return err
+			}
+			teachersLoaded = true
+		}
+		for _, teacherID := range teacherIDs {
+			notifications = append(notifications, models.Notification{
+				UserID:  teacherID,
+				Type:    models.NotificationTypeAtRisk,
+				Title:   fmt.Sprintf("Student at risk: %s", names[flag.StudentID]),
+				Message: fmt.Sprintf("%s may be falling behind in %s: %s.", names[flag.StudentID], class.ClassName, flag.Detail),
+				ClassID: &class.ClassID,
+			})
+		}
+	}
+	return createNotifications(tx, notifications)
+}
+
+// emailGuardiansOfAtRisk emails the guardians who receive alerts about new
+// flags of rules that notify guardians. Failures are logged.
+func emailGuardiansOfAtRisk(db *gorm.DB, class *models.Class, rules []models.AtRiskRule, roster []atRiskStudent, flags []models.AtRiskFlag) {
+	ruleByID := make(map[int]models.AtRiskRule, len(rules))
+	for _, rule := range rules {
+		ruleByID[rule.RuleID] = rule
+	}
+	names := make(map[int]string, len(roster))
+	for _, student := range roster {
+		names[student.UserID] = student.StudentName
+	}
+
+	// One email per student, listing every new reason
+	details := make(map[int][]string)
+	var studentIDs []int
+	for _, flag := range flags {
+		if !ruleByID[flag.RuleID].NotifyGuardians {
+			continue
+		}
+		if _, ok := details[flag.StudentID]; !ok {
+			studentIDs = append(studentIDs, flag.StudentID)
+		}
+		details[flag.StudentID] = append(details[flag.StudentID], flag.Detail)
+	}
+	if len(studentIDs) == 0 {
+		return
+	}
+
+	var guardians []models.Guardian
+	if err := db.Where("student_id IN ? AND receives_alerts = ?", studentIDs, true).Find(&guardians).Error; err != nil {
+		log.Printf("Failed to get guardians for at-risk alerts in class %d: %v", class.ClassID, err)
+		return
+	}
+
+	for _, guardian := range guardians {
+		name := names[guardian.StudentID]
+		body := fmt.Sprintf("Dear %s,\n\n%s may be falling behind in %s:\n\n- %s\n\nPlease contact the class teacher if you have any questions.\n\nClassConnect",
+			guardian.Name, name, class.ClassName, strings.Join(details[guardian.StudentID], "\n- "))
+		if err := utils.SendEmail(guardian.Email, fmt.Sprintf("%s may need support in %s", name, class.ClassName), body); err != nil {
+			log.Printf("Failed to email guardian %d about student %d: %v", guardian.GuardianID, guardian.StudentID, err)
+		}
+	}
+}
+
+// atRiskMetrics computes the measures the rules compare with their
+// thresholds, each at most once per evaluation
+type atRiskMetrics struct {
+	db      *gorm.DB
+	classID int
+	roster  []atRiskStudent
+	now     time.Time
+
+	missedInARow map[int]int
+	averages     map[int]float64
+	lastActivity map[int]time.Time
+	absences     map[int][2]int // Absent and counted sessions
+}
+
+// match returns the students a rule flags, with the reason for each
+func (m *atRiskMetrics) match(rule models.AtRiskRule) (map[int]string, error) {
+	matched := make(map[int]string)
+
+	switch rule.RuleType {
+	case models.AtRiskRuleMissedInARow:
+		if m.missedInARow == nil {
+			missed, err := m.countMissedInARow()
+			if err != nil {
+				return nil, err
+			}
+			m.missedInARow = missed
+		}
+		for studentID, missed := range m.missedInARow {
+			if float64(missed) >= rule.Threshold {
+				matched[studentID] = fmt.Sprintf("Missed the last %d assignments", missed)
+			}
+		}
+
+	case models.AtRiskRuleAverageBelow:
+		if m.averages == nil {
+			averages, err := m.averageGrades()
+			if err != nil {
+				return nil, err
+			}
+			m.averages = averages
+		}
+		for studentID, average := range m.averages {
+			if average < rule.Threshold {
+				matched[studentID] = fmt.Sprintf("Average grade is %.0f%%", average)
+			}
+		}
+
+	case models.AtRiskRuleInactiveDays:
+		if m.lastActivity == nil {
+			lastActivity, err := m.lastActivities()
+			if err != nil {
+				return nil, err
+			}
+			m.lastActivity = lastActivity
+		}
+		for studentID, last := range m.lastActivity {
+			days := int(m.now.Sub(last).Hours() / 24)
+			if float64(days) >= rule.Threshold {
+				matched[studentID] = fmt.Sprintf("No activity for %d days", days)
+			}
+		}
+
+	case models.AtRiskRuleAbsenceRate:
+		if m.absences == nil {
+			absences, err := m.absenceCounts()
+			if err != nil {
+				return nil, err
+			}
+			m.absences = absences
+		}
+		for studentID, counts := range m.absences {
+			if counts[1] == 0 {
+				continue
+			}
+			rate := float64(counts[0]) * 100 / float64(counts[1])
+			if rate >= rule.Threshold {
+				matched[studentID] = fmt.Sprintf("Absent from %d of %d sessions", counts[0], counts[1])
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// countMissedInARow counts, for every student, the assignments missed since
+// their last submission, newest first. Assignments are only missed once the
+// student's own due date, after extensions and accommodations, has passed.
+func (m *atRiskMetrics) countMissedInARow() (map[int]int, error) {
+	missed := make(map[int]int, len(m.roster))
+	if len(m.roster) == 0 {
+		return missed, nil
+	}
+
+	var assignments []models.Assignment
+	if err := m.db.Where("class_id = ? AND is_published = ? AND due_date < ?", m.classID, true, m.now).
+		Order("due_date DESC, assignment_id DESC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	if len(assignments) == 0 {
+		return missed, nil
+	}
+
+	assignmentIDs := make([]int, len(assignments))
+	for i := range assignments {
+		assignmentIDs[i] = assignments[i].AssignmentID
+	}
+	var submitted []struct {
+		AssignmentID int
+		UserID       int
+	}
+	if err := m.db.Model(&models.Submission{}).Select("assignment_id, user_id").
+		Where("assignment_id IN ?", assignmentIDs).Scan(&submitted).Error; err != nil {
+		return nil, fmt.Errorf("failed to get submissions: %w", err)
+	}
+	hasSubmitted := make(map[[2]int]bool, len(submitted))
+	for _, submission := range submitted {
+		hasSubmitted[[2]int{submission.AssignmentID, submission.UserID}] = true
+	}
+
+	// A student's streak ends at their most recent submission
+	done := make(map[int]bool, len(m.roster))
+	for i := range assignments {
+		assignment := &assignments[i]
+		if assignment.DueDate.IsZero() {
+			continue
+		}
+
+		var studentIDs []int
+		for _, student := range m.roster {
+			if done[student.UserID] {
+				continue
+			}
+			if assignment.SectionID != nil && (student.SectionID == nil || *student.SectionID != *assignment.SectionID) {
+				continue
+			}
+			studentIDs = append(studentIDs, student.UserID)
+		}
+		if len(studentIDs) == 0 {
+			continue
+		}
+
+		dueDates, err := effectiveDueDates(m.db, assignment, studentIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get due dates: %w", err)
+		}
+		for _, studentID := range studentIDs {
+			switch {
+			case hasSubmitted[[2]int{assignment.AssignmentID, studentID}]:
+				done[studentID] = true
+			case dueDates[studentID].Before(m.now):
+				missed[studentID]++
+			}
+		}
+	}
+
+	return missed, nil
+}
+
+// averageGrades returns every student's average released grade as a
+// percentage of the assignments' points
+func (m *atRiskMetrics) averageGrades() (map[int]float64, error) {
+	var rows []struct {
+		UserID         int
+		AveragePercent float64
+	}
+	if err := m.db.Raw(`
+		SELECT s.user_id, AVG(s.grade * 100.0 / a.points_possible) AS average_percent
+		`+enrolledSubmissionsFrom+`
+		WHERE a.class_id = ? AND a.is_published = 1 AND a.points_possible > 0
+			AND s.grade IS NOT NULL AND s.grade_released = 1
+		GROUP BY s.user_id
+	`, m.classID).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get average grades: %w", err)
+	}
+
+	averages := make(map[int]float64, len(rows))
+	for _, row := range rows {
+		averages[row.UserID] = row.AveragePercent
+	}
+	return averages, nil
+}
+
+// lastActivities returns when every student last submitted work, wrote in
+// the class chat or attended a session, or else when they enrolled
+func (m *atRiskMetrics) lastActivities() (map[int]time.Time, error) {
+	lastActivity := make(map[int]time.Time, len(m.roster))
+	for _, student := range m.roster {
+		lastActivity[student.UserID] = student.EnrollmentDate
+	}
+
+	var rows []struct {
+		UserID       int
+		LastActivity time.Time
+	}
+	if err := m.db.Raw(`
+		SELECT user_id, MAX(last_activity) AS last_activity
+		FROM (
+			SELECT s.user_id, MAX(s.submission_date) AS last_activity
+			FROM submissions s
+			JOIN assignments a ON a.assignment_id = s.assignment_id
+			WHERE a.class_id = ?
+			GROUP BY s.user_id
+			UNION ALL
+			SELECT m.user_id, MAX(m.timestamp)
+			FROM chat_messages m
+			WHERE m.class_id = ? AND m.is_deleted = 0
+			GROUP BY m.user_id
+			UNION ALL
+			SELECT r.student_id, MAX(COALESCE(r.checked_in_at, cs.start_time))
+			FROM attendance_records r
+			JOIN class_sessions cs ON cs.session_id = r.session_id
+			WHERE cs.class_id = ? AND r.status IN ('present', 'late')
+			GROUP BY r.student_id
+		) activity
+		GROUP BY user_id
+	`, m.classID, m.classID, m.classID).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get student activity: %w", err)
+	}
+
+	for _, row := range rows {
+		if last, ok := lastActivity[row.UserID]; ok && row.LastActivity.After(last) {
+			lastActivity[row.UserID] = row.LastActivity
+		}
+	}
+	return lastActivity, nil
+}
+
+// absenceCounts returns every student's absences and the sessions that count
+// towards their absence rate, which excludes excused absences
+func (m *atRiskMetrics) absenceCounts() (map[int][2]int, error) {
+	var rows []struct {
+		StudentID int
+		Absent    int
+		Counted   int
+	}
+	if err := m.db.Raw(`
+		SELECT r.student_id,
+			SUM(CASE WHEN r.status = 'absent' THEN 1 ELSE 0 END) AS absent,
+			SUM(CASE WHEN r.status <> 'excused' THEN 1 ELSE 0 END) AS counted
+		FROM attendance_records r
+		JOIN class_sessions cs ON cs.session_id = r.session_id
+		JOIN class_enrollments ce ON ce.class_id = cs.class_id AND ce.user_id = r.student_id
+			AND ce.is_active = 1 AND ce.status = 'active'
+		WHERE cs.class_id = ?
+		GROUP BY r.student_id
+	`, m.classID).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+
+	absences := make(map[int][2]int, len(rows))
+	for _, row := range rows {
+		absences[row.StudentID] = [2]int{row.Absent, row.Counted}
+	}
+	return absences, nil
+}
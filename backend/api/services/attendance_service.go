@@ -0,0 +1,574 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// checkInCodePeriod is how long a self check-in code stays valid
+	checkInCodePeriod = 30 * time.Second
+	// checkInOpensBefore is how early students can check in before a session starts
+	checkInOpensBefore = 15 * time.Minute
+	// checkInLateAfter is how long after the start a check-in is still counted as present
+	checkInLateAfter = 10 * time.Minute
+	// maxScheduleDays limits how many days a single schedule can generate sessions for
+	maxScheduleDays = 366
+)
+
+// AttendanceMark is a teacher's attendance entry for one student
+type AttendanceMark struct {
+	StudentID int    `json:"studentId" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Note      string `json:"note"`
+}
+
+// AttendanceService handles class schedules, sessions and attendance
+type AttendanceService interface {
+	Service
+	// Schedules and sessions
+	CreateSchedule(classID, dayOfWeek int, startTime, endTime string, startDate, endDate time.Time) (*models.ClassSchedule, int, error)
+	GetSchedules(classID int) ([]models.ClassSchedule, error)
+	DeleteSchedule(classID, scheduleID int) error
+	CreateSession(classID int, startTime, endTime time.Time) (*models.ClassSession, error)
+	GetSessions(classID int, from, to *time.Time) ([]models.ClassSession, error)
+
+	// Attendance
+	GetSessionAttendance(classID, sessionID int) ([]models.SessionAttendanceResponse, error)
+	MarkAttendance(classID, sessionID, teacherID int, marks []AttendanceMark) error
+	GetCheckInCode(classID, sessionID int) (string, time.Time, error)
+	CheckIn(classID, sessionID, studentID int, code string) (*models.AttendanceRecord, error)
+
+	// Reports
+	GetClassReport(classID int) (*models.ClassAttendanceReport, error)
+	GetStudentReport(classID, studentID int) (*models.StudentAttendanceReport, error)
+}
+
+// AttendanceServiceImpl implements AttendanceService
+type AttendanceServiceImpl struct {
+	*BaseService
+}
+
+// NewAttendanceService creates a new AttendanceService
+func NewAttendanceService(db *gorm.DB) AttendanceService {
+	return &AttendanceServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// rosterStudent is an actively enrolled student of a class
+type rosterStudent struct {
+	UserID         int       `gorm:"column:user_id"`
+	FirstName      string    `gorm:"column:first_name"`
+	LastName       string    `gorm:"column:last_name"`
+	EnrollmentDate time.Time `gorm:"column:enrollment_date"`
+}
+
+// CreateSchedule creates a weekly meeting time and generates a session for
+// every matching day in the date range. It returns the number of sessions created.
+func (s *AttendanceServiceImpl) CreateSchedule(classID, dayOfWeek int, startTime, endTime string, startDate, endDate time.Time) (*models.ClassSchedule, int, error) {
+	// Archived classes keep their attendance as it is
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, 0, err
+	}
+
+	if dayOfWeek < 0 || dayOfWeek > 6 {
+		return nil, 0, errors.New("day of week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	start, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return nil, 0, errors.New("start time must be in HH:MM format")
+	}
+	end, err := time.Parse("15:04", endTime)
+	if err != nil {
+		return nil, 0, errors.New("end time must be in HH:MM format")
+	}
+	if !end.After(start) {
+		return nil, 0, errors.New("end time must be after the start time")
+	}
+
+	firstDay := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.Local)
+	lastDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.Local)
+	if lastDay.Before(firstDay) {
+		return nil, 0, errors.New("end date must not be before the start date")
+	}
+	if lastDay.Sub(firstDay) > maxScheduleDays*24*time.Hour {
+		return nil, 0, fmt.Errorf("a schedule can cover at most %d days", maxScheduleDays)
+	}
+
+	schedule := models.ClassSchedule{
+		ClassID:   classID,
+		DayOfWeek: dayOfWeek,
+		StartTime: startTime,
+		EndTime:   endTime,
+		StartDate: firstDay,
+		EndDate:   lastDay,
+	}
+	created := 0
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&schedule).Error; err != nil {
+			return err
+		}
+
+		for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+			if int(day.Weekday()) != dayOfWeek {
+				continue
+			}
+
+			session, err := newClassSession(classID,
+				time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, time.Local),
+				time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, time.Local))
+			if err != nil {
+				return err
+			}
+			session.ScheduleID = &schedule.ScheduleID
+
+			if err := tx.Create(session).Error; err != nil {
+				return err
+			}
+			created++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return &schedule, created, nil
+}
+
+// GetSchedules retrieves the meeting schedules of a class
+func (s *AttendanceServiceImpl) GetSchedules(classID int) ([]models.ClassSchedule, error) {
+	var schedules []models.ClassSchedule
+	if err := s.db.Where("class_id = ?", classID).
+		Order("day_of_week ASC, start_time ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule deletes a schedule and its upcoming sessions that have no
+// attendance yet. Past sessions are kept so attendance history is preserved.
+func (s *AttendanceServiceImpl) DeleteSchedule(classID, scheduleID int) error {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var schedule models.ClassSchedule
+		if err := tx.Where("schedule_id = ? AND class_id = ?", scheduleID, classID).First(&schedule).Error; err != nil {
+			return fmt.Errorf("schedule not found: %w", err)
+		}
+
+		if err := tx.Where("schedule_id = ? AND start_time > ?", scheduleID, time.Now()).
+			Where("session_id NOT IN (SELECT session_id FROM attendance_records)").
+			Delete(&models.ClassSession{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.ClassSession{}).
+			Where("schedule_id = ?", scheduleID).
+			Update("schedule_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&schedule).Error
+	})
+}
+
+// CreateSession creates a one-off session, such as an extra review meeting
+func (s *AttendanceServiceImpl) CreateSession(classID int, startTime, endTime time.Time) (*models.ClassSession, error) {
+	// Archived classes keep their attendance as it is
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	if !endTime.After(startTime) {
+		return nil, errors.New("end time must be after the start time")
+	}
+
+	session, err := newClassSession(classID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetSessions retrieves the sessions of a class, optionally limited to a date range
+func (s *AttendanceServiceImpl) GetSessions(classID int, from, to *time.Time) ([]models.ClassSession, error) {
+	query := s.db.Where("class_id = ?", classID)
+	if from != nil {
+		query = query.Where("start_time >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("start_time <= ?", *to)
+	}
+
+	var sessions []models.ClassSession
+	if err := query.Order("start_time ASC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// GetSessionAttendance returns the attendance sheet of a session: every
+// enrolled student together with their attendance, if it has been taken
+func (s *AttendanceServiceImpl) GetSessionAttendance(classID, sessionID int) ([]models.SessionAttendanceResponse, error) {
+	if _, err := s.getSession(classID, sessionID); err != nil {
+		return nil, err
+	}
+
+	students, err := s.getRoster(classID)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.AttendanceRecord
+	if err := s.db.Where("session_id = ?", sessionID).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+	recordsByStudent := make(map[int]models.AttendanceRecord, len(records))
+	for _, record := range records {
+		recordsByStudent[record.StudentID] = record
+	}
+
+	sheet := make([]models.SessionAttendanceResponse, 0, len(students))
+	for _, student := range students {
+		row := models.SessionAttendanceResponse{
+			StudentID: student.UserID,
+			FirstName: student.FirstName,
+			LastName:  student.LastName,
+		}
+		if record, ok := recordsByStudent[student.UserID]; ok {
+			row.Status = record.Status
+			row.Note = record.Note
+			row.CheckedInAt = record.CheckedInAt
+		}
+		sheet = append(sheet, row)
+	}
+
+	return sheet, nil
+}
+
+// MarkAttendance records attendance for one or more students at a session.
+// All marks are saved together, or none are if any of them is invalid.
+func (s *AttendanceServiceImpl) MarkAttendance(classID, sessionID, teacherID int, marks []AttendanceMark) error {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
+	if _, err := s.getSession(classID, sessionID); err != nil {
+		return err
+	}
+
+	students, err := s.getRoster(classID)
+	if err != nil {
+		return err
+	}
+	enrolled := make(map[int]bool, len(students))
+	for _, student := range students {
+		enrolled[student.UserID] = true
+	}
+
+	for _, mark := range marks {
+		if !models.IsValidAttendanceStatus(mark.Status) {
+			return fmt.Errorf("invalid attendance status %q", mark.Status)
+		}
+		if !enrolled[mark.StudentID] {
+			return fmt.Errorf("student %d is not enrolled in this class", mark.StudentID)
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, mark := range marks {
+			var record models.AttendanceRecord
+			err := tx.Where("session_id = ? AND student_id = ?", sessionID, mark.StudentID).First(&record).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			record.SessionID = sessionID
+			record.StudentID = mark.StudentID
+			record.Status = mark.Status
+			record.Note = mark.Note
+			record.MarkedBy = &teacherID
+
+			if err := tx.Save(&record).Error; err != nil {
+				return fmt.Errorf("failed to save attendance: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetCheckInCode returns the current self check-in code of a session and when it expires
+func (s *AttendanceServiceImpl) GetCheckInCode(classID, sessionID int) (string, time.Time, error) {
+	session, err := s.getSession(classID, sessionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	window := now.Unix() / int64(checkInCodePeriod.Seconds())
+	expiresAt := time.Unix((window+1)*int64(checkInCodePeriod.Seconds()), 0)
+
+	return checkInCode(session.CheckInSecret, window), expiresAt, nil
+}
+
+// CheckIn lets a student record their own attendance with the code shown by
+// the teacher. Students checking in more than a few minutes after the start
+// are marked late.
+func (s *AttendanceServiceImpl) CheckIn(classID, sessionID, studentID int, code string) (*models.AttendanceRecord, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.getSession(classID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.Before(session.StartTime.Add(-checkInOpensBefore)) || now.After(session.EndTime) {
+		return nil, errors.New("check-in is not open for this session")
+	}
+
+	// Accept the current code and the one before it, in case it rotated while the student was typing
+	window := now.Unix() / int64(checkInCodePeriod.Seconds())
+	if !hmac.Equal([]byte(code), []byte(checkInCode(session.CheckInSecret, window))) &&
+		!hmac.Equal([]byte(code), []byte(checkInCode(session.CheckInSecret, window-1))) {
+		return nil, errors.New("invalid or expired check-in code")
+	}
+
+	// Check if student is enrolled in the class
+	var count int64
+	if err := s.db.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND user_id = ? AND is_active = ?", classID, studentID, true).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("student is not enrolled in this class")
+	}
+
+	var record models.AttendanceRecord
+	err = s.db.Where("session_id = ? AND student_id = ?", sessionID, studentID).First(&record).Error
+	if err == nil {
+		if record.CheckedInAt != nil {
+			return nil, errors.New("already checked in to this session")
+		}
+		// Keep an excuse the teacher already recorded
+		if record.Status == models.AttendanceStatusExcused {
+			return &record, nil
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	status := models.AttendanceStatusPresent
+	if now.After(session.StartTime.Add(checkInLateAfter)) {
+		status = models.AttendanceStatusLate
+	}
+
+	record.SessionID = sessionID
+	record.StudentID = studentID
+	record.Status = status
+	record.CheckedInAt = &now
+
+	if err := s.db.Save(&record).Error; err != nil {
+		return nil, fmt.Errorf("failed to save attendance: %w", err)
+	}
+
+	return &record, nil
+}
+
+// GetClassReport summarizes attendance for every enrolled student over the sessions held so far
+func (s *AttendanceServiceImpl) GetClassReport(classID int) (*models.ClassAttendanceReport, error) {
+	sessions, records, err := s.getHeldSessions(classID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.getRoster(classID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ClassAttendanceReport{
+		ClassID:  classID,
+		Sessions: len(sessions),
+		Students: make([]models.AttendanceSummary, 0, len(students)),
+	}
+
+	for _, student := range students {
+		summary := models.AttendanceSummary{
+			StudentID: student.UserID,
+			FirstName: student.FirstName,
+			LastName:  student.LastName,
+		}
+		for _, session := range sessions {
+			record, marked := records[attendanceKey{session.SessionID, student.UserID}]
+			// Sessions before the student joined only count if attendance was taken anyway
+			if !marked && session.StartTime.Before(student.EnrollmentDate) {
+				continue
+			}
+			summary.Add(record.Status)
+		}
+		report.Students = append(report.Students, summary)
+	}
+
+	return report, nil
+}
+
+// GetStudentReport lists a student's attendance at every session of a class held so far
+func (s *AttendanceServiceImpl) GetStudentReport(classID, studentID int) (*models.StudentAttendanceReport, error) {
+	var student rosterStudent
+	if err := s.db.Table("class_enrollments").
+		Joins("JOIN users ON class_enrollments.user_id = users.user_id").
+		Select("users.user_id, users.first_name, users.last_name, class_enrollments.enrollment_date").
+		Where("class_enrollments.class_id = ? AND class_enrollments.user_id = ?", classID, studentID).
+		Order("class_enrollments.enrollment_date DESC").
+		Limit(1).
+		Scan(&student).Error; err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	if student.UserID == 0 {
+		return nil, errors.New("student not found in this class")
+	}
+
+	sessions, records, err := s.getHeldSessions(classID, &studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.StudentAttendanceReport{
+		ClassID: classID,
+		Summary: models.AttendanceSummary{
+			StudentID: student.UserID,
+			FirstName: student.FirstName,
+			LastName:  student.LastName,
+		},
+		Sessions: make([]models.StudentSessionAttendance, 0, len(sessions)),
+	}
+
+	for _, session := range sessions {
+		record, marked := records[attendanceKey{session.SessionID, studentID}]
+		if !marked && session.StartTime.Before(student.EnrollmentDate) {
+			continue
+		}
+		report.Summary.Add(record.Status)
+		report.Sessions = append(report.Sessions, models.StudentSessionAttendance{
+			SessionID:   session.SessionID,
+			StartTime:   session.StartTime,
+			EndTime:     session.EndTime,
+			Status:      record.Status,
+			Note:        record.Note,
+			CheckedInAt: record.CheckedInAt,
+		})
+	}
+
+	return report, nil
+}
+
+// attendanceKey identifies the attendance record of a student at a session
+type attendanceKey struct {
+	sessionID int
+	studentID int
+}
+
+// getHeldSessions returns the sessions of a class that have started, along
+// with their attendance records, optionally for a single student
+func (s *AttendanceServiceImpl) getHeldSessions(classID int, studentID *int) ([]models.ClassSession, map[attendanceKey]models.AttendanceRecord, error) {
+	var sessions []models.ClassSession
+	if err := s.db.Where("class_id = ? AND start_time <= ?", classID, time.Now()).
+		Order("start_time ASC").
+		Find(&sessions).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	query := s.db.Joins("JOIN class_sessions ON attendance_records.session_id = class_sessions.session_id").
+		Where("class_sessions.class_id = ?", classID)
+	if studentID != nil {
+		query = query.Where("attendance_records.student_id = ?", *studentID)
+	}
+
+	var records []models.AttendanceRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+
+	recordMap := make(map[attendanceKey]models.AttendanceRecord, len(records))
+	for _, record := range records {
+		recordMap[attendanceKey{record.SessionID, record.StudentID}] = record
+	}
+
+	return sessions, recordMap, nil
+}
+
+// getSession retrieves a session of a class
+func (s *AttendanceServiceImpl) getSession(classID, sessionID int) (*models.ClassSession, error) {
+	var session models.ClassSession
+	if err := s.db.Where("session_id = ? AND class_id = ?", sessionID, classID).First(&session).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	return &session, nil
+}
+
+// getRoster retrieves the actively enrolled students of a class
+func (s *AttendanceServiceImpl) getRoster(classID int) ([]rosterStudent, error) {
+	var students []rosterStudent
+	if err := s.db.Table("class_enrollments").
+		Joins("JOIN users ON class_enrollments.user_id = users.user_id").
+		Select("users.user_id, users.first_name, users.last_name, class_enrollments.enrollment_date").
+		Where("class_enrollments.class_id = ? AND class_enrollments.is_active = ?", classID, true).
+		Order("users.last_name ASC, users.first_name ASC").
+		Scan(&students).Error; err != nil {
+		return nil, fmt.Errorf("failed to get class roster: %w", err)
+	}
+	return students, nil
+}
+
+// newClassSession builds a session with a fresh check-in secret
+func newClassSession(classID int, startTime, endTime time.Time) (*models.ClassSession, error) {
+	secret, err := utils.GenerateSecureRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ClassSession{
+		ClassID:       classID,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		CheckInSecret: secret,
+	}, nil
+}
+
+// checkInCode derives the 6-digit check-in code of a session for a time window
+func checkInCode(secret string, window int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(window))
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, as in HOTP
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
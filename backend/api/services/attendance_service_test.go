@@ -0,0 +1,64 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCheckInCode(t *testing.T) {
+	// The truncation follows HOTP, so the SHA-256 vectors of RFC 6238 apply
+	// (last 6 of their 8 digits, with 30-second windows)
+	const rfcSecret = "12345678901234567890123456789012"
+
+	tests := []struct {
+		name   string
+		secret string
+		window int64
+		want   string
+	}{
+		{"RFC 6238 at 59 seconds", rfcSecret, 59 / 30, "119246"},
+		{"RFC 6238 at 1111111109 seconds", rfcSecret, 1111111109 / 30, "084774"},
+		{"RFC 6238 at 1234567890 seconds", rfcSecret, 1234567890 / 30, "819424"},
+		{"RFC 6238 at 2000000000 seconds", rfcSecret, 2000000000 / 30, "698825"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkInCode(tt.secret, tt.window); got != tt.want {
+				t.Errorf("checkInCode(%q, %d) = %q, want %q", tt.secret, tt.window, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckInCodeFormat(t *testing.T) {
+	sixDigits := regexp.MustCompile(`^[0-9]{6}$`)
+
+	tests := []struct {
+		secret string
+		window int64
+	}{
+		{"", 0},
+		{"session-secret", -1},
+		{"session-secret", 0},
+		{"session-secret", 57870000},
+		{"another-secret", 57870000},
+	}
+
+	for _, tt := range tests {
+		code := checkInCode(tt.secret, tt.window)
+		if !sixDigits.MatchString(code) {
+			t.Errorf("checkInCode(%q, %d) = %q, want 6 digits", tt.secret, tt.window, code)
+		}
+		if again := checkInCode(tt.secret, tt.window); again != code {
+			t.Errorf("checkInCode(%q, %d) is not stable: %q then %q", tt.secret, tt.window, code, again)
+		}
+	}
+
+	if checkInCode("session-secret", 57870000) == checkInCode("session-secret", 57870001) {
+		t.Error("consecutive windows share a code")
+	}
+	if checkInCode("session-secret", 57870000) == checkInCode("another-secret", 57870000) {
+		t.Error("different sessions share a code")
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// calendarFeedPast and calendarFeedFuture limit the date range of the iCalendar feed
+	calendarFeedPast   = 60 * 24 * time.Hour
+	calendarFeedFuture = 365 * 24 * time.Hour
+	// maxCalendarRange limits the date range of a single calendar request
+	maxCalendarRange = 400 * 24 * time.Hour
+)
+
+// CalendarService combines assignment due dates, scheduled announcements and
+// class sessions from all of a user's classes
+type CalendarService interface {
+	Service
+	GetEvents(userID int, from, to time.Time) ([]models.CalendarEvent, error)
+	GetFeedToken(userID int) (*models.CalendarFeedToken, error)
+	ResetFeedToken(userID int) (*models.CalendarFeedToken, error)
+	GetFeed(token string) ([]byte, error)
+}
+
+// CalendarServiceImpl implements CalendarService
+type CalendarServiceImpl struct {
+	*BaseService
+}
+
+// NewCalendarService creates a new CalendarService
+func NewCalendarService(db *gorm.DB) CalendarService {
+	return &CalendarServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetEvents returns the calendar events of a user between two times, ordered by start time
+func (s *CalendarServiceImpl) GetEvents(userID int, from, to time.Time) ([]models.CalendarEvent, error) {
+	if to.Before(from) {
+		return nil, errors.New("the end of the range must not be before the start")
+	}
+	if to.Sub(from) > maxCalendarRange {
+		return nil, errors.New("the calendar range can be at most 400 days")
+	}
+
+	var user models.User
+	if err := s.db.Where("user_id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	isStudent := user.UserRole == "student"
+
+	classes, err := s.getUserClasses(user)
+	if err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 {
+		return []models.CalendarEvent{}, nil
+	}
+
+	classIDs := make([]int, 0, len(classes))
+	classNames := make(map[int]string, len(classes))
+	for _, class := range classes {
+		classIDs = append(classIDs, class.ClassID)
+		classNames[class.ClassID] = class.ClassName
+	}
+
+	// Students only see content for the whole class or for their own sections
+	sectionScope := func(db *gorm.DB) *gorm.DB {
+		if !isStudent {
+			return db
+		}
+		return db.Where(`(section_id IS NULL OR section_id IN (
+			SELECT section_id FROM class_enrollments
+			WHERE user_id = ? AND is_active = 1 AND section_id IS NOT NULL))`, userID)
+	}
+
+	events := []models.CalendarEvent{}
+
+	// Assignment due dates
+	var assignments []models.Assignment
+	assignmentQuery := s.db.Where("class_id IN ? AND due_date >= ? AND due_date <= ?", classIDs, from, to).Scopes(sectionScope)
+	if isStudent {
+		assignmentQuery = assignmentQuery.Where("is_published = ?", true)
+	}
+	if err := assignmentQuery.Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	for _, assignment := range assignments {
+		events = append(events, models.CalendarEvent{
+			ID:          fmt.Sprintf("%s-%d", models.CalendarEventAssignment, assignment.AssignmentID),
+			Type:        models.CalendarEventAssignment,
+			ClassID:     assignment.ClassID,
+			ClassName:   classNames[assignment.ClassID],
+			ItemID:      assignment.AssignmentID,
+			Title:       "Due: " + assignment.Title,
+			Description: assignment.Description,
+			Start:       assignment.DueDate,
+			End:         assignment.DueDate,
+		})
+	}
+
+	// Scheduled announcements. Students only see them once they are published.
+	var announcements []models.Announcement
+	announcementQuery := s.db.Where("class_id IN ? AND scheduled_date >= ? AND scheduled_date <= ?", classIDs, from, to).Scopes(sectionScope)
+	if isStudent {
+		announcementQuery = announcementQuery.Where("is_published = ?", true)
+	}
+	if err := announcementQuery.Find(&announcements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get announcements: %w", err)
+	}
+	for _, announcement := range announcements {
+		title := announcement.Title
+		if title == "" {
+			title = "Announcement"
+		}
+		events = append(events, models.CalendarEvent{
+			ID:          fmt.Sprintf("%s-%d", models.CalendarEventAnnouncement, announcement.AnnouncementID),
+			Type:        models.CalendarEventAnnouncement,
+			ClassID:     announcement.ClassID,
+			ClassName:   classNames[announcement.ClassID],
+			ItemID:      announcement.AnnouncementID,
+			Title:       title,
+			Description: announcement.Content,
+			Start:       *announcement.ScheduledDate,
+			End:         *announcement.ScheduledDate,
+		})
+	}
+
+	// Class sessions
+	var sessions []models.ClassSession
+	if err := s.db.Where("class_id IN ? AND start_time >= ? AND start_time <= ?", classIDs, from, to).
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for _, session := range sessions {
+		events = append(events, models.CalendarEvent{
+			ID:        fmt.Sprintf("%s-%d", models.CalendarEventSession, session.SessionID),
+			Type:      models.CalendarEventSession,
+			ClassID:   session.ClassID,
+			ClassName: classNames[session.ClassID],
+			ItemID:    session.SessionID,
+			Title:     classNames[session.ClassID],
+			Start:     session.StartTime,
+			End:       session.EndTime,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+
+	return events, nil
+}
+
+// GetFeedToken returns the user's calendar feed token, creating one if needed
+func (s *CalendarServiceImpl) GetFeedToken(userID int) (*models.CalendarFeedToken, error) {
+	var feedToken models.CalendarFeedToken
+	err := s.db.Where("user_id = ?", userID).First(&feedToken).Error
+	if err == nil {
+		return &feedToken, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	token, err := utils.GenerateSecureRandomString(40)
+	if err != nil {
+		return nil, err
+	}
+
+	feedToken = models.CalendarFeedToken{
+		UserID: userID,
+		Token:  token,
+	}
+	if err := s.db.Create(&feedToken).Error; err != nil {
+		return nil, fmt.Errorf("failed to create calendar feed token: %w", err)
+	}
+
+	return &feedToken, nil
+}
+
+// ResetFeedToken replaces the user's calendar feed token, so the old feed URL stops working
+func (s *CalendarServiceImpl) ResetFeedToken(userID int) (*models.CalendarFeedToken, error) {
+	if err := s.db.Where("user_id = ?", userID).Delete(&models.CalendarFeedToken{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to reset calendar feed token: %w", err)
+	}
+	return s.GetFeedToken(userID)
+}
+
+// GetFeed renders the iCalendar feed that belongs to a feed token
+func (s *CalendarServiceImpl) GetFeed(token string) ([]byte, error) {
+	var feedToken models.CalendarFeedToken
+	if err := s.db.Where("token = ?", token).First(&feedToken).Error; err != nil {
+		return nil, fmt.Errorf("calendar feed not found: %w", err)
+	}
+
+	now := time.Now()
+	events, err := s.GetEvents(feedToken.UserID, now.Add(-calendarFeedPast), now.Add(calendarFeedFuture))
+	if err != nil {
+		return nil, err
+	}
+
+	icalEvents := make([]utils.ICalEvent, 0, len(events))
+	for _, event := range events {
+		icalEvents = append(icalEvents, utils.ICalEvent{
+			UID:         event.ID + "@classconnect",
+			Summary:     fmt.Sprintf("[%s] %s", event.ClassName, event.Title),
+			Description: event.Description,
+			Start:       event.Start,
+			End:         event.End,
+		})
+	}
+
+	return utils.WriteICalendar("ClassConnect", icalEvents), nil
+}
+
+// getUserClasses retrieves the active classes a user teaches or is enrolled in
+func (s *CalendarServiceImpl) getUserClasses(user models.User) ([]models.Class, error) {
+	var classes []models.Class
+	query := s.db.Where("classes.is_archived = ?", false)
+
+	if user.UserRole == "student" {
+		query = query.Joins("JOIN class_enrollments ON classes.class_id = class_enrollments.class_id").
+			Where("class_enrollments.user_id = ? AND class_enrollments.is_active = ?", user.UserID, true)
+	} else {
+		query = query.Joins("JOIN class_teachers ON classes.class_id = class_teachers.class_id").
+			Where("class_teachers.user_id = ?", user.UserID)
+	}
+
+	if err := query.Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get classes: %w", err)
+	}
+	return classes, nil
+}
@@ -96,6 +96,11 @@ func (s *ChatServiceImpl) SendChatMessage(classID, userID int, content string) (
 		return models.ChatMessageResponse{}, fmt.Errorf("class not found: %w", err)
 	}
 
+	// Archived classes are read-only
+	if class.IsArchived {
+		return models.ChatMessageResponse{}, ErrClassArchived
+	}
+
 	// Check if user exists
 	var user models.User
 	if err := s.db.Where("user_id = ?", userID).First(&user).Error; err != nil {
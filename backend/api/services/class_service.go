@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/yongdilun/classconnect-backend/api/models"
@@ -18,20 +19,65 @@ type ClassService interface {
 	GetClassByCode(classCode string) (*models.Class, error)
 	UpdateClass(classID int, className, description, subject, themeColor string) error
 	ArchiveClass(classID int) error
+	UnarchiveClass(classID int) error
 	DeleteClass(classID int) error
+	CloneClass(sourceClassID, teacherID int, options CloneClassOptions) (*models.Class, error)
 
 	// Teacher-specific operations
-	GetTeacherClasses(teacherID int) ([]models.Class, error)
+	GetTeacherClasses(teacherID int, options ClassListOptions) ([]models.Class, error)
 	IsTeacherInClass(teacherID, classID int) (bool, error)
 	AddTeacherToClass(teacherID, classID int, isOwner bool) error
 	RemoveTeacherFromClass(teacherID, classID int) error
 
 	// Student-specific operations
-	GetStudentClasses(studentID int) ([]models.Class, error)
+	GetStudentClasses(studentID int, options ClassListOptions) ([]models.Class, error)
 	IsStudentInClass(studentID, classID int) (bool, error)
-	EnrollStudentInClass(studentID int, classCode string) (*models.Class, error)
+	EnrollStudentInClass(studentID int, classCode string) (*models.Class, *models.ClassEnrollment, error)
 	RemoveStudentFromClass(studentID, classID int) error
 	GetClassStudents(classID int) ([]models.StudentProfile, error)
+
+	// Enrollment approval and capacity
+	UpdateEnrollmentSettings(classID int, requiresApproval bool, maxCapacity *int) error
+	GetEnrollments(classID int, status string) ([]models.EnrollmentResponse, error)
+	ApproveEnrollment(classID, studentID int) (*models.ClassEnrollment, error)
+	RejectEnrollment(classID, studentID int) error
+
+	// Terms and sections
+	SetClassTerm(classID int, termID *int) error
+	CreateSection(classID int, name string) (*models.ClassSection, error)
+	GetSections(classID int) ([]models.ClassSection, error)
+	DeleteSection(classID, sectionID int) error
+	SetStudentSection(classID, studentID int, sectionID *int) error
+}
+
+// ErrClassArchived is returned when trying to change the content of an archived class
+var ErrClassArchived = errors.New("class is archived and read-only")
+
+// ensureClassWritable returns ErrClassArchived when the class is archived
+func ensureClassWritable(db *gorm.DB, classID int) error {
+	var class models.Class
+	if err := db.Select("class_id", "is_archived").Where("class_id = ?", classID).First(&class).Error; err != nil {
+		return fmt.Errorf("class not found: %w", err)
+	}
+	if class.IsArchived {
+		return ErrClassArchived
+	}
+	return nil
+}
+
+// ClassListOptions filters the classes returned for a teacher or student
+type ClassListOptions struct {
+	IncludeArchived bool
+	TermID          *int
+}
+
+// CloneClassOptions controls what is copied when a class is cloned
+type CloneClassOptions struct {
+	ClassName                  string // Name of the new class, defaults to "<source name> (Copy)"
+	DueDateOffsetDays          int    // Days added to every copied due date
+	IncludePinnedAnnouncements bool   // Copy pinned announcements
+	IncludeCoTeachers          bool   // Add the other teachers of the source class
+	TermID                     *int   // Term of the new class
 }
 
 // ClassServiceImpl implements ClassService
@@ -49,18 +95,7 @@ func NewClassService(db *gorm.DB) ClassService {
 // CreateClass creates a new class
 func (s *ClassServiceImpl) CreateClass(teacherID int, className, description, subject, themeColor string) (*models.Class, error) {
 	// Generate unique class code
-	classCode := utils.GenerateRandomString(6)
-
-	// Check if class code already exists
-	for {
-		var count int64
-		s.db.Model(&models.Class{}).Where("class_code = ?", classCode).Count(&count)
-		if count == 0 {
-			break
-		}
-		// Generate a new code if collision occurs
-		classCode = utils.GenerateRandomString(6)
-	}
+	classCode := generateUniqueClassCode(s.db)
 
 	// Create class with transaction
 	var class models.Class
@@ -105,6 +140,24 @@ func (s *ClassServiceImpl) CreateClass(teacherID int, className, description, su
 	return &class, nil
 }
 
+// generateUniqueClassCode generates a random class code that is not used by any class
+func generateUniqueClassCode(db *gorm.DB) string {
+	classCode := utils.GenerateRandomString(6)
+
+	// Check if class code already exists
+	for {
+		var count int64
+		db.Model(&models.Class{}).Where("class_code = ?", classCode).Count(&count)
+		if count == 0 {
+			break
+		}
+		// Generate a new code if collision occurs
+		classCode = utils.GenerateRandomString(6)
+	}
+
+	return classCode
+}
+
 // GetClassByID retrieves a class by ID
 func (s *ClassServiceImpl) GetClassByID(classID int) (*models.Class, error) {
 	var class models.Class
@@ -140,18 +193,222 @@ func (s *ClassServiceImpl) ArchiveClass(classID int) error {
 	return s.db.Model(&models.Class{}).Where("class_id = ?", classID).Update("is_archived", true).Error
 }
 
+// UnarchiveClass restores an archived class
+func (s *ClassServiceImpl) UnarchiveClass(classID int) error {
+	return s.db.Model(&models.Class{}).Where("class_id = ?", classID).Update("is_archived", false).Error
+}
+
 // DeleteClass deletes a class
 func (s *ClassServiceImpl) DeleteClass(classID int) error {
 	return s.db.Delete(&models.Class{}, classID).Error
 }
 
+// CloneClass creates a new class from an existing one, for example to reuse a
+// class for a new term. Assignments are copied with their due dates shifted by
+// the given offset. Students, submissions and chat messages are never copied.
+func (s *ClassServiceImpl) CloneClass(sourceClassID, teacherID int, options CloneClassOptions) (*models.Class, error) {
+	source, err := s.getClass(s.db, sourceClassID)
+	if err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+
+	if options.TermID != nil {
+		var term models.Term
+		if err := s.db.Where("term_id = ?", *options.TermID).First(&term).Error; err != nil {
+			return nil, fmt.Errorf("term not found: %w", err)
+		}
+	}
+
+	className := options.ClassName
+	if className == "" {
+		className = source.ClassName + " (Copy)"
+	}
+
+	var class models.Class
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Create class
+		class = models.Class{
+			ClassName:        className,
+			ClassCode:        generateUniqueClassCode(tx),
+			Description:      source.Description,
+			Subject:          source.Subject,
+			CreatedDate:      now,
+			IsArchived:       false,
+			ThemeColor:       source.ThemeColor,
+			CreatorID:        teacherID,
+			RequiresApproval: source.RequiresApproval,
+			MaxCapacity:      source.MaxCapacity,
+			TermID:           options.TermID,
+		}
+		if err := tx.Create(&class).Error; err != nil {
+			return err
+		}
+
+		// The teacher cloning the class owns the copy
+		owner := models.ClassTeacher{
+			UserID:    teacherID,
+			ClassID:   class.ClassID,
+			IsOwner:   true,
+			AddedDate: now,
+		}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+
+		if options.IncludeCoTeachers {
+			var coTeachers []models.ClassTeacher
+			if err := tx.Where("class_id = ? AND user_id != ?", sourceClassID, teacherID).Find(&coTeachers).Error; err != nil {
+				return err
+			}
+			for _, coTeacher := range coTeachers {
+				teacherClass := models.ClassTeacher{
+					UserID:    coTeacher.UserID,
+					ClassID:   class.ClassID,
+					IsOwner:   false,
+					AddedDate: now,
+				}
+				if err := tx.Create(&teacherClass).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// Copy sections so section-targeted content keeps its target
+		var sections []models.ClassSection
+		if err := tx.Where("class_id = ?", sourceClassID).Find(&sections).Error; err != nil {
+			return err
+		}
+		sectionIDs := make(map[int]int, len(sections))
+		for _, section := range sections {
+			copied := models.ClassSection{
+				ClassID: class.ClassID,
+				Name:    section.Name,
+			}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+			sectionIDs[section.SectionID] = copied.SectionID
+		}
+		copySectionID := func(sectionID *int) *int {
+			if sectionID == nil {
+				return nil
+			}
+			copied := sectionIDs[*sectionID]
+			return &copied
+		}
+
+		// Copy assignments with shifted due dates
+		var assignments []models.Assignment
+		if err := tx.Where("class_id = ?", sourceClassID).Find(&assignments).Error; err != nil {
+			return err
+		}
+		for _, assignment := range assignments {
+			copied := models.Assignment{
+				ClassID:          class.ClassID,
+				Title:            assignment.Title,
+				Description:      assignment.Description,
+				DueDate:          assignment.DueDate.AddDate(0, 0, options.DueDateOffsetDays),
+				PointsPossible:   assignment.PointsPossible,
+				IsPublished:      assignment.IsPublished,
+				CreatedBy:        teacherID,
+				AllowLateSubmit:  assignment.AllowLateSubmit,
+				SectionID:        copySectionID(assignment.SectionID),
+				AssignmentType:   assignment.AssignmentType,
+				TimeLimitMinutes: assignment.TimeLimitMinutes,
+				MaxAttempts:      assignment.MaxAttempts,
+				ShuffleQuestions: assignment.ShuffleQuestions,
+				ShuffleOptions:   assignment.ShuffleOptions,
+				RubricID:         assignment.RubricID,
+
+				MaxResubmissions:          assignment.MaxResubmissions,
+				AllowResubmitAfterGrading: assignment.AllowResubmitAfterGrading,
+				GroupMode:                 assignment.GroupMode,
+				MaxGroupSize:              assignment.MaxGroupSize,
+				PeerReviewCount:           assignment.PeerReviewCount,
+				PeerReviewPrompts:         assignment.PeerReviewPrompts,
+				PeerReviewWeight:          assignment.PeerReviewWeight,
+				AnonymousGrading:          assignment.AnonymousGrading,
+			}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+
+			if assignment.AssignmentType == models.AssignmentTypeQuiz {
+				questions, err := getQuizQuestions(tx, assignment.AssignmentID, nil)
+				if err != nil {
+					return err
+				}
+				if err := replaceQuizQuestions(tx, copied.AssignmentID, questions); err != nil {
+					return err
+				}
+
+				var pools []models.QuizPool
+				if err := tx.Where("assignment_id = ?", assignment.AssignmentID).Find(&pools).Error; err != nil {
+					return err
+				}
+				for _, pool := range pools {
+					pool.PoolID = 0
+					pool.AssignmentID = copied.AssignmentID
+					if err := tx.Create(&pool).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if options.IncludePinnedAnnouncements {
+			var announcements []models.Announcement
+			if err := tx.Where("class_id = ? AND is_pinned = ?", sourceClassID, true).Find(&announcements).Error; err != nil {
+				return err
+			}
+			for _, announcement := range announcements {
+				copied := models.Announcement{
+					ClassID:     class.ClassID,
+					Title:       announcement.Title,
+					Content:     announcement.Content,
+					CreatedBy:   teacherID,
+					CreatedDate: now,
+					IsPublished: announcement.IsPublished,
+					IsPinned:    true,
+					SectionID:   copySectionID(announcement.SectionID),
+				}
+				if announcement.ScheduledDate != nil {
+					scheduled := announcement.ScheduledDate.AddDate(0, 0, options.DueDateOffsetDays)
+					copied.ScheduledDate = &scheduled
+				}
+				if err := tx.Create(&copied).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &class, nil
+}
+
 // GetTeacherClasses retrieves all classes for a teacher
-func (s *ClassServiceImpl) GetTeacherClasses(teacherID int) ([]models.Class, error) {
+func (s *ClassServiceImpl) GetTeacherClasses(teacherID int, options ClassListOptions) ([]models.Class, error) {
 	var classes []models.Class
 
-	err := s.db.Joins("JOIN class_teachers ON classes.class_id = class_teachers.class_id").
-		Where("class_teachers.user_id = ?", teacherID).
-		Find(&classes).Error
+	query := s.db.Joins("JOIN class_teachers ON classes.class_id = class_teachers.class_id").
+		Where("class_teachers.user_id = ?", teacherID)
+	if !options.IncludeArchived {
+		query = query.Where("classes.is_archived = ?", false)
+	}
+	if options.TermID != nil {
+		query = query.Where("classes.term_id = ?", *options.TermID)
+	}
+
+	err := query.Find(&classes).Error
 
 	if err != nil {
 		return nil, err
@@ -219,12 +476,19 @@ func (s *ClassServiceImpl) RemoveTeacherFromClass(teacherID, classID int) error
 }
 
 // GetStudentClasses retrieves all classes for a student
-func (s *ClassServiceImpl) GetStudentClasses(studentID int) ([]models.Class, error) {
+func (s *ClassServiceImpl) GetStudentClasses(studentID int, options ClassListOptions) ([]models.Class, error) {
 	var classes []models.Class
 
-	err := s.db.Joins("JOIN class_enrollments ON classes.class_id = class_enrollments.class_id").
-		Where("class_enrollments.user_id = ? AND class_enrollments.is_active = ?", studentID, true).
-		Find(&classes).Error
+	query := s.db.Joins("JOIN class_enrollments ON classes.class_id = class_enrollments.class_id").
+		Where("class_enrollments.user_id = ? AND class_enrollments.is_active = ?", studentID, true)
+	if !options.IncludeArchived {
+		query = query.Where("classes.is_archived = ?", false)
+	}
+	if options.TermID != nil {
+		query = query.Where("classes.term_id = ?", *options.TermID)
+	}
+
+	err := query.Find(&classes).Error
 
 	if err != nil {
 		return nil, err
@@ -247,45 +511,417 @@ func (s *ClassServiceImpl) IsStudentInClass(studentID, classID int) (bool, error
 	return count > 0, nil
 }
 
-// EnrollStudentInClass enrolls a student in a class using a class code
-func (s *ClassServiceImpl) EnrollStudentInClass(studentID int, classCode string) (*models.Class, error) {
+// EnrollStudentInClass enrolls a student in a class using a class code.
+// Depending on the class settings the enrollment may be created as pending
+// (teacher approval required) or waitlisted (class is at capacity). A student
+// who left or was removed or rejected before gets their old enrollment back.
+func (s *ClassServiceImpl) EnrollStudentInClass(studentID int, classCode string) (*models.Class, *models.ClassEnrollment, error) {
 	// Get class by code
 	class, err := s.GetClassByCode(classCode)
 	if err != nil {
-		return nil, errors.New("invalid class code")
+		return nil, nil, errors.New("invalid class code")
+	}
+
+	var enrollment models.ClassEnrollment
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Concurrent joins wait here, so the capacity check and the
+		// enrollment below cannot interleave
+		class, err = lockClass(tx, class.ClassID)
+		if err != nil {
+			return err
+		}
+
+		// Archived classes don't accept new students
+		if class.IsArchived {
+			return ErrClassArchived
+		}
+
+		err = tx.Where("user_id = ? AND class_id = ?", studentID, class.ClassID).
+			Order("enrollment_id DESC").
+			First(&enrollment).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		switch enrollment.Status {
+		case models.EnrollmentStatusActive:
+			return errors.New("student is already enrolled in this class")
+		case models.EnrollmentStatusPending, models.EnrollmentStatusWaitlisted:
+			return errors.New("an enrollment request for this class is already pending")
+		}
+
+		status := models.EnrollmentStatusActive
+		if class.RequiresApproval {
+			status = models.EnrollmentStatusPending
+		} else {
+			full, err := isClassFull(tx, class)
+			if err != nil {
+				return err
+			}
+			if full {
+				status = models.EnrollmentStatusWaitlisted
+			}
+		}
+
+		enrollment.UserID = studentID
+		enrollment.ClassID = class.ClassID
+		enrollment.EnrollmentDate = time.Now()
+		enrollment.IsActive = status == models.EnrollmentStatusActive
+		enrollment.Status = status
+
+		if enrollment.EnrollmentID == 0 {
+			return tx.Create(&enrollment).Error
+		}
+		return tx.Model(&models.ClassEnrollment{}).
+			Where("enrollment_id = ?", enrollment.EnrollmentID).
+			Updates(map[string]interface{}{
+				"enrollment_date": enrollment.EnrollmentDate,
+				"is_active":       enrollment.IsActive,
+				"status":          enrollment.Status,
+			}).Error
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return class, &enrollment, nil
+}
+
+// RemoveStudentFromClass removes a student from a class
+func (s *ClassServiceImpl) RemoveStudentFromClass(studentID, classID int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureClassWritable(tx, classID); err != nil {
+			return err
+		}
+
+		// Set enrollment to inactive instead of deleting
+		if err := tx.Model(&models.ClassEnrollment{}).
+			Where("user_id = ? AND class_id = ?", studentID, classID).
+			Updates(map[string]interface{}{
+				"is_active": false,
+				"status":    models.EnrollmentStatusRemoved,
+			}).Error; err != nil {
+			return err
+		}
+
+		// A seat may have freed up for the waitlist
+		return s.promoteWaitlist(tx, classID)
+	})
+}
+
+// UpdateEnrollmentSettings updates the approval and capacity settings of a class
+func (s *ClassServiceImpl) UpdateEnrollmentSettings(classID int, requiresApproval bool, maxCapacity *int) error {
+	if maxCapacity != nil && *maxCapacity < 1 {
+		return errors.New("max capacity must be at least 1")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureClassWritable(tx, classID); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"requires_approval": requiresApproval,
+			"max_capacity":      maxCapacity,
+		}
+
+		if err := tx.Model(&models.Class{}).Where("class_id = ?", classID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Raising or removing the capacity may free seats for the waitlist
+		return s.promoteWaitlist(tx, classID)
+	})
+}
+
+// GetEnrollments retrieves the enrollments of a class with the given status.
+// Waitlisted enrollments include their position in the queue.
+func (s *ClassServiceImpl) GetEnrollments(classID int, status string) ([]models.EnrollmentResponse, error) {
+	var enrollments []models.EnrollmentResponse
+
+	err := s.db.Table("class_enrollments").
+		Joins("JOIN users ON class_enrollments.user_id = users.user_id").
+		Joins("LEFT JOIN student_profiles ON class_enrollments.user_id = student_profiles.user_id").
+		Select(`class_enrollments.enrollment_id, class_enrollments.class_id,
+			class_enrollments.user_id AS student_id, student_profiles.first_name,
+			student_profiles.last_name, users.email, class_enrollments.status,
+			class_enrollments.enrollment_date, class_enrollments.section_id`).
+		Where("class_enrollments.class_id = ? AND class_enrollments.status = ?", classID, status).
+		Order("class_enrollments.enrollment_date ASC").
+		Scan(&enrollments).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	if status == models.EnrollmentStatusWaitlisted {
+		for i := range enrollments {
+			enrollments[i].WaitlistPosition = i + 1
+		}
 	}
 
-	// Check if student is already enrolled
-	isEnrolled, err := s.IsStudentInClass(studentID, class.ClassID)
+	return enrollments, nil
+}
+
+// ApproveEnrollment approves a pending enrollment. If the class is already
+// at capacity the student is moved to the waitlist instead.
+func (s *ClassServiceImpl) ApproveEnrollment(classID, studentID int) (*models.ClassEnrollment, error) {
+	var enrollment models.ClassEnrollment
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		class, err := lockClass(tx, classID)
+		if err != nil {
+			return err
+		}
+		if class.IsArchived {
+			return ErrClassArchived
+		}
+
+		if err := tx.Where("class_id = ? AND user_id = ? AND status = ?", classID, studentID, models.EnrollmentStatusPending).
+			First(&enrollment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("no pending enrollment found for this student")
+			}
+			return err
+		}
+
+		full, err := isClassFull(tx, class)
+		if err != nil {
+			return err
+		}
+
+		enrollment.Status = models.EnrollmentStatusActive
+		if full {
+			enrollment.Status = models.EnrollmentStatusWaitlisted
+		}
+		enrollment.IsActive = enrollment.Status == models.EnrollmentStatusActive
+
+		return tx.Model(&models.ClassEnrollment{}).
+			Where("enrollment_id = ?", enrollment.EnrollmentID).
+			Updates(map[string]interface{}{
+				"status":    enrollment.Status,
+				"is_active": enrollment.IsActive,
+			}).Error
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
-	if isEnrolled {
-		return nil, errors.New("student is already enrolled in this class")
+	return &enrollment, nil
+}
+
+// RejectEnrollment rejects a pending or waitlisted enrollment
+func (s *ClassServiceImpl) RejectEnrollment(classID, studentID int) error {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND user_id = ? AND status IN ?", classID, studentID,
+			[]string{models.EnrollmentStatusPending, models.EnrollmentStatusWaitlisted}).
+		Updates(map[string]interface{}{
+			"status":    models.EnrollmentStatusRejected,
+			"is_active": false,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("no pending enrollment found for this student")
 	}
 
-	// Enroll student
-	enrollment := models.ClassEnrollment{
-		UserID:         studentID,
-		ClassID:        class.ClassID,
-		EnrollmentDate: time.Now(),
-		IsActive:       true,
+	return nil
+}
+
+// SetClassTerm assigns a class to a term, or removes it from its term when termID is nil
+func (s *ClassServiceImpl) SetClassTerm(classID int, termID *int) error {
+	if termID != nil {
+		var term models.Term
+		if err := s.db.Where("term_id = ?", *termID).First(&term).Error; err != nil {
+			return fmt.Errorf("term not found: %w", err)
+		}
 	}
 
-	if err := s.db.Create(&enrollment).Error; err != nil {
+	return s.db.Model(&models.Class{}).Where("class_id = ?", classID).Update("term_id", termID).Error
+}
+
+// CreateSection creates a section within a class
+func (s *ClassServiceImpl) CreateSection(classID int, name string) (*models.ClassSection, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
 		return nil, err
 	}
 
-	return class, nil
+	section := models.ClassSection{
+		ClassID: classID,
+		Name:    name,
+	}
+	if err := s.db.Create(&section).Error; err != nil {
+		return nil, fmt.Errorf("failed to create section: %w", err)
+	}
+
+	return &section, nil
 }
 
-// RemoveStudentFromClass removes a student from a class
-func (s *ClassServiceImpl) RemoveStudentFromClass(studentID, classID int) error {
-	// Set enrollment to inactive instead of deleting
-	return s.db.Model(&models.ClassEnrollment{}).
-		Where("user_id = ? AND class_id = ?", studentID, classID).
-		Update("is_active", false).Error
+// GetSections retrieves the sections of a class
+func (s *ClassServiceImpl) GetSections(classID int) ([]models.ClassSection, error) {
+	var sections []models.ClassSection
+	if err := s.db.Where("class_id = ?", classID).Order("name ASC").Find(&sections).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sections: %w", err)
+	}
+	return sections, nil
+}
+
+// DeleteSection deletes a section. Students, assignments and announcements in
+// the section fall back to the whole class.
+func (s *ClassServiceImpl) DeleteSection(classID, sectionID int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureClassWritable(tx, classID); err != nil {
+			return err
+		}
+		if err := validateSection(tx, classID, &sectionID); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.ClassEnrollment{}, &models.Assignment{}, &models.Announcement{}} {
+			if err := tx.Model(model).Where("section_id = ?", sectionID).Update("section_id", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&models.ClassSection{}, sectionID).Error
+	})
+}
+
+// SetStudentSection moves a student into a section, or out of any section when sectionID is nil
+func (s *ClassServiceImpl) SetStudentSection(classID, studentID int, sectionID *int) error {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+	if err := validateSection(s.db, classID, sectionID); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND user_id = ? AND is_active = ?", classID, studentID, true).
+		Update("section_id", sectionID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("student is not enrolled in this class")
+	}
+
+	return nil
+}
+
+// validateSection checks that a section belongs to a class. A nil section is always valid.
+func validateSection(db *gorm.DB, classID int, sectionID *int) error {
+	if sectionID == nil {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.ClassSection{}).
+		Where("section_id = ? AND class_id = ?", *sectionID, classID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("section not found in this class")
+	}
+
+	return nil
+}
+
+// studentSectionScope limits a query on assignments or announcements to the
+// items targeted at the whole class or at the student's own section
+func studentSectionScope(classID, studentID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`(section_id IS NULL OR section_id IN (
+			SELECT section_id FROM class_enrollments
+			WHERE class_id = ? AND user_id = ? AND is_active = 1 AND section_id IS NOT NULL))`, classID, studentID)
+	}
+}
+
+// getClass loads a class using the given transaction
+func (s *ClassServiceImpl) getClass(tx *gorm.DB, classID int) (*models.Class, error) {
+	var class models.Class
+	if err := tx.First(&class, classID).Error; err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// lockClass locks a class row until the transaction ends and loads the
+// class. Every path that fills a seat takes the lock before checking the
+// capacity, so two students cannot take the last seat at once.
+func lockClass(tx *gorm.DB, classID int) (*models.Class, error) {
+	if err := tx.Exec("SELECT class_id FROM classes WITH (UPDLOCK, HOLDLOCK) WHERE class_id = ?", classID).Error; err != nil {
+		return nil, err
+	}
+	var class models.Class
+	if err := tx.First(&class, classID).Error; err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// isClassFull checks whether a class has reached its maximum capacity
+func isClassFull(tx *gorm.DB, class *models.Class) (bool, error) {
+	if class.MaxCapacity == nil {
+		return false, nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND is_active = ?", class.ClassID, true).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count >= int64(*class.MaxCapacity), nil
+}
+
+// promoteWaitlist activates waitlisted students, oldest first, while seats are available
+func (s *ClassServiceImpl) promoteWaitlist(tx *gorm.DB, classID int) error {
+	class, err := lockClass(tx, classID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		full, err := isClassFull(tx, class)
+		if err != nil {
+			return err
+		}
+		if full {
+			return nil
+		}
+
+		var next models.ClassEnrollment
+		err = tx.Where("class_id = ? AND status = ?", classID, models.EnrollmentStatusWaitlisted).
+			Order("enrollment_date ASC").
+			First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.ClassEnrollment{}).
+			Where("enrollment_id = ?", next.EnrollmentID).
+			Updates(map[string]interface{}{
+				"status":    models.EnrollmentStatusActive,
+				"is_active": true,
+			}).Error; err != nil {
+			return err
+		}
+	}
 }
 
 // GetClassStudents retrieves all students in a class
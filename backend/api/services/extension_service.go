@@ -0,0 +1,389 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// ExtensionService handles per-student due date extensions and standing
+// class accommodations
+type ExtensionService interface {
+	Service
+	GetExtensions(classID, assignmentID int) ([]models.AssignmentExtension, error)
+	GrantExtension(classID, assignmentID, studentID, teacherID int, dueDate time.Time, reason string) (*models.AssignmentExtension, error)
+	RevokeExtension(classID, assignmentID, studentID int) error
+	GetAccommodations(classID int) ([]models.Accommodation, error)
+	GetAccommodation(classID, studentID int) (*models.Accommodation, error)
+	SetAccommodation(classID, studentID, teacherID int, accommodation models.Accommodation) (*models.Accommodation, error)
+	DeleteAccommodation(classID, studentID int) error
+}
+
+// ExtensionServiceImpl implements ExtensionService
+type ExtensionServiceImpl struct {
+	*BaseService
+}
+
+// NewExtensionService creates a new ExtensionService
+func NewExtensionService(db *gorm.DB) ExtensionService {
+	return &ExtensionServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetExtensions lists the extensions granted for an assignment
+func (s *ExtensionServiceImpl) GetExtensions(classID, assignmentID int) ([]models.AssignmentExtension, error) {
+	if _, err := s.getAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	var extensions []models.AssignmentExtension
+	if err := s.db.Where("assignment_id = ?", assignmentID).Order("student_id ASC").Find(&extensions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get extensions: %w", err)
+	}
+
+	return extensions, nil
+}
+
+// GrantExtension gives a student a new due date for an assignment, replacing
+// any earlier extension, and updates the late flag of their submission
+func (s *ExtensionServiceImpl) GrantExtension(classID, assignmentID, studentID, teacherID int, dueDate time.Time, reason string) (*models.AssignmentExtension, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if dueDate.IsZero() {
+		return nil, errors.New("extension due date is required")
+	}
+	if err := s.checkStudent(classID, studentID); err != nil {
+		return nil, err
+	}
+
+	var extension models.AssignmentExtension
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).First(&extension).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		extension.AssignmentID = assignmentID
+		extension.StudentID = studentID
+		extension.DueDate = dueDate
+		extension.Reason = reason
+		extension.GrantedBy = teacherID
+		if err := tx.Save(&extension).Error; err != nil {
+			return err
+		}
+
+		return recalculateLateFlags(tx, assignment, []int{studentID})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant extension: %w", err)
+	}
+
+	return &extension, nil
+}
+
+// RevokeExtension removes a student's extension for an assignment
+func (s *ExtensionServiceImpl) RevokeExtension(classID, assignmentID, studentID int) error {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).Delete(&models.AssignmentExtension{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to revoke extension: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("extension not found")
+		}
+
+		if err := recalculateLateFlags(tx, assignment, []int{studentID}); err != nil {
+			return fmt.Errorf("failed to update late submissions: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetAccommodations lists the accommodations of the students in a class
+func (s *ExtensionServiceImpl) GetAccommodations(classID int) ([]models.Accommodation, error) {
+	var accommodations []models.Accommodation
+	if err := s.db.Where("class_id = ?", classID).Order("student_id ASC").Find(&accommodations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get accommodations: %w", err)
+	}
+
+	return accommodations, nil
+}
+
+// GetAccommodation returns a student's accommodation in a class
+func (s *ExtensionServiceImpl) GetAccommodation(classID, studentID int) (*models.Accommodation, error) {
+	var accommodation models.Accommodation
+	if err := s.db.Where("class_id = ? AND student_id = ?", classID, studentID).First(&accommodation).Error; err != nil {
+		return nil, fmt.Errorf("accommodation not found: %w", err)
+	}
+
+	return &accommodation, nil
+}
+
+// SetAccommodation creates or replaces a student's accommodation in a class
+// and updates the late flags of the student's submissions
+func (s *ExtensionServiceImpl) SetAccommodation(classID, studentID, teacherID int, accommodation models.Accommodation) (*models.Accommodation, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	if accommodation.ExtraDays < 0 || accommodation.ExtraTimePercent < 0 {
+		return nil, errors.New("extra days and extra time cannot be negative")
+	}
+	if accommodation.ExtraDays == 0 && accommodation.ExtraTimePercent == 0 {
+		return nil, errors.New("accommodation must add extra days or extra time")
+	}
+	if err := s.checkStudent(classID, studentID); err != nil {
+		return nil, err
+	}
+
+	var existing models.Accommodation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("class_id = ? AND student_id = ?", classID, studentID).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		existing.ClassID = classID
+		existing.StudentID = studentID
+		existing.ExtraDays = accommodation.ExtraDays
+		existing.ExtraTimePercent = accommodation.ExtraTimePercent
+		existing.Note = accommodation.Note
+		existing.CreatedBy = teacherID
+		if err := tx.Save(&existing).Error; err != nil {
+			return err
+		}
+
+		return recalculateClassLateFlags(tx, classID, studentID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save accommodation: %w", err)
+	}
+
+	return &existing, nil
+}
+
+// DeleteAccommodation removes a student's accommodation in a class
+func (s *ExtensionServiceImpl) DeleteAccommodation(classID, studentID int) error {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("class_id = ? AND student_id = ?", classID, studentID).Delete(&models.Accommodation{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete accommodation: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("accommodation not found")
+		}
+
+		if err := recalculateClassLateFlags(tx, classID, studentID); err != nil {
+			return fmt.Errorf("failed to update late submissions: %w", err)
+		}
+		return nil
+	})
+}
+
+// getAssignment retrieves an assignment of a class
+func (s *ExtensionServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// checkStudent checks that a student is enrolled in a class
+func (s *ExtensionServiceImpl) checkStudent(classID, studentID int) error {
+	var count int64
+	if err := s.db.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND user_id = ? AND is_active = ?", classID, studentID, true).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if count == 0 {
+		return errors.New("student is not enrolled in this class")
+	}
+	return nil
+}
+
+// effectiveDueDates returns the due date of an assignment for each of the
+// given students. A student's due date is the later of their extension and
+// the assignment's due date moved by their accommodation. Assignments
+// without a due date have no due date for anyone.
+func effectiveDueDates(db *gorm.DB, assignment *models.Assignment, studentIDs []int) (map[int]time.Time, error) {
+	dueDates := make(map[int]time.Time, len(studentIDs))
+	for _, studentID := range studentIDs {
+		dueDates[studentID] = assignment.DueDate
+	}
+	if assignment.DueDate.IsZero() || len(studentIDs) == 0 {
+		return dueDates, nil
+	}
+
+	var accommodations []models.Accommodation
+	if err := db.Where("class_id = ? AND student_id IN ?", assignment.ClassID, studentIDs).Find(&accommodations).Error; err != nil {
+		return nil, err
+	}
+	for _, accommodation := range accommodations {
+		dueDates[accommodation.StudentID] = accommodation.ApplyToDueDate(assignment.DueDate)
+	}
+
+	var extensions []models.AssignmentExtension
+	if err := db.Where("assignment_id = ? AND student_id IN ?", assignment.AssignmentID, studentIDs).Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+	for _, extension := range extensions {
+		if extension.DueDate.After(dueDates[extension.StudentID]) {
+			dueDates[extension.StudentID] = extension.DueDate
+		}
+	}
+
+	return dueDates, nil
+}
+
+// studentDueDates returns one student's due date for each of the given
+// assignments of a class, keyed by assignment ID
+func studentDueDates(db *gorm.DB, classID, studentID int, assignments []models.Assignment) (map[int]time.Time, error) {
+	var accommodation *models.Accommodation
+	var found models.Accommodation
+	err := db.Where("class_id = ? AND student_id = ?", classID, studentID).First(&found).Error
+	if err == nil {
+		accommodation = &found
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var extensions []models.AssignmentExtension
+	if err := db.Where("student_id = ? AND assignment_id IN (SELECT assignment_id FROM assignments WHERE class_id = ?)", studentID, classID).
+		Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+	extended := make(map[int]time.Time, len(extensions))
+	for _, extension := range extensions {
+		extended[extension.AssignmentID] = extension.DueDate
+	}
+
+	dueDates := make(map[int]time.Time, len(assignments))
+	for _, assignment := range assignments {
+		dueDate := assignment.DueDate
+		if !dueDate.IsZero() {
+			if accommodation != nil {
+				dueDate = accommodation.ApplyToDueDate(dueDate)
+			}
+			if extension, ok := extended[assignment.AssignmentID]; ok && extension.After(dueDate) {
+				dueDate = extension
+			}
+		}
+		dueDates[assignment.AssignmentID] = dueDate
+	}
+
+	return dueDates, nil
+}
+
+// effectiveDueDate returns the due date of an assignment for one student
+func effectiveDueDate(db *gorm.DB, assignment *models.Assignment, studentID int) (time.Time, error) {
+	dueDates, err := effectiveDueDates(db, assignment, []int{studentID})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dueDates[studentID], nil
+}
+
+// quizTimeLimit returns a student's time limit for a quiz, including the extra
+// time of their accommodation. It returns nil when the quiz has no time limit.
+func quizTimeLimit(db *gorm.DB, assignment *models.Assignment, studentID int) (*time.Duration, error) {
+	if assignment.TimeLimitMinutes == nil {
+		return nil, nil
+	}
+
+	limit := time.Duration(*assignment.TimeLimitMinutes) * time.Minute
+
+	var accommodation models.Accommodation
+	err := db.Where("class_id = ? AND student_id = ?", assignment.ClassID, studentID).First(&accommodation).Error
+	if err == nil {
+		limit = accommodation.ApplyToTimeLimit(limit)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return &limit, nil
+}
+
+// isLateFor reports whether work submitted at the given time is late for a due date
+func isLateFor(dueDate, submittedAt time.Time) bool {
+	return !dueDate.IsZero() && !submittedAt.IsZero() && submittedAt.After(dueDate)
+}
+
+// recalculateLateFlags updates the late flag of the submissions of an
+// assignment against each student's due date. With no student IDs, all
+// submissions of the assignment are updated.
+func recalculateLateFlags(db *gorm.DB, assignment *models.Assignment, studentIDs []int) error {
+	query := db.Where("assignment_id = ?", assignment.AssignmentID)
+	if len(studentIDs) > 0 {
+		query = query.Where("user_id IN ?", studentIDs)
+	}
+
+	var submissions []models.Submission
+	if err := query.Find(&submissions).Error; err != nil {
+		return err
+	}
+
+	submitters := make([]int, 0, len(submissions))
+	for _, submission := range submissions {
+		submitters = append(submitters, submission.StudentID)
+	}
+	dueDates, err := effectiveDueDates(db, assignment, submitters)
+	if err != nil {
+		return err
+	}
+
+	for _, submission := range submissions {
+		isLate := isLateFor(dueDates[submission.StudentID], submission.SubmissionDate)
+		if submission.IsLate == isLate {
+			continue
+		}
+		if err := db.Model(&models.Submission{}).
+			Where("submission_id = ?", submission.SubmissionID).
+			Update("is_late", isLate).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recalculateClassLateFlags updates the late flags of a student's submissions
+// for every assignment of a class
+func recalculateClassLateFlags(db *gorm.DB, classID, studentID int) error {
+	var assignments []models.Assignment
+	if err := db.Where("class_id = ?", classID).Find(&assignments).Error; err != nil {
+		return err
+	}
+
+	for i := range assignments {
+		if err := recalculateLateFlags(db, &assignments[i], []int{studentID}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// maxGradeRows limits the size of a single grade import
+const maxGradeRows = 5000
+
+// GradingService handles bulk grading and the release of grades to students
+type GradingService interface {
+	Service
+	GetGradeRelease(classID, assignmentID int) (*models.GradeRelease, error)
+	ReleaseGrades(classID, assignmentID, teacherID int) (*models.GradeRelease, error)
+	ImportGrades(classID, assignmentID, teacherID int, csvData io.Reader, dryRun bool) (*models.GradeImportReport, error)
+}
+
+// GradingServiceImpl implements GradingService
+type GradingServiceImpl struct {
+	*BaseService
+}
+
+// NewGradingService creates a new GradingService
+func NewGradingService(db *gorm.DB) GradingService {
+	return &GradingServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetGradeRelease returns how many of an assignment's grades are released
+// and how many are drafts
+func (s *GradingServiceImpl) GetGradeRelease(classID, assignmentID int) (*models.GradeRelease, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	return gradeRelease(s.db, assignment)
+}
+
+// ReleaseGrades releases every draft grade of an assignment at once and
+// notifies the students whose grades were released. Grades changed after a
+// release are drafts again until the next release. Student identities of an
+// anonymously graded assignment are revealed once every grade is released.
+func (s *GradingServiceImpl) ReleaseGrades(classID, assignmentID, teacherID int) (*models.GradeRelease, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var class models.Class
+	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+
+	// Archived classes are read-only
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
+
+	var pending []models.Submission
+	if err := s.db.Where("assignment_id = ? AND grade IS NOT NULL AND grade_released = ?", assignmentID, false).
+		Find(&pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to get graded submissions: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil, errors.New("there are no unreleased grades for this assignment")
+	}
+
+	submissionIDs := make([]int, 0, len(pending))
+	notifications := make([]models.Notification, 0, len(pending))
+	for _, submission := range pending {
+		submissionIDs = append(submissionIDs, submission.SubmissionID)
+		notifications = append(notifications, models.Notification{
+			UserID:       submission.StudentID,
+			Type:         models.NotificationTypeGradeReleased,
+			Title:        fmt.Sprintf("Grade released: %s", assignment.Title),
+			Message:      fmt.Sprintf("Your grade for %s in %s is now available: %d/%d.", assignment.Title, class.ClassName, *submission.Grade, assignment.PointsPossible),
+			ClassID:      &assignment.ClassID,
+			AssignmentID: &assignment.AssignmentID,
+		})
+	}
+
+	releasedAt := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Submission{}).Where("submission_id IN ?", submissionIDs).
+			Update("grade_released", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(assignment).Update("grades_released_at", releasedAt).Error; err != nil {
+			return err
+		}
+		assignment.GradesReleasedAt = &releasedAt
+		if err := createNotifications(tx, notifications); err != nil {
+			return err
+		}
+		return revealIdentitiesIfComplete(tx, assignment, teacherID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to release grades: %w", err)
+	}
+
+	release, err := gradeRelease(s.db, assignment)
+	if err != nil {
+		return nil, err
+	}
+	release.ReleasedNow = len(pending)
+
+	return release, nil
+}
+
+// gradeImportEntry is a validated grade import row with the submission it grades
+type gradeImportEntry struct {
+	studentID  int
+	submission *models.Submission
+	grade      int
+	feedback   string
+}
+
+// ImportGrades grades the submissions of an assignment from a CSV file with
+// a grade column, a student ID, email or anonymous ID column and an optional
+// feedback column. Every row is checked against the class roster and the
+// assignment's points first; the grades are then applied in one transaction,
+// and only if no row has an error. Imported grades are drafts until released.
+func (s *GradingServiceImpl) ImportGrades(classID, assignmentID, teacherID int, csvData io.Reader, dryRun bool) (*models.GradeImportReport, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	records, err := utils.ReadCSVRecords(csvData, maxGradeRows)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	if len(records) > 0 {
+		values := records[0].Values
+		if !hasCSVColumn(values, "grade", "score", "points") {
+			return nil, errors.New("invalid CSV file: a grade column is required")
+		}
+		if !hasCSVColumn(values, "student id", "user id", "email", "email address", "anonymous id") {
+			return nil, errors.New("invalid CSV file: a student ID, email or anonymous ID column is required")
+		}
+	}
+
+	roster, err := newGradeRoster(s.db, assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.GradeImportReport{
+		AssignmentID: assignmentID,
+		DryRun:       dryRun,
+		Rows:         make([]models.GradeImportRow, 0, len(records)),
+	}
+
+	var entries []gradeImportEntry
+	rows := make(map[int]int) // Student ID to the first row grading them
+	for _, record := range records {
+		row, entry := roster.planRow(record)
+		if entry.studentID != 0 {
+			if first, ok := rows[entry.studentID]; ok {
+				row.Status = models.GradeRowError
+				row.Message = fmt.Sprintf("student is already listed on row %d", first)
+			} else {
+				rows[entry.studentID] = row.Row
+			}
+		}
+		if row.Status == models.GradeRowGraded {
+			entries = append(entries, entry)
+		}
+		report.AddRow(row)
+	}
+
+	if dryRun || report.Errors > 0 || len(entries) == 0 {
+		return report, nil
+	}
+
+	gradedDate := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, entry := range entries {
+			updates := map[string]interface{}{
+				"grade":          entry.grade,
+				"status":         "graded",
+				"graded_by":      teacherID,
+				"graded_date":    gradedDate,
+				"grade_released": false,
+			}
+			if entry.feedback != "" {
+				updates["feedback"] = entry.feedback
+			}
+
+			// A single grade replaces any earlier rubric scores
+			if err := tx.Where("submission_id = ?", entry.submission.SubmissionID).Delete(&models.RubricScore{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(entry.submission).Updates(updates).Error; err != nil {
+				return err
+			}
+			grade := entry.grade
+			if err := logGradingAudit(tx, assignment, teacherID, entry.submission.StudentID, models.GradingAuditGraded, &grade); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply grades: %w", err)
+	}
+	report.Applied = true
+
+	log.Printf("Grade import for assignment %d: %d graded, %d unchanged, %d skipped",
+		assignmentID, report.Graded, report.Unchanged, report.Skipped)
+
+	return report, nil
+}
+
+func (s *GradingServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// gradeRelease counts the released and draft grades of an assignment
+func gradeRelease(db *gorm.DB, assignment *models.Assignment) (*models.GradeRelease, error) {
+	var counts []struct {
+		GradeReleased bool
+		Count         int
+	}
+	if err := db.Model(&models.Submission{}).
+		Select("grade_released, COUNT(*) AS count").
+		Where("assignment_id = ? AND grade IS NOT NULL", assignment.AssignmentID).
+		Group("grade_released").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count grades: %w", err)
+	}
+
+	release := models.GradeRelease{
+		AssignmentID: assignment.AssignmentID,
+		ReleasedAt:   assignment.GradesReleasedAt,
+	}
+	for _, count := range counts {
+		if count.GradeReleased {
+			release.Released += count.Count
+		} else {
+			release.Pending += count.Count
+		}
+		release.Graded += count.Count
+	}
+
+	return &release, nil
+}
+
+// hasCSVColumn reports whether a CSV record has any of the given columns
+func hasCSVColumn(values map[string]string, names ...string) bool {
+	for _, name := range names {
+		if _, ok := values[utils.NormalizeCSVHeader(name)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// gradeRoster holds the students a grade import can grade: those actively
+// enrolled in the class, with their submissions to the assignment
+type gradeRoster struct {
+	db          *gorm.DB
+	assignment  *models.Assignment
+	key         string // Anonymity key when the assignment is graded anonymously
+	students    map[int]string
+	emails      map[string]int
+	anonymous   map[string]int
+	submissions map[int]*models.Submission
+}
+
+func newGradeRoster(db *gorm.DB, assignment *models.Assignment) (*gradeRoster, error) {
+	roster := &gradeRoster{
+		db:          db,
+		assignment:  assignment,
+		students:    make(map[int]string),
+		emails:      make(map[string]int),
+		anonymous:   make(map[string]int),
+		submissions: make(map[int]*models.Submission),
+	}
+
+	var studentIDs []int
+	if err := db.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND is_active = ? AND status = ?", assignment.ClassID, true, models.EnrollmentStatusActive).
+		Pluck("user_id", &studentIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get class roster: %w", err)
+	}
+	if len(studentIDs) == 0 {
+		return roster, nil
+	}
+
+	var users []models.User
+	if err := db.Where("user_id IN ?", studentIDs).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get students: %w", err)
+	}
+	for _, user := range users {
+		roster.students[user.UserID] = user.Email
+		roster.emails[strings.ToLower(user.Email)] = user.UserID
+	}
+
+	if assignment.AnonymousGrading {
+		key, err := anonymityKey(db, assignment)
+		if err != nil {
+			return nil, err
+		}
+		roster.key = key
+		for studentID := range roster.students {
+			anonymousID, _ := studentPseudonym(key, studentID)
+			roster.anonymous[anonymousID] = studentID
+		}
+	}
+
+	var submissions []models.Submission
+	if err := db.Where("assignment_id = ? AND user_id IN ?", assignment.AssignmentID, studentIDs).
+		Find(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get submissions: %w", err)
+	}
+	for i := range submissions {
+		roster.submissions[submissions[i].StudentID] = &submissions[i]
+	}
+
+	return roster, nil
+}
+
+// planRow validates a CSV row and works out what it changes
+func (r *gradeRoster) planRow(record utils.CSVRecord) (models.GradeImportRow, gradeImportEntry) {
+	row := models.GradeImportRow{
+		Row:      record.Line,
+		Feedback: record.Get("feedback", "comment", "comments"),
+		Status:   models.GradeRowError,
+	}
+	hidden := r.assignment.IdentitiesHidden()
+
+	studentID, err := r.resolveStudent(record, hidden)
+	if err != nil {
+		row.Message = err.Error()
+		return row, gradeImportEntry{}
+	}
+	entry := gradeImportEntry{studentID: studentID}
+	if hidden {
+		row.AnonymousID, row.StudentName = studentPseudonym(r.key, studentID)
+	} else {
+		row.StudentID = studentID
+		row.Email = r.students[studentID]
+		row.StudentName = studentDisplayName(r.db, studentID)
+	}
+
+	gradeText := record.Get("grade", "score", "points")
+	if gradeText == "" {
+		row.Status = models.GradeRowSkipped
+		row.Message = "no grade given"
+		return row, entry
+	}
+	grade, err := strconv.Atoi(gradeText)
+	if err != nil {
+		row.Message = fmt.Sprintf("grade %q is not a whole number", gradeText)
+		return row, entry
+	}
+	row.Grade = &grade
+	if grade < 0 || grade > r.assignment.PointsPossible {
+		row.Message = fmt.Sprintf("grade must be between 0 and %d", r.assignment.PointsPossible)
+		return row, entry
+	}
+
+	submission, ok := r.submissions[studentID]
+	if !ok {
+		row.Message = "student has no submission for this assignment"
+		return row, entry
+	}
+	row.PreviousGrade = submission.Grade
+
+	if submission.Grade != nil && *submission.Grade == grade && (row.Feedback == "" || row.Feedback == submission.Feedback) {
+		row.Status = models.GradeRowUnchanged
+		return row, entry
+	}
+
+	row.Status = models.GradeRowGraded
+	entry.submission = submission
+	entry.grade = grade
+	entry.feedback = row.Feedback
+	return row, entry
+}
+
+// resolveStudent finds the student of a row from its student ID, email or
+// anonymous ID. While identities are hidden only anonymous IDs are accepted.
+func (r *gradeRoster) resolveStudent(record utils.CSVRecord, hidden bool) (int, error) {
+	idText := record.Get("student id", "user id")
+	email := strings.ToLower(record.Get("email", "email address"))
+	anonymousID := strings.ToLower(record.Get("anonymous id"))
+	if strings.HasPrefix(idText, models.AnonymousIDPrefix) {
+		anonymousID, idText = strings.ToLower(idText), ""
+	}
+
+	if anonymousID != "" {
+		if !r.assignment.AnonymousGrading {
+			return 0, errors.New("assignment is not graded anonymously")
+		}
+		studentID, ok := r.anonymous[anonymousID]
+		if !ok {
+			return 0, errors.New("no enrolled student has this anonymous ID")
+		}
+		return studentID, nil
+	}
+	if idText == "" && email == "" {
+		return 0, errors.New("a student ID, email or anonymous ID is required")
+	}
+	if hidden {
+		return 0, ErrIdentitiesHidden
+	}
+
+	studentID := 0
+	if idText != "" {
+		id, err := strconv.Atoi(idText)
+		if err != nil {
+			return 0, fmt.Errorf("invalid student ID %q", idText)
+		}
+		if _, ok := r.students[id]; !ok {
+			return 0, errors.New("student is not enrolled in this class")
+		}
+		studentID = id
+	}
+	if email != "" {
+		id, ok := r.emails[email]
+		if !ok {
+			return 0, errors.New("no enrolled student has this email")
+		}
+		if studentID != 0 && studentID != id {
+			return 0, errors.New("student ID and email belong to different students")
+		}
+		studentID = id
+	}
+
+	return studentID, nil
+}
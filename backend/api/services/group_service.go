@@ -0,0 +1,566 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// GroupService handles the groups of group assignments
+type GroupService interface {
+	Service
+	GetGroups(classID, assignmentID int) ([]models.AssignmentGroup, error)
+	CreateGroup(classID, assignmentID, creatorID int, name string, studentIDs []int) (*models.AssignmentGroup, error)
+	CreateOwnGroup(classID, assignmentID, studentID int, name string) (*models.AssignmentGroup, error)
+	SetMembers(classID, assignmentID, groupID int, studentIDs []int) (*models.AssignmentGroup, error)
+	DeleteGroup(classID, assignmentID, groupID int) error
+	JoinGroup(classID, assignmentID, groupID, studentID int) (*models.AssignmentGroup, error)
+	LeaveGroup(classID, assignmentID, groupID, studentID int) error
+	GradeGroup(classID, assignmentID, groupID, teacherID, grade int, feedback string) ([]models.SubmissionResponse, error)
+}
+
+// GroupServiceImpl implements GroupService
+type GroupServiceImpl struct {
+	*BaseService
+}
+
+// NewGroupService creates a new GroupService
+func NewGroupService(db *gorm.DB) GroupService {
+	return &GroupServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetGroups lists the groups of an assignment with their members
+func (s *GroupServiceImpl) GetGroups(classID, assignmentID int) ([]models.AssignmentGroup, error) {
+	if _, err := s.getGroupAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	var groups []models.AssignmentGroup
+	if err := s.db.Where("assignment_id = ?", assignmentID).Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	for i := range groups {
+		if err := loadGroupMembers(s.db, &groups[i]); err != nil {
+			return nil, fmt.Errorf("failed to get group members: %w", err)
+		}
+	}
+
+	return groups, nil
+}
+
+// CreateGroup creates a group with the given members (teacher)
+func (s *GroupServiceImpl) CreateGroup(classID, assignmentID, creatorID int, name string, studentIDs []int) (*models.AssignmentGroup, error) {
+	assignment, err := s.getGroupAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	return s.createGroup(assignment, creatorID, name, studentIDs)
+}
+
+// CreateOwnGroup creates a group with the student as its first member. Only
+// allowed when students form their own groups.
+func (s *GroupServiceImpl) CreateOwnGroup(classID, assignmentID, studentID int, name string) (*models.AssignmentGroup, error) {
+	assignment, err := s.getGroupAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+	if assignment.GroupMode != models.GroupModeSelf {
+		return nil, errors.New("groups for this assignment are set by the teacher")
+	}
+
+	return s.createGroup(assignment, studentID, name, []int{studentID})
+}
+
+// SetMembers replaces the members of a group (teacher)
+func (s *GroupServiceImpl) SetMembers(classID, assignmentID, groupID int, studentIDs []int) (*models.AssignmentGroup, error) {
+	assignment, err := s.getGroupAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+	group, err := s.getGroup(assignmentID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGroupNotSubmitted(s.db, groupID); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGroup(tx, groupID); err != nil {
+			return fmt.Errorf("failed to update group members: %w", err)
+		}
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.AssignmentGroupMember{}).Error; err != nil {
+			return fmt.Errorf("failed to update group members: %w", err)
+		}
+		return addGroupMembers(tx, assignment, group, studentIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadGroupMembers(s.db, group); err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	return group, nil
+}
+
+// DeleteGroup deletes a group that has not submitted yet
+func (s *GroupServiceImpl) DeleteGroup(classID, assignmentID, groupID int) error {
+	if _, err := s.getGroupAssignment(classID, assignmentID); err != nil {
+		return err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+	if _, err := s.getGroup(assignmentID, groupID); err != nil {
+		return err
+	}
+	if err := checkGroupNotSubmitted(s.db, groupID); err != nil {
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.AssignmentGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.AssignmentGroup{}, groupID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	return nil
+}
+
+// JoinGroup adds a student to a group when students form their own groups
+func (s *GroupServiceImpl) JoinGroup(classID, assignmentID, groupID, studentID int) (*models.AssignmentGroup, error) {
+	assignment, err := s.getGroupAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+	if assignment.GroupMode != models.GroupModeSelf {
+		return nil, errors.New("groups for this assignment are set by the teacher")
+	}
+	group, err := s.getGroup(assignmentID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGroupNotSubmitted(s.db, groupID); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return addGroupMembers(tx, assignment, group, []int{studentID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadGroupMembers(s.db, group); err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	return group, nil
+}
+
+// LeaveGroup removes a student from a group when students form their own
+// groups. The last member to leave deletes the group.
+func (s *GroupServiceImpl) LeaveGroup(classID, assignmentID, groupID, studentID int) error {
+	assignment, err := s.getGroupAssignment(classID, assignmentID)
+	if err != nil {
+		return err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+	if assignment.GroupMode != models.GroupModeSelf {
+		return errors.New("groups for this assignment are set by the teacher")
+	}
+	if _, err := s.getGroup(assignmentID, groupID); err != nil {
+		return err
+	}
+	if err := checkGroupNotSubmitted(s.db, groupID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Nobody can join while the last member deletes the group
+		if err := lockGroup(tx, groupID); err != nil {
+			return fmt.Errorf("failed to leave group: %w", err)
+		}
+
+		result := tx.Where("group_id = ? AND student_id = ?", groupID, studentID).Delete(&models.AssignmentGroupMember{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to leave group: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("student is not a member of this group")
+		}
+
+		var remaining int64
+		if err := tx.Model(&models.AssignmentGroupMember{}).Where("group_id = ?", groupID).Count(&remaining).Error; err != nil {
+			return fmt.Errorf("failed to leave group: %w", err)
+		}
+		if remaining == 0 {
+			if err := tx.Delete(&models.AssignmentGroup{}, groupID).Error; err != nil {
+				return fmt.Errorf("failed to delete empty group: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GradeGroup gives every member of a group the same grade. A member's grade
+// can then be overridden with GradeSubmission.
+func (s *GroupServiceImpl) GradeGroup(classID, assignmentID, groupID, teacherID, grade int, feedback string) ([]models.SubmissionResponse, error) {
+	assignment, err := s.getGroupAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getGroup(assignmentID, groupID); err != nil {
+		return nil, err
+	}
+
+	var submissions []models.Submission
+	if err := s.db.Where("assignment_id = ? AND group_id = ?", assignmentID, groupID).Find(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get group submissions: %w", err)
+	}
+	if len(submissions) == 0 {
+		return nil, errors.New("group has not submitted this assignment")
+	}
+
+	gradedDate := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range submissions {
+			gradeValue := grade
+			gradedBy := teacherID
+			submissions[i].Grade = &gradeValue
+			submissions[i].Feedback = feedback
+			submissions[i].Status = "graded"
+			submissions[i].GradedBy = &gradedBy
+			submissions[i].GradedDate = &gradedDate
+			submissions[i].GradeReleased = false
+
+			// A single grade replaces any earlier rubric scores
+			if err := tx.Where("submission_id = ?", submissions[i].SubmissionID).Delete(&models.RubricScore{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Save(&submissions[i]).Error; err != nil {
+				return err
+			}
+			if err := logGradingAudit(tx, assignment, teacherID, submissions[i].StudentID, models.GradingAuditGraded, submissions[i].Grade); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to grade group: %w", err)
+	}
+
+	responses := make([]models.SubmissionResponse, 0, len(submissions))
+	for _, submission := range submissions {
+		submission.Assignment = assignment
+		submission.StudentName = studentDisplayName(s.db, submission.StudentID)
+		responses = append(responses, submission.ToResponse())
+	}
+
+	return responses, nil
+}
+
+// createGroup creates a group and adds its first members
+func (s *GroupServiceImpl) createGroup(assignment *models.Assignment, creatorID int, name string, studentIDs []int) (*models.AssignmentGroup, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("group name is required")
+	}
+
+	group := &models.AssignmentGroup{
+		AssignmentID: assignment.AssignmentID,
+		Name:         name,
+		CreatedBy:    creatorID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return fmt.Errorf("failed to create group: %w", err)
+		}
+		return addGroupMembers(tx, assignment, group, studentIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadGroupMembers(s.db, group); err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	return group, nil
+}
+
+// getGroupAssignment retrieves an assignment of a class that is done in groups
+func (s *GroupServiceImpl) getGroupAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	if !assignment.IsGroupAssignment() {
+		return nil, errors.New("assignment is not a group assignment")
+	}
+	return &assignment, nil
+}
+
+// getGroup retrieves a group of an assignment
+func (s *GroupServiceImpl) getGroup(assignmentID, groupID int) (*models.AssignmentGroup, error) {
+	var group models.AssignmentGroup
+	if err := s.db.Where("group_id = ? AND assignment_id = ?", groupID, assignmentID).First(&group).Error; err != nil {
+		return nil, fmt.Errorf("group not found: %w", err)
+	}
+	return &group, nil
+}
+
+// addGroupMembers adds students to a group. Every student must be able to
+// submit the assignment and must not be in another group for it; the group
+// cannot grow beyond the assignment's maximum group size. Must run in a
+// transaction, which holds the group's lock until it ends.
+func addGroupMembers(tx *gorm.DB, assignment *models.Assignment, group *models.AssignmentGroup, studentIDs []int) error {
+	if err := lockGroup(tx, group.GroupID); err != nil {
+		return fmt.Errorf("failed to lock group: %w", err)
+	}
+
+	var count int64
+	if err := tx.Model(&models.AssignmentGroupMember{}).Where("group_id = ?", group.GroupID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count group members: %w", err)
+	}
+
+	seen := make(map[int]bool, len(studentIDs))
+	for _, studentID := range studentIDs {
+		if seen[studentID] {
+			continue
+		}
+		seen[studentID] = true
+
+		if assignment.MaxGroupSize != nil && int(count) >= *assignment.MaxGroupSize {
+			return fmt.Errorf("group is full: at most %d member(s) allowed", *assignment.MaxGroupSize)
+		}
+
+		if err := checkCanSubmit(tx, assignment, studentID); err != nil {
+			return fmt.Errorf("student %d: %w", studentID, err)
+		}
+
+		existing, err := findStudentGroup(tx, assignment.AssignmentID, studentID)
+		if err != nil {
+			return fmt.Errorf("failed to check group membership: %w", err)
+		}
+		if existing != nil {
+			return fmt.Errorf("student %d is already in a group for this assignment", studentID)
+		}
+
+		member := models.AssignmentGroupMember{
+			GroupID:      group.GroupID,
+			AssignmentID: assignment.AssignmentID,
+			StudentID:    studentID,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return fmt.Errorf("failed to add group member: %w", err)
+		}
+		count++
+	}
+
+	return nil
+}
+
+// lockGroup locks a group row until the transaction ends, so that members
+// are counted and added by one request at a time
+func lockGroup(tx *gorm.DB, groupID int) error {
+	return tx.Exec("SELECT group_id FROM assignment_groups WITH (UPDLOCK, HOLDLOCK) WHERE group_id = ?", groupID).Error
+}
+
+// loadGroupMembers loads the members of a group with their names
+func loadGroupMembers(db *gorm.DB, group *models.AssignmentGroup) error {
+	var members []models.AssignmentGroupMember
+	if err := db.Where("group_id = ?", group.GroupID).Order("joined_at ASC").Find(&members).Error; err != nil {
+		return err
+	}
+
+	for i := range members {
+		members[i].StudentName = studentDisplayName(db, members[i].StudentID)
+	}
+
+	group.Members = members
+	return nil
+}
+
+// findStudentGroup returns the group a student is in for an assignment, with
+// its members, or nil when the student is not in a group
+func findStudentGroup(db *gorm.DB, assignmentID, studentID int) (*models.AssignmentGroup, error) {
+	var member models.AssignmentGroupMember
+	err := db.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var group models.AssignmentGroup
+	if err := db.Where("group_id = ?", member.GroupID).First(&group).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("group_id = ?", group.GroupID).Order("joined_at ASC").Find(&group.Members).Error; err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// checkGroupNotSubmitted checks that a group has no submission yet; the
+// members of a group are fixed once it has submitted
+func checkGroupNotSubmitted(db *gorm.DB, groupID int) error {
+	var count int64
+	if err := db.Model(&models.Submission{}).Where("group_id = ?", groupID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check group submission: %w", err)
+	}
+	if count > 0 {
+		return errors.New("group has already submitted and its members cannot change")
+	}
+	return nil
+}
+
+// submitGroupWork stores the submission of a group: every member gets the
+// same content in their own submission row, with a late flag for their own
+// due date. It returns the submitter's submission and its version number.
+func submitGroupWork(db *gorm.DB, assignment *models.Assignment, studentID int, content, fileURL string) (*models.Submission, int, error) {
+	group, err := findStudentGroup(db, assignment.AssignmentID, studentID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get group: %w", err)
+	}
+	if group == nil {
+		return nil, 0, errors.New("student is not in a group for this assignment")
+	}
+	memberIDs := group.MemberIDs()
+
+	var existing []models.Submission
+	if err := db.Where("assignment_id = ? AND user_id IN ?", assignment.AssignmentID, memberIDs).Find(&existing).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get group submissions: %w", err)
+	}
+	submissions := make(map[int]*models.Submission, len(memberIDs))
+	for i := range existing {
+		submissions[existing[i].StudentID] = &existing[i]
+	}
+
+	// The resubmission rules apply to the group's shared work
+	if submitted, ok := submissions[studentID]; ok {
+		for _, submission := range existing {
+			if submission.Grade != nil && !assignment.AllowResubmitAfterGrading {
+				return nil, 0, errors.New("submission has already been graded and resubmissions are not allowed")
+			}
+		}
+
+		storedVersions, err := countSubmissionVersions(db, submitted.SubmissionID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count submission versions: %w", err)
+		}
+		if assignment.MaxResubmissions != nil && max(storedVersions, 1)-1 >= *assignment.MaxResubmissions {
+			return nil, 0, fmt.Errorf("resubmission limit reached: %d resubmission(s) allowed", *assignment.MaxResubmissions)
+		}
+	}
+
+	dueDates, err := effectiveDueDates(db, assignment, memberIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get due dates: %w", err)
+	}
+
+	now := time.Now()
+	var submitterVersion int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, memberID := range memberIDs {
+			submission, ok := submissions[memberID]
+			if ok {
+				// Submissions made before versions were recorded keep their work as version 1
+				storedVersions, err := countSubmissionVersions(tx, submission.SubmissionID)
+				if err != nil {
+					return err
+				}
+				if storedVersions == 0 {
+					if _, err := recordSubmissionVersion(tx, submission); err != nil {
+						return err
+					}
+				}
+			} else {
+				submission = &models.Submission{AssignmentID: assignment.AssignmentID, StudentID: memberID}
+				submissions[memberID] = submission
+			}
+
+			submission.Content = content
+			if fileURL != "" || !ok {
+				submission.FileURL = fileURL
+			}
+			submission.SubmissionDate = now
+			submission.Status = "submitted"
+			submission.IsLate = isLateFor(dueDates[memberID], now)
+			submission.GroupID = &group.GroupID
+			if err := tx.Save(submission).Error; err != nil {
+				return err
+			}
+
+			version, err := recordSubmissionVersion(tx, submission)
+			if err != nil {
+				return err
+			}
+			if memberID == studentID {
+				submitterVersion = version
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to save group submission: %w", err)
+	}
+
+	return submissions[studentID], submitterVersion, nil
+}
+
+// studentDisplayName returns a student's full name, or a placeholder when the
+// student has no profile
+func studentDisplayName(db *gorm.DB, studentID int) string {
+	var student models.StudentProfile
+	if err := db.Where("user_id = ?", studentID).First(&student).Error; err != nil {
+		return fmt.Sprintf("Student #%d", studentID)
+	}
+	return fmt.Sprintf("%s %s", student.FirstName, student.LastName)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// GuardianService manages the parents and guardians of students
+type GuardianService interface {
+	Service
+	GetGuardians(studentID int) ([]models.Guardian, error)
+	AddGuardian(studentID int, guardian models.Guardian) (*models.Guardian, error)
+	UpdateGuardian(studentID, guardianID int, guardian models.Guardian) (*models.Guardian, error)
+	DeleteGuardian(studentID, guardianID int) error
+	TeachesStudent(teacherID, studentID int) (bool, error)
+}
+
+// GuardianServiceImpl implements GuardianService
+type GuardianServiceImpl struct {
+	*BaseService
+}
+
+// NewGuardianService creates a new GuardianService
+func NewGuardianService(db *gorm.DB) GuardianService {
+	return &GuardianServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetGuardians returns the guardians of a student
+func (s *GuardianServiceImpl) GetGuardians(studentID int) ([]models.Guardian, error) {
+	if err := s.checkStudent(studentID); err != nil {
+		return nil, err
+	}
+
+	var guardians []models.Guardian
+	if err := s.db.Where("student_id = ?", studentID).Order("guardian_id").Find(&guardians).Error; err != nil {
+		return nil, fmt.Errorf("failed to get guardians: %w", err)
+	}
+	return guardians, nil
+}
+
+// AddGuardian adds a guardian to a student
+func (s *GuardianServiceImpl) AddGuardian(studentID int, guardian models.Guardian) (*models.Guardian, error) {
+	if err := s.checkStudent(studentID); err != nil {
+		return nil, err
+	}
+	if err := validateGuardian(&guardian); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Guardian{}).Where("student_id = ? AND email = ?", studentID, guardian.Email).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check guardians: %w", err)
+	}
+	if count > 0 {
+		return nil, errors.New("a guardian with this email already exists for the student")
+	}
+
+	guardian.GuardianID = 0
+	guardian.StudentID = studentID
+	if err := s.db.Create(&guardian).Error; err != nil {
+		return nil, fmt.Errorf("failed to add guardian: %w", err)
+	}
+	return &guardian, nil
+}
+
+// UpdateGuardian changes the details of a student's guardian
+func (s *GuardianServiceImpl) UpdateGuardian(studentID, guardianID int, guardian models.Guardian) (*models.Guardian, error) {
+	existing, err := s.getGuardian(studentID, guardianID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateGuardian(&guardian); err != nil {
+		return nil, err
+	}
+
+	existing.Name = guardian.Name
+	existing.Email = guardian.Email
+	existing.Relationship = guardian.Relationship
+	existing.ReceivesAlerts = guardian.ReceivesAlerts
+	if err := s.db.Save(existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to update guardian: %w", err)
+	}
+	return existing, nil
+}
+
+// DeleteGuardian removes a guardian from a student
+func (s *GuardianServiceImpl) DeleteGuardian(studentID, guardianID int) error {
+	guardian, err := s.getGuardian(studentID, guardianID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(guardian).Error; err != nil {
+		return fmt.Errorf("failed to delete guardian: %w", err)
+	}
+	return nil
+}
+
+// TeachesStudent reports whether a teacher teaches a class the student is
+// actively enrolled in
+func (s *GuardianServiceImpl) TeachesStudent(teacherID, studentID int) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.ClassEnrollment{}).
+		Joins("JOIN class_teachers ct ON ct.class_id = class_enrollments.class_id").
+		Where("class_enrollments.user_id = ? AND class_enrollments.is_active = ? AND ct.user_id = ?", studentID, true, teacherID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *GuardianServiceImpl) checkStudent(studentID int) error {
+	var user models.User
+	if err := s.db.Where("user_id = ? AND user_role = ?", studentID, "student").First(&user).Error; err != nil {
+		return fmt.Errorf("student not found: %w", err)
+	}
+	return nil
+}
+
+func (s *GuardianServiceImpl) getGuardian(studentID, guardianID int) (*models.Guardian, error) {
+	var guardian models.Guardian
+	if err := s.db.Where("guardian_id = ? AND student_id = ?", guardianID, studentID).First(&guardian).Error; err != nil {
+		return nil, fmt.Errorf("guardian not found: %w", err)
+	}
+	return &guardian, nil
+}
+
+// validateGuardian trims a guardian's details and checks the name and email
+func validateGuardian(guardian *models.Guardian) error {
+	guardian.Name = strings.TrimSpace(guardian.Name)
+	guardian.Email = strings.ToLower(strings.TrimSpace(guardian.Email))
+	guardian.Relationship = strings.TrimSpace(guardian.Relationship)
+
+	if guardian.Name == "" {
+		return errors.New("guardian name is required")
+	}
+	if _, err := mail.ParseAddress(guardian.Email); err != nil {
+		return errors.New("invalid email address")
+	}
+	return nil
+}
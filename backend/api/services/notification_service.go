@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// NotificationService handles the in-app notifications of users
+type NotificationService interface {
+	Service
+	GetNotifications(userID int, unreadOnly bool) ([]models.Notification, error)
+	MarkRead(userID, notificationID int) (*models.Notification, error)
+	MarkAllRead(userID int) (int, error)
+}
+
+// NotificationServiceImpl implements NotificationService
+type NotificationServiceImpl struct {
+	*BaseService
+}
+
+// NewNotificationService creates a new NotificationService
+func NewNotificationService(db *gorm.DB) NotificationService {
+	return &NotificationServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetNotifications returns a user's notifications, newest first
+func (s *NotificationServiceImpl) GetNotifications(userID int, unreadOnly bool) ([]models.Notification, error) {
+	query := s.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks one of a user's notifications as read
+func (s *NotificationServiceImpl) MarkRead(userID, notificationID int) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("notification_id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return nil, fmt.Errorf("notification not found: %w", err)
+	}
+
+	if !notification.IsRead {
+		readAt := time.Now()
+		notification.IsRead = true
+		notification.ReadAt = &readAt
+		if err := s.db.Save(&notification).Error; err != nil {
+			return nil, fmt.Errorf("failed to update notification: %w", err)
+		}
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of a user as read and returns
+// how many were marked
+func (s *NotificationServiceImpl) MarkAllRead(userID int) (int, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update notifications: %w", result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// createNotifications stores notifications, usually as part of the
+// transaction of the change they report
+func createNotifications(tx *gorm.DB, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&notifications, 100).Error
+}
@@ -0,0 +1,904 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// maxOneRosterMessages limits how many row messages are kept per file
+const maxOneRosterMessages = 200
+
+// maxOneRosterFileSize limits the uncompressed size of a CSV file in a bundle (50 MB)
+const maxOneRosterFileSize = 50 << 20
+
+// maxOneRosterRows limits the number of rows read from a CSV file in a bundle
+const maxOneRosterRows = 100000
+
+// errOneRosterDryRun is used to roll back the import transaction in dry-run mode
+var errOneRosterDryRun = errors.New("oneroster dry run")
+
+// OneRosterService imports and exports OneRoster 1.2 CSV bundles
+type OneRosterService interface {
+	Service
+	ImportBundle(importerID int, bundle []byte, dryRun bool) (*models.OneRosterImportReport, error)
+	ExportResults(classIDs []int) ([]byte, error)
+}
+
+// OneRosterServiceImpl implements OneRosterService
+type OneRosterServiceImpl struct {
+	*BaseService
+}
+
+// NewOneRosterService creates a new OneRosterService
+func NewOneRosterService(db *gorm.DB) OneRosterService {
+	return &OneRosterServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// oneRosterImport holds the state of a single bundle import
+type oneRosterImport struct {
+	tx         *gorm.DB
+	importerID int
+	files      map[string]*zip.File
+	userRoles  map[string]string // userSourcedId -> student or teacher
+	report     *models.OneRosterImportReport
+
+	// placeholderHash is a password hash nobody knows the password for.
+	// Imported users sign in after setting a password with "forgot password".
+	placeholderHash string
+}
+
+// ImportBundle imports a OneRoster CSV zip bundle. Orgs are validated;
+// academic sessions become terms, and users, classes and enrollments are
+// created or updated.
+// Records are matched on sourcedId (and users on email) so the same bundle
+// can be imported repeatedly without creating duplicates.
+func (s *OneRosterServiceImpl) ImportBundle(importerID int, bundle []byte, dryRun bool) (*models.OneRosterImportReport, error) {
+	zr, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		return nil, errors.New("invalid OneRoster bundle: not a zip file")
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[strings.ToLower(path.Base(f.Name))] = f
+	}
+
+	if _, ok := files["manifest.csv"]; !ok {
+		return nil, errors.New("invalid OneRoster bundle: manifest.csv is missing")
+	}
+
+	secret, err := utils.GenerateSecureRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	placeholderHash, err := utils.HashPassword(secret)
+	if err != nil {
+		return nil, errors.New("error creating secure password")
+	}
+
+	imp := &oneRosterImport{
+		importerID:      importerID,
+		files:           files,
+		userRoles:       make(map[string]string),
+		report:          &models.OneRosterImportReport{DryRun: dryRun},
+		placeholderHash: placeholderHash,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		imp.tx = tx
+
+		steps := []struct {
+			file   string
+			handle func(*oneRosterImport, utils.CSVRecord, *models.OneRosterFileReport) error
+		}{
+			{"orgs.csv", s.importOrg},
+			{"academicsessions.csv", s.importAcademicSession},
+			{"roles.csv", s.importRole},
+			{"users.csv", s.importUser},
+			{"classes.csv", s.importClass},
+			{"enrollments.csv", s.importEnrollment},
+		}
+
+		// Teacher roles can also be inferred from enrollments
+		if err := s.collectEnrollmentRoles(imp); err != nil {
+			return err
+		}
+
+		for _, step := range steps {
+			if err := s.importFile(imp, step.file, step.handle); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			return errOneRosterDryRun
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errOneRosterDryRun) {
+		return nil, err
+	}
+
+	return imp.report, nil
+}
+
+// importFile runs a row handler over every record of a file in the bundle
+func (s *OneRosterServiceImpl) importFile(imp *oneRosterImport, name string,
+	handle func(*oneRosterImport, utils.CSVRecord, *models.OneRosterFileReport) error) error {
+	records, ok, err := readBundleFile(imp.files, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	fileReport := &models.OneRosterFileReport{File: name, Rows: len(records)}
+	imp.report.Files = append(imp.report.Files, fileReport)
+
+	for _, record := range records {
+		if record.Get("sourcedId") == "" {
+			addOneRosterMessage(fileReport, record, "sourcedId is required")
+			fileReport.Errors++
+			continue
+		}
+		if err := handle(imp, record, fileReport); err != nil {
+			return fmt.Errorf("%s line %d: %w", name, record.Line, err)
+		}
+	}
+
+	log.Printf("OneRoster import %s: %d rows, %d created, %d updated, %d skipped, %d errors",
+		name, fileReport.Rows, fileReport.Created, fileReport.Updated, fileReport.Skipped, fileReport.Errors)
+
+	return nil
+}
+
+// importOrg validates an org row. Orgs have no local table and are kept for reference only.
+func (s *OneRosterServiceImpl) importOrg(imp *oneRosterImport, record utils.CSVRecord, report *models.OneRosterFileReport) error {
+	if record.Get("name") == "" {
+		addOneRosterMessage(report, record, "name is required")
+		report.Errors++
+		return nil
+	}
+	report.Skipped++
+	return nil
+}
+
+// importAcademicSession creates or updates the term of an academic session.
+// Deleted sessions keep their term, since its classes may still refer to it.
+func (s *OneRosterServiceImpl) importAcademicSession(imp *oneRosterImport, record utils.CSVRecord, report *models.OneRosterFileReport) error {
+	sourcedID := record.Get("sourcedId")
+	tx := imp.tx
+
+	localID, mapped, err := lookupOneRosterMapping(tx, models.OneRosterEntityTerm, sourcedID)
+	if err != nil {
+		return err
+	}
+
+	if isOneRosterDeleted(record) {
+		if mapped {
+			addOneRosterMessage(report, record, "deleted academic session kept as a term, delete it by hand if it is no longer needed")
+		}
+		report.Skipped++
+		return nil
+	}
+
+	title := record.Get("title")
+	if title == "" {
+		addOneRosterMessage(report, record, "title is required")
+		report.Errors++
+		return nil
+	}
+	startDate, startErr := models.ParseTime(record.Get("startDate"))
+	endDate, endErr := models.ParseTime(record.Get("endDate"))
+	if startErr != nil || endErr != nil {
+		addOneRosterMessage(report, record, "startDate and endDate must be dates")
+		report.Errors++
+		return nil
+	}
+	if !endDate.After(startDate) {
+		addOneRosterMessage(report, record, "endDate must be after startDate")
+		report.Errors++
+		return nil
+	}
+
+	if mapped {
+		// Moving the end date into the future allows the term to be auto-archived again
+		updates := map[string]interface{}{
+			"name":       title,
+			"start_date": startDate,
+			"end_date":   endDate,
+		}
+		if endDate.After(time.Now()) {
+			updates["is_archived"] = false
+		}
+		if err := tx.Model(&models.Term{}).Where("term_id = ?", localID).Updates(updates).Error; err != nil {
+			return err
+		}
+		report.Updated++
+		return nil
+	}
+
+	term := models.Term{
+		Name:      title,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	if err := tx.Create(&term).Error; err != nil {
+		return err
+	}
+	if err := saveOneRosterMapping(tx, models.OneRosterEntityTerm, sourcedID, term.TermID); err != nil {
+		return err
+	}
+
+	report.Created++
+	return nil
+}
+
+// importRole records the role of a user from roles.csv (OneRoster 1.2)
+func (s *OneRosterServiceImpl) importRole(imp *oneRosterImport, record utils.CSVRecord, report *models.OneRosterFileReport) error {
+	userSourcedID := record.Get("userSourcedId")
+	role := mapOneRosterRole(record.Get("role"))
+	if userSourcedID == "" || role == "" {
+		addOneRosterMessage(report, record, fmt.Sprintf("unsupported role %q", record.Get("role")))
+		report.Skipped++
+		return nil
+	}
+
+	// Primary roles win over secondary ones
+	if _, exists := imp.userRoles[userSourcedID]; !exists || strings.EqualFold(record.Get("roleType"), "primary") {
+		imp.userRoles[userSourcedID] = role
+	}
+	report.Updated++
+	return nil
+}
+
+// importUser creates or updates a user and their profile
+func (s *OneRosterServiceImpl) importUser(imp *oneRosterImport, record utils.CSVRecord, report *models.OneRosterFileReport) error {
+	sourcedID := record.Get("sourcedId")
+	tx := imp.tx
+
+	localID, mapped, err := lookupOneRosterMapping(tx, models.OneRosterEntityUser, sourcedID)
+	if err != nil {
+		return err
+	}
+
+	if isOneRosterDeleted(record) {
+		if !mapped {
+			report.Skipped++
+			return nil
+		}
+		if err := tx.Model(&models.User{}).Where("user_id = ?", localID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		report.Updated++
+		return nil
+	}
+
+	// OneRoster 1.1 has the role on the user row, 1.2 has it in roles.csv
+	role := mapOneRosterRole(record.Get("role"))
+	if role == "" {
+		role = imp.userRoles[sourcedID]
+	}
+	if role == "" {
+		addOneRosterMessage(report, record, "user has no student or teacher role")
+		report.Skipped++
+		return nil
+	}
+
+	email := strings.ToLower(record.Get("email"))
+	if email == "" && strings.Contains(record.Get("username"), "@") {
+		email = strings.ToLower(record.Get("username"))
+	}
+	firstName := record.Get("preferredGivenName", "givenName")
+	lastName := record.Get("preferredFamilyName", "familyName")
+	if email == "" || firstName == "" || lastName == "" {
+		addOneRosterMessage(report, record, "email, givenName and familyName are required")
+		report.Errors++
+		return nil
+	}
+	isActive := !strings.EqualFold(record.Get("enabledUser"), "false")
+
+	var user models.User
+	if mapped {
+		if err := tx.First(&user, localID).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Where("email = ?", email).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// Existing user: update their details and link the sourcedId
+	if user.UserID != 0 {
+		if user.UserRole != role {
+			addOneRosterMessage(report, record, fmt.Sprintf("existing account %s is a %s, not a %s", email, user.UserRole, role))
+			report.Errors++
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"first_name": firstName,
+			"last_name":  lastName,
+			"is_active":  isActive,
+		}
+		if user.Email != email {
+			var taken int64
+			if err := tx.Model(&models.User{}).Where("email = ? AND user_id <> ?", email, user.UserID).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				addOneRosterMessage(report, record, fmt.Sprintf("email %s is already used by another account", email))
+				report.Errors++
+				return nil
+			}
+			updates["email"] = email
+		}
+		if err := tx.Model(&models.User{}).Where("user_id = ?", user.UserID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := s.upsertProfile(tx, user.UserID, role, firstName, lastName, record.Get("grades")); err != nil {
+			return err
+		}
+		if !mapped {
+			if err := saveOneRosterMapping(tx, models.OneRosterEntityUser, sourcedID, user.UserID); err != nil {
+				return err
+			}
+		}
+		report.Updated++
+		return nil
+	}
+
+	user = models.User{
+		Email:          email,
+		PasswordHash:   imp.placeholderHash,
+		FirstName:      firstName,
+		LastName:       lastName,
+		UserRole:       role,
+		IsActive:       isActive,
+		DateRegistered: time.Now(),
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return err
+	}
+	if err := s.upsertProfile(tx, user.UserID, role, firstName, lastName, record.Get("grades")); err != nil {
+		return err
+	}
+	if err := saveOneRosterMapping(tx, models.OneRosterEntityUser, sourcedID, user.UserID); err != nil {
+		return err
+	}
+
+	report.Created++
+	return nil
+}
+
+// upsertProfile creates or updates the student or teacher profile of a user
+func (s *OneRosterServiceImpl) upsertProfile(tx *gorm.DB, userID int, role, firstName, lastName, grades string) error {
+	if role == "teacher" {
+		var profile models.TeacherProfile
+		err := tx.Where("user_id = ?", userID).First(&profile).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&models.TeacherProfile{
+				UserID:    userID,
+				FirstName: firstName,
+				LastName:  lastName,
+				HireDate:  time.Now(),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&profile).Updates(map[string]interface{}{
+			"first_name": firstName,
+			"last_name":  lastName,
+		}).Error
+	}
+
+	// OneRoster grades is a comma separated list, use the first one
+	gradeLevel := strings.TrimSpace(strings.Split(grades, ",")[0])
+
+	var profile models.StudentProfile
+	err := tx.Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Create(&models.StudentProfile{
+			UserID:         userID,
+			FirstName:      firstName,
+			LastName:       lastName,
+			GradeLevel:     gradeLevel,
+			EnrollmentDate: time.Now(),
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"first_name": firstName,
+		"last_name":  lastName,
+	}
+	if gradeLevel != "" {
+		updates["grade_level"] = gradeLevel
+	}
+	return tx.Model(&profile).Updates(updates).Error
+}
+
+// importClass creates or updates a class
+func (s *OneRosterServiceImpl) importClass(imp *oneRosterImport, record utils.CSVRecord, report *models.OneRosterFileReport) error {
+	sourcedID := record.Get("sourcedId")
+	tx := imp.tx
+
+	localID, mapped, err := lookupOneRosterMapping(tx, models.OneRosterEntityClass, sourcedID)
+	if err != nil {
+		return err
+	}
+
+	if isOneRosterDeleted(record) {
+		if !mapped {
+			report.Skipped++
+			return nil
+		}
+		if err := tx.Model(&models.Class{}).Where("class_id = ?", localID).Update("is_archived", true).Error; err != nil {
+			return err
+		}
+		report.Updated++
+		return nil
+	}
+
+	title := record.Get("title")
+	if title == "" {
+		addOneRosterMessage(report, record, "title is required")
+		report.Errors++
+		return nil
+	}
+	subject := strings.TrimSpace(strings.Split(record.Get("subjects"), ",")[0])
+	termID, err := oneRosterClassTerm(tx, record, report)
+	if err != nil {
+		return err
+	}
+
+	if mapped {
+		updates := map[string]interface{}{
+			"class_name": title,
+			"subject":    subject,
+		}
+		if termID != nil {
+			updates["term_id"] = *termID
+		}
+		if err := tx.Model(&models.Class{}).Where("class_id = ?", localID).Updates(updates).Error; err != nil {
+			return err
+		}
+		report.Updated++
+		return nil
+	}
+
+	class := models.Class{
+		ClassName:   title,
+		ClassCode:   generateUniqueClassCode(tx),
+		Description: record.Get("classCode"),
+		Subject:     subject,
+		CreatedDate: time.Now(),
+		CreatorID:   imp.importerID,
+		TermID:      termID,
+	}
+	if err := tx.Create(&class).Error; err != nil {
+		return err
+	}
+	if err := saveOneRosterMapping(tx, models.OneRosterEntityClass, sourcedID, class.ClassID); err != nil {
+		return err
+	}
+
+	report.Created++
+	return nil
+}
+
+// oneRosterClassTerm returns the term of a class row: the first of its
+// academic sessions that was imported as a term, or nil when there is none
+func oneRosterClassTerm(tx *gorm.DB, record utils.CSVRecord, report *models.OneRosterFileReport) (*int, error) {
+	for _, sessionID := range strings.Split(record.Get("termSourcedIds"), ",") {
+		sessionID = strings.TrimSpace(sessionID)
+		if sessionID == "" {
+			continue
+		}
+		termID, mapped, err := lookupOneRosterMapping(tx, models.OneRosterEntityTerm, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if mapped {
+			return &termID, nil
+		}
+		addOneRosterMessage(report, record, fmt.Sprintf("academic session %s was not imported", sessionID))
+	}
+	return nil, nil
+}
+
+// importEnrollment creates or updates a class enrollment or class teacher
+func (s *OneRosterServiceImpl) importEnrollment(imp *oneRosterImport, record utils.CSVRecord, report *models.OneRosterFileReport) error {
+	tx := imp.tx
+
+	role := mapOneRosterRole(record.Get("role"))
+	if role == "" {
+		addOneRosterMessage(report, record, fmt.Sprintf("unsupported role %q", record.Get("role")))
+		report.Skipped++
+		return nil
+	}
+
+	classID, classMapped, err := lookupOneRosterMapping(tx, models.OneRosterEntityClass, record.Get("classSourcedId"))
+	if err != nil {
+		return err
+	}
+	userID, userMapped, err := lookupOneRosterMapping(tx, models.OneRosterEntityUser, record.Get("userSourcedId"))
+	if err != nil {
+		return err
+	}
+	if !classMapped || !userMapped {
+		addOneRosterMessage(report, record, "unknown classSourcedId or userSourcedId")
+		report.Errors++
+		return nil
+	}
+
+	deleted := isOneRosterDeleted(record)
+
+	if role == "teacher" {
+		var classTeacher models.ClassTeacher
+		err := tx.Where("class_id = ? AND user_id = ?", classID, userID).First(&classTeacher).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		exists := err == nil
+		isOwner := strings.EqualFold(record.Get("primary"), "true")
+
+		switch {
+		case deleted && !exists:
+			report.Skipped++
+		case deleted:
+			if err := tx.Delete(&classTeacher).Error; err != nil {
+				return err
+			}
+			report.Updated++
+		case exists:
+			if err := tx.Model(&classTeacher).Update("is_owner", isOwner).Error; err != nil {
+				return err
+			}
+			report.Updated++
+		default:
+			if err := tx.Create(&models.ClassTeacher{
+				UserID:    userID,
+				ClassID:   classID,
+				IsOwner:   isOwner,
+				AddedDate: time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+			report.Created++
+		}
+		return nil
+	}
+
+	var enrollment models.ClassEnrollment
+	err = tx.Where("class_id = ? AND user_id = ?", classID, userID).
+		Order("enrollment_date DESC").
+		First(&enrollment).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	exists := err == nil
+
+	status := models.EnrollmentStatusActive
+	if deleted {
+		status = models.EnrollmentStatusRemoved
+	}
+
+	switch {
+	case deleted && !exists:
+		report.Skipped++
+		return nil
+	case exists:
+		if err := tx.Model(&models.ClassEnrollment{}).Where("enrollment_id = ?", enrollment.EnrollmentID).
+			Updates(map[string]interface{}{
+				"status":    status,
+				"is_active": !deleted,
+			}).Error; err != nil {
+			return err
+		}
+		report.Updated++
+	default:
+		enrollment = models.ClassEnrollment{
+			UserID:         userID,
+			ClassID:        classID,
+			EnrollmentDate: time.Now(),
+			IsActive:       true,
+			Status:         status,
+		}
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		report.Created++
+	}
+
+	return saveOneRosterMapping(tx, models.OneRosterEntityEnrollment, record.Get("sourcedId"), enrollment.EnrollmentID)
+}
+
+// collectEnrollmentRoles infers user roles from enrollments.csv so bundles
+// without roles.csv or a users.csv role column can still be imported
+func (s *OneRosterServiceImpl) collectEnrollmentRoles(imp *oneRosterImport) error {
+	records, ok, err := readBundleFile(imp.files, "enrollments.csv")
+	if err != nil || !ok {
+		return err
+	}
+
+	for _, record := range records {
+		userSourcedID := record.Get("userSourcedId")
+		role := mapOneRosterRole(record.Get("role"))
+		if userSourcedID == "" || role == "" {
+			continue
+		}
+		// A teacher enrollment anywhere makes the user a teacher
+		if imp.userRoles[userSourcedID] != "teacher" {
+			imp.userRoles[userSourcedID] = role
+		}
+	}
+
+	return nil
+}
+
+// ExportResults builds a OneRoster 1.2 CSV bundle with the line items
+// (assignments) and results (grades) of the given classes. When no class
+// IDs are given all classes imported from OneRoster are exported.
+func (s *OneRosterServiceImpl) ExportResults(classIDs []int) ([]byte, error) {
+	var mappings []models.OneRosterMapping
+	if err := s.db.Where("entity_type IN ?", []string{
+		models.OneRosterEntityClass, models.OneRosterEntityUser, models.OneRosterEntityLineItem,
+	}).Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load OneRoster mappings: %w", err)
+	}
+
+	sourcedIDs := map[string]map[int]string{
+		models.OneRosterEntityClass:    {},
+		models.OneRosterEntityUser:     {},
+		models.OneRosterEntityLineItem: {},
+	}
+	for _, m := range mappings {
+		sourcedIDs[m.EntityType][m.LocalID] = m.SourcedID
+	}
+
+	if len(classIDs) == 0 {
+		for classID := range sourcedIDs[models.OneRosterEntityClass] {
+			classIDs = append(classIDs, classID)
+		}
+	}
+
+	sourcedIDFor := func(entityType string, localID int) string {
+		if id, ok := sourcedIDs[entityType][localID]; ok {
+			return id
+		}
+		return fmt.Sprintf("classconnect-%s-%d", strings.ToLower(entityType), localID)
+	}
+
+	var assignments []models.Assignment
+	if len(classIDs) > 0 {
+		if err := s.db.Where("class_id IN ?", classIDs).Order("class_id, assignment_id").Find(&assignments).Error; err != nil {
+			return nil, fmt.Errorf("failed to get assignments: %w", err)
+		}
+	}
+
+	assignmentIDs := make([]int, 0, len(assignments))
+	for _, a := range assignments {
+		assignmentIDs = append(assignmentIDs, a.AssignmentID)
+	}
+
+	var submissions []models.Submission
+	if len(assignmentIDs) > 0 {
+		if err := s.db.Where("assignment_id IN ? AND grade IS NOT NULL AND grade_released = ?", assignmentIDs, true).
+			Order("assignment_id, user_id").
+			Find(&submissions).Error; err != nil {
+			return nil, fmt.Errorf("failed to get submissions: %w", err)
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	const categoryID = "classconnect-category-assignments"
+
+	manifest := [][]string{
+		{"propertyName", "value"},
+		{"manifest.version", "1.0"},
+		{"oneroster.version", "1.2"},
+		{"file.academicSessions", "absent"},
+		{"file.categories", "bulk"},
+		{"file.classes", "absent"},
+		{"file.classResources", "absent"},
+		{"file.courses", "absent"},
+		{"file.courseResources", "absent"},
+		{"file.demographics", "absent"},
+		{"file.enrollments", "absent"},
+		{"file.lineItemLearningObjectiveIds", "absent"},
+		{"file.lineItems", "bulk"},
+		{"file.lineItemScoreScales", "absent"},
+		{"file.orgs", "absent"},
+		{"file.resources", "absent"},
+		{"file.resultLearningObjectiveIds", "absent"},
+		{"file.results", "bulk"},
+		{"file.resultScoreScales", "absent"},
+		{"file.roles", "absent"},
+		{"file.scoreScales", "absent"},
+		{"file.userProfiles", "absent"},
+		{"file.userResources", "absent"},
+		{"file.users", "absent"},
+		{"source.systemName", "ClassConnect"},
+		{"source.systemCode", "classconnect"},
+	}
+
+	categories := [][]string{
+		{"sourcedId", "status", "dateLastModified", "title"},
+		{categoryID, "active", now, "Assignments"},
+	}
+
+	lineItems := [][]string{{
+		"sourcedId", "status", "dateLastModified", "title", "description", "assignDate", "dueDate",
+		"classSourcedId", "categorySourcedId", "gradingPeriodSourcedId", "resultValueMin", "resultValueMax",
+	}}
+	for _, a := range assignments {
+		dueDate := ""
+		if !a.DueDate.IsZero() {
+			dueDate = a.DueDate.UTC().Format(time.RFC3339)
+		}
+		lineItems = append(lineItems, []string{
+			sourcedIDFor(models.OneRosterEntityLineItem, a.AssignmentID), "active", now,
+			a.Title, a.Description, a.CreatedAt.UTC().Format(time.RFC3339), dueDate,
+			sourcedIDFor(models.OneRosterEntityClass, a.ClassID), categoryID, "",
+			"0", strconv.Itoa(a.PointsPossible),
+		})
+	}
+
+	results := [][]string{{
+		"sourcedId", "status", "dateLastModified", "lineItemSourcedId", "userSourcedId",
+		"scoreStatus", "score", "scoreDate", "comment",
+	}}
+	for _, sub := range submissions {
+		scoreDate := sub.SubmissionDate
+		if sub.GradedDate != nil {
+			scoreDate = *sub.GradedDate
+		}
+		results = append(results, []string{
+			fmt.Sprintf("classconnect-result-%d", sub.SubmissionID), "active", now,
+			sourcedIDFor(models.OneRosterEntityLineItem, sub.AssignmentID),
+			sourcedIDFor(models.OneRosterEntityUser, sub.StudentID),
+			"fully graded", strconv.Itoa(*sub.Grade), scoreDate.UTC().Format("2006-01-02"), sub.Feedback,
+		})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name string
+		rows [][]string
+	}{
+		{"manifest.csv", manifest},
+		{"categories.csv", categories},
+		{"lineItems.csv", lineItems},
+		{"results.csv", results},
+	} {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(file.rows); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("OneRoster export: %d classes, %d line items, %d results", len(classIDs), len(assignments), len(submissions))
+
+	return buf.Bytes(), nil
+}
+
+// readBundleFile reads a CSV file from the bundle. The boolean is false when the file is absent.
+func readBundleFile(files map[string]*zip.File, name string) ([]utils.CSVRecord, bool, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid OneRoster bundle: %s: %v", name, err)
+	}
+	defer rc.Close()
+
+	// The size in the zip header can be forged, so the limit is applied while reading
+	data, err := io.ReadAll(io.LimitReader(rc, maxOneRosterFileSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid OneRoster bundle: %s: %v", name, err)
+	}
+	if len(data) > maxOneRosterFileSize {
+		return nil, false, fmt.Errorf("invalid OneRoster bundle: %s is larger than 50 MB", name)
+	}
+
+	records, err := utils.ReadCSVRecords(bytes.NewReader(data), maxOneRosterRows)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid OneRoster bundle: %s: %v", name, err)
+	}
+
+	return records, true, nil
+}
+
+// lookupOneRosterMapping finds the local ID for a sourcedId
+func lookupOneRosterMapping(tx *gorm.DB, entityType, sourcedID string) (int, bool, error) {
+	if sourcedID == "" {
+		return 0, false, nil
+	}
+
+	var mapping models.OneRosterMapping
+	err := tx.Where("entity_type = ? AND sourced_id = ?", entityType, sourcedID).First(&mapping).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return mapping.LocalID, true, nil
+}
+
+// saveOneRosterMapping links a sourcedId to a local ID, replacing any previous link
+func saveOneRosterMapping(tx *gorm.DB, entityType, sourcedID string, localID int) error {
+	var mapping models.OneRosterMapping
+	err := tx.Where("entity_type = ? AND sourced_id = ?", entityType, sourcedID).First(&mapping).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Create(&models.OneRosterMapping{
+			EntityType: entityType,
+			SourcedID:  sourcedID,
+			LocalID:    localID,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	if mapping.LocalID == localID {
+		return nil
+	}
+	return tx.Model(&mapping).Update("local_id", localID).Error
+}
+
+// mapOneRosterRole maps a OneRoster role to a ClassConnect role
+func mapOneRosterRole(role string) string {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "student":
+		return "student"
+	case "teacher", "aide", "proctor":
+		return "teacher"
+	default:
+		return ""
+	}
+}
+
+// isOneRosterDeleted reports whether a row is marked for deletion
+func isOneRosterDeleted(record utils.CSVRecord) bool {
+	return strings.EqualFold(record.Get("status"), "tobedeleted")
+}
+
+// addOneRosterMessage records a row message on a file report
+func addOneRosterMessage(report *models.OneRosterFileReport, record utils.CSVRecord, message string) {
+	if len(report.Messages) < maxOneRosterMessages {
+		report.Messages = append(report.Messages, fmt.Sprintf("line %d (%s): %s", record.Line, record.Get("sourcedId"), message))
+	}
+}
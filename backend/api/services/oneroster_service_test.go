@@ -0,0 +1,71 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadBundleFile(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"users.csv":   "sourcedId,email\nu1,a@example.com\n\nu2,b@example.com\n",
+		"classes.csv": "sourcedId,title\n" + strings.Repeat("c,Class\n", maxOneRosterRows+1),
+		"orgs.csv":    "sourcedId,name\n" + strings.Repeat(" ", maxOneRosterFileSize),
+		"roles.csv":   "",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		wantOK   bool
+		wantRows int
+		wantErr  string
+	}{
+		{"file", "users.csv", true, 2, ""},
+		{"missing file", "enrollments.csv", false, 0, ""},
+		{"empty file", "roles.csv", false, 0, "invalid OneRoster bundle: roles.csv: CSV file is empty"},
+		{"too many rows", "classes.csv", false, 0, "invalid OneRoster bundle: classes.csv: CSV file has too many rows"},
+		{"too large once unzipped", "orgs.csv", false, 0, "invalid OneRoster bundle: orgs.csv is larger than 50 MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, ok, err := readBundleFile(files, tt.file)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantOK || len(records) != tt.wantRows {
+				t.Errorf("readBundleFile() = %d records, %v, want %d records, %v", len(records), ok, tt.wantRows, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// PeerReviewService handles the peer review of assignment submissions
+type PeerReviewService interface {
+	Service
+	GetSettings(classID, assignmentID int) (*models.PeerReviewSettings, error)
+	SaveSettings(classID, assignmentID int, settings models.PeerReviewSettings) (*models.PeerReviewSettings, error)
+	AssignReviewers(classID, assignmentID int) (int, error)
+	GetReviews(classID, assignmentID int) ([]models.PeerReview, error)
+	GetReviewsToWrite(classID, assignmentID, reviewerID int) ([]models.PeerReview, error)
+	GetReceivedReviews(classID, assignmentID, studentID int) ([]models.PeerReview, error)
+	SubmitReview(classID, assignmentID, reviewID, reviewerID int, review models.PeerReview) (*models.PeerReview, error)
+	RateReview(classID, assignmentID, reviewID, qualityScore int) (*models.PeerReview, error)
+}
+
+// PeerReviewServiceImpl implements PeerReviewService
+type PeerReviewServiceImpl struct {
+	*BaseService
+}
+
+// NewPeerReviewService creates a new PeerReviewService
+func NewPeerReviewService(db *gorm.DB) PeerReviewService {
+	return &PeerReviewServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetSettings returns the peer review settings of an assignment
+func (s *PeerReviewServiceImpl) GetSettings(classID, assignmentID int) (*models.PeerReviewSettings, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := assignment.PeerReviewSettings()
+	return &settings, nil
+}
+
+// SaveSettings turns peer review on or off and sets its prompts and the
+// weight of review quality. The number of reviews cannot change once
+// reviewers have been assigned.
+func (s *PeerReviewServiceImpl) SaveSettings(classID, assignmentID int, settings models.PeerReviewSettings) (*models.PeerReviewSettings, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.AssignmentType == models.AssignmentTypeQuiz {
+		return nil, errors.New("quizzes cannot be peer reviewed")
+	}
+	if settings.ReviewCount < 0 {
+		return nil, errors.New("reviewCount cannot be negative")
+	}
+	if settings.QualityWeight < 0 || settings.QualityWeight > 100 {
+		return nil, errors.New("qualityWeight must be between 0 and 100")
+	}
+	if assignment.PeerReviewAssignedAt != nil && settings.ReviewCount != assignment.PeerReviewCount {
+		return nil, errors.New("reviewers have already been assigned, the number of reviews cannot change")
+	}
+
+	prompts := make([]string, 0, len(settings.Prompts))
+	for _, prompt := range settings.Prompts {
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+
+	assignment.PeerReviewCount = settings.ReviewCount
+	assignment.PeerReviewPrompts = prompts
+	assignment.PeerReviewWeight = settings.QualityWeight
+	if err := s.db.Model(assignment).Select("peer_review_count", "peer_review_prompts", "peer_review_weight").Updates(assignment).Error; err != nil {
+		return nil, fmt.Errorf("failed to save peer review settings: %w", err)
+	}
+
+	saved := assignment.PeerReviewSettings()
+	return &saved, nil
+}
+
+// AssignReviewers anonymously assigns every submission to classmates for
+// review. Each student (or group) that submitted reviews the next
+// ReviewCount submissions of a shuffled list, so nobody reviews their own
+// work and every submission gets the same number of reviews. It returns the
+// number of reviews assigned.
+func (s *PeerReviewServiceImpl) AssignReviewers(classID, assignmentID int) (int, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return 0, err
+	}
+
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return 0, err
+	}
+
+	return assignPeerReviewers(s.db, assignment)
+}
+
+// GetReviews lists all reviews of an assignment with their reviewers (teacher)
+func (s *PeerReviewServiceImpl) GetReviews(classID, assignmentID int) ([]models.PeerReview, error) {
+	if _, err := s.getAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	var reviews []models.PeerReview
+	if err := s.db.Where("assignment_id = ?", assignmentID).Order("submission_id ASC, review_id ASC").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to get peer reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// GetReviewsToWrite lists the reviews a student has to write, with the
+// submissions to review but without their authors. Reviewers are assigned on
+// first access once the due date has passed.
+func (s *PeerReviewServiceImpl) GetReviewsToWrite(classID, assignmentID, reviewerID int) ([]models.PeerReview, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.PeerReviewCount == 0 {
+		return nil, errors.New("assignment has no peer review")
+	}
+
+	if assignment.PeerReviewAssignedAt == nil && !assignment.DueDate.IsZero() {
+		startsAt, err := peerReviewStartsAt(s.db, assignment)
+		if err != nil {
+			return nil, err
+		}
+		if time.Now().After(startsAt) {
+			if _, err := assignPeerReviewers(s.db, assignment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var reviews []models.PeerReview
+	if err := s.db.Where("assignment_id = ? AND reviewer_id = ?", assignmentID, reviewerID).Order("review_id ASC").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to get peer reviews: %w", err)
+	}
+
+	submissionIDs := make([]int, 0, len(reviews))
+	for _, review := range reviews {
+		submissionIDs = append(submissionIDs, review.SubmissionID)
+	}
+	var submissions []models.Submission
+	if len(submissionIDs) > 0 {
+		if err := s.db.Where("submission_id IN ?", submissionIDs).Find(&submissions).Error; err != nil {
+			return nil, fmt.Errorf("failed to get submissions: %w", err)
+		}
+	}
+	bySubmission := make(map[int]models.Submission, len(submissions))
+	for _, submission := range submissions {
+		bySubmission[submission.SubmissionID] = submission
+	}
+
+	for i := range reviews {
+		submission := bySubmission[reviews[i].SubmissionID]
+		reviews[i] = reviews[i].Anonymous()
+		reviews[i].Content = submission.Content
+		reviews[i].FileURL = submission.FileURL
+	}
+
+	return reviews, nil
+}
+
+// GetReceivedReviews lists the completed reviews of a student's submission,
+// without their reviewers
+func (s *PeerReviewServiceImpl) GetReceivedReviews(classID, assignmentID, studentID int) ([]models.PeerReview, error) {
+	if _, err := s.getAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	var submission models.Submission
+	if err := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&submission).Error; err != nil {
+		return nil, fmt.Errorf("submission not found: %w", err)
+	}
+
+	// Group work is reviewed once for the whole group
+	query := s.db.Where("assignment_id = ? AND status = ?", assignmentID, models.PeerReviewStatusCompleted)
+	if submission.GroupID != nil {
+		query = query.Where("submission_id IN (SELECT submission_id FROM submissions WHERE assignment_id = ? AND group_id = ?)", assignmentID, *submission.GroupID)
+	} else {
+		query = query.Where("submission_id = ?", submission.SubmissionID)
+	}
+
+	var reviews []models.PeerReview
+	if err := query.Order("completed_at ASC").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to get peer reviews: %w", err)
+	}
+
+	for i := range reviews {
+		reviews[i] = reviews[i].Anonymous()
+	}
+
+	return reviews, nil
+}
+
+// SubmitReview stores a reviewer's review. Assignments with a rubric are
+// reviewed by scoring every criterion, otherwise by answering every prompt;
+// without prompts a comment is required.
+func (s *PeerReviewServiceImpl) SubmitReview(classID, assignmentID, reviewID, reviewerID int, review models.PeerReview) (*models.PeerReview, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.PeerReview
+	if err := s.db.Where("review_id = ? AND assignment_id = ? AND reviewer_id = ?", reviewID, assignmentID, reviewerID).First(&existing).Error; err != nil {
+		return nil, fmt.Errorf("peer review not found: %w", err)
+	}
+
+	existing.Comment = strings.TrimSpace(review.Comment)
+	existing.Answers = nil
+	existing.RubricScores = nil
+	existing.Points = nil
+
+	switch {
+	case assignment.RubricID != nil:
+		rubric, err := loadRubric(s.db, *assignment.RubricID)
+		if err != nil {
+			return nil, err
+		}
+
+		scores := make([]models.RubricScore, 0, len(review.RubricScores))
+		for _, score := range review.RubricScores {
+			scores = append(scores, models.RubricScore{CriterionID: score.CriterionID, LevelID: score.LevelID, Comment: score.Comment})
+		}
+		points, err := scoreWithRubric(rubric, assignment.PointsPossible, scores)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, score := range scores {
+			existing.RubricScores = append(existing.RubricScores, models.PeerReviewScore{
+				CriterionID: score.CriterionID,
+				LevelID:     score.LevelID,
+				Points:      score.Points,
+				Comment:     strings.TrimSpace(score.Comment),
+			})
+		}
+		existing.Points = &points
+
+	case len(assignment.PeerReviewPrompts) > 0:
+		if len(review.Answers) != len(assignment.PeerReviewPrompts) {
+			return nil, fmt.Errorf("expected answers to %d prompt(s), got %d", len(assignment.PeerReviewPrompts), len(review.Answers))
+		}
+		for i, prompt := range assignment.PeerReviewPrompts {
+			answer := strings.TrimSpace(review.Answers[i].Answer)
+			if answer == "" {
+				return nil, fmt.Errorf("prompt %d has not been answered", i+1)
+			}
+			existing.Answers = append(existing.Answers, models.PeerReviewAnswer{Prompt: prompt, Answer: answer})
+		}
+
+	default:
+		if existing.Comment == "" {
+			return nil, errors.New("review comment is required")
+		}
+	}
+
+	now := time.Now()
+	existing.Status = models.PeerReviewStatusCompleted
+	existing.CompletedAt = &now
+	if err := s.db.Save(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to save peer review: %w", err)
+	}
+
+	reviewed := existing.Anonymous()
+	return &reviewed, nil
+}
+
+// RateReview records the teacher's rating of a review's quality (0-100)
+func (s *PeerReviewServiceImpl) RateReview(classID, assignmentID, reviewID, qualityScore int) (*models.PeerReview, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+	if qualityScore < 0 || qualityScore > 100 {
+		return nil, errors.New("qualityScore must be between 0 and 100")
+	}
+
+	var review models.PeerReview
+	if err := s.db.Where("review_id = ? AND assignment_id = ?", reviewID, assignmentID).First(&review).Error; err != nil {
+		return nil, fmt.Errorf("peer review not found: %w", err)
+	}
+	if review.Status != models.PeerReviewStatusCompleted {
+		return nil, errors.New("only completed reviews can be rated")
+	}
+
+	review.QualityScore = &qualityScore
+	if err := s.db.Model(&review).Update("quality_score", qualityScore).Error; err != nil {
+		return nil, fmt.Errorf("failed to rate peer review: %w", err)
+	}
+
+	return &review, nil
+}
+
+// getAssignment retrieves an assignment of a class
+func (s *PeerReviewServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// peerReviewStartsAt returns when reviewers can be assigned: the latest due
+// date of the assignment's students, including extensions and accommodations,
+// so no work is handed out for review while a student may still submit
+func peerReviewStartsAt(db *gorm.DB, assignment *models.Assignment) (time.Time, error) {
+	if assignment.DueDate.IsZero() {
+		return assignment.DueDate, nil
+	}
+
+	var studentIDs []int
+	if err := db.Model(&models.ClassEnrollment{}).Where("class_id = ? AND is_active = ?", assignment.ClassID, true).
+		Pluck("user_id", &studentIDs).Error; err != nil {
+		return time.Time{}, fmt.Errorf("failed to get students: %w", err)
+	}
+	var submitterIDs []int
+	if err := db.Model(&models.Submission{}).Where("assignment_id = ?", assignment.AssignmentID).
+		Pluck("user_id", &submitterIDs).Error; err != nil {
+		return time.Time{}, fmt.Errorf("failed to get students: %w", err)
+	}
+
+	dueDates, err := effectiveDueDates(db, assignment, append(studentIDs, submitterIDs...))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get due dates: %w", err)
+	}
+	startsAt := assignment.DueDate
+	for _, dueDate := range dueDates {
+		if dueDate.After(startsAt) {
+			startsAt = dueDate
+		}
+	}
+	return startsAt, nil
+}
+
+// peerReviewWork is a piece of work to review: one submission, written by one
+// student or by the members of a group
+type peerReviewWork struct {
+	submissionID int
+	authorIDs    []int
+}
+
+// assignPeerReviewers assigns the reviewers of an assignment's submissions
+func assignPeerReviewers(db *gorm.DB, assignment *models.Assignment) (int, error) {
+	if assignment.PeerReviewCount == 0 {
+		return 0, errors.New("assignment has no peer review")
+	}
+	startsAt, err := peerReviewStartsAt(db, assignment)
+	if err != nil {
+		return 0, err
+	}
+	if time.Now().Before(startsAt) {
+		return 0, errors.New("reviewers can only be assigned after every student's due date, including extensions")
+	}
+
+	var submissions []models.Submission
+	if err := db.Where("assignment_id = ?", assignment.AssignmentID).Order("submission_id ASC").Find(&submissions).Error; err != nil {
+		return 0, fmt.Errorf("failed to get submissions: %w", err)
+	}
+
+	// Group members share one piece of work
+	var works []*peerReviewWork
+	groupWorks := make(map[int]*peerReviewWork)
+	for _, submission := range submissions {
+		if submission.GroupID == nil {
+			works = append(works, &peerReviewWork{submissionID: submission.SubmissionID, authorIDs: []int{submission.StudentID}})
+			continue
+		}
+		work, ok := groupWorks[*submission.GroupID]
+		if !ok {
+			work = &peerReviewWork{submissionID: submission.SubmissionID}
+			groupWorks[*submission.GroupID] = work
+			works = append(works, work)
+		}
+		work.authorIDs = append(work.authorIDs, submission.StudentID)
+	}
+	if len(works) < 2 {
+		return 0, errors.New("at least two submissions are needed for peer review")
+	}
+
+	reviewsEach := min(assignment.PeerReviewCount, len(works)-1)
+	rand.Shuffle(len(works), func(i, j int) { works[i], works[j] = works[j], works[i] })
+
+	now := time.Now()
+	created := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Lock in the assignment so that concurrent requests assign reviewers once
+		result := tx.Model(&models.Assignment{}).
+			Where("assignment_id = ? AND peer_review_assigned_at IS NULL", assignment.AssignmentID).
+			Update("peer_review_assigned_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("reviewers have already been assigned")
+		}
+
+		for k, work := range works {
+			for offset := 1; offset <= reviewsEach; offset++ {
+				reviewed := works[(k+offset)%len(works)]
+				for _, reviewerID := range work.authorIDs {
+					review := models.PeerReview{
+						AssignmentID: assignment.AssignmentID,
+						SubmissionID: reviewed.submissionID,
+						ReviewerID:   reviewerID,
+						Status:       models.PeerReviewStatusAssigned,
+						AssignedAt:   now,
+					}
+					if err := tx.Create(&review).Error; err != nil {
+						return err
+					}
+					created++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "already been assigned") {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+
+	assignment.PeerReviewAssignedAt = &now
+	return created, nil
+}
+
+// withPeerReviewSummaries adds each student's peer review progress to the
+// submission responses of an assignment. When review quality counts toward
+// the grade, the adjusted grade is added as well: reviews that were not
+// written count as 0 and written reviews the teacher has not rated as 100.
+func withPeerReviewSummaries(db *gorm.DB, assignment *models.Assignment, responses []models.SubmissionResponse) error {
+	if assignment.PeerReviewCount == 0 || assignment.PeerReviewAssignedAt == nil {
+		return nil
+	}
+
+	var reviews []models.PeerReview
+	if err := db.Where("assignment_id = ?", assignment.AssignmentID).Find(&reviews).Error; err != nil {
+		return err
+	}
+
+	// Group work is reviewed through the group's first submission, as in assignPeerReviewers
+	var submissions []models.Submission
+	if err := db.Where("assignment_id = ?", assignment.AssignmentID).Order("submission_id ASC").Find(&submissions).Error; err != nil {
+		return err
+	}
+	workOf := make(map[int]int, len(submissions)) // Student ID to reviewed submission ID
+	groupWork := make(map[int]int)
+	for _, submission := range submissions {
+		workOf[submission.StudentID] = submission.SubmissionID
+		if submission.GroupID == nil {
+			continue
+		}
+		if id, ok := groupWork[*submission.GroupID]; ok {
+			workOf[submission.StudentID] = id
+		} else {
+			groupWork[*submission.GroupID] = submission.SubmissionID
+		}
+	}
+
+	summaries := make(map[int]*models.PeerReviewSummary)
+	quality := make(map[int]float64)
+	rated := make(map[int]int) // Rated and unwritten reviews per reviewer
+	received := make(map[int]int)
+	for _, review := range reviews {
+		summary, ok := summaries[review.ReviewerID]
+		if !ok {
+			summary = &models.PeerReviewSummary{}
+			summaries[review.ReviewerID] = summary
+		}
+		summary.Assigned++
+		if review.Status != models.PeerReviewStatusCompleted {
+			// Unwritten reviews count as 0
+			rated[review.ReviewerID]++
+			continue
+		}
+		summary.Completed++
+		received[review.SubmissionID]++
+		if review.QualityScore != nil {
+			quality[review.ReviewerID] += float64(*review.QualityScore)
+			rated[review.ReviewerID]++
+		} else {
+			summary.Unrated++
+		}
+	}
+
+	for i := range responses {
+		studentID := responses[i].StudentID
+		summary, ok := summaries[studentID]
+		if !ok {
+			summary = &models.PeerReviewSummary{}
+		}
+		summary.Received = received[workOf[studentID]]
+		if rated[studentID] > 0 {
+			average := quality[studentID] / float64(rated[studentID])
+			summary.AverageQuality = &average
+		}
+		responses[i].PeerReviews = summary
+
+		// The adjusted grade is pending until the teacher has rated every written review
+		if assignment.PeerReviewWeight > 0 && responses[i].Grade != nil && summary.AverageQuality != nil && summary.Unrated == 0 {
+			weight := float64(assignment.PeerReviewWeight) / 100
+			reviewPoints := *summary.AverageQuality / 100 * float64(assignment.PointsPossible)
+			adjusted := int(math.Round(float64(*responses[i].Grade)*(1-weight) + reviewPoints*weight))
+			responses[i].AdjustedGrade = &adjusted
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,460 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ProgressReportService builds students' progress reports for a class or a term
+type ProgressReportService interface {
+	Service
+	GetClassReport(classID, studentID int) (*models.ProgressReport, error)
+	GetTermReport(termID, studentID int, teacherID *int) (*models.ProgressReport, error)
+	ClassReportsZip(classID int) ([]byte, error)
+}
+
+// ProgressReportServiceImpl implements ProgressReportService
+type ProgressReportServiceImpl struct {
+	*BaseService
+}
+
+// NewProgressReportService creates a new ProgressReportService
+func NewProgressReportService(db *gorm.DB) ProgressReportService {
+	return &ProgressReportServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// reportStudent is an enrolled student a report is built for
+type reportStudent struct {
+	UserID      int
+	SectionID   *int
+	StudentName string
+}
+
+// GetClassReport returns a student's progress report for one class
+func (s *ProgressReportServiceImpl) GetClassReport(classID, studentID int) (*models.ProgressReport, error) {
+	class, err := s.getClass(classID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := reportStudents(s.db, classID, &studentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(students) == 0 {
+		return nil, fmt.Errorf("student not found in class: %w", gorm.ErrRecordNotFound)
+	}
+
+	data, err := loadClassReportData(s.db, class)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := data.progress(s.db, students[0])
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ProgressReport{
+		StudentID:   studentID,
+		StudentName: students[0].StudentName,
+		Title:       class.ClassName,
+		GeneratedAt: time.Now(),
+		Classes:     []models.ClassProgress{*progress},
+	}
+	if class.TermID != nil {
+		var term models.Term
+		if err := s.db.First(&term, *class.TermID).Error; err == nil {
+			report.TermName = term.Name
+		}
+	}
+	return report, nil
+}
+
+// GetTermReport returns a student's progress report for their classes in a
+// term. With a teacherID only the classes that teacher teaches are included;
+// admins pass nil to include every class.
+func (s *ProgressReportServiceImpl) GetTermReport(termID, studentID int, teacherID *int) (*models.ProgressReport, error) {
+	var term models.Term
+	if err := s.db.First(&term, termID).Error; err != nil {
+		return nil, fmt.Errorf("term not found: %w", err)
+	}
+
+	var user models.User
+	if err := s.db.Where("user_id = ? AND user_role = ?", studentID, "student").First(&user).Error; err != nil {
+		return nil, fmt.Errorf("student not found: %w", err)
+	}
+
+	query := s.db.Joins("JOIN class_enrollments ce ON ce.class_id = classes.class_id").
+		Where("classes.term_id = ? AND ce.user_id = ? AND ce.is_active = ? AND ce.status = ?",
+			termID, studentID, true, models.EnrollmentStatusActive)
+	if teacherID != nil {
+		query = query.Where("classes.class_id IN (?)",
+			s.db.Model(&models.ClassTeacher{}).Select("class_id").Where("user_id = ?", *teacherID))
+	}
+	var classes []models.Class
+	if err := query.Order("classes.class_name ASC").Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get classes: %w", err)
+	}
+	if len(classes) == 0 {
+		if teacherID != nil {
+			return nil, fmt.Errorf("student is not enrolled in any of your classes in this term")
+		}
+		return nil, fmt.Errorf("student is not enrolled in any class in this term")
+	}
+
+	report := &models.ProgressReport{
+		StudentID:   studentID,
+		StudentName: studentDisplayName(s.db, studentID),
+		Title:       term.Name,
+		TermName:    term.Name,
+		GeneratedAt: time.Now(),
+	}
+	for i := range classes {
+		students, err := reportStudents(s.db, classes[i].ClassID, &studentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(students) == 0 {
+			continue
+		}
+
+		data, err := loadClassReportData(s.db, &classes[i])
+		if err != nil {
+			return nil, err
+		}
+		progress, err := data.progress(s.db, students[0])
+		if err != nil {
+			return nil, err
+		}
+		report.Classes = append(report.Classes, *progress)
+	}
+
+	return report, nil
+}
+
+// ClassReportsZip returns a zip with the class progress report PDF of every
+// student enrolled in a class
+func (s *ProgressReportServiceImpl) ClassReportsZip(classID int) ([]byte, error) {
+	class, err := s.getClass(classID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := reportStudents(s.db, classID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(students) == 0 {
+		return nil, fmt.Errorf("class has no enrolled students")
+	}
+
+	data, err := loadClassReportData(s.db, class)
+	if err != nil {
+		return nil, err
+	}
+
+	var termName string
+	if class.TermID != nil {
+		var term models.Term
+		if err := s.db.First(&term, *class.TermID).Error; err == nil {
+			termName = term.Name
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+	for _, student := range students {
+		progress, err := data.progress(s.db, student)
+		if err != nil {
+			return nil, err
+		}
+
+		report := models.ProgressReport{
+			StudentID:   student.UserID,
+			StudentName: student.StudentName,
+			Title:       class.ClassName,
+			TermName:    termName,
+			GeneratedAt: now,
+			Classes:     []models.ClassProgress{*progress},
+		}
+		pdf, err := utils.WriteProgressReportPDF(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render report of student %d: %w", student.UserID, err)
+		}
+
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     utils.ProgressReportFilename(report),
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write reports zip: %w", err)
+		}
+		if _, err := w.Write(pdf); err != nil {
+			return nil, fmt.Errorf("failed to write reports zip: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write reports zip: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *ProgressReportServiceImpl) getClass(classID int) (*models.Class, error) {
+	var class models.Class
+	if err := s.db.First(&class, classID).Error; err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+	return &class, nil
+}
+
+// reportStudents returns the students actively enrolled in a class by name,
+// or only the given student
+func reportStudents(db *gorm.DB, classID int, studentID *int) ([]reportStudent, error) {
+	query := `
+		SELECT ce.user_id, ce.section_id,
+			COALESCE(sp.first_name + ' ' + sp.last_name, '') AS student_name
+		FROM class_enrollments ce
+		LEFT JOIN student_profiles sp ON sp.user_id = ce.user_id
+		WHERE ce.class_id = ? AND ce.is_active = 1 AND ce.status = 'active'`
+	args := []interface{}{classID}
+	if studentID != nil {
+		query += " AND ce.user_id = ?"
+		args = append(args, *studentID)
+	}
+	query += " ORDER BY sp.last_name, sp.first_name, ce.user_id"
+
+	var students []reportStudent
+	if err := db.Raw(query, args...).Scan(&students).Error; err != nil {
+		return nil, fmt.Errorf("failed to get class roster: %w", err)
+	}
+	for i := range students {
+		if students[i].StudentName == "" {
+			students[i].StudentName = fmt.Sprintf("Student #%d", students[i].UserID)
+		}
+	}
+	return students, nil
+}
+
+// classReportData is what the reports of a class share, loaded once for
+// every student in a batch
+type classReportData struct {
+	class       *models.Class
+	teachers    []string
+	assignments []models.Assignment
+	names       map[int]string // Authors of feedback and comments
+}
+
+func loadClassReportData(db *gorm.DB, class *models.Class) (*classReportData, error) {
+	data := &classReportData{class: class, names: make(map[int]string)}
+
+	var teachers []struct {
+		UserID    int
+		FirstName string
+		LastName  string
+	}
+	if err := db.Raw(`
+		SELECT ct.user_id, tp.first_name, tp.last_name
+		FROM class_teachers ct
+		JOIN teacher_profiles tp ON tp.user_id = ct.user_id
+		WHERE ct.class_id = ?
+		ORDER BY ct.is_owner DESC, tp.last_name, tp.first_name
+	`, class.ClassID).Scan(&teachers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get class teachers: %w", err)
+	}
+	for _, teacher := range teachers {
+		name := fmt.Sprintf("%s %s", teacher.FirstName, teacher.LastName)
+		data.teachers = append(data.teachers, name)
+		data.names[teacher.UserID] = name
+	}
+
+	if err := db.Where("class_id = ? AND is_published = ?", class.ClassID, true).
+		Order("due_date ASC, assignment_id ASC").Find(&data.assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+
+	return data, nil
+}
+
+// authorName returns the name of the teacher who graded or commented
+func (d *classReportData) authorName(db *gorm.DB, userID int) string {
+	if name, ok := d.names[userID]; ok {
+		return name
+	}
+	name := fmt.Sprintf("Staff #%d", userID)
+	var teacher models.TeacherProfile
+	if err := db.Where("user_id = ?", userID).First(&teacher).Error; err == nil {
+		name = fmt.Sprintf("%s %s", teacher.FirstName, teacher.LastName)
+	}
+	d.names[userID] = name
+	return name
+}
+
+// progress builds a student's progress in the class. Grades and feedback
+// that are not released yet are left out.
+func (d *classReportData) progress(db *gorm.DB, student reportStudent) (*models.ClassProgress, error) {
+	progress := &models.ClassProgress{
+		ClassID:     d.class.ClassID,
+		ClassName:   d.class.ClassName,
+		Teachers:    d.teachers,
+		Assignments: []models.ReportAssignment{},
+		Comments:    []models.ReportComment{},
+	}
+
+	var assignments []models.Assignment
+	for _, assignment := range d.assignments {
+		if assignment.SectionID != nil && (student.SectionID == nil || *student.SectionID != *assignment.SectionID) {
+			continue
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if len(assignments) > 0 {
+		dueDates, err := studentDueDates(db, d.class.ClassID, student.UserID, assignments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get due dates: %w", err)
+		}
+
+		assignmentIDs := make([]int, len(assignments))
+		titles := make(map[int]string, len(assignments))
+		for i, assignment := range assignments {
+			assignmentIDs[i] = assignment.AssignmentID
+			titles[assignment.AssignmentID] = assignment.Title
+		}
+
+		var submissions []models.Submission
+		if err := db.Where("user_id = ? AND assignment_id IN ?", student.UserID, assignmentIDs).
+			Find(&submissions).Error; err != nil {
+			return nil, fmt.Errorf("failed to get submissions: %w", err)
+		}
+		byAssignment := make(map[int]*models.Submission, len(submissions))
+		submissionAssignments := make(map[int]int, len(submissions))
+		submissionIDs := make([]int, 0, len(submissions))
+		for i := range submissions {
+			byAssignment[submissions[i].AssignmentID] = &submissions[i]
+			submissionAssignments[submissions[i].SubmissionID] = submissions[i].AssignmentID
+			submissionIDs = append(submissionIDs, submissions[i].SubmissionID)
+		}
+
+		now := time.Now()
+		var percentTotal float64
+		var graded int
+		for _, assignment := range assignments {
+			item := models.ReportAssignment{
+				AssignmentID:   assignment.AssignmentID,
+				Title:          assignment.Title,
+				DueDate:        dueDates[assignment.AssignmentID],
+				PointsPossible: assignment.PointsPossible,
+			}
+			progress.Assigned++
+
+			submission := byAssignment[assignment.AssignmentID]
+			switch {
+			case submission != nil:
+				progress.Submitted++
+				submittedAt := submission.SubmissionDate
+				item.SubmittedAt = &submittedAt
+				item.IsLate = submission.IsLate
+				if submission.IsLate {
+					progress.Late++
+				}
+				item.Status = models.ReportAssignmentSubmitted
+
+				if submission.Grade != nil && submission.GradeReleased {
+					item.Status = models.ReportAssignmentGraded
+					item.Grade = submission.Grade
+					if assignment.PointsPossible > 0 {
+						percentTotal += float64(*submission.Grade) * 100 / float64(assignment.PointsPossible)
+						graded++
+					}
+
+					if feedback := strings.TrimSpace(submission.Feedback); feedback != "" {
+						comment := models.ReportComment{
+							AssignmentTitle: assignment.Title,
+							Body:            feedback,
+							CreatedAt:       submission.SubmissionDate,
+						}
+						if submission.GradedBy != nil {
+							comment.Author = d.authorName(db, *submission.GradedBy)
+						}
+						if submission.GradedDate != nil {
+							comment.CreatedAt = *submission.GradedDate
+						}
+						progress.Comments = append(progress.Comments, comment)
+					}
+				}
+			case !item.DueDate.IsZero() && item.DueDate.Before(now):
+				item.Status = models.ReportAssignmentMissing
+				progress.Missing++
+			default:
+				item.Status = models.ReportAssignmentUpcoming
+			}
+
+			progress.Assignments = append(progress.Assignments, item)
+		}
+		if graded > 0 {
+			average := percentTotal / float64(graded)
+			progress.AveragePercent = &average
+		}
+
+		if len(submissionIDs) > 0 {
+			var comments []models.SubmissionComment
+			if err := db.Where("submission_id IN ? AND author_role <> ? AND is_deleted = ?", submissionIDs, "student", false).
+				Find(&comments).Error; err != nil {
+				return nil, fmt.Errorf("failed to get submission comments: %w", err)
+			}
+			for _, comment := range comments {
+				progress.Comments = append(progress.Comments, models.ReportComment{
+					AssignmentTitle: titles[submissionAssignments[comment.SubmissionID]],
+					Author:          d.authorName(db, comment.AuthorID),
+					Body:            comment.Body,
+					CreatedAt:       comment.CreatedAt,
+				})
+			}
+			sort.SliceStable(progress.Comments, func(i, j int) bool {
+				return progress.Comments[i].CreatedAt.Before(progress.Comments[j].CreatedAt)
+			})
+		}
+	}
+
+	var attendance []struct {
+		Status string
+		Count  int
+	}
+	if err := db.Raw(`
+		SELECT r.status, COUNT(*) AS count
+		FROM attendance_records r
+		JOIN class_sessions cs ON cs.session_id = r.session_id
+		WHERE cs.class_id = ? AND r.student_id = ?
+		GROUP BY r.status
+	`, d.class.ClassID, student.UserID).Scan(&attendance).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+	for _, row := range attendance {
+		progress.Attendance.Sessions += row.Count
+		switch row.Status {
+		case models.AttendanceStatusPresent:
+			progress.Attendance.Present = row.Count
+		case models.AttendanceStatusLate:
+			progress.Attendance.Late = row.Count
+		case models.AttendanceStatusAbsent:
+			progress.Attendance.Absent = row.Count
+		case models.AttendanceStatusExcused:
+			progress.Attendance.Excused = row.Count
+		}
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,963 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// maxQTIFileSize limits the size of a single XML file read from a QTI package (5 MB)
+const maxQTIFileSize = 5 << 20
+
+// Supported QTI versions for export
+const (
+	QTIVersion21 = "2.1"
+	QTIVersion30 = "3.0"
+)
+
+// QTIService imports and exports quizzes as IMS QTI 2.1 and 3.0 content packages
+type QTIService interface {
+	Service
+	ImportPackage(classID, assignmentID int, pkg []byte, dryRun bool) (*models.QTIImportReport, error)
+	ExportPackage(classID, assignmentID int, version string) ([]byte, error)
+}
+
+// QTIServiceImpl implements QTIService
+type QTIServiceImpl struct {
+	*BaseService
+}
+
+// NewQTIService creates a new QTIService
+func NewQTIService(db *gorm.DB) QTIService {
+	return &QTIServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// ImportPackage converts the assessment items of a QTI zip package into quiz
+// questions and appends them to the assignment, which becomes a quiz.
+// Items that cannot be converted are skipped and explained in the report.
+// Like SaveQuiz, it refuses to change the questions once students have
+// started the quiz.
+func (s *QTIServiceImpl) ImportPackage(classID, assignmentID int, pkg []byte, dryRun bool) (*models.QTIImportReport, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	// Fail early, including dry runs; the check is repeated under the lock below
+	var attempts int64
+	if err := s.db.Model(&models.QuizAttempt{}).Where("assignment_id = ?", assignmentID).Count(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count quiz attempts: %w", err)
+	}
+	if attempts > 0 {
+		return nil, ErrQuizHasAttempts
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		return nil, errors.New("invalid QTI package: not a zip file")
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+	}
+
+	itemFiles, err := qtiItemFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(itemFiles) == 0 {
+		return nil, errors.New("invalid QTI package: no assessment items found")
+	}
+
+	report := &models.QTIImportReport{DryRun: dryRun}
+	var questions []models.QuizQuestion
+
+	for _, name := range itemFiles {
+		itemReport := models.QTIItemReport{File: name}
+
+		root, err := readQTIFile(files[name])
+		if err != nil {
+			itemReport.Status = models.QTIItemSkipped
+			itemReport.Messages = []string{fmt.Sprintf("invalid XML: %v", err)}
+			report.Items = append(report.Items, itemReport)
+			report.Skipped++
+			continue
+		}
+		if report.Version == "" {
+			report.Version = qtiVersion(root)
+		}
+
+		itemReport.Identifier = root.attrs["identifier"]
+		itemReport.Title = root.attrs["title"]
+
+		question, warnings, err := convertQTIItem(root)
+		if err == nil {
+			err = normalizeQuizQuestion(&question)
+		}
+		if err != nil {
+			itemReport.Status = models.QTIItemSkipped
+			itemReport.Messages = append(warnings, err.Error())
+			report.Items = append(report.Items, itemReport)
+			report.Skipped++
+			continue
+		}
+
+		itemReport.Status = models.QTIItemImported
+		if len(warnings) > 0 {
+			itemReport.Status = models.QTIItemConverted
+		}
+		itemReport.QuestionType = question.QuestionType
+		itemReport.Messages = warnings
+		report.Items = append(report.Items, itemReport)
+		report.Imported++
+		questions = append(questions, question)
+	}
+
+	if dryRun || len(questions) == 0 {
+		return report, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Archived classes are read-only
+		if err := ensureClassWritable(tx, classID); err != nil {
+			return err
+		}
+		attempts, err := lockQuiz(tx, assignmentID)
+		if err != nil {
+			return err
+		}
+		if attempts > 0 {
+			return ErrQuizHasAttempts
+		}
+
+		var position int
+		if err := tx.Model(&models.QuizQuestion{}).
+			Where("assignment_id = ? AND student_id IS NULL", assignmentID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&position).Error; err != nil {
+			return err
+		}
+
+		for i := range questions {
+			position++
+			questions[i].AssignmentID = assignmentID
+			questions[i].Position = position
+			if err := tx.Create(&questions[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Assignment{}).
+			Where("assignment_id = ?", assignmentID).
+			Update("assignment_type", models.AssignmentTypeQuiz).Error
+	})
+	if errors.Is(err, ErrQuizHasAttempts) || errors.Is(err, ErrClassArchived) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save imported questions: %w", err)
+	}
+
+	log.Printf("QTI import into assignment %d: %d imported, %d skipped", assignmentID, report.Imported, report.Skipped)
+
+	return report, nil
+}
+
+// ExportPackage writes the questions of a quiz as a QTI content package with
+// one item per question and an assessment test that references them
+func (s *QTIServiceImpl) ExportPackage(classID, assignmentID int, version string) ([]byte, error) {
+	if version == "" {
+		version = QTIVersion21
+	}
+	if version != QTIVersion21 && version != QTIVersion30 {
+		return nil, fmt.Errorf("unsupported QTI version %q, use %s or %s", version, QTIVersion21, QTIVersion30)
+	}
+	v3 := version == QTIVersion30
+
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	if assignment.AssignmentType != models.AssignmentTypeQuiz {
+		return nil, errors.New("assignment is not a quiz")
+	}
+
+	// Question bank pools are drawn per student and are not part of the package
+	questions, err := getQuizQuestions(s.db, assignmentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, itemType, testType := "http://www.imsglobal.org/xsd/imsqti_v2p1", "imsqti_item_xmlv2p1", "imsqti_test_xmlv2p1"
+	manifestNamespace, schema, schemaVersion := "http://www.imsglobal.org/xsd/imscp_v1p1", "QTIv2.1 Package", "1.0.0"
+	if v3 {
+		namespace, itemType, testType = "http://www.imsglobal.org/xsd/imsqtiasi_v3p0", "imsqti_item_xmlv3p0", "imsqti_test_xmlv3p0"
+		manifestNamespace, schema, schemaVersion = "http://www.imsglobal.org/xsd/qti/qtiv3p0/imscp_v1p1", "QTI Package", "3.0.0"
+	}
+
+	testID := fmt.Sprintf("TEST-%d", assignment.AssignmentID)
+	testResource := qtiEl("resource", "identifier", testID, "type", testType, "href", "test.xml").
+		add(qtiEl("file", "href", "test.xml"))
+	resources := qtiEl("resources").add(testResource)
+
+	section := qtiEl("assessmentSection", "identifier", "SECTION-1", "title", assignment.Title, "visible", "true")
+	if assignment.ShuffleQuestions {
+		section.add(qtiEl("ordering", "shuffle", "true"))
+	}
+	testPart := qtiEl("testPart", "identifier", "PART-1", "navigationMode", "nonlinear", "submissionMode", "simultaneous")
+	if assignment.TimeLimitMinutes != nil {
+		testPart.add(qtiEl("timeLimits", "maxTime", strconv.Itoa(*assignment.TimeLimitMinutes*60)))
+	}
+	testPart.add(section)
+	test := qtiEl("assessmentTest", "xmlns", namespace, "identifier", testID, "title", assignment.Title).add(testPart)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for i, question := range questions {
+		itemID := fmt.Sprintf("ITEM-%d", i+1)
+		href := fmt.Sprintf("items/item-%d.xml", i+1)
+
+		item, err := qtiItemElement(question, itemID, namespace, assignment.ShuffleOptions, v3)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQTIFile(zw, href, item, v3); err != nil {
+			return nil, err
+		}
+
+		section.add(qtiEl("assessmentItemRef", "identifier", itemID, "href", href))
+		testResource.add(qtiEl("dependency", "identifierref", itemID))
+		resources.add(qtiEl("resource", "identifier", itemID, "type", itemType, "href", href).
+			add(qtiEl("file", "href", href)))
+	}
+
+	if err := writeQTIFile(zw, "test.xml", test, v3); err != nil {
+		return nil, err
+	}
+
+	// Manifest element names are the same in both versions
+	manifest := qtiEl("manifest", "xmlns", manifestNamespace, "identifier", fmt.Sprintf("MANIFEST-%d", assignment.AssignmentID)).add(
+		qtiEl("metadata").add(qtiText("schema", schema), qtiText("schemaversion", schemaVersion)),
+		qtiEl("organizations"),
+		resources,
+	)
+	if err := writeQTIFile(zw, "imsmanifest.xml", manifest, false); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// qtiNode is an element or text node of a parsed QTI document. Element and
+// attribute names are normalized so QTI 2.x ("choiceInteraction") and
+// QTI 3.0 ("qti-choice-interaction") documents can be read the same way.
+type qtiNode struct {
+	name      string // normalized name, empty for text nodes
+	raw       string // name as written in the document
+	namespace string
+	attrs     map[string]string
+	children  []*qtiNode
+	text      string
+}
+
+// normalizeQTIName lowercases a name and removes the QTI 3.0 prefix and hyphens
+func normalizeQTIName(name string) string {
+	name = strings.TrimPrefix(name, "qti-")
+	return strings.ToLower(strings.ReplaceAll(name, "-", ""))
+}
+
+// parseQTIXML parses an XML document into a qtiNode tree and returns its root element
+func parseQTIXML(r io.Reader) (*qtiNode, error) {
+	dec := xml.NewDecoder(r)
+	dec.Entity = xml.HTMLEntity
+
+	document := &qtiNode{}
+	stack := []*qtiNode{document}
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &qtiNode{
+				name:      normalizeQTIName(t.Name.Local),
+				raw:       t.Name.Local,
+				namespace: t.Name.Space,
+				attrs:     make(map[string]string, len(t.Attr)),
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "" {
+					node.attrs[normalizeQTIName(attr.Name.Local)] = attr.Value
+				}
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &qtiNode{text: string(t)})
+		}
+	}
+
+	for _, child := range document.children {
+		if child.name != "" {
+			return child, nil
+		}
+	}
+	return nil, errors.New("document has no root element")
+}
+
+// findAll returns the descendants of n that match, in document order
+func (n *qtiNode) findAll(match func(*qtiNode) bool) []*qtiNode {
+	var found []*qtiNode
+	for _, child := range n.children {
+		if child.name == "" {
+			continue
+		}
+		if match(child) {
+			found = append(found, child)
+		}
+		found = append(found, child.findAll(match)...)
+	}
+	return found
+}
+
+// find returns the first descendant of n with the given normalized name
+func (n *qtiNode) find(name string) *qtiNode {
+	if found := n.findAll(func(c *qtiNode) bool { return c.name == name }); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// values returns the trimmed text of the value elements below n
+func (n *qtiNode) values() []string {
+	var values []string
+	for _, value := range n.findAll(func(c *qtiNode) bool { return c.name == "value" }) {
+		values = append(values, strings.TrimSpace(value.textContent(nil)))
+	}
+	return values
+}
+
+// qtiBlockElements are HTML block elements. Their text is separated from the
+// text around them, and their names are not prefixed in QTI 3.0.
+var qtiBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "ul": true, "ol": true, "table": true, "tr": true,
+	"td": true, "th": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true,
+}
+
+// textContent returns the text below n with whitespace collapsed, leaving out elements that match skip
+func (n *qtiNode) textContent(skip func(*qtiNode) bool) string {
+	var b strings.Builder
+	var walk func(*qtiNode)
+	walk = func(node *qtiNode) {
+		for _, child := range node.children {
+			if child.name == "" {
+				b.WriteString(child.text)
+				continue
+			}
+			if skip != nil && skip(child) {
+				continue
+			}
+			block := qtiBlockElements[child.name] || child.name == "prompt"
+			if block {
+				b.WriteString(" ")
+			}
+			walk(child)
+			if block {
+				b.WriteString(" ")
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// qtiVersion guesses the QTI version of a document from its root element
+func qtiVersion(root *qtiNode) string {
+	switch {
+	case strings.HasPrefix(root.raw, "qti-") || strings.Contains(root.namespace, "v3p0"):
+		return QTIVersion30
+	case strings.Contains(root.namespace, "v2p2"):
+		return "2.2"
+	case strings.Contains(root.namespace, "v2p1"):
+		return QTIVersion21
+	case strings.Contains(root.namespace, "v2p0"):
+		return "2.0"
+	}
+	return "unknown"
+}
+
+// readQTIFile reads and parses an XML file from a package
+func readQTIFile(f *zip.File) (*qtiNode, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return parseQTIXML(io.LimitReader(rc, maxQTIFileSize))
+}
+
+// qtiItemFiles returns the item files of a package, in the order of the
+// manifest when there is one and by file name otherwise
+func qtiItemFiles(files map[string]*zip.File) ([]string, error) {
+	var itemFiles []string
+
+	if manifestFile, ok := files["imsmanifest.xml"]; ok {
+		manifest, err := readQTIFile(manifestFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QTI package: imsmanifest.xml: %v", err)
+		}
+		for _, resource := range manifest.findAll(func(c *qtiNode) bool { return c.name == "resource" }) {
+			href := resource.attrs["href"]
+			if href == "" || !strings.Contains(resource.attrs["type"], "imsqti_item") {
+				continue
+			}
+			name := path.Clean(href)
+			if _, ok := files[name]; ok {
+				itemFiles = append(itemFiles, name)
+			}
+		}
+		if len(itemFiles) > 0 {
+			return itemFiles, nil
+		}
+	}
+
+	// Without a usable manifest, take every XML file whose root is an assessment item
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if strings.EqualFold(path.Ext(name), ".xml") && name != "imsmanifest.xml" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		root, err := readQTIFile(files[name])
+		if err != nil || root.name == "assessmentitem" {
+			// Broken files are kept so the report can explain them
+			itemFiles = append(itemFiles, name)
+		}
+	}
+
+	return itemFiles, nil
+}
+
+// convertQTIItem converts an assessment item into a quiz question. It returns
+// warnings about parts of the item that were changed or dropped, and an error
+// when the item cannot be converted at all.
+func convertQTIItem(item *qtiNode) (models.QuizQuestion, []string, error) {
+	var question models.QuizQuestion
+	var warnings []string
+
+	if item.name != "assessmentitem" {
+		return question, nil, fmt.Errorf("%s is not an assessment item", item.raw)
+	}
+
+	body := item.find("itembody")
+	if body == nil {
+		return question, nil, errors.New("item has no item body")
+	}
+
+	isInteraction := func(n *qtiNode) bool { return strings.HasSuffix(n.name, "interaction") }
+	interactions := body.findAll(isInteraction)
+	if len(interactions) == 0 {
+		return question, nil, errors.New("item has no interaction to answer")
+	}
+	if len(interactions) > 1 {
+		return question, nil, fmt.Errorf("items with %d interactions are not supported", len(interactions))
+	}
+	interaction := interactions[0]
+
+	var declaration *qtiNode
+	for _, d := range item.findAll(func(c *qtiNode) bool { return c.name == "responsedeclaration" }) {
+		if d.attrs["identifier"] == interaction.attrs["responseidentifier"] {
+			declaration = d
+			break
+		}
+	}
+	if declaration == nil {
+		return question, nil, fmt.Errorf("response declaration %q is missing", interaction.attrs["responseidentifier"])
+	}
+
+	var correct []string
+	if correctResponse := declaration.find("correctresponse"); correctResponse != nil {
+		correct = correctResponse.values()
+	}
+
+	// Mapped values give partial or alternative answers
+	mapped := make(map[string]float64)
+	caseSensitive := true
+	var mapKeys []string
+	if mapping := declaration.find("mapping"); mapping != nil {
+		for _, entry := range mapping.findAll(func(c *qtiNode) bool { return c.name == "mapentry" }) {
+			value, _ := strconv.ParseFloat(entry.attrs["mappedvalue"], 64)
+			key := entry.attrs["mapkey"]
+			if _, ok := mapped[key]; !ok {
+				mapKeys = append(mapKeys, key)
+			}
+			mapped[key] = value
+			if entry.attrs["casesensitive"] == "false" {
+				caseSensitive = false
+			}
+		}
+	}
+	if len(correct) == 0 {
+		for _, key := range mapKeys {
+			if mapped[key] > 0 {
+				correct = append(correct, key)
+			}
+		}
+	}
+
+	// The prompt is the item body without the interaction, plus the interaction's own prompt
+	prompt := body.textContent(isInteraction)
+	if interactionPrompt := interaction.find("prompt"); interactionPrompt != nil {
+		prompt = strings.TrimSpace(prompt + " " + interactionPrompt.textContent(nil))
+	}
+	if prompt == "" {
+		prompt = item.attrs["title"]
+	}
+	question.Prompt = prompt
+	question.Points = qtiItemPoints(item, declaration)
+
+	isMedia := func(n *qtiNode) bool {
+		switch n.name {
+		case "img", "object", "audio", "video", "math", "include":
+			return true
+		}
+		return false
+	}
+	if len(body.findAll(isMedia)) > 0 {
+		warnings = append(warnings, "images, media and math markup are not supported and were removed")
+	}
+
+	switch interaction.name {
+	case "choiceinteraction", "inlinechoiceinteraction":
+		choiceName := "simplechoice"
+		if interaction.name == "inlinechoiceinteraction" {
+			choiceName = "inlinechoice"
+		}
+		isCorrect := make(map[string]bool, len(correct))
+		for _, value := range correct {
+			isCorrect[value] = true
+		}
+		for _, choice := range interaction.findAll(func(c *qtiNode) bool { return c.name == choiceName }) {
+			question.Options = append(question.Options, models.QuizOption{
+				ID:      choice.attrs["identifier"],
+				Text:    choice.textContent(nil),
+				Correct: isCorrect[choice.attrs["identifier"]],
+			})
+		}
+
+		switch {
+		case declaration.attrs["cardinality"] == "multiple":
+			question.QuestionType = models.QuestionTypeMultiSelect
+			if len(mapped) > 0 && !matchesPartialCredit(mapped, isCorrect, question.Points) {
+				warnings = append(warnings, "the item's own partial-credit scores were replaced by ClassConnect partial credit")
+			}
+		case isTrueFalseChoice(question.Options):
+			question.QuestionType = models.QuestionTypeTrueFalse
+			for _, option := range question.Options {
+				if option.Correct {
+					question.CorrectAnswers = []string{strings.ToLower(option.ID)}
+				}
+			}
+			question.Options = nil
+		default:
+			question.QuestionType = models.QuestionTypeMultipleChoice
+		}
+
+	case "textentryinteraction":
+		switch declaration.attrs["basetype"] {
+		case "float", "integer":
+			question.QuestionType = models.QuestionTypeNumeric
+			if len(correct) > 0 {
+				question.CorrectAnswers = correct[:1]
+			}
+			tolerance, warning := qtiTolerance(item, correct)
+			question.Tolerance = tolerance
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+		case "string", "":
+			question.QuestionType = models.QuestionTypeShortAnswer
+			question.CaseSensitive = caseSensitive
+			seen := make(map[string]bool)
+			for _, answer := range correct {
+				if !seen[answer] {
+					seen[answer] = true
+					question.CorrectAnswers = append(question.CorrectAnswers, answer)
+				}
+			}
+			for _, key := range mapKeys {
+				if mapped[key] > 0 && !seen[key] {
+					seen[key] = true
+					question.CorrectAnswers = append(question.CorrectAnswers, key)
+				}
+			}
+		default:
+			return question, warnings, fmt.Errorf("text entry responses of type %q are not supported", declaration.attrs["basetype"])
+		}
+
+	case "extendedtextinteraction":
+		return question, warnings, errors.New("extended text (essay) responses cannot be graded automatically")
+
+	default:
+		return question, warnings, fmt.Errorf("%s items are not supported", interaction.raw)
+	}
+
+	if rp := item.find("responseprocessing"); rp != nil && rp.attrs["template"] == "" &&
+		question.QuestionType != models.QuestionTypeNumeric {
+		warnings = append(warnings, "custom response processing was replaced by ClassConnect scoring")
+	}
+
+	return question, warnings, nil
+}
+
+// matchesPartialCredit reports whether a mapping scores like ClassConnect partial
+// credit: each correct choice adds an equal share of the points and each wrong choice removes one
+func matchesPartialCredit(mapped map[string]float64, isCorrect map[string]bool, points float64) bool {
+	if len(isCorrect) == 0 {
+		return false
+	}
+	share := points / float64(len(isCorrect))
+	for key, value := range mapped {
+		expected := -share
+		if isCorrect[key] {
+			expected = share
+		}
+		if math.Abs(value-expected) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+// isTrueFalseChoice reports whether the options of a choice are exactly "true" and "false"
+func isTrueFalseChoice(options []models.QuizOption) bool {
+	if len(options) != 2 {
+		return false
+	}
+	ids := strings.ToLower(options[0].ID) + "," + strings.ToLower(options[1].ID)
+	return ids == "true,false" || ids == "false,true"
+}
+
+// qtiItemPoints reads the maximum score of an item, defaulting to one point
+func qtiItemPoints(item, declaration *qtiNode) float64 {
+	for _, outcome := range item.findAll(func(c *qtiNode) bool { return c.name == "outcomedeclaration" }) {
+		if outcome.attrs["identifier"] == "MAXSCORE" {
+			if values := outcome.values(); len(values) > 0 {
+				if points, err := strconv.ParseFloat(values[0], 64); err == nil && points > 0 {
+					return points
+				}
+			}
+		}
+		if outcome.attrs["identifier"] == "SCORE" {
+			if points, err := strconv.ParseFloat(outcome.attrs["normalmaximum"], 64); err == nil && points > 0 {
+				return points
+			}
+		}
+	}
+
+	if mapping := declaration.find("mapping"); mapping != nil {
+		if points, err := strconv.ParseFloat(mapping.attrs["upperbound"], 64); err == nil && points > 0 {
+			return points
+		}
+	}
+
+	return 1
+}
+
+// qtiTolerance reads the tolerance of a numeric item from its response processing.
+// Relative tolerances are converted to an absolute tolerance around the correct answer.
+func qtiTolerance(item *qtiNode, correct []string) (float64, string) {
+	equal := item.find("equal")
+	if equal == nil {
+		return 0, ""
+	}
+
+	fields := strings.Fields(equal.attrs["tolerance"])
+	if len(fields) == 0 {
+		return 0, ""
+	}
+	tolerance, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || tolerance < 0 {
+		return 0, "the tolerance could not be read and exact answers are required"
+	}
+
+	switch equal.attrs["tolerancemode"] {
+	case "absolute":
+		return tolerance, ""
+	case "relative":
+		if len(correct) == 0 {
+			return 0, ""
+		}
+		value, err := strconv.ParseFloat(correct[0], 64)
+		if err != nil {
+			return 0, ""
+		}
+		return math.Abs(value) * tolerance / 100, "the relative tolerance was converted to an absolute tolerance"
+	}
+	return 0, ""
+}
+
+// qtiElement is an element written to an exported QTI document.
+// Names are given in QTI 2.1 form and converted for QTI 3.0 when written;
+// HTML elements such as "p" keep their name in both versions.
+type qtiElement struct {
+	name     string
+	attrs    []string // name, value pairs
+	children []*qtiElement
+	text     string
+}
+
+// qtiEl creates an element with attribute name and value pairs
+func qtiEl(name string, attrs ...string) *qtiElement {
+	return &qtiElement{name: name, attrs: attrs}
+}
+
+// qtiText creates an element containing text
+func qtiText(name, text string, attrs ...string) *qtiElement {
+	return &qtiElement{name: name, attrs: attrs, text: text}
+}
+
+// add appends child elements and returns the element
+func (e *qtiElement) add(children ...*qtiElement) *qtiElement {
+	e.children = append(e.children, children...)
+	return e
+}
+
+// qti3Name converts a QTI 2.1 name such as "choiceInteraction" to its QTI 3.0 form "choice-interaction"
+func qti3Name(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// encode writes the element and its children
+func (e *qtiElement) encode(enc *xml.Encoder, v3 bool) error {
+	name := e.name
+	if v3 && !qtiBlockElements[name] {
+		name = "qti-" + qti3Name(name)
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	for i := 0; i+1 < len(e.attrs); i += 2 {
+		attrName := e.attrs[i]
+		if v3 && attrName != "xmlns" {
+			attrName = qti3Name(attrName)
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attrName}, Value: e.attrs[i+1]})
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if e.text != "" {
+		if err := enc.EncodeToken(xml.CharData(e.text)); err != nil {
+			return err
+		}
+	}
+	for _, child := range e.children {
+		if err := child.encode(enc, v3); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// writeQTIFile writes an XML document to the package
+func writeQTIFile(zw *zip.Writer, name string, root *qtiElement, v3 bool) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := root.encode(enc, v3); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// qtiFloat formats a number for a QTI document
+func qtiFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// qtiItemElement builds the assessment item for a quiz question
+func qtiItemElement(question models.QuizQuestion, identifier, namespace string, shuffle, v3 bool) (*qtiElement, error) {
+	matchCorrect := "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	mapResponse := "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"
+	if v3 {
+		matchCorrect = "https://purl.imsglobal.org/spec/qti/v3p0/rptemplates/match_correct.xml"
+		mapResponse = "https://purl.imsglobal.org/spec/qti/v3p0/rptemplates/map_response.xml"
+	}
+
+	item := qtiEl("assessmentItem", "xmlns", namespace, "identifier", identifier,
+		"title", fmt.Sprintf("Question %d", question.Position), "adaptive", "false", "timeDependent", "false")
+	declaration := qtiEl("responseDeclaration", "identifier", "RESPONSE")
+	body := qtiEl("itemBody")
+	processing := qtiEl("responseProcessing", "template", matchCorrect)
+
+	switch question.QuestionType {
+	case models.QuestionTypeMultipleChoice, models.QuestionTypeMultiSelect:
+		cardinality, maxChoices := "single", "1"
+		if question.QuestionType == models.QuestionTypeMultiSelect {
+			cardinality, maxChoices = "multiple", "0"
+		}
+		declaration.attrs = append(declaration.attrs, "cardinality", cardinality, "baseType", "identifier")
+
+		correctResponse := qtiEl("correctResponse")
+		interaction := qtiEl("choiceInteraction", "responseIdentifier", "RESPONSE",
+			"shuffle", strconv.FormatBool(shuffle), "maxChoices", maxChoices).
+			add(qtiText("prompt", question.Prompt))
+
+		correctCount := 0
+		for _, option := range question.Options {
+			if option.Correct {
+				correctCount++
+			}
+		}
+		mapping := qtiEl("mapping", "lowerBound", "0", "upperBound", qtiFloat(question.Points), "defaultValue", "0")
+
+		for i, option := range question.Options {
+			choiceID := fmt.Sprintf("CHOICE_%d", i+1)
+			interaction.add(qtiText("simpleChoice", option.Text, "identifier", choiceID))
+			if option.Correct {
+				correctResponse.add(qtiText("value", choiceID))
+			}
+
+			// ClassConnect partial credit: each correct choice adds a share, each wrong choice removes one
+			if correctCount > 0 {
+				share := question.Points / float64(correctCount)
+				if !option.Correct {
+					share = -share
+				}
+				mapping.add(qtiEl("mapEntry", "mapKey", choiceID, "mappedValue", qtiFloat(share)))
+			}
+		}
+
+		declaration.add(correctResponse)
+		if question.QuestionType == models.QuestionTypeMultiSelect {
+			declaration.add(mapping)
+			processing = qtiEl("responseProcessing", "template", mapResponse)
+		}
+		body.add(interaction)
+
+	case models.QuestionTypeTrueFalse:
+		if len(question.CorrectAnswers) != 1 {
+			return nil, fmt.Errorf("question %d has no correct answer", question.Position)
+		}
+		declaration.attrs = append(declaration.attrs, "cardinality", "single", "baseType", "identifier")
+		declaration.add(qtiEl("correctResponse").add(qtiText("value", question.CorrectAnswers[0])))
+		body.add(qtiEl("choiceInteraction", "responseIdentifier", "RESPONSE", "shuffle", "false", "maxChoices", "1").add(
+			qtiText("prompt", question.Prompt),
+			qtiText("simpleChoice", "True", "identifier", "true"),
+			qtiText("simpleChoice", "False", "identifier", "false"),
+		))
+
+	case models.QuestionTypeNumeric:
+		if len(question.CorrectAnswers) != 1 {
+			return nil, fmt.Errorf("question %d has no correct answer", question.Position)
+		}
+		declaration.attrs = append(declaration.attrs, "cardinality", "single", "baseType", "float")
+		declaration.add(qtiEl("correctResponse").add(qtiText("value", question.CorrectAnswers[0])))
+		body.add(
+			qtiText("p", question.Prompt),
+			qtiEl("p").add(qtiEl("textEntryInteraction", "responseIdentifier", "RESPONSE")),
+		)
+
+		equal := qtiEl("equal", "toleranceMode", "exact")
+		if question.Tolerance > 0 {
+			tolerance := qtiFloat(question.Tolerance)
+			equal = qtiEl("equal", "toleranceMode", "absolute", "tolerance", tolerance+" "+tolerance)
+		}
+		equal.add(qtiEl("variable", "identifier", "RESPONSE"), qtiEl("correct", "identifier", "RESPONSE"))
+		processing = qtiEl("responseProcessing").add(
+			qtiEl("responseCondition").add(
+				qtiEl("responseIf").add(
+					equal,
+					qtiEl("setOutcomeValue", "identifier", "SCORE").add(
+						qtiText("baseValue", qtiFloat(question.Points), "baseType", "float"),
+					),
+				),
+			),
+		)
+
+	case models.QuestionTypeShortAnswer:
+		if len(question.CorrectAnswers) == 0 {
+			return nil, fmt.Errorf("question %d has no accepted answers", question.Position)
+		}
+		declaration.attrs = append(declaration.attrs, "cardinality", "single", "baseType", "string")
+		mapping := qtiEl("mapping", "lowerBound", "0", "upperBound", qtiFloat(question.Points), "defaultValue", "0")
+		for _, answer := range question.CorrectAnswers {
+			mapping.add(qtiEl("mapEntry", "mapKey", answer, "mappedValue", qtiFloat(question.Points),
+				"caseSensitive", strconv.FormatBool(question.CaseSensitive)))
+		}
+		declaration.add(qtiEl("correctResponse").add(qtiText("value", question.CorrectAnswers[0])), mapping)
+		body.add(
+			qtiText("p", question.Prompt),
+			qtiEl("p").add(qtiEl("textEntryInteraction", "responseIdentifier", "RESPONSE")),
+		)
+		processing = qtiEl("responseProcessing", "template", mapResponse)
+
+	default:
+		return nil, fmt.Errorf("question %d has unknown type %q", question.Position, question.QuestionType)
+	}
+
+	item.add(
+		declaration,
+		qtiEl("outcomeDeclaration", "identifier", "SCORE", "cardinality", "single", "baseType", "float").
+			add(qtiEl("defaultValue").add(qtiText("value", "0"))),
+		qtiEl("outcomeDeclaration", "identifier", "MAXSCORE", "cardinality", "single", "baseType", "float").
+			add(qtiEl("defaultValue").add(qtiText("value", qtiFloat(question.Points)))),
+		body,
+		processing,
+	)
+
+	return item, nil
+}
@@ -0,0 +1,544 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+)
+
+// qtiTestItem wraps the declarations and body of a QTI 2.1 assessment item
+func qtiTestItem(inner string) string {
+	return `<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="ITEM-1" title="Item title">` +
+		inner + `</assessmentItem>`
+}
+
+func parseQTITestXML(t *testing.T, doc string) *qtiNode {
+	t.Helper()
+	root, err := parseQTIXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parseQTIXML: %v", err)
+	}
+	return root
+}
+
+func TestConvertQTIItem(t *testing.T) {
+	const choiceDeclaration = `<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+		<correctResponse><value>B</value></correctResponse></responseDeclaration>`
+	const matchCorrect = `<responseProcessing template="http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"/>`
+
+	tests := []struct {
+		name         string
+		doc          string
+		want         models.QuizQuestion
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "multiple choice",
+			doc: qtiTestItem(choiceDeclaration + `
+				<outcomeDeclaration identifier="MAXSCORE" cardinality="single" baseType="float">
+					<defaultValue><value>2</value></defaultValue></outcomeDeclaration>
+				<itemBody><choiceInteraction responseIdentifier="RESPONSE" maxChoices="1">
+					<prompt>Which city is the capital of France?</prompt>
+					<simpleChoice identifier="A">Lyon</simpleChoice>
+					<simpleChoice identifier="B">Paris</simpleChoice>
+				</choiceInteraction></itemBody>` + matchCorrect),
+			want: models.QuizQuestion{
+				QuestionType: models.QuestionTypeMultipleChoice,
+				Prompt:       "Which city is the capital of France?",
+				Points:       2,
+				Options: []models.QuizOption{
+					{ID: "A", Text: "Lyon"},
+					{ID: "B", Text: "Paris", Correct: true},
+				},
+			},
+		},
+		{
+			name: "QTI 3.0 names and body text around the interaction",
+			doc: `<qti-assessment-item xmlns="http://www.imsglobal.org/xsd/imsqtiasi_v3p0" identifier="ITEM-1" title="Item">
+				<qti-response-declaration identifier="RESPONSE" cardinality="single" base-type="identifier">
+					<qti-correct-response><qti-value>A</qti-value></qti-correct-response></qti-response-declaration>
+				<qti-item-body><p>Look at the map.</p>
+					<qti-choice-interaction response-identifier="RESPONSE" max-choices="1">
+						<qti-prompt>Which river is longest?</qti-prompt>
+						<qti-simple-choice identifier="A">Nile</qti-simple-choice>
+						<qti-simple-choice identifier="B">Thames</qti-simple-choice>
+					</qti-choice-interaction></qti-item-body>
+				<qti-response-processing template="https://purl.imsglobal.org/spec/qti/v3p0/rptemplates/match_correct.xml"/>
+				</qti-assessment-item>`,
+			want: models.QuizQuestion{
+				QuestionType: models.QuestionTypeMultipleChoice,
+				Prompt:       "Look at the map. Which river is longest?",
+				Points:       1,
+				Options: []models.QuizOption{
+					{ID: "A", Text: "Nile", Correct: true},
+					{ID: "B", Text: "Thames"},
+				},
+			},
+		},
+		{
+			name: "multi-select with ClassConnect partial credit",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="multiple" baseType="identifier">
+					<correctResponse><value>A</value><value>B</value></correctResponse>
+					<mapping lowerBound="0" upperBound="4" defaultValue="0">
+						<mapEntry mapKey="A" mappedValue="2"/><mapEntry mapKey="B" mappedValue="2"/>
+						<mapEntry mapKey="C" mappedValue="-2"/>
+					</mapping></responseDeclaration>
+				<itemBody><choiceInteraction responseIdentifier="RESPONSE" maxChoices="0">
+					<prompt>Pick the primes</prompt>
+					<simpleChoice identifier="A">2</simpleChoice>
+					<simpleChoice identifier="B">3</simpleChoice>
+					<simpleChoice identifier="C">4</simpleChoice>
+				</choiceInteraction></itemBody>
+				<responseProcessing template="http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"/>`),
+			want: models.QuizQuestion{
+				QuestionType: models.QuestionTypeMultiSelect,
+				Prompt:       "Pick the primes",
+				Points:       4,
+				Options: []models.QuizOption{
+					{ID: "A", Text: "2", Correct: true},
+					{ID: "B", Text: "3", Correct: true},
+					{ID: "C", Text: "4"},
+				},
+			},
+		},
+		{
+			name: "multi-select with its own partial credit",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="multiple" baseType="identifier">
+					<mapping lowerBound="0" upperBound="3" defaultValue="0">
+						<mapEntry mapKey="A" mappedValue="2"/><mapEntry mapKey="B" mappedValue="1"/>
+					</mapping></responseDeclaration>
+				<itemBody><choiceInteraction responseIdentifier="RESPONSE" maxChoices="0">
+					<prompt>Pick</prompt>
+					<simpleChoice identifier="A">One</simpleChoice>
+					<simpleChoice identifier="B">Two</simpleChoice>
+					<simpleChoice identifier="C">Three</simpleChoice>
+				</choiceInteraction></itemBody>
+				<responseProcessing template="http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"/>`),
+			want: models.QuizQuestion{
+				QuestionType: models.QuestionTypeMultiSelect,
+				Prompt:       "Pick",
+				Points:       3,
+				Options: []models.QuizOption{
+					{ID: "A", Text: "One", Correct: true},
+					{ID: "B", Text: "Two", Correct: true},
+					{ID: "C", Text: "Three"},
+				},
+			},
+			wantWarnings: []string{"partial-credit scores were replaced"},
+		},
+		{
+			name: "true/false choice",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+					<correctResponse><value>False</value></correctResponse></responseDeclaration>
+				<itemBody><choiceInteraction responseIdentifier="RESPONSE" maxChoices="1">
+					<prompt>The sun orbits the earth.</prompt>
+					<simpleChoice identifier="True">True</simpleChoice>
+					<simpleChoice identifier="False">False</simpleChoice>
+				</choiceInteraction></itemBody>` + matchCorrect),
+			want: models.QuizQuestion{
+				QuestionType:   models.QuestionTypeTrueFalse,
+				Prompt:         "The sun orbits the earth.",
+				Points:         1,
+				CorrectAnswers: []string{"false"},
+			},
+		},
+		{
+			name: "inline choice",
+			doc: qtiTestItem(choiceDeclaration + `
+				<itemBody><p>Water boils at
+					<inlineChoiceInteraction responseIdentifier="RESPONSE">
+						<inlineChoice identifier="A">50</inlineChoice>
+						<inlineChoice identifier="B">100</inlineChoice>
+					</inlineChoiceInteraction> degrees.</p></itemBody>` + matchCorrect),
+			want: models.QuizQuestion{
+				QuestionType: models.QuestionTypeMultipleChoice,
+				Prompt:       "Water boils at degrees.",
+				Points:       1,
+				Options: []models.QuizOption{
+					{ID: "A", Text: "50"},
+					{ID: "B", Text: "100", Correct: true},
+				},
+			},
+		},
+		{
+			name: "numeric with an absolute tolerance",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="float">
+					<correctResponse><value>9.81</value></correctResponse></responseDeclaration>
+				<itemBody><p>Gravity in m/s²?</p><p><textEntryInteraction responseIdentifier="RESPONSE"/></p></itemBody>
+				<responseProcessing><responseCondition><responseIf>
+					<equal toleranceMode="absolute" tolerance="0.05 0.05">
+						<variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></equal>
+					<setOutcomeValue identifier="SCORE"><baseValue baseType="float">1</baseValue></setOutcomeValue>
+				</responseIf></responseCondition></responseProcessing>`),
+			want: models.QuizQuestion{
+				QuestionType:   models.QuestionTypeNumeric,
+				Prompt:         "Gravity in m/s²?",
+				Points:         1,
+				CorrectAnswers: []string{"9.81"},
+				Tolerance:      0.05,
+			},
+		},
+		{
+			name: "numeric with a relative tolerance",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="integer">
+					<correctResponse><value>-20</value></correctResponse></responseDeclaration>
+				<itemBody><p>Temperature?</p><p><textEntryInteraction responseIdentifier="RESPONSE"/></p></itemBody>
+				<responseProcessing><responseCondition><responseIf>
+					<equal toleranceMode="relative" tolerance="10">
+						<variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></equal>
+				</responseIf></responseCondition></responseProcessing>`),
+			want: models.QuizQuestion{
+				QuestionType:   models.QuestionTypeNumeric,
+				Prompt:         "Temperature?",
+				Points:         1,
+				CorrectAnswers: []string{"-20"},
+				Tolerance:      2,
+			},
+			wantWarnings: []string{"relative tolerance was converted"},
+		},
+		{
+			name: "numeric with an unreadable tolerance",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="float">
+					<correctResponse><value>1.5</value></correctResponse></responseDeclaration>
+				<itemBody><p>Value?</p><p><textEntryInteraction responseIdentifier="RESPONSE"/></p></itemBody>
+				<responseProcessing><equal toleranceMode="absolute" tolerance="abc"/></responseProcessing>`),
+			want: models.QuizQuestion{
+				QuestionType:   models.QuestionTypeNumeric,
+				Prompt:         "Value?",
+				Points:         1,
+				CorrectAnswers: []string{"1.5"},
+			},
+			wantWarnings: []string{"exact answers are required"},
+		},
+		{
+			name: "short answer from the correct response and mapping",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string">
+					<correctResponse><value>Washington</value></correctResponse>
+					<mapping lowerBound="0" upperBound="2" defaultValue="0">
+						<mapEntry mapKey="Washington" mappedValue="2" caseSensitive="false"/>
+						<mapEntry mapKey="George Washington" mappedValue="2" caseSensitive="false"/>
+						<mapEntry mapKey="Lincoln" mappedValue="0" caseSensitive="false"/>
+					</mapping></responseDeclaration>
+				<itemBody><p>Who was the first president?</p><p><textEntryInteraction responseIdentifier="RESPONSE"/></p></itemBody>
+				<responseProcessing template="http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"/>`),
+			want: models.QuizQuestion{
+				QuestionType:   models.QuestionTypeShortAnswer,
+				Prompt:         "Who was the first president?",
+				Points:         2,
+				CorrectAnswers: []string{"Washington", "George Washington"},
+			},
+		},
+		{
+			name: "case-sensitive short answer without a prompt uses the title",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single">
+					<correctResponse><value>NaCl</value></correctResponse></responseDeclaration>
+				<itemBody><textEntryInteraction responseIdentifier="RESPONSE"/></itemBody>` + matchCorrect),
+			want: models.QuizQuestion{
+				QuestionType:   models.QuestionTypeShortAnswer,
+				Prompt:         "Item title",
+				Points:         1,
+				CorrectAnswers: []string{"NaCl"},
+				CaseSensitive:  true,
+			},
+		},
+		{
+			name: "media and custom response processing",
+			doc: qtiTestItem(choiceDeclaration + `
+				<itemBody><p>Which shape is shown? <img src="shape.png" alt="shape"/></p>
+					<choiceInteraction responseIdentifier="RESPONSE" maxChoices="1">
+						<simpleChoice identifier="A">Circle</simpleChoice>
+						<simpleChoice identifier="B">Square</simpleChoice>
+					</choiceInteraction></itemBody>
+				<responseProcessing><responseCondition/></responseProcessing>`),
+			want: models.QuizQuestion{
+				QuestionType: models.QuestionTypeMultipleChoice,
+				Prompt:       "Which shape is shown?",
+				Points:       1,
+				Options: []models.QuizOption{
+					{ID: "A", Text: "Circle"},
+					{ID: "B", Text: "Square", Correct: true},
+				},
+			},
+			wantWarnings: []string{"images, media and math markup", "custom response processing"},
+		},
+
+		// Items that cannot be converted
+		{
+			name: "extended text",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string"/>
+				<itemBody><extendedTextInteraction responseIdentifier="RESPONSE"><prompt>Discuss.</prompt></extendedTextInteraction></itemBody>`),
+			wantErr: "extended text (essay) responses cannot be graded automatically",
+		},
+		{
+			name: "match interaction",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="multiple" baseType="directedPair"/>
+				<itemBody><matchInteraction responseIdentifier="RESPONSE"><prompt>Match them.</prompt></matchInteraction></itemBody>`),
+			wantErr: "matchInteraction items are not supported",
+		},
+		{
+			name: "QTI 3.0 hotspot interaction",
+			doc: `<qti-assessment-item identifier="ITEM-1">
+				<qti-response-declaration identifier="RESPONSE" cardinality="single" base-type="identifier"/>
+				<qti-item-body><qti-hotspot-interaction response-identifier="RESPONSE"/></qti-item-body>
+				</qti-assessment-item>`,
+			wantErr: "qti-hotspot-interaction items are not supported",
+		},
+		{
+			name: "text entry of an unsupported type",
+			doc: qtiTestItem(`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="point"/>
+				<itemBody><textEntryInteraction responseIdentifier="RESPONSE"/></itemBody>`),
+			wantErr: `text entry responses of type "point" are not supported`,
+		},
+		{
+			name: "two interactions",
+			doc: qtiTestItem(`<responseDeclaration identifier="R1" cardinality="single" baseType="string"/>
+				<responseDeclaration identifier="R2" cardinality="single" baseType="string"/>
+				<itemBody><textEntryInteraction responseIdentifier="R1"/><textEntryInteraction responseIdentifier="R2"/></itemBody>`),
+			wantErr: "items with 2 interactions are not supported",
+		},
+		{
+			name:    "no interaction",
+			doc:     qtiTestItem(`<itemBody><p>Read the passage.</p></itemBody>`),
+			wantErr: "item has no interaction to answer",
+		},
+		{
+			name:    "no item body",
+			doc:     qtiTestItem(`<responseDeclaration identifier="RESPONSE"/>`),
+			wantErr: "item has no item body",
+		},
+		{
+			name: "missing response declaration",
+			doc: qtiTestItem(`<responseDeclaration identifier="OTHER" cardinality="single" baseType="string"/>
+				<itemBody><textEntryInteraction responseIdentifier="RESPONSE"/></itemBody>`),
+			wantErr: `response declaration "RESPONSE" is missing`,
+		},
+		{
+			name:    "not an assessment item",
+			doc:     `<assessmentTest identifier="TEST-1"/>`,
+			wantErr: "assessmentTest is not an assessment item",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question, warnings, err := convertQTIItem(parseQTITestXML(t, tt.doc))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(question, tt.want) {
+				t.Errorf("question = %+v, want %+v", question, tt.want)
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %q, want %d", warnings, len(tt.wantWarnings))
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.Contains(warnings[i], want) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestQTIItemRoundTrip(t *testing.T) {
+	questions := []models.QuizQuestion{
+		{
+			Position:     1,
+			QuestionType: models.QuestionTypeMultipleChoice,
+			Prompt:       "Capital of France?",
+			Points:       2,
+			Options:      []models.QuizOption{{Text: "Lyon"}, {Text: "Paris", Correct: true}},
+		},
+		{
+			Position:     2,
+			QuestionType: models.QuestionTypeMultiSelect,
+			Prompt:       "Pick the primes",
+			Points:       3,
+			Options:      []models.QuizOption{{Text: "2", Correct: true}, {Text: "3", Correct: true}, {Text: "4"}},
+		},
+		{
+			Position:       3,
+			QuestionType:   models.QuestionTypeTrueFalse,
+			Prompt:         "The earth is round.",
+			Points:         1,
+			CorrectAnswers: []string{"true"},
+		},
+		{
+			Position:       4,
+			QuestionType:   models.QuestionTypeNumeric,
+			Prompt:         "Value of pi?",
+			Points:         1.5,
+			CorrectAnswers: []string{"3.14"},
+			Tolerance:      0.01,
+		},
+		{
+			Position:       5,
+			QuestionType:   models.QuestionTypeShortAnswer,
+			Prompt:         "Symbol for sodium?",
+			Points:         1,
+			CorrectAnswers: []string{"Na", "Natrium"},
+			CaseSensitive:  true,
+		},
+	}
+
+	for _, v3 := range []bool{false, true} {
+		namespace := "http://www.imsglobal.org/xsd/imsqti_v2p1"
+		if v3 {
+			namespace = "http://www.imsglobal.org/xsd/imsqtiasi_v3p0"
+		}
+		for _, question := range questions {
+			t.Run(fmt.Sprintf("%s v3=%v", question.QuestionType, v3), func(t *testing.T) {
+				element, err := qtiItemElement(question, "ITEM-1", namespace, false, v3)
+				if err != nil {
+					t.Fatalf("qtiItemElement: %v", err)
+				}
+				var buf bytes.Buffer
+				enc := xml.NewEncoder(&buf)
+				if err := element.encode(enc, v3); err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+				if err := enc.Flush(); err != nil {
+					t.Fatalf("flush: %v", err)
+				}
+
+				root := parseQTITestXML(t, buf.String())
+				wantVersion := QTIVersion21
+				if v3 {
+					wantVersion = QTIVersion30
+				}
+				if got := qtiVersion(root); got != wantVersion {
+					t.Errorf("qtiVersion() = %q, want %q", got, wantVersion)
+				}
+
+				got, warnings, err := convertQTIItem(root)
+				if err != nil {
+					t.Fatalf("convertQTIItem: %v", err)
+				}
+				if len(warnings) > 0 {
+					t.Errorf("unexpected warnings: %q", warnings)
+				}
+
+				// Exported choices are given new identifiers
+				want := question
+				want.Position = 0
+				want.Options = nil
+				for i, option := range question.Options {
+					option.ID = got.Options[i].ID
+					want.Options = append(want.Options, option)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("round trip = %+v, want %+v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestQTIItemElementUnknownType(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.QuizQuestion
+		wantErr  string
+	}{
+		{"unknown type", models.QuizQuestion{Position: 1, QuestionType: "essay"}, `question 1 has unknown type "essay"`},
+		{"true/false without answer", models.QuizQuestion{Position: 2, QuestionType: models.QuestionTypeTrueFalse}, "question 2 has no correct answer"},
+		{"numeric without answer", models.QuizQuestion{Position: 3, QuestionType: models.QuestionTypeNumeric}, "question 3 has no correct answer"},
+		{"short answer without answers", models.QuizQuestion{Position: 4, QuestionType: models.QuestionTypeShortAnswer}, "question 4 has no accepted answers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := qtiItemElement(tt.question, "ITEM-1", "", false, false)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsTrueFalseChoice(t *testing.T) {
+	tests := []struct {
+		ids  []string
+		want bool
+	}{
+		{[]string{"true", "false"}, true},
+		{[]string{"FALSE", "True"}, true},
+		{[]string{"true", "true"}, false},
+		{[]string{"true"}, false},
+		{[]string{"true", "false", "maybe"}, false},
+		{[]string{"yes", "no"}, false},
+	}
+
+	for _, tt := range tests {
+		var options []models.QuizOption
+		for _, id := range tt.ids {
+			options = append(options, models.QuizOption{ID: id})
+		}
+		if got := isTrueFalseChoice(options); got != tt.want {
+			t.Errorf("isTrueFalseChoice(%q) = %v, want %v", tt.ids, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesPartialCredit(t *testing.T) {
+	isCorrect := map[string]bool{"A": true, "B": true}
+
+	tests := []struct {
+		name      string
+		mapped    map[string]float64
+		isCorrect map[string]bool
+		want      bool
+	}{
+		{"equal shares", map[string]float64{"A": 2, "B": 2, "C": -2}, isCorrect, true},
+		{"correct choices only", map[string]float64{"A": 2, "B": 2}, isCorrect, true},
+		{"unequal shares", map[string]float64{"A": 3, "B": 1}, isCorrect, false},
+		{"wrong choice not penalized", map[string]float64{"A": 2, "B": 2, "C": 0}, isCorrect, false},
+		{"no correct choices", map[string]float64{"A": 1}, map[string]bool{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesPartialCredit(tt.mapped, tt.isCorrect, 4); got != tt.want {
+				t.Errorf("matchesPartialCredit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQTIItemPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want float64
+	}{
+		{"default", qtiTestItem(`<responseDeclaration identifier="RESPONSE"/>`), 1},
+		{"MAXSCORE", qtiTestItem(`<responseDeclaration identifier="RESPONSE"/>
+			<outcomeDeclaration identifier="MAXSCORE"><defaultValue><value>5</value></defaultValue></outcomeDeclaration>`), 5},
+		{"SCORE normal maximum", qtiTestItem(`<responseDeclaration identifier="RESPONSE"/>
+			<outcomeDeclaration identifier="SCORE" normalMaximum="3"/>`), 3},
+		{"mapping upper bound", qtiTestItem(`<responseDeclaration identifier="RESPONSE"><mapping upperBound="4"/></responseDeclaration>`), 4},
+		{"zero MAXSCORE falls back", qtiTestItem(`<responseDeclaration identifier="RESPONSE"><mapping upperBound="2"/></responseDeclaration>
+			<outcomeDeclaration identifier="MAXSCORE"><defaultValue><value>0</value></defaultValue></outcomeDeclaration>`), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := parseQTITestXML(t, tt.doc)
+			if got := qtiItemPoints(item, item.find("responsedeclaration")); got != tt.want {
+				t.Errorf("qtiItemPoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
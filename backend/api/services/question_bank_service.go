@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// QuestionBankService handles teachers' question banks
+type QuestionBankService interface {
+	Service
+	CreateBank(ownerID int, name, description string) (*models.QuestionBank, error)
+	GetBanks(ownerID int) ([]models.QuestionBank, error)
+	GetBank(bankID int, tags []string, difficulty string) (*models.QuestionBank, error)
+	UpdateBank(bankID int, name, description string) (*models.QuestionBank, error)
+	DeleteBank(bankID int) error
+	AddQuestion(bankID int, question models.BankQuestion) (*models.BankQuestion, error)
+	UpdateQuestion(bankID, questionID int, question models.BankQuestion) (*models.BankQuestion, error)
+	DeleteQuestion(bankID, questionID int) error
+	IsBankOwner(userID, bankID int) (bool, error)
+}
+
+// QuestionBankServiceImpl implements QuestionBankService
+type QuestionBankServiceImpl struct {
+	*BaseService
+}
+
+// NewQuestionBankService creates a new QuestionBankService
+func NewQuestionBankService(db *gorm.DB) QuestionBankService {
+	return &QuestionBankServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// CreateBank creates an empty question bank
+func (s *QuestionBankServiceImpl) CreateBank(ownerID int, name, description string) (*models.QuestionBank, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("bank name is required")
+	}
+
+	bank := &models.QuestionBank{
+		OwnerID:     ownerID,
+		Name:        name,
+		Description: description,
+	}
+	if err := s.db.Create(bank).Error; err != nil {
+		return nil, fmt.Errorf("failed to create question bank: %w", err)
+	}
+
+	return bank, nil
+}
+
+// GetBanks lists a teacher's question banks with their question counts
+func (s *QuestionBankServiceImpl) GetBanks(ownerID int) ([]models.QuestionBank, error) {
+	var banks []models.QuestionBank
+	if err := s.db.Where("owner_id = ?", ownerID).Order("name ASC").Find(&banks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get question banks: %w", err)
+	}
+
+	var counts []struct {
+		BankID int
+		Count  int
+	}
+	if err := s.db.Model(&models.BankQuestion{}).
+		Select("bank_id, COUNT(*) AS count").
+		Where("bank_id IN (?)", s.db.Model(&models.QuestionBank{}).Select("bank_id").Where("owner_id = ?", ownerID)).
+		Group("bank_id").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count bank questions: %w", err)
+	}
+
+	countByBank := make(map[int]int, len(counts))
+	for _, c := range counts {
+		countByBank[c.BankID] = c.Count
+	}
+	for i := range banks {
+		banks[i].QuestionCount = countByBank[banks[i].BankID]
+	}
+
+	return banks, nil
+}
+
+// GetBank returns a question bank with its questions, optionally filtered by tags and difficulty
+func (s *QuestionBankServiceImpl) GetBank(bankID int, tags []string, difficulty string) (*models.QuestionBank, error) {
+	bank, err := s.getBank(bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &models.QuizPool{
+		BankID:     bankID,
+		Tags:       normalizeTags(tags),
+		Difficulty: strings.ToLower(strings.TrimSpace(difficulty)),
+	}
+	questions, err := poolCandidates(s.db, pool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank questions: %w", err)
+	}
+
+	bank.Questions = questions
+	bank.QuestionCount = len(questions)
+	return bank, nil
+}
+
+// UpdateBank renames a question bank and updates its description
+func (s *QuestionBankServiceImpl) UpdateBank(bankID int, name, description string) (*models.QuestionBank, error) {
+	bank, err := s.getBank(bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("bank name is required")
+	}
+
+	bank.Name = name
+	bank.Description = description
+	if err := s.db.Save(bank).Error; err != nil {
+		return nil, fmt.Errorf("failed to update question bank: %w", err)
+	}
+
+	return bank, nil
+}
+
+// DeleteBank deletes a question bank and its questions. Banks used by quizzes cannot be deleted.
+func (s *QuestionBankServiceImpl) DeleteBank(bankID int) error {
+	if _, err := s.getBank(bankID); err != nil {
+		return err
+	}
+
+	var used int64
+	if err := s.db.Model(&models.QuizPool{}).Where("bank_id = ?", bankID).Count(&used).Error; err != nil {
+		return fmt.Errorf("failed to check question bank usage: %w", err)
+	}
+	if used > 0 {
+		return fmt.Errorf("question bank is used by %d quiz pool(s)", used)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bank_id = ?", bankID).Delete(&models.BankQuestion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.QuestionBank{}, bankID).Error
+	})
+}
+
+// AddQuestion adds a question to a question bank
+func (s *QuestionBankServiceImpl) AddQuestion(bankID int, question models.BankQuestion) (*models.BankQuestion, error) {
+	if _, err := s.getBank(bankID); err != nil {
+		return nil, err
+	}
+
+	if err := normalizeBankQuestion(&question); err != nil {
+		return nil, err
+	}
+
+	question.BankQuestionID = 0
+	question.BankID = bankID
+	if err := s.db.Create(&question).Error; err != nil {
+		return nil, fmt.Errorf("failed to add question: %w", err)
+	}
+
+	return &question, nil
+}
+
+// UpdateQuestion replaces a question in a question bank. Questions already
+// drawn into quizzes are copies and do not change.
+func (s *QuestionBankServiceImpl) UpdateQuestion(bankID, questionID int, question models.BankQuestion) (*models.BankQuestion, error) {
+	var existing models.BankQuestion
+	if err := s.db.Where("bank_question_id = ? AND bank_id = ?", questionID, bankID).First(&existing).Error; err != nil {
+		return nil, fmt.Errorf("question not found: %w", err)
+	}
+
+	if err := normalizeBankQuestion(&question); err != nil {
+		return nil, err
+	}
+
+	question.BankQuestionID = existing.BankQuestionID
+	question.BankID = bankID
+	question.CreatedAt = existing.CreatedAt
+	if err := s.db.Save(&question).Error; err != nil {
+		return nil, fmt.Errorf("failed to update question: %w", err)
+	}
+
+	return &question, nil
+}
+
+// DeleteQuestion removes a question from a question bank
+func (s *QuestionBankServiceImpl) DeleteQuestion(bankID, questionID int) error {
+	result := s.db.Where("bank_question_id = ? AND bank_id = ?", questionID, bankID).Delete(&models.BankQuestion{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete question: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("question not found")
+	}
+	return nil
+}
+
+// IsBankOwner checks if a user owns a question bank
+func (s *QuestionBankServiceImpl) IsBankOwner(userID, bankID int) (bool, error) {
+	bank, err := s.getBank(bankID)
+	if err != nil {
+		return false, err
+	}
+	return bank.OwnerID == userID, nil
+}
+
+// getBank retrieves a question bank by ID
+func (s *QuestionBankServiceImpl) getBank(bankID int) (*models.QuestionBank, error) {
+	var bank models.QuestionBank
+	if err := s.db.First(&bank, bankID).Error; err != nil {
+		return nil, fmt.Errorf("question bank not found: %w", err)
+	}
+	return &bank, nil
+}
+
+// normalizeBankQuestion validates a bank question the same way as a quiz
+// question and normalizes its tags and difficulty
+func normalizeBankQuestion(question *models.BankQuestion) error {
+	quizQuestion := question.QuizQuestion()
+	if err := normalizeQuizQuestion(&quizQuestion); err != nil {
+		return err
+	}
+
+	question.Prompt = quizQuestion.Prompt
+	question.Points = quizQuestion.Points
+	question.Options = quizQuestion.Options
+	question.CorrectAnswers = quizQuestion.CorrectAnswers
+	question.Tags = normalizeTags(question.Tags)
+
+	question.Difficulty = strings.ToLower(strings.TrimSpace(question.Difficulty))
+	if question.Difficulty == "" {
+		question.Difficulty = models.DifficultyMedium
+	}
+	if !validDifficulty(question.Difficulty) {
+		return fmt.Errorf("unknown difficulty %q", question.Difficulty)
+	}
+
+	return nil
+}
+
+// normalizeTags trims and lowercases tags and removes empty and duplicate tags
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// validDifficulty reports whether a difficulty is one of the known levels
+func validDifficulty(difficulty string) bool {
+	switch difficulty {
+	case models.DifficultyEasy, models.DifficultyMedium, models.DifficultyHard:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,1036 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// quizSubmitGrace is how long after the time limit a submission is still accepted,
+// to allow for network delays when the browser submits at the last second
+const quizSubmitGrace = 30 * time.Second
+
+// ErrQuizHasAttempts is returned when changing the questions of a quiz that
+// students have already started, whose attempts are scored against them
+var ErrQuizHasAttempts = errors.New("questions cannot be changed once students have started the quiz")
+
+// errAttemptSubmitted is returned when an attempt has already been submitted
+var errAttemptSubmitted = errors.New("attempt has already been submitted")
+
+// QuizService handles quiz questions, attempts and automatic scoring
+type QuizService interface {
+	Service
+	SaveQuiz(classID, assignmentID int, settings models.QuizSettings, questions []models.QuizQuestion) (*models.QuizResponse, error)
+	GetQuiz(classID, assignmentID int, includeQuestions bool) (*models.QuizResponse, error)
+	StartAttempt(classID, assignmentID, studentID int) (*models.QuizAttempt, error)
+	SaveAnswers(classID, assignmentID, attemptID, studentID int, answers map[int]models.QuizAnswer) (*models.QuizAttempt, error)
+	SubmitAttempt(classID, assignmentID, attemptID, studentID int, answers map[int]models.QuizAnswer) (*models.QuizAttempt, error)
+	GetAttempts(classID, assignmentID int, studentID *int) ([]models.QuizAttempt, error)
+	SavePools(classID, assignmentID int, ownerID *int, pools []models.QuizPool) (*models.QuizResponse, error)
+	GetStudentQuestions(classID, assignmentID, studentID int) ([]models.QuizQuestion, error)
+}
+
+// QuizServiceImpl implements QuizService
+type QuizServiceImpl struct {
+	*BaseService
+}
+
+// NewQuizService creates a new QuizService
+func NewQuizService(db *gorm.DB) QuizService {
+	return &QuizServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// SaveQuiz turns an assignment into a quiz and replaces its settings and
+// questions. Once students have started the quiz only the settings can
+// change; the questions must be sent unchanged.
+func (s *QuizServiceImpl) SaveQuiz(classID, assignmentID int, settings models.QuizSettings, questions []models.QuizQuestion) (*models.QuizResponse, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.TimeLimitMinutes != nil && *settings.TimeLimitMinutes <= 0 {
+		return nil, errors.New("time limit must be a positive number of minutes")
+	}
+	if settings.MaxAttempts != nil && *settings.MaxAttempts <= 0 {
+		return nil, errors.New("max attempts must be a positive number")
+	}
+	pools, err := s.getPools(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 && len(pools) == 0 {
+		return nil, errors.New("a quiz needs at least one question")
+	}
+	for i := range questions {
+		if err := normalizeQuizQuestion(&questions[i]); err != nil {
+			return nil, fmt.Errorf("question %d: %w", i+1, err)
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Archived classes are read-only
+		if err := ensureClassWritable(tx, classID); err != nil {
+			return err
+		}
+		attempts, err := lockQuiz(tx, assignmentID)
+		if err != nil {
+			return err
+		}
+		if attempts > 0 {
+			existing, err := getQuizQuestions(tx, assignmentID, nil)
+			if err != nil {
+				return err
+			}
+			if !sameQuizQuestions(existing, questions) {
+				return ErrQuizHasAttempts
+			}
+			questions = existing
+		} else if err := replaceQuizQuestions(tx, assignmentID, questions); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Assignment{}).
+			Where("assignment_id = ?", assignmentID).
+			Updates(map[string]interface{}{
+				"assignment_type":    models.AssignmentTypeQuiz,
+				"time_limit_minutes": settings.TimeLimitMinutes,
+				"max_attempts":       settings.MaxAttempts,
+				"shuffle_questions":  settings.ShuffleQuestions,
+				"shuffle_options":    settings.ShuffleOptions,
+			}).Error
+	})
+	if errors.Is(err, ErrQuizHasAttempts) || errors.Is(err, ErrClassArchived) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save quiz: %w", err)
+	}
+
+	assignment.TimeLimitMinutes = settings.TimeLimitMinutes
+	assignment.MaxAttempts = settings.MaxAttempts
+	assignment.ShuffleQuestions = settings.ShuffleQuestions
+	assignment.ShuffleOptions = settings.ShuffleOptions
+
+	return buildQuizResponse(assignment, questions, pools, true), nil
+}
+
+// GetQuiz returns a quiz's settings, and its questions with answer keys when includeQuestions is set
+func (s *QuizServiceImpl) GetQuiz(classID, assignmentID int, includeQuestions bool) (*models.QuizResponse, error) {
+	assignment, err := s.getQuizAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := getQuizQuestions(s.db, assignmentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pools, err := s.getPools(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildQuizResponse(assignment, questions, pools, includeQuestions), nil
+}
+
+// StartAttempt starts a new attempt, or returns the attempt the student
+// already has in progress. Questions are returned without answer keys.
+func (s *QuizServiceImpl) StartAttempt(classID, assignmentID, studentID int) (*models.QuizAttempt, error) {
+	assignment, err := s.getQuizAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanSubmit(s.db, assignment, studentID); err != nil {
+		return nil, err
+	}
+
+	var attempt *models.QuizAttempt
+	var questions []models.QuizQuestion
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Questions cannot be replaced while an attempt is being started
+		if _, err := lockQuiz(tx, assignmentID); err != nil {
+			return err
+		}
+		if err := drawPoolQuestions(tx, assignmentID, studentID); err != nil {
+			return err
+		}
+
+		var err error
+		if questions, err = getQuizQuestions(tx, assignmentID, &studentID); err != nil {
+			return err
+		}
+		if len(questions) == 0 {
+			return errors.New("quiz has no questions")
+		}
+
+		var attempts []models.QuizAttempt
+		if err := tx.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+			Order("attempt_number ASC").
+			Find(&attempts).Error; err != nil {
+			return err
+		}
+
+		// Resume the attempt in progress, or close it if its time is up
+		for i := range attempts {
+			if attempts[i].SubmittedAt != nil {
+				continue
+			}
+			if !attemptExpired(&attempts[i], time.Now()) {
+				attempt = &attempts[i]
+				return nil
+			}
+			if err := s.finalizeAttempt(tx, assignment, &attempts[i], questions); err != nil && !errors.Is(err, errAttemptSubmitted) {
+				return err
+			}
+		}
+
+		if assignment.MaxAttempts != nil && len(attempts) >= *assignment.MaxAttempts {
+			return errors.New("no attempts left for this quiz")
+		}
+
+		timeLimit, err := quizTimeLimit(tx, assignment, studentID)
+		if err != nil {
+			return err
+		}
+
+		attempt = newQuizAttempt(assignment, studentID, len(attempts)+1, questions, timeLimit)
+		return tx.Create(attempt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.Questions = attemptQuestions(attempt, questions)
+	return attempt, nil
+}
+
+// SaveAnswers stores the answers of an attempt in progress without submitting it
+func (s *QuizServiceImpl) SaveAnswers(classID, assignmentID, attemptID, studentID int, answers map[int]models.QuizAnswer) (*models.QuizAttempt, error) {
+	if _, err := s.getQuizAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.getAttempt(assignmentID, attemptID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.SubmittedAt != nil {
+		return nil, errAttemptSubmitted
+	}
+	if attemptExpired(attempt, time.Now()) {
+		return nil, errors.New("the time limit for this attempt has passed")
+	}
+
+	mergeQuizAnswers(attempt, answers)
+	// The attempt may have been submitted since it was read
+	result := s.db.Model(attempt).Where("submitted_at IS NULL").Update("answers", attempt.Answers)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to save answers: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errAttemptSubmitted
+	}
+
+	return attempt, nil
+}
+
+// SubmitAttempt submits an attempt and scores it. The best attempt is written
+// into the student's submission unless a teacher has overridden the grade.
+func (s *QuizServiceImpl) SubmitAttempt(classID, assignmentID, attemptID, studentID int, answers map[int]models.QuizAnswer) (*models.QuizAttempt, error) {
+	assignment, err := s.getQuizAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.getAttempt(assignmentID, attemptID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.SubmittedAt != nil {
+		return nil, errAttemptSubmitted
+	}
+	if err := checkCanSubmit(s.db, assignment, studentID); err != nil {
+		return nil, err
+	}
+
+	questions, err := getQuizQuestions(s.db, assignmentID, &studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Answers sent after the time limit are ignored; the saved answers are scored instead
+	if !attemptExpired(attempt, time.Now()) {
+		mergeQuizAnswers(attempt, answers)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return s.finalizeAttempt(tx, assignment, attempt, questions)
+	})
+	if errors.Is(err, errAttemptSubmitted) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit attempt: %w", err)
+	}
+
+	return attempt, nil
+}
+
+// GetAttempts lists the attempts at a quiz, optionally for a single student.
+// Attempts whose time is up are submitted first.
+func (s *QuizServiceImpl) GetAttempts(classID, assignmentID int, studentID *int) ([]models.QuizAttempt, error) {
+	assignment, err := s.getQuizAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("assignment_id = ?", assignmentID)
+	if studentID != nil {
+		query = query.Where("student_id = ?", *studentID)
+	}
+
+	var attempts []models.QuizAttempt
+	if err := query.Order("student_id ASC, attempt_number ASC").Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attempts: %w", err)
+	}
+
+	// Students can have different questions drawn from question banks
+	questionsByStudent := make(map[int][]models.QuizQuestion)
+	now := time.Now()
+	for i := range attempts {
+		if attempts[i].SubmittedAt != nil || !attemptExpired(&attempts[i], now) {
+			continue
+		}
+		attemptStudentID := attempts[i].StudentID
+		questions, ok := questionsByStudent[attemptStudentID]
+		if !ok {
+			if questions, err = getQuizQuestions(s.db, assignmentID, &attemptStudentID); err != nil {
+				return nil, err
+			}
+			questionsByStudent[attemptStudentID] = questions
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			return s.finalizeAttempt(tx, assignment, &attempts[i], questions)
+		})
+		// An attempt submitted meanwhile has been reloaded
+		if err != nil && !errors.Is(err, errAttemptSubmitted) {
+			return nil, fmt.Errorf("failed to submit expired attempt: %w", err)
+		}
+	}
+
+	return attempts, nil
+}
+
+// SavePools replaces the question bank pools of a quiz. When ownerID is set,
+// only banks owned by that user can be used. Students who already have
+// questions drawn keep them, so their attempts can still be reviewed.
+func (s *QuizServiceImpl) SavePools(classID, assignmentID int, ownerID *int, pools []models.QuizPool) (*models.QuizResponse, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	// Every student must get the full draw count of every pool, which pools
+	// sharing questions cannot promise, so a question may match one pool only
+	claimedBy := make(map[int]int)
+	for i := range pools {
+		pool := &pools[i]
+
+		var bank models.QuestionBank
+		if err := s.db.First(&bank, pool.BankID).Error; err != nil {
+			return nil, fmt.Errorf("pool %d: question bank not found: %w", i+1, err)
+		}
+		if ownerID != nil && bank.OwnerID != *ownerID {
+			return nil, fmt.Errorf("pool %d: question bank %q belongs to another teacher", i+1, bank.Name)
+		}
+		if pool.DrawCount <= 0 {
+			return nil, fmt.Errorf("pool %d: draw count must be a positive number", i+1)
+		}
+		if pool.PointsPerQuestion != nil && *pool.PointsPerQuestion <= 0 {
+			return nil, fmt.Errorf("pool %d: points per question must be a positive number", i+1)
+		}
+		pool.Tags = normalizeTags(pool.Tags)
+		pool.Difficulty = strings.ToLower(strings.TrimSpace(pool.Difficulty))
+		if pool.Difficulty != "" && !validDifficulty(pool.Difficulty) {
+			return nil, fmt.Errorf("pool %d: unknown difficulty %q", i+1, pool.Difficulty)
+		}
+
+		candidates, err := poolCandidates(s.db, pool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bank questions: %w", err)
+		}
+		if len(candidates) < pool.DrawCount {
+			return nil, fmt.Errorf("pool %d: question bank %q has only %d matching questions", i+1, bank.Name, len(candidates))
+		}
+		for _, candidate := range candidates {
+			if other, ok := claimedBy[candidate.BankQuestionID]; ok {
+				return nil, fmt.Errorf("pool %d: shares questions with pool %d, pools must not overlap", i+1, other+1)
+			}
+			claimedBy[candidate.BankQuestionID] = i
+		}
+	}
+
+	questions, err := getQuizQuestions(s.db, assignmentID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 && len(pools) == 0 {
+		return nil, errors.New("a quiz needs at least one question")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("assignment_id = ?", assignmentID).Delete(&models.QuizPool{}).Error; err != nil {
+			return err
+		}
+		for i := range pools {
+			pools[i].PoolID = 0
+			pools[i].AssignmentID = assignmentID
+			if err := tx.Create(&pools[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Assignment{}).
+			Where("assignment_id = ?", assignmentID).
+			Update("assignment_type", models.AssignmentTypeQuiz).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save question pools: %w", err)
+	}
+
+	return buildQuizResponse(assignment, questions, pools, true), nil
+}
+
+// GetStudentQuestions returns the questions a student gets, including the
+// questions drawn for them from question banks, with answer keys
+func (s *QuizServiceImpl) GetStudentQuestions(classID, assignmentID, studentID int) ([]models.QuizQuestion, error) {
+	if _, err := s.getQuizAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	return getQuizQuestions(s.db, assignmentID, &studentID)
+}
+
+// finalizeAttempt scores an attempt, marks it submitted and updates the student's grade.
+// The attempt row is locked first; when it has been submitted in the meantime
+// the attempt is reloaded and errAttemptSubmitted is returned.
+func (s *QuizServiceImpl) finalizeAttempt(tx *gorm.DB, assignment *models.Assignment, attempt *models.QuizAttempt, questions []models.QuizQuestion) error {
+	if err := tx.Exec("SELECT attempt_id FROM quiz_attempts WITH (UPDLOCK, HOLDLOCK) WHERE attempt_id = ?", attempt.AttemptID).Error; err != nil {
+		return err
+	}
+	var current models.QuizAttempt
+	if err := tx.Where("attempt_id = ?", attempt.AttemptID).First(&current).Error; err != nil {
+		return err
+	}
+	if current.SubmittedAt != nil {
+		*attempt = current
+		return errAttemptSubmitted
+	}
+
+	now := time.Now()
+	submittedAt := now
+	if attempt.ExpiresAt != nil && now.After(*attempt.ExpiresAt) {
+		submittedAt = *attempt.ExpiresAt
+	}
+
+	results := make(map[int]float64, len(questions))
+	var score, maxScore float64
+	for _, question := range questions {
+		earned := scoreQuizQuestion(question, attempt.Answers[question.QuestionID])
+		results[question.QuestionID] = earned
+		score += earned
+		maxScore += question.Points
+	}
+
+	attempt.SubmittedAt = &submittedAt
+	attempt.Results = results
+	attempt.Score = &score
+	attempt.MaxScore = maxScore
+
+	if err := tx.Save(attempt).Error; err != nil {
+		return err
+	}
+
+	return recordQuizGrade(tx, assignment, attempt.StudentID)
+}
+
+// recordQuizGrade writes the student's best attempt into their submission,
+// scaled to the assignment's points. A grade set by a teacher is kept.
+func recordQuizGrade(tx *gorm.DB, assignment *models.Assignment, studentID int) error {
+	var attempts []models.QuizAttempt
+	if err := tx.Where("assignment_id = ? AND student_id = ? AND submitted_at IS NOT NULL", assignment.AssignmentID, studentID).
+		Order("attempt_number ASC").
+		Find(&attempts).Error; err != nil {
+		return err
+	}
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	best := attempts[0]
+	last := attempts[0]
+	for _, attempt := range attempts[1:] {
+		if attempt.Score != nil && (best.Score == nil || *attempt.Score > *best.Score) {
+			best = attempt
+		}
+		if attempt.SubmittedAt.After(*last.SubmittedAt) {
+			last = attempt
+		}
+	}
+
+	var submission models.Submission
+	err := tx.Where("assignment_id = ? AND user_id = ?", assignment.AssignmentID, studentID).First(&submission).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	submission.AssignmentID = assignment.AssignmentID
+	submission.StudentID = studentID
+	submission.SubmissionDate = *last.SubmittedAt
+	dueDate, err := effectiveDueDate(tx, assignment, studentID)
+	if err != nil {
+		return err
+	}
+	submission.IsLate = isLateFor(dueDate, *last.SubmittedAt)
+	submission.Content = fmt.Sprintf("Quiz: best of %d attempt(s), %s/%s points",
+		len(attempts), formatQuizPoints(*best.Score), formatQuizPoints(best.MaxScore))
+
+	// Keep a grade a teacher set through GradeSubmission
+	if submission.GradedBy == nil {
+		grade := 0
+		if best.MaxScore > 0 {
+			grade = int(math.Round(*best.Score / best.MaxScore * float64(assignment.PointsPossible)))
+		}
+		gradedDate := time.Now()
+		submission.Grade = &grade
+		submission.GradedDate = &gradedDate
+		submission.Status = "graded"
+		submission.GradeReleased = false // Draft until the teacher releases the assignment's grades
+	}
+
+	return tx.Save(&submission).Error
+}
+
+// getAssignment retrieves an assignment of a class
+func (s *QuizServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// getQuizAssignment retrieves an assignment of a class and checks that it is a quiz
+func (s *QuizServiceImpl) getQuizAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.AssignmentType != models.AssignmentTypeQuiz {
+		return nil, errors.New("assignment is not a quiz")
+	}
+	return assignment, nil
+}
+
+// getPools retrieves the question bank pools of a quiz
+func (s *QuizServiceImpl) getPools(assignmentID int) ([]models.QuizPool, error) {
+	var pools []models.QuizPool
+	if err := s.db.Where("assignment_id = ?", assignmentID).Order("pool_id ASC").Find(&pools).Error; err != nil {
+		return nil, fmt.Errorf("failed to get question pools: %w", err)
+	}
+	return pools, nil
+}
+
+// getQuizQuestions retrieves the questions of a quiz in order. Without a
+// student only the questions shared by all students are returned; with a
+// student the questions drawn for them follow the shared questions.
+func getQuizQuestions(db *gorm.DB, assignmentID int, studentID *int) ([]models.QuizQuestion, error) {
+	query := db.Where("assignment_id = ?", assignmentID)
+	if studentID != nil {
+		query = query.Where("(student_id IS NULL OR student_id = ?)", *studentID)
+	} else {
+		query = query.Where("student_id IS NULL")
+	}
+
+	var questions []models.QuizQuestion
+	if err := query.Order("student_id ASC, position ASC").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get questions: %w", err)
+	}
+	return questions, nil
+}
+
+// getAttempt retrieves a student's attempt at a quiz
+func (s *QuizServiceImpl) getAttempt(assignmentID, attemptID, studentID int) (*models.QuizAttempt, error) {
+	var attempt models.QuizAttempt
+	if err := s.db.Where("attempt_id = ? AND assignment_id = ? AND student_id = ?", attemptID, assignmentID, studentID).
+		First(&attempt).Error; err != nil {
+		return nil, fmt.Errorf("attempt not found: %w", err)
+	}
+	return &attempt, nil
+}
+
+// lockQuiz locks the assignment row of a quiz until the transaction ends and
+// returns the number of attempts at the quiz. Changing the questions and
+// starting an attempt both take the lock, so neither can race the other.
+func lockQuiz(tx *gorm.DB, assignmentID int) (int64, error) {
+	if err := tx.Exec("SELECT assignment_id FROM assignments WITH (UPDLOCK, HOLDLOCK) WHERE assignment_id = ?", assignmentID).Error; err != nil {
+		return 0, err
+	}
+
+	var attempts int64
+	if err := tx.Model(&models.QuizAttempt{}).Where("assignment_id = ?", assignmentID).Count(&attempts).Error; err != nil {
+		return 0, err
+	}
+	return attempts, nil
+}
+
+// sameQuizQuestions reports whether saving questions would leave the
+// existing questions of a quiz as they are. Both must be normalized.
+func sameQuizQuestions(existing, questions []models.QuizQuestion) bool {
+	if len(existing) != len(questions) {
+		return false
+	}
+	for i := range existing {
+		a, b := existing[i], questions[i]
+		if a.QuestionType != b.QuestionType || a.Prompt != b.Prompt || a.Points != b.Points ||
+			a.Tolerance != b.Tolerance || a.CaseSensitive != b.CaseSensitive ||
+			len(a.Options) != len(b.Options) || len(a.CorrectAnswers) != len(b.CorrectAnswers) {
+			return false
+		}
+		for j := range a.Options {
+			if a.Options[j] != b.Options[j] {
+				return false
+			}
+		}
+		for j := range a.CorrectAnswers {
+			if a.CorrectAnswers[j] != b.CorrectAnswers[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// replaceQuizQuestions deletes the shared questions of a quiz and inserts new ones in order.
+// Questions already drawn for students from question banks are kept.
+func replaceQuizQuestions(tx *gorm.DB, assignmentID int, questions []models.QuizQuestion) error {
+	if err := tx.Where("assignment_id = ? AND student_id IS NULL", assignmentID).Delete(&models.QuizQuestion{}).Error; err != nil {
+		return err
+	}
+
+	for i := range questions {
+		questions[i].QuestionID = 0
+		questions[i].AssignmentID = assignmentID
+		questions[i].Position = i + 1
+		questions[i].StudentID = nil
+		questions[i].PoolID = nil
+		questions[i].BankQuestionID = nil
+		if err := tx.Create(&questions[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildQuizResponse describes a quiz, with or without its questions and pools
+func buildQuizResponse(assignment *models.Assignment, questions []models.QuizQuestion, pools []models.QuizPool, includeQuestions bool) *models.QuizResponse {
+	response := &models.QuizResponse{
+		AssignmentID:  assignment.AssignmentID,
+		Settings:      assignment.QuizSettings(),
+		QuestionCount: len(questions),
+	}
+	for _, question := range questions {
+		response.TotalPoints += question.Points
+	}
+	for _, pool := range pools {
+		response.QuestionCount += pool.DrawCount
+		if pool.PointsPerQuestion != nil {
+			response.TotalPoints += float64(pool.DrawCount) * *pool.PointsPerQuestion
+		}
+	}
+	if includeQuestions {
+		response.Questions = questions
+		response.Pools = pools
+	}
+	return response
+}
+
+// drawPoolQuestions draws a student's questions from the pools of a quiz.
+// Questions are copied from the bank so later edits to the bank do not change
+// what the student answered. Nothing is drawn if the student already has a draw.
+func drawPoolQuestions(tx *gorm.DB, assignmentID, studentID int) error {
+	var pools []models.QuizPool
+	if err := tx.Where("assignment_id = ?", assignmentID).Order("pool_id ASC").Find(&pools).Error; err != nil {
+		return err
+	}
+	if len(pools) == 0 {
+		return nil
+	}
+
+	var drawn int64
+	if err := tx.Model(&models.QuizQuestion{}).
+		Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Count(&drawn).Error; err != nil {
+		return err
+	}
+	if drawn > 0 {
+		return nil
+	}
+
+	// A bank question is drawn at most once, even when questions added to a
+	// bank since the pools were saved make them overlap
+	used := make(map[int]bool)
+	position := 0
+	for _, pool := range pools {
+		candidates, err := poolCandidates(tx, &pool)
+		if err != nil {
+			return err
+		}
+
+		available := candidates[:0]
+		for _, candidate := range candidates {
+			if !used[candidate.BankQuestionID] {
+				available = append(available, candidate)
+			}
+		}
+		rand.Shuffle(len(available), func(i, j int) { available[i], available[j] = available[j], available[i] })
+		if len(available) > pool.DrawCount {
+			available = available[:pool.DrawCount]
+		}
+
+		for _, bankQuestion := range available {
+			used[bankQuestion.BankQuestionID] = true
+			position++
+
+			poolID, bankQuestionID, drawnFor := pool.PoolID, bankQuestion.BankQuestionID, studentID
+			question := bankQuestion.QuizQuestion()
+			question.AssignmentID = assignmentID
+			question.Position = position
+			question.StudentID = &drawnFor
+			question.PoolID = &poolID
+			question.BankQuestionID = &bankQuestionID
+			if pool.PointsPerQuestion != nil {
+				question.Points = *pool.PointsPerQuestion
+			}
+			if err := tx.Create(&question).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// poolCandidates returns the bank questions that match a pool's tags and difficulty
+func poolCandidates(db *gorm.DB, pool *models.QuizPool) ([]models.BankQuestion, error) {
+	query := db.Where("bank_id = ?", pool.BankID)
+	if pool.Difficulty != "" {
+		query = query.Where("difficulty = ?", pool.Difficulty)
+	}
+
+	var questions []models.BankQuestion
+	if err := query.Order("bank_question_id ASC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+
+	matching := questions[:0]
+	for _, question := range questions {
+		if question.HasTags(pool.Tags) {
+			matching = append(matching, question)
+		}
+	}
+	return matching, nil
+}
+
+// newQuizAttempt creates an attempt with its own question and option order.
+// A nil time limit leaves the attempt untimed.
+func newQuizAttempt(assignment *models.Assignment, studentID, attemptNumber int, questions []models.QuizQuestion, timeLimit *time.Duration) *models.QuizAttempt {
+	now := time.Now()
+	attempt := &models.QuizAttempt{
+		AssignmentID:  assignment.AssignmentID,
+		StudentID:     studentID,
+		AttemptNumber: attemptNumber,
+		StartedAt:     now,
+		Answers:       map[int]models.QuizAnswer{},
+		OptionOrder:   map[int][]string{},
+	}
+
+	if timeLimit != nil {
+		expiresAt := now.Add(*timeLimit)
+		attempt.ExpiresAt = &expiresAt
+	}
+
+	for _, question := range questions {
+		attempt.QuestionOrder = append(attempt.QuestionOrder, question.QuestionID)
+		attempt.MaxScore += question.Points
+
+		if assignment.ShuffleOptions && len(question.Options) > 0 {
+			optionIDs := make([]string, 0, len(question.Options))
+			for _, option := range question.Options {
+				optionIDs = append(optionIDs, option.ID)
+			}
+			rand.Shuffle(len(optionIDs), func(i, j int) { optionIDs[i], optionIDs[j] = optionIDs[j], optionIDs[i] })
+			attempt.OptionOrder[question.QuestionID] = optionIDs
+		}
+	}
+
+	if assignment.ShuffleQuestions {
+		rand.Shuffle(len(attempt.QuestionOrder), func(i, j int) {
+			attempt.QuestionOrder[i], attempt.QuestionOrder[j] = attempt.QuestionOrder[j], attempt.QuestionOrder[i]
+		})
+	}
+
+	return attempt
+}
+
+// attemptQuestions returns the questions of an attempt in the order the student sees them, without answer keys
+func attemptQuestions(attempt *models.QuizAttempt, questions []models.QuizQuestion) []models.QuizQuestion {
+	byID := make(map[int]models.QuizQuestion, len(questions))
+	for _, question := range questions {
+		byID[question.QuestionID] = question
+	}
+
+	ordered := make([]models.QuizQuestion, 0, len(attempt.QuestionOrder))
+	for _, questionID := range attempt.QuestionOrder {
+		question, ok := byID[questionID]
+		if !ok {
+			continue
+		}
+		stripped := question.ForStudent()
+
+		if optionOrder, ok := attempt.OptionOrder[questionID]; ok {
+			optionsByID := make(map[string]models.QuizOption, len(stripped.Options))
+			for _, option := range stripped.Options {
+				optionsByID[option.ID] = option
+			}
+			stripped.Options = stripped.Options[:0:0]
+			for _, optionID := range optionOrder {
+				if option, ok := optionsByID[optionID]; ok {
+					stripped.Options = append(stripped.Options, option)
+				}
+			}
+		}
+
+		ordered = append(ordered, stripped)
+	}
+
+	return ordered
+}
+
+// attemptExpired reports whether an attempt's time limit, including the grace period, has passed
+func attemptExpired(attempt *models.QuizAttempt, now time.Time) bool {
+	return attempt.ExpiresAt != nil && now.After(attempt.ExpiresAt.Add(quizSubmitGrace))
+}
+
+// mergeQuizAnswers adds answers to an attempt, replacing earlier answers to the same questions
+func mergeQuizAnswers(attempt *models.QuizAttempt, answers map[int]models.QuizAnswer) {
+	if attempt.Answers == nil {
+		attempt.Answers = map[int]models.QuizAnswer{}
+	}
+	for questionID, answer := range answers {
+		attempt.Answers[questionID] = answer
+	}
+}
+
+// normalizeQuizQuestion validates a question and fills in defaults
+func normalizeQuizQuestion(question *models.QuizQuestion) error {
+	question.Prompt = strings.TrimSpace(question.Prompt)
+	if question.Prompt == "" {
+		return errors.New("prompt is required")
+	}
+	if question.Points < 0 {
+		return errors.New("points must not be negative")
+	}
+	if question.Points == 0 {
+		question.Points = 1
+	}
+
+	switch question.QuestionType {
+	case models.QuestionTypeMultipleChoice, models.QuestionTypeMultiSelect:
+		if len(question.Options) < 2 {
+			return errors.New("at least two options are required")
+		}
+		seen := make(map[string]bool, len(question.Options))
+		correct := 0
+		for i := range question.Options {
+			if question.Options[i].ID == "" {
+				question.Options[i].ID = strconv.Itoa(i + 1)
+			}
+			if seen[question.Options[i].ID] {
+				return fmt.Errorf("duplicate option id %q", question.Options[i].ID)
+			}
+			seen[question.Options[i].ID] = true
+			if question.Options[i].Correct {
+				correct++
+			}
+		}
+		if question.QuestionType == models.QuestionTypeMultipleChoice && correct != 1 {
+			return errors.New("multiple-choice questions need exactly one correct option")
+		}
+		if question.QuestionType == models.QuestionTypeMultiSelect && correct == 0 {
+			return errors.New("multi-select questions need at least one correct option")
+		}
+		question.CorrectAnswers = nil
+
+	case models.QuestionTypeTrueFalse:
+		if len(question.CorrectAnswers) != 1 {
+			return errors.New("true/false questions need a single correct answer")
+		}
+		answer := strings.ToLower(strings.TrimSpace(question.CorrectAnswers[0]))
+		if answer != "true" && answer != "false" {
+			return errors.New(`the correct answer must be "true" or "false"`)
+		}
+		question.CorrectAnswers = []string{answer}
+		question.Options = nil
+
+	case models.QuestionTypeNumeric:
+		if len(question.CorrectAnswers) != 1 {
+			return errors.New("numeric questions need a single correct answer")
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(question.CorrectAnswers[0]), 64); err != nil {
+			return errors.New("the correct answer must be a number")
+		}
+		if question.Tolerance < 0 {
+			return errors.New("tolerance must not be negative")
+		}
+		question.Options = nil
+
+	case models.QuestionTypeShortAnswer:
+		accepted := question.CorrectAnswers[:0]
+		for _, answer := range question.CorrectAnswers {
+			if answer = strings.TrimSpace(answer); answer != "" {
+				accepted = append(accepted, answer)
+			}
+		}
+		if len(accepted) == 0 {
+			return errors.New("short-answer questions need at least one accepted answer")
+		}
+		question.CorrectAnswers = accepted
+		question.Options = nil
+
+	default:
+		return fmt.Errorf("unknown question type %q", question.QuestionType)
+	}
+
+	return nil
+}
+
+// scoreQuizQuestion returns the points earned for an answer.
+// Multi-select questions give partial credit: each correct option selected
+// earns a share of the points and each incorrect option selected removes one.
+func scoreQuizQuestion(question models.QuizQuestion, answer models.QuizAnswer) float64 {
+	switch question.QuestionType {
+	case models.QuestionTypeMultipleChoice:
+		if len(answer.Selected) != 1 {
+			return 0
+		}
+		for _, option := range question.Options {
+			if option.ID == answer.Selected[0] && option.Correct {
+				return question.Points
+			}
+		}
+		return 0
+
+	case models.QuestionTypeMultiSelect:
+		correctOptions := make(map[string]bool)
+		for _, option := range question.Options {
+			if option.Correct {
+				correctOptions[option.ID] = true
+			}
+		}
+		if len(correctOptions) == 0 {
+			return 0
+		}
+		selected := make(map[string]bool)
+		net := 0
+		for _, optionID := range answer.Selected {
+			if selected[optionID] {
+				continue
+			}
+			selected[optionID] = true
+			if correctOptions[optionID] {
+				net++
+			} else {
+				net--
+			}
+		}
+		if net <= 0 {
+			return 0
+		}
+		return question.Points * float64(net) / float64(len(correctOptions))
+
+	case models.QuestionTypeTrueFalse:
+		if len(question.CorrectAnswers) == 1 && strings.EqualFold(strings.TrimSpace(answer.Value), question.CorrectAnswers[0]) {
+			return question.Points
+		}
+		return 0
+
+	case models.QuestionTypeNumeric:
+		if len(question.CorrectAnswers) != 1 {
+			return 0
+		}
+		expected, err := strconv.ParseFloat(strings.TrimSpace(question.CorrectAnswers[0]), 64)
+		if err != nil {
+			return 0
+		}
+		given, err := strconv.ParseFloat(strings.TrimSpace(answer.Value), 64)
+		if err != nil {
+			return 0
+		}
+		// Allow for floating point error on exact answers
+		if math.Abs(given-expected) <= question.Tolerance+1e-9 {
+			return question.Points
+		}
+		return 0
+
+	case models.QuestionTypeShortAnswer:
+		given := normalizeShortAnswer(answer.Value, question.CaseSensitive)
+		if given == "" {
+			return 0
+		}
+		for _, accepted := range question.CorrectAnswers {
+			if given == normalizeShortAnswer(accepted, question.CaseSensitive) {
+				return question.Points
+			}
+		}
+		return 0
+	}
+
+	return 0
+}
+
+// normalizeShortAnswer trims and collapses whitespace, and lowercases unless case matters
+func normalizeShortAnswer(answer string, caseSensitive bool) string {
+	answer = strings.Join(strings.Fields(answer), " ")
+	if !caseSensitive {
+		answer = strings.ToLower(answer)
+	}
+	return answer
+}
+
+// formatQuizPoints formats a point value without trailing zeros
+func formatQuizPoints(points float64) string {
+	return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+)
+
+func TestScoreQuizQuestion(t *testing.T) {
+	multipleChoice := models.QuizQuestion{
+		QuestionType: models.QuestionTypeMultipleChoice,
+		Points:       2,
+		Options: []models.QuizOption{
+			{ID: "a", Text: "Paris", Correct: true},
+			{ID: "b", Text: "Lyon"},
+		},
+	}
+	// Three correct options of four, worth 3 points: each one is worth 1
+	multiSelect := models.QuizQuestion{
+		QuestionType: models.QuestionTypeMultiSelect,
+		Points:       3,
+		Options: []models.QuizOption{
+			{ID: "a", Correct: true},
+			{ID: "b", Correct: true},
+			{ID: "c", Correct: true},
+			{ID: "d"},
+		},
+	}
+	trueFalse := models.QuizQuestion{
+		QuestionType:   models.QuestionTypeTrueFalse,
+		Points:         1,
+		CorrectAnswers: []string{"true"},
+	}
+	numeric := models.QuizQuestion{
+		QuestionType:   models.QuestionTypeNumeric,
+		Points:         4,
+		CorrectAnswers: []string{"3.14"},
+		Tolerance:      0.01,
+	}
+	exactNumeric := models.QuizQuestion{
+		QuestionType:   models.QuestionTypeNumeric,
+		Points:         1,
+		CorrectAnswers: []string{"0.3"},
+	}
+	shortAnswer := models.QuizQuestion{
+		QuestionType:   models.QuestionTypeShortAnswer,
+		Points:         2,
+		CorrectAnswers: []string{"George Washington", "Washington"},
+	}
+	caseSensitive := shortAnswer
+	caseSensitive.CaseSensitive = true
+
+	tests := []struct {
+		name     string
+		question models.QuizQuestion
+		answer   models.QuizAnswer
+		want     float64
+	}{
+		// Multiple choice
+		{"multiple choice correct", multipleChoice, models.QuizAnswer{Selected: []string{"a"}}, 2},
+		{"multiple choice wrong", multipleChoice, models.QuizAnswer{Selected: []string{"b"}}, 0},
+		{"multiple choice unknown option", multipleChoice, models.QuizAnswer{Selected: []string{"z"}}, 0},
+		{"multiple choice two options", multipleChoice, models.QuizAnswer{Selected: []string{"a", "b"}}, 0},
+		{"multiple choice unanswered", multipleChoice, models.QuizAnswer{}, 0},
+
+		// Multi-select partial credit
+		{"multi-select all correct", multiSelect, models.QuizAnswer{Selected: []string{"a", "b", "c"}}, 3},
+		{"multi-select one of three", multiSelect, models.QuizAnswer{Selected: []string{"a"}}, 1},
+		{"multi-select two of three", multiSelect, models.QuizAnswer{Selected: []string{"c", "a"}}, 2},
+		{"multi-select wrong option cancels a right one", multiSelect, models.QuizAnswer{Selected: []string{"a", "b", "d"}}, 1},
+		{"multi-select everything", multiSelect, models.QuizAnswer{Selected: []string{"a", "b", "c", "d"}}, 2},
+		{"multi-select never negative", multiSelect, models.QuizAnswer{Selected: []string{"a", "d", "x", "y"}}, 0},
+		{"multi-select duplicates count once", multiSelect, models.QuizAnswer{Selected: []string{"a", "a", "a"}}, 1},
+		{"multi-select unanswered", multiSelect, models.QuizAnswer{}, 0},
+		{"multi-select without correct options", models.QuizQuestion{
+			QuestionType: models.QuestionTypeMultiSelect,
+			Points:       3,
+			Options:      []models.QuizOption{{ID: "a"}},
+		}, models.QuizAnswer{Selected: []string{"a"}}, 0},
+
+		// True/false
+		{"true/false correct", trueFalse, models.QuizAnswer{Value: "true"}, 1},
+		{"true/false ignores case and spaces", trueFalse, models.QuizAnswer{Value: " TRUE "}, 1},
+		{"true/false wrong", trueFalse, models.QuizAnswer{Value: "false"}, 0},
+		{"true/false unanswered", trueFalse, models.QuizAnswer{}, 0},
+
+		// Numeric tolerance
+		{"numeric exact", numeric, models.QuizAnswer{Value: "3.14"}, 4},
+		{"numeric within tolerance above", numeric, models.QuizAnswer{Value: "3.149"}, 4},
+		{"numeric within tolerance below", numeric, models.QuizAnswer{Value: "3.131"}, 4},
+		{"numeric on the tolerance edge", numeric, models.QuizAnswer{Value: "3.15"}, 4},
+		{"numeric outside tolerance", numeric, models.QuizAnswer{Value: "3.16"}, 0},
+		{"numeric with spaces", numeric, models.QuizAnswer{Value: " 3.14 "}, 4},
+		{"numeric not a number", numeric, models.QuizAnswer{Value: "pi"}, 0},
+		{"numeric unanswered", numeric, models.QuizAnswer{}, 0},
+		{"numeric without tolerance allows rounding error", exactNumeric, models.QuizAnswer{Value: "0.30000000000000004"}, 1},
+		{"numeric without tolerance", exactNumeric, models.QuizAnswer{Value: "0.31"}, 0},
+		{"numeric with a broken answer key", models.QuizQuestion{
+			QuestionType:   models.QuestionTypeNumeric,
+			Points:         1,
+			CorrectAnswers: []string{"abc"},
+		}, models.QuizAnswer{Value: "1"}, 0},
+
+		// Short answer
+		{"short answer exact", shortAnswer, models.QuizAnswer{Value: "George Washington"}, 2},
+		{"short answer any accepted answer", shortAnswer, models.QuizAnswer{Value: "washington"}, 2},
+		{"short answer collapses spaces", shortAnswer, models.QuizAnswer{Value: "  george   washington "}, 2},
+		{"short answer wrong", shortAnswer, models.QuizAnswer{Value: "Lincoln"}, 0},
+		{"short answer blank", shortAnswer, models.QuizAnswer{Value: "   "}, 0},
+		{"short answer case sensitive match", caseSensitive, models.QuizAnswer{Value: "Washington"}, 2},
+		{"short answer case sensitive mismatch", caseSensitive, models.QuizAnswer{Value: "washington"}, 0},
+
+		{"unknown question type", models.QuizQuestion{QuestionType: "essay", Points: 5}, models.QuizAnswer{Value: "text"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreQuizQuestion(tt.question, tt.answer)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("scoreQuizQuestion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeShortAnswer(t *testing.T) {
+	tests := []struct {
+		answer        string
+		caseSensitive bool
+		want          string
+	}{
+		{"Paris", false, "paris"},
+		{"Paris", true, "Paris"},
+		{"  New \t York\n", false, "new york"},
+		{"", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeShortAnswer(tt.answer, tt.caseSensitive); got != tt.want {
+			t.Errorf("normalizeShortAnswer(%q, %v) = %q, want %q", tt.answer, tt.caseSensitive, got, tt.want)
+		}
+	}
+}
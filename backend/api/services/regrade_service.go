@@ -0,0 +1,438 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// maxRegradeReasonLength is the longest reason or response accepted, in characters
+const maxRegradeReasonLength = 2000
+
+// RegradeService manages students' requests to have their grades reviewed
+type RegradeService interface {
+	Service
+	CreateRequest(classID, assignmentID, studentID int, reason string, criterionIDs []int) (*models.RegradeRequest, error)
+	GetRequests(classID, assignmentID int, status string) ([]models.RegradeRequest, error)
+	GetStudentRequests(classID, assignmentID, studentID int) ([]models.RegradeRequest, error)
+	AcceptRequest(classID, assignmentID, requestID, teacherID int, grade *int, scores []models.RubricScore, response string) (*models.RegradeRequest, error)
+	RejectRequest(classID, assignmentID, requestID, teacherID int, response string) (*models.RegradeRequest, error)
+}
+
+// RegradeServiceImpl implements RegradeService
+type RegradeServiceImpl struct {
+	*BaseService
+}
+
+// NewRegradeService creates a new RegradeService
+func NewRegradeService(db *gorm.DB) RegradeService {
+	return &RegradeServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// CreateRequest opens a regrade request on a student's graded submission.
+// The grade must have been released, and a submission can only have one
+// pending request at a time. The teachers of the class are notified.
+func (s *RegradeServiceImpl) CreateRequest(classID, assignmentID, studentID int, reason string, criterionIDs []int) (*models.RegradeRequest, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	var submission models.Submission
+	if err := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&submission).Error; err != nil {
+		return nil, fmt.Errorf("submission not found: %w", err)
+	}
+	if submission.Grade == nil || !submission.GradeReleased {
+		return nil, errors.New("only released grades can be disputed")
+	}
+
+	reason, err = validateRegradeText(reason, "reason")
+	if err != nil {
+		return nil, err
+	}
+
+	criterionIDs, err = s.validateCriteria(assignment, criterionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending int64
+	if err := s.db.Model(&models.RegradeRequest{}).
+		Where("submission_id = ? AND status = ?", submission.SubmissionID, models.RegradeRequestPending).
+		Count(&pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to check regrade requests: %w", err)
+	}
+	if pending > 0 {
+		return nil, errors.New("a regrade request for this submission is already pending")
+	}
+
+	request := models.RegradeRequest{
+		AssignmentID:  assignmentID,
+		SubmissionID:  submission.SubmissionID,
+		StudentID:     studentID,
+		Reason:        reason,
+		CriterionIDs:  criterionIDs,
+		Status:        models.RegradeRequestPending,
+		OriginalGrade: *submission.Grade,
+	}
+
+	studentName := studentDisplayName(s.db, studentID)
+	if assignment.IdentitiesHidden() {
+		key, err := anonymityKey(s.db, assignment)
+		if err != nil {
+			return nil, err
+		}
+		_, studentName = studentPseudonym(key, studentID)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&request).Error; err != nil {
+			return fmt.Errorf("failed to save regrade request: %w", err)
+		}
+
+		var teacherIDs []int
+		if err := tx.Model(&models.ClassTeacher{}).Where("class_id = ?", classID).
+			Pluck("user_id", &teacherIDs).Error; err != nil {
+			return fmt.Errorf("failed to get class teachers: %w", err)
+		}
+		notifications := make([]models.Notification, 0, len(teacherIDs))
+		for _, teacherID := range teacherIDs {
+			notifications = append(notifications, models.Notification{
+				UserID:       teacherID,
+				Type:         models.NotificationTypeRegradeRequested,
+				Title:        fmt.Sprintf("Regrade requested: %s", assignment.Title),
+				Message:      fmt.Sprintf("%s asked for their grade of %d/%d on %s to be reviewed.", studentName, request.OriginalGrade, assignment.PointsPossible, assignment.Title),
+				ClassID:      &assignment.ClassID,
+				AssignmentID: &assignment.AssignmentID,
+			})
+		}
+		if err := createNotifications(tx, notifications); err != nil {
+			return fmt.Errorf("failed to create notifications: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// GetRequests returns the regrade requests of an assignment, oldest first,
+// optionally only those with the given status. Students are shown by
+// pseudonym while the assignment's identities are hidden.
+func (s *RegradeServiceImpl) GetRequests(classID, assignmentID int, status string) ([]models.RegradeRequest, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("assignment_id = ?", assignmentID)
+	if status != "" {
+		switch status {
+		case models.RegradeRequestPending, models.RegradeRequestAccepted, models.RegradeRequestRejected:
+			query = query.Where("status = ?", status)
+		default:
+			return nil, fmt.Errorf("invalid status %q", status)
+		}
+	}
+
+	var requests []models.RegradeRequest
+	if err := query.Order("created_at, request_id").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get regrade requests: %w", err)
+	}
+
+	var key string
+	if assignment.IdentitiesHidden() {
+		if key, err = anonymityKey(s.db, assignment); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range requests {
+		if key != "" {
+			requests[i].AnonymousID, requests[i].StudentName = studentPseudonym(key, requests[i].StudentID)
+			requests[i].StudentID = 0
+		} else {
+			requests[i].StudentName = studentDisplayName(s.db, requests[i].StudentID)
+		}
+		s.setResolverName(&requests[i])
+	}
+
+	return requests, nil
+}
+
+// GetStudentRequests returns a student's regrade requests for an assignment, oldest first
+func (s *RegradeServiceImpl) GetStudentRequests(classID, assignmentID, studentID int) ([]models.RegradeRequest, error) {
+	if _, err := s.getAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	var requests []models.RegradeRequest
+	if err := s.db.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Order("created_at, request_id").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get regrade requests: %w", err)
+	}
+
+	for i := range requests {
+		s.setResolverName(&requests[i])
+	}
+
+	return requests, nil
+}
+
+// AcceptRequest resolves a pending regrade request with a new grade, given
+// either directly or by rubric scores. The new grade is released to the
+// student right away, since they are waiting for the answer.
+func (s *RegradeServiceImpl) AcceptRequest(classID, assignmentID, requestID, teacherID int, grade *int, scores []models.RubricScore, response string) (*models.RegradeRequest, error) {
+	assignment, request, err := s.getPendingRequest(classID, assignmentID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (grade == nil) == (len(scores) == 0) {
+		return nil, errors.New("either a grade or rubric scores are required")
+	}
+	if len(scores) > 0 {
+		if assignment.RubricID == nil {
+			return nil, errors.New("assignment has no rubric")
+		}
+		rubric, err := loadRubric(s.db, *assignment.RubricID)
+		if err != nil {
+			return nil, err
+		}
+		total, err := scoreWithRubric(rubric, assignment.PointsPossible, scores)
+		if err != nil {
+			return nil, err
+		}
+		grade = &total
+	} else if *grade < 0 || *grade > assignment.PointsPossible {
+		return nil, fmt.Errorf("grade must be between 0 and %d", assignment.PointsPossible)
+	}
+
+	response = strings.TrimSpace(response)
+	if utf8.RuneCountInString(response) > maxRegradeReasonLength {
+		return nil, fmt.Errorf("response cannot be longer than %d characters", maxRegradeReasonLength)
+	}
+
+	var submission models.Submission
+	if err := s.db.First(&submission, request.SubmissionID).Error; err != nil {
+		return nil, fmt.Errorf("submission not found: %w", err)
+	}
+
+	resolvedAt := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := resolveRegradeRequest(tx, request, models.RegradeRequestAccepted, teacherID, grade, response, resolvedAt); err != nil {
+			return err
+		}
+
+		// The new grade replaces the rubric scores it was given with, if any
+		if err := tx.Where("submission_id = ?", submission.SubmissionID).Delete(&models.RubricScore{}).Error; err != nil {
+			return fmt.Errorf("failed to update rubric scores: %w", err)
+		}
+		for i := range scores {
+			scores[i].ScoreID = 0
+			scores[i].SubmissionID = submission.SubmissionID
+			if err := tx.Create(&scores[i]).Error; err != nil {
+				return fmt.Errorf("failed to update rubric scores: %w", err)
+			}
+		}
+
+		gradedBy := teacherID
+		submission.Grade = grade
+		submission.Status = "graded"
+		submission.GradedBy = &gradedBy
+		submission.GradedDate = &resolvedAt
+		submission.GradeReleased = true
+		if err := tx.Save(&submission).Error; err != nil {
+			return fmt.Errorf("failed to update submission: %w", err)
+		}
+		if err := logGradingAudit(tx, assignment, teacherID, request.StudentID, models.GradingAuditRegraded, grade); err != nil {
+			return fmt.Errorf("failed to update grading audit log: %w", err)
+		}
+
+		message := fmt.Sprintf("Your regrade request for %s was accepted. Your grade changed from %d/%d to %d/%d.",
+			assignment.Title, request.OriginalGrade, assignment.PointsPossible, *grade, assignment.PointsPossible)
+		return notifyRegradeResolved(tx, assignment, request, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.setResolverName(request)
+	return request, nil
+}
+
+// RejectRequest resolves a pending regrade request without changing the
+// grade. The teacher must explain why.
+func (s *RegradeServiceImpl) RejectRequest(classID, assignmentID, requestID, teacherID int, response string) (*models.RegradeRequest, error) {
+	assignment, request, err := s.getPendingRequest(classID, assignmentID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err = validateRegradeText(response, "response")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := resolveRegradeRequest(tx, request, models.RegradeRequestRejected, teacherID, nil, response, time.Now()); err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("Your regrade request for %s was declined. Your grade stays %d/%d.",
+			assignment.Title, request.OriginalGrade, assignment.PointsPossible)
+		return notifyRegradeResolved(tx, assignment, request, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.setResolverName(request)
+	return request, nil
+}
+
+func (s *RegradeServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// getPendingRequest returns a request that is still to be resolved. Requests
+// in archived classes cannot be resolved.
+func (s *RegradeServiceImpl) getPendingRequest(classID, assignmentID, requestID int) (*models.Assignment, *models.RegradeRequest, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, nil, err
+	}
+
+	var request models.RegradeRequest
+	if err := s.db.Where("request_id = ? AND assignment_id = ?", requestID, assignmentID).First(&request).Error; err != nil {
+		return nil, nil, fmt.Errorf("regrade request not found: %w", err)
+	}
+	if request.Status != models.RegradeRequestPending {
+		return nil, nil, errors.New("regrade request has already been resolved")
+	}
+
+	return assignment, &request, nil
+}
+
+// validateCriteria checks that the disputed criteria belong to the
+// assignment's rubric and removes duplicates
+func (s *RegradeServiceImpl) validateCriteria(assignment *models.Assignment, criterionIDs []int) ([]int, error) {
+	if len(criterionIDs) == 0 {
+		return nil, nil
+	}
+	if assignment.RubricID == nil {
+		return nil, errors.New("assignment has no rubric")
+	}
+
+	rubric, err := loadRubric(s.db, *assignment.RubricID)
+	if err != nil {
+		return nil, err
+	}
+	criteria := make(map[int]bool, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		criteria[criterion.CriterionID] = true
+	}
+
+	seen := make(map[int]bool, len(criterionIDs))
+	result := make([]int, 0, len(criterionIDs))
+	for _, criterionID := range criterionIDs {
+		if !criteria[criterionID] {
+			return nil, fmt.Errorf("criterion %d is not part of the assignment's rubric", criterionID)
+		}
+		if !seen[criterionID] {
+			seen[criterionID] = true
+			result = append(result, criterionID)
+		}
+	}
+	return result, nil
+}
+
+func (s *RegradeServiceImpl) setResolverName(request *models.RegradeRequest) {
+	if request.ResolvedBy == nil {
+		return
+	}
+	var teacher models.TeacherProfile
+	if err := s.db.Where("user_id = ?", *request.ResolvedBy).First(&teacher).Error; err == nil {
+		request.ResolverName = fmt.Sprintf("%s %s", teacher.FirstName, teacher.LastName)
+	}
+}
+
+// validateRegradeText trims the reason or response of a regrade request and checks its length
+func validateRegradeText(text, field string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("%s is required", field)
+	}
+	if utf8.RuneCountInString(text) > maxRegradeReasonLength {
+		return "", fmt.Errorf("%s cannot be longer than %d characters", field, maxRegradeReasonLength)
+	}
+	return text, nil
+}
+
+// resolveRegradeRequest marks a pending request as accepted or rejected. It
+// fails when another teacher resolved the request first.
+func resolveRegradeRequest(tx *gorm.DB, request *models.RegradeRequest, status string, teacherID int, newGrade *int, response string, resolvedAt time.Time) error {
+	result := tx.Model(&models.RegradeRequest{}).
+		Where("request_id = ? AND status = ?", request.RequestID, models.RegradeRequestPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"new_grade":   newGrade,
+			"response":    response,
+			"resolved_by": teacherID,
+			"resolved_at": resolvedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update regrade request: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("regrade request has already been resolved")
+	}
+
+	request.Status = status
+	request.NewGrade = newGrade
+	request.Response = response
+	request.ResolvedBy = &teacherID
+	request.ResolvedAt = &resolvedAt
+	return nil
+}
+
+// notifyRegradeResolved tells the student how their regrade request was resolved
+func notifyRegradeResolved(tx *gorm.DB, assignment *models.Assignment, request *models.RegradeRequest, message string) error {
+	if request.Response != "" {
+		message = fmt.Sprintf("%s Teacher's response: %s", message, request.Response)
+	}
+	notification := models.Notification{
+		UserID:       request.StudentID,
+		Type:         models.NotificationTypeRegradeResolved,
+		Title:        fmt.Sprintf("Regrade request %s: %s", request.Status, assignment.Title),
+		Message:      message,
+		ClassID:      &assignment.ClassID,
+		AssignmentID: &assignment.AssignmentID,
+	}
+	if err := createNotifications(tx, []models.Notification{notification}); err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
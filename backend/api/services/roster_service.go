@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// maxRosterRows limits the size of a single roster import
+const maxRosterRows = 5000
+
+// RosterImportOptions controls how a roster import is applied
+type RosterImportOptions struct {
+	DryRun         bool // Report what would happen without changing anything
+	CreateAccounts bool // Create student accounts for unknown emails
+	SendInvites    bool // Email newly created students a link to set their password
+}
+
+// RosterService provides bulk roster operations for classes
+type RosterService interface {
+	Service
+	ImportRoster(classID int, csvData io.Reader, options RosterImportOptions) (*models.RosterImportReport, error)
+}
+
+// RosterServiceImpl implements RosterService
+type RosterServiceImpl struct {
+	*BaseService
+}
+
+// NewRosterService creates a new RosterService
+func NewRosterService(db *gorm.DB) RosterService {
+	return &RosterServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// rosterEntry is a parsed roster row together with the action planned for it
+type rosterEntry struct {
+	row  models.RosterImportRow
+	user *models.User
+}
+
+// ImportRoster enrolls the students listed in a CSV file into a class.
+// The CSV must have an email column and may have first name and last name
+// columns. Teacher-initiated enrollments skip the approval step but still
+// respect the class capacity, overflowing into the waitlist.
+func (s *RosterServiceImpl) ImportRoster(classID int, csvData io.Reader, options RosterImportOptions) (*models.RosterImportReport, error) {
+	// Check if class exists
+	var class models.Class
+	if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
+
+	records, err := utils.ReadCSVRecords(csvData, maxRosterRows)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+
+	if len(records) > 0 {
+		_, hasEmail := records[0].Values["email"]
+		_, hasEmailAddress := records[0].Values["emailaddress"]
+		if !hasEmail && !hasEmailAddress {
+			return nil, errors.New("invalid CSV file: an email column is required")
+		}
+	}
+
+	// Count current seats so a dry run can report who would be waitlisted.
+	// A real import decides each row under the class lock in applyRow.
+	var activeCount int64
+	if err := s.db.Model(&models.ClassEnrollment{}).
+		Where("class_id = ? AND is_active = ?", classID, true).
+		Count(&activeCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count enrollments: %w", err)
+	}
+	seatAvailable := func() bool {
+		return class.MaxCapacity == nil || activeCount < int64(*class.MaxCapacity)
+	}
+
+	report := &models.RosterImportReport{
+		ClassID: classID,
+		DryRun:  options.DryRun,
+		Rows:    make([]models.RosterImportRow, 0, len(records)),
+	}
+
+	seen := make(map[string]bool)
+	for _, record := range records {
+		entry := s.planRow(record, classID, options, seen)
+
+		if options.DryRun {
+			// Decide between an active seat and the waitlist
+			if entry.row.Status == models.RosterRowCreated || entry.row.Status == models.RosterRowEnrolled {
+				if seatAvailable() {
+					activeCount++
+				} else {
+					entry.row.Status = models.RosterRowWaitlisted
+					entry.row.Message = "class is full, student added to the waitlist"
+				}
+			}
+		} else if entry.row.Status != models.RosterRowSkipped && entry.row.Status != models.RosterRowError {
+			if err := s.applyRow(&entry, classID, options.SendInvites); err != nil {
+				log.Printf("Roster import row %d failed: %v", entry.row.Row, err)
+				entry.row.Status = models.RosterRowError
+				entry.row.Message = err.Error()
+			}
+		}
+
+		report.AddRow(entry.row)
+	}
+
+	log.Printf("Roster import for class %d (dryRun=%v): %d created, %d enrolled, %d waitlisted, %d skipped, %d errors",
+		classID, options.DryRun, report.Created, report.Enrolled, report.Waitlisted, report.Skipped, report.Errors)
+
+	return report, nil
+}
+
+// planRow validates a CSV row and works out what should happen to it
+func (s *RosterServiceImpl) planRow(record utils.CSVRecord, classID int, options RosterImportOptions, seen map[string]bool) rosterEntry {
+	entry := rosterEntry{
+		row: models.RosterImportRow{
+			Row:       record.Line,
+			Email:     strings.ToLower(record.Get("email", "email address")),
+			FirstName: record.Get("first name", "given name"),
+			LastName:  record.Get("last name", "family name", "surname"),
+		},
+	}
+
+	// Fall back to a single "name" column
+	if entry.row.FirstName == "" && entry.row.LastName == "" {
+		if parts := strings.Fields(record.Get("name", "full name")); len(parts) > 0 {
+			entry.row.FirstName = parts[0]
+			entry.row.LastName = strings.Join(parts[1:], " ")
+		}
+	}
+
+	fail := func(status, message string) rosterEntry {
+		entry.row.Status = status
+		entry.row.Message = message
+		return entry
+	}
+
+	if entry.row.Email == "" {
+		return fail(models.RosterRowError, "email is required")
+	}
+	if _, err := mail.ParseAddress(entry.row.Email); err != nil {
+		return fail(models.RosterRowError, "invalid email address")
+	}
+	if seen[entry.row.Email] {
+		return fail(models.RosterRowSkipped, "duplicate row in file")
+	}
+	seen[entry.row.Email] = true
+
+	var user models.User
+	err := s.db.Where("email = ?", entry.row.Email).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fail(models.RosterRowError, "failed to look up user")
+	}
+
+	// Unknown email: create an account if allowed
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if !options.CreateAccounts {
+			return fail(models.RosterRowError, "no account exists for this email")
+		}
+		if entry.row.FirstName == "" || entry.row.LastName == "" {
+			return fail(models.RosterRowError, "first and last name are required to create an account")
+		}
+		entry.row.Status = models.RosterRowCreated
+		return entry
+	}
+
+	entry.user = &user
+	entry.row.UserID = user.UserID
+	if entry.row.FirstName == "" {
+		entry.row.FirstName = user.FirstName
+	}
+	if entry.row.LastName == "" {
+		entry.row.LastName = user.LastName
+	}
+
+	if user.UserRole != "student" {
+		return fail(models.RosterRowError, fmt.Sprintf("user is a %s, not a student", user.UserRole))
+	}
+
+	// Look at the most recent enrollment for this class
+	var enrollment models.ClassEnrollment
+	err = s.db.Where("user_id = ? AND class_id = ?", user.UserID, classID).
+		Order("enrollment_id DESC").
+		First(&enrollment).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fail(models.RosterRowError, "failed to look up enrollment")
+	}
+	if enrollment.Status == models.EnrollmentStatusActive {
+		return fail(models.RosterRowSkipped, "already enrolled")
+	}
+
+	entry.row.Status = models.RosterRowEnrolled
+	return entry
+}
+
+// applyRow creates the account (if needed) and the enrollment for a planned
+// row. The class is locked while its capacity is checked, so concurrent
+// imports and joins cannot fill the same seat. A student who left or was
+// removed, rejected or is still waiting gets their latest enrollment back.
+func (s *RosterServiceImpl) applyRow(entry *rosterEntry, classID int, sendInvite bool) error {
+	var inviteToken string
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		class, err := lockClass(tx, classID)
+		if err != nil {
+			return err
+		}
+		if class.IsArchived {
+			return ErrClassArchived
+		}
+
+		if entry.user == nil {
+			user, token, err := s.createStudentAccount(tx, entry.row.Email, entry.row.FirstName, entry.row.LastName)
+			if err != nil {
+				return err
+			}
+			entry.user = user
+			entry.row.UserID = user.UserID
+			inviteToken = token
+		}
+
+		var enrollment models.ClassEnrollment
+		err = tx.Where("user_id = ? AND class_id = ?", entry.user.UserID, classID).
+			Order("enrollment_id DESC").
+			First(&enrollment).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if enrollment.Status == models.EnrollmentStatusActive {
+			entry.row.Status = models.RosterRowSkipped
+			entry.row.Message = "already enrolled"
+			return nil
+		}
+
+		full, err := isClassFull(tx, class)
+		if err != nil {
+			return err
+		}
+		status := models.EnrollmentStatusActive
+		if full {
+			status = models.EnrollmentStatusWaitlisted
+			entry.row.Status = models.RosterRowWaitlisted
+			entry.row.Message = "class is full, student added to the waitlist"
+		}
+
+		enrollment.UserID = entry.user.UserID
+		enrollment.ClassID = classID
+		enrollment.EnrollmentDate = time.Now()
+		enrollment.IsActive = status == models.EnrollmentStatusActive
+		enrollment.Status = status
+
+		if enrollment.EnrollmentID == 0 {
+			return tx.Create(&enrollment).Error
+		}
+		return tx.Model(&models.ClassEnrollment{}).
+			Where("enrollment_id = ?", enrollment.EnrollmentID).
+			Updates(map[string]interface{}{
+				"enrollment_date": enrollment.EnrollmentDate,
+				"is_active":       enrollment.IsActive,
+				"status":          enrollment.Status,
+			}).Error
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if inviteToken != "" && sendInvite {
+		link := fmt.Sprintf("%s/reset-password?token=%s", utils.AppURL(), inviteToken)
+		body := fmt.Sprintf("Hi %s,\n\nYour teacher has created a ClassConnect account for you.\n"+
+			"Set your password to get started:\n\n%s\n\nThis link expires in 7 days.", entry.row.FirstName, link)
+		if err := utils.SendEmail(entry.row.Email, "You're invited to ClassConnect", body); err != nil {
+			entry.row.Message = "account created but the invite email could not be sent"
+		}
+	}
+
+	return nil
+}
+
+// createStudentAccount creates a student user and profile with a random
+// password and returns a password reset token the student can use to log in
+func (s *RosterServiceImpl) createStudentAccount(tx *gorm.DB, email, firstName, lastName string) (*models.User, string, error) {
+	password, err := utils.GenerateSecureRandomString(24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, "", errors.New("error creating secure password")
+	}
+
+	user := models.User{
+		Email:          email,
+		PasswordHash:   hashedPassword,
+		FirstName:      firstName,
+		LastName:       lastName,
+		UserRole:       "student",
+		IsActive:       true,
+		DateRegistered: time.Now(),
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create user: %w", err)
+	}
+
+	profile := models.StudentProfile{
+		UserID:         user.UserID,
+		FirstName:      firstName,
+		LastName:       lastName,
+		EnrollmentDate: time.Now(),
+	}
+	if err := tx.Create(&profile).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create student profile: %w", err)
+	}
+
+	token, err := utils.GenerateSecureRandomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reset := models.PasswordReset{
+		UserID:    user.UserID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+	}
+	if err := tx.Create(&reset).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create invite token: %w", err)
+	}
+
+	return &user, token, nil
+}
@@ -0,0 +1,481 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// RubricService handles grading rubrics and attaching them to assignments
+type RubricService interface {
+	Service
+	CreateRubric(ownerID int, rubric models.Rubric) (*models.Rubric, error)
+	GetRubrics(ownerID int) ([]models.Rubric, error)
+	GetRubric(rubricID int) (*models.Rubric, error)
+	UpdateRubric(rubricID int, rubric models.Rubric) (*models.Rubric, error)
+	DeleteRubric(rubricID int) error
+	IsRubricOwner(userID, rubricID int) (bool, error)
+	AttachRubric(classID, assignmentID int, rubricID *int) (models.AssignmentResponse, error)
+	GetAssignmentRubric(classID, assignmentID int) (*models.Rubric, error)
+}
+
+// RubricServiceImpl implements RubricService
+type RubricServiceImpl struct {
+	*BaseService
+}
+
+// NewRubricService creates a new RubricService
+func NewRubricService(db *gorm.DB) RubricService {
+	return &RubricServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// CreateRubric creates a rubric with its criteria and levels
+func (s *RubricServiceImpl) CreateRubric(ownerID int, rubric models.Rubric) (*models.Rubric, error) {
+	if err := normalizeRubric(&rubric); err != nil {
+		return nil, err
+	}
+
+	rubric.RubricID = 0
+	rubric.OwnerID = ownerID
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rubric).Error; err != nil {
+			return err
+		}
+		return saveRubricCriteria(tx, &rubric)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rubric: %w", err)
+	}
+
+	return &rubric, nil
+}
+
+// GetRubrics lists a teacher's rubrics
+func (s *RubricServiceImpl) GetRubrics(ownerID int) ([]models.Rubric, error) {
+	var rubrics []models.Rubric
+	if err := s.db.Where("owner_id = ?", ownerID).Order("title ASC").Find(&rubrics).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rubrics: %w", err)
+	}
+
+	for i := range rubrics {
+		if err := loadRubricCriteria(s.db, &rubrics[i]); err != nil {
+			return nil, fmt.Errorf("failed to get rubric criteria: %w", err)
+		}
+	}
+
+	return rubrics, nil
+}
+
+// GetRubric returns a rubric with its criteria and levels
+func (s *RubricServiceImpl) GetRubric(rubricID int) (*models.Rubric, error) {
+	return loadRubric(s.db, rubricID)
+}
+
+// UpdateRubric replaces the title, criteria and levels of a rubric.
+// Rubrics that have been used for grading cannot be changed.
+func (s *RubricServiceImpl) UpdateRubric(rubricID int, rubric models.Rubric) (*models.Rubric, error) {
+	existing, err := loadRubric(s.db, rubricID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := normalizeRubric(&rubric); err != nil {
+		return nil, err
+	}
+
+	used, err := rubricUsedForGrading(s.db, existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rubric usage: %w", err)
+	}
+	if used {
+		return nil, errors.New("rubric has already been used for grading and cannot be changed")
+	}
+
+	rubric.RubricID = existing.RubricID
+	rubric.OwnerID = existing.OwnerID
+	rubric.CreatedAt = existing.CreatedAt
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteRubricCriteria(tx, existing); err != nil {
+			return err
+		}
+		if err := tx.Save(&rubric).Error; err != nil {
+			return err
+		}
+		return saveRubricCriteria(tx, &rubric)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rubric: %w", err)
+	}
+
+	return &rubric, nil
+}
+
+// DeleteRubric deletes a rubric. Rubrics attached to assignments cannot be deleted.
+func (s *RubricServiceImpl) DeleteRubric(rubricID int) error {
+	rubric, err := loadRubric(s.db, rubricID)
+	if err != nil {
+		return err
+	}
+
+	var attached int64
+	if err := s.db.Model(&models.Assignment{}).Where("rubric_id = ?", rubricID).Count(&attached).Error; err != nil {
+		return fmt.Errorf("failed to check rubric usage: %w", err)
+	}
+	if attached > 0 {
+		return fmt.Errorf("rubric is used by %d assignment(s)", attached)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteRubricCriteria(tx, rubric); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Rubric{}, rubricID).Error
+	})
+}
+
+// IsRubricOwner checks if a user owns a rubric
+func (s *RubricServiceImpl) IsRubricOwner(userID, rubricID int) (bool, error) {
+	var rubric models.Rubric
+	if err := s.db.First(&rubric, rubricID).Error; err != nil {
+		return false, fmt.Errorf("rubric not found: %w", err)
+	}
+	return rubric.OwnerID == userID, nil
+}
+
+// AttachRubric attaches a rubric to an assignment, or detaches it when rubricID
+// is nil. The rubric cannot change once submissions have been graded with it.
+func (s *RubricServiceImpl) AttachRubric(classID, assignmentID int, rubricID *int) (models.AssignmentResponse, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return models.AssignmentResponse{}, err
+	}
+
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return models.AssignmentResponse{}, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	unchanged := (assignment.RubricID == nil && rubricID == nil) ||
+		(assignment.RubricID != nil && rubricID != nil && *assignment.RubricID == *rubricID)
+	if unchanged {
+		return assignment.ToResponse(), nil
+	}
+
+	var scored int64
+	if err := s.db.Model(&models.RubricScore{}).
+		Where("submission_id IN (SELECT submission_id FROM submissions WHERE assignment_id = ?)", assignmentID).
+		Count(&scored).Error; err != nil {
+		return models.AssignmentResponse{}, fmt.Errorf("failed to check rubric grades: %w", err)
+	}
+	if scored > 0 {
+		return models.AssignmentResponse{}, errors.New("the assignment's rubric has already been used for grading and cannot be replaced or removed")
+	}
+
+	if rubricID != nil {
+		if _, err := loadRubric(s.db, *rubricID); err != nil {
+			return models.AssignmentResponse{}, err
+		}
+	}
+
+	if err := s.db.Model(&assignment).Update("rubric_id", rubricID).Error; err != nil {
+		return models.AssignmentResponse{}, fmt.Errorf("failed to attach rubric: %w", err)
+	}
+	assignment.RubricID = rubricID
+
+	return assignment.ToResponse(), nil
+}
+
+// GetAssignmentRubric returns the rubric attached to an assignment
+func (s *RubricServiceImpl) GetAssignmentRubric(classID, assignmentID int) (*models.Rubric, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	if assignment.RubricID == nil {
+		return nil, errors.New("rubric not found: assignment has no rubric")
+	}
+
+	return loadRubric(s.db, *assignment.RubricID)
+}
+
+// loadRubric retrieves a rubric with its criteria and levels
+func loadRubric(db *gorm.DB, rubricID int) (*models.Rubric, error) {
+	var rubric models.Rubric
+	if err := db.First(&rubric, rubricID).Error; err != nil {
+		return nil, fmt.Errorf("rubric not found: %w", err)
+	}
+	if err := loadRubricCriteria(db, &rubric); err != nil {
+		return nil, fmt.Errorf("failed to get rubric criteria: %w", err)
+	}
+	return &rubric, nil
+}
+
+// loadRubricCriteria loads the criteria and levels of a rubric in order
+func loadRubricCriteria(db *gorm.DB, rubric *models.Rubric) error {
+	var criteria []models.RubricCriterion
+	if err := db.Where("rubric_id = ?", rubric.RubricID).Order("position ASC").Find(&criteria).Error; err != nil {
+		return err
+	}
+
+	criterionIDs := make([]int, 0, len(criteria))
+	for _, criterion := range criteria {
+		criterionIDs = append(criterionIDs, criterion.CriterionID)
+	}
+
+	var levels []models.RubricLevel
+	if len(criterionIDs) > 0 {
+		if err := db.Where("criterion_id IN ?", criterionIDs).Order("position ASC").Find(&levels).Error; err != nil {
+			return err
+		}
+	}
+
+	levelsByCriterion := make(map[int][]models.RubricLevel)
+	for _, level := range levels {
+		levelsByCriterion[level.CriterionID] = append(levelsByCriterion[level.CriterionID], level)
+	}
+	for i := range criteria {
+		criteria[i].Levels = levelsByCriterion[criteria[i].CriterionID]
+	}
+
+	rubric.Criteria = criteria
+	return nil
+}
+
+// saveRubricCriteria inserts the criteria and levels of a rubric
+func saveRubricCriteria(tx *gorm.DB, rubric *models.Rubric) error {
+	for i := range rubric.Criteria {
+		criterion := &rubric.Criteria[i]
+		criterion.CriterionID = 0
+		criterion.RubricID = rubric.RubricID
+		criterion.Position = i + 1
+		if err := tx.Create(criterion).Error; err != nil {
+			return err
+		}
+
+		for j := range criterion.Levels {
+			level := &criterion.Levels[j]
+			level.LevelID = 0
+			level.CriterionID = criterion.CriterionID
+			level.Position = j + 1
+			if err := tx.Create(level).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteRubricCriteria deletes the criteria and levels of a rubric
+func deleteRubricCriteria(tx *gorm.DB, rubric *models.Rubric) error {
+	criterionIDs := make([]int, 0, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		criterionIDs = append(criterionIDs, criterion.CriterionID)
+	}
+	if len(criterionIDs) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("criterion_id IN ?", criterionIDs).Delete(&models.RubricLevel{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("criterion_id IN ?", criterionIDs).Delete(&models.RubricCriterion{}).Error
+}
+
+// rubricUsedForGrading reports whether any submission has been scored with the rubric
+func rubricUsedForGrading(db *gorm.DB, rubric *models.Rubric) (bool, error) {
+	criterionIDs := make([]int, 0, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		criterionIDs = append(criterionIDs, criterion.CriterionID)
+	}
+	if len(criterionIDs) == 0 {
+		return false, nil
+	}
+
+	var count int64
+	if err := db.Model(&models.RubricScore{}).Where("criterion_id IN ?", criterionIDs).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// normalizeRubric validates a rubric and trims its titles
+func normalizeRubric(rubric *models.Rubric) error {
+	rubric.Title = strings.TrimSpace(rubric.Title)
+	if rubric.Title == "" {
+		return errors.New("rubric title is required")
+	}
+	if len(rubric.Criteria) == 0 {
+		return errors.New("a rubric needs at least one criterion")
+	}
+
+	for i := range rubric.Criteria {
+		criterion := &rubric.Criteria[i]
+		criterion.Title = strings.TrimSpace(criterion.Title)
+		if criterion.Title == "" {
+			return fmt.Errorf("criterion %d: title is required", i+1)
+		}
+		if len(criterion.Levels) == 0 {
+			return fmt.Errorf("criterion %q needs at least one level", criterion.Title)
+		}
+		for j := range criterion.Levels {
+			level := &criterion.Levels[j]
+			level.Title = strings.TrimSpace(level.Title)
+			if level.Title == "" {
+				return fmt.Errorf("criterion %q, level %d: title is required", criterion.Title, j+1)
+			}
+			if level.Points < 0 {
+				return fmt.Errorf("criterion %q, level %q: points must not be negative", criterion.Title, level.Title)
+			}
+		}
+	}
+
+	if rubric.MaxPoints() == 0 {
+		return errors.New("a rubric needs at least one level worth points")
+	}
+
+	return nil
+}
+
+// scoreWithRubric checks that every criterion of the rubric is scored exactly once
+// with one of its levels. It fills in the points of each score and returns the
+// grade, scaled to the assignment's points when the rubric's total differs.
+func scoreWithRubric(rubric *models.Rubric, pointsPossible int, scores []models.RubricScore) (int, error) {
+	criteria := make(map[int]models.RubricCriterion, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		criteria[criterion.CriterionID] = criterion
+	}
+
+	scored := make(map[int]bool, len(scores))
+	total := 0
+	for i := range scores {
+		criterion, ok := criteria[scores[i].CriterionID]
+		if !ok {
+			return 0, fmt.Errorf("criterion %d is not part of the assignment's rubric", scores[i].CriterionID)
+		}
+		if scored[criterion.CriterionID] {
+			return 0, fmt.Errorf("criterion %q is scored more than once", criterion.Title)
+		}
+		scored[criterion.CriterionID] = true
+
+		found := false
+		for _, level := range criterion.Levels {
+			if level.LevelID == scores[i].LevelID {
+				scores[i].Points = level.Points
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("level %d is not a level of criterion %q", scores[i].LevelID, criterion.Title)
+		}
+		total += scores[i].Points
+	}
+
+	for _, criterion := range rubric.Criteria {
+		if !scored[criterion.CriterionID] {
+			return 0, fmt.Errorf("criterion %q has not been scored", criterion.Title)
+		}
+	}
+
+	maxPoints := rubric.MaxPoints()
+	if maxPoints == pointsPossible {
+		return total, nil
+	}
+	return int(math.Round(float64(total) * float64(pointsPossible) / float64(maxPoints))), nil
+}
+
+// loadRubricScores returns the rubric scores of submissions, keyed by submission ID
+func loadRubricScores(db *gorm.DB, submissionIDs []int) (map[int][]models.RubricScoreResponse, error) {
+	result := make(map[int][]models.RubricScoreResponse)
+	if len(submissionIDs) == 0 {
+		return result, nil
+	}
+
+	var scores []models.RubricScore
+	if err := db.Where("submission_id IN ?", submissionIDs).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	if len(scores) == 0 {
+		return result, nil
+	}
+
+	criterionIDs := make([]int, 0, len(scores))
+	for _, score := range scores {
+		criterionIDs = append(criterionIDs, score.CriterionID)
+	}
+
+	var criteria []models.RubricCriterion
+	if err := db.Where("criterion_id IN ?", criterionIDs).Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+	var levels []models.RubricLevel
+	if err := db.Where("criterion_id IN ?", criterionIDs).Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	criteriaByID := make(map[int]*models.RubricCriterion, len(criteria))
+	for i := range criteria {
+		criteriaByID[criteria[i].CriterionID] = &criteria[i]
+	}
+	levelsByID := make(map[int]models.RubricLevel, len(levels))
+	for _, level := range levels {
+		levelsByID[level.LevelID] = level
+		if criterion, ok := criteriaByID[level.CriterionID]; ok {
+			criterion.Levels = append(criterion.Levels, level)
+		}
+	}
+
+	// Keep the rubric's criterion order
+	positions := make(map[int]int, len(criteria))
+	for _, criterion := range criteria {
+		positions[criterion.CriterionID] = criterion.Position
+	}
+
+	for _, score := range scores {
+		response := models.RubricScoreResponse{
+			CriterionID: score.CriterionID,
+			LevelID:     score.LevelID,
+			Points:      score.Points,
+			Comment:     score.Comment,
+		}
+		if criterion, ok := criteriaByID[score.CriterionID]; ok {
+			response.Criterion = criterion.Title
+			response.MaxPoints = criterion.MaxPoints()
+		}
+		if level, ok := levelsByID[score.LevelID]; ok {
+			response.Level = level.Title
+		}
+
+		list := append(result[score.SubmissionID], response)
+		for i := len(list) - 1; i > 0 && positions[list[i].CriterionID] < positions[list[i-1].CriterionID]; i-- {
+			list[i], list[i-1] = list[i-1], list[i]
+		}
+		result[score.SubmissionID] = list
+	}
+
+	return result, nil
+}
+
+// withRubricScores adds rubric scores to submission responses
+func withRubricScores(db *gorm.DB, responses []models.SubmissionResponse) error {
+	submissionIDs := make([]int, 0, len(responses))
+	for _, response := range responses {
+		if response.SubmissionID != 0 {
+			submissionIDs = append(submissionIDs, response.SubmissionID)
+		}
+	}
+
+	scores, err := loadRubricScores(db, submissionIDs)
+	if err != nil {
+		return err
+	}
+	for i := range responses {
+		responses[i].RubricScores = scores[responses[i].SubmissionID]
+	}
+	return nil
+}
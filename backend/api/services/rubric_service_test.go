@@ -0,0 +1,213 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+)
+
+func TestScoreWithRubric(t *testing.T) {
+	// Worth 15 points: Content up to 10, Style up to 5
+	rubric := &models.Rubric{
+		Title: "Essay",
+		Criteria: []models.RubricCriterion{
+			{CriterionID: 1, Title: "Content", Levels: []models.RubricLevel{
+				{LevelID: 11, Points: 0}, {LevelID: 12, Points: 5}, {LevelID: 13, Points: 10},
+			}},
+			{CriterionID: 2, Title: "Style", Levels: []models.RubricLevel{
+				{LevelID: 21, Points: 0}, {LevelID: 22, Points: 5},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		pointsPossible int
+		scores         []models.RubricScore
+		want           int
+		wantPoints     []int
+		wantErr        string
+	}{
+		{
+			name:           "full marks",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 13}, {CriterionID: 2, LevelID: 22}},
+			want:           15,
+			wantPoints:     []int{10, 5},
+		},
+		{
+			name:           "partial marks in any order",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 2, LevelID: 21}, {CriterionID: 1, LevelID: 12}},
+			want:           5,
+			wantPoints:     []int{0, 5},
+		},
+		{
+			name:           "points sent by the client are replaced",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 11, Points: 99}, {CriterionID: 2, LevelID: 21, Points: 99}},
+			want:           0,
+			wantPoints:     []int{0, 0},
+		},
+		{
+			name:           "scaled up to the assignment's points",
+			pointsPossible: 100,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 13}, {CriterionID: 2, LevelID: 22}},
+			want:           100,
+			wantPoints:     []int{10, 5},
+		},
+		{
+			name:           "scaled and rounded up",
+			pointsPossible: 100,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 12}, {CriterionID: 2, LevelID: 22}},
+			want:           67,
+			wantPoints:     []int{5, 5},
+		},
+		{
+			name:           "scaled and rounded down",
+			pointsPossible: 10,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 12}, {CriterionID: 2, LevelID: 21}},
+			want:           3,
+			wantPoints:     []int{5, 0},
+		},
+		{
+			name:           "criterion from another rubric",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 13}, {CriterionID: 3, LevelID: 31}},
+			wantErr:        "criterion 3 is not part of the assignment's rubric",
+		},
+		{
+			name:           "criterion scored twice",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 13}, {CriterionID: 1, LevelID: 12}},
+			wantErr:        `criterion "Content" is scored more than once`,
+		},
+		{
+			name:           "level of another criterion",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 22}, {CriterionID: 2, LevelID: 22}},
+			wantErr:        `level 22 is not a level of criterion "Content"`,
+		},
+		{
+			name:           "criterion not scored",
+			pointsPossible: 15,
+			scores:         []models.RubricScore{{CriterionID: 1, LevelID: 13}},
+			wantErr:        `criterion "Style" has not been scored`,
+		},
+		{
+			name:           "no scores",
+			pointsPossible: 15,
+			wantErr:        `criterion "Content" has not been scored`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scoreWithRubric(rubric, tt.pointsPossible, tt.scores)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("scoreWithRubric() = %d, want %d", got, tt.want)
+			}
+
+			points := make([]int, len(tt.scores))
+			for i, score := range tt.scores {
+				points[i] = score.Points
+			}
+			if !reflect.DeepEqual(points, tt.wantPoints) {
+				t.Errorf("score points = %v, want %v", points, tt.wantPoints)
+			}
+		})
+	}
+}
+
+func TestNormalizeRubric(t *testing.T) {
+	level := func(title string, points int) models.RubricLevel {
+		return models.RubricLevel{Title: title, Points: points}
+	}
+
+	tests := []struct {
+		name    string
+		rubric  models.Rubric
+		wantErr string
+	}{
+		{
+			name: "valid",
+			rubric: models.Rubric{Title: " Essay ", Criteria: []models.RubricCriterion{
+				{Title: " Content ", Levels: []models.RubricLevel{level(" Weak ", 0), level("Strong", 4)}},
+			}},
+		},
+		{
+			name:    "no title",
+			rubric:  models.Rubric{Title: "  "},
+			wantErr: "rubric title is required",
+		},
+		{
+			name:    "no criteria",
+			rubric:  models.Rubric{Title: "Essay"},
+			wantErr: "a rubric needs at least one criterion",
+		},
+		{
+			name: "criterion without title",
+			rubric: models.Rubric{Title: "Essay", Criteria: []models.RubricCriterion{
+				{Title: "Content", Levels: []models.RubricLevel{level("Good", 1)}},
+				{Title: " ", Levels: []models.RubricLevel{level("Good", 1)}},
+			}},
+			wantErr: "criterion 2: title is required",
+		},
+		{
+			name: "criterion without levels",
+			rubric: models.Rubric{Title: "Essay", Criteria: []models.RubricCriterion{
+				{Title: "Content"},
+			}},
+			wantErr: `criterion "Content" needs at least one level`,
+		},
+		{
+			name: "level without title",
+			rubric: models.Rubric{Title: "Essay", Criteria: []models.RubricCriterion{
+				{Title: "Content", Levels: []models.RubricLevel{level("Good", 1), level("", 0)}},
+			}},
+			wantErr: `criterion "Content", level 2: title is required`,
+		},
+		{
+			name: "negative points",
+			rubric: models.Rubric{Title: "Essay", Criteria: []models.RubricCriterion{
+				{Title: "Content", Levels: []models.RubricLevel{level("Bad", -1)}},
+			}},
+			wantErr: `criterion "Content", level "Bad": points must not be negative`,
+		},
+		{
+			name: "worth no points",
+			rubric: models.Rubric{Title: "Essay", Criteria: []models.RubricCriterion{
+				{Title: "Content", Levels: []models.RubricLevel{level("Done", 0)}},
+			}},
+			wantErr: "a rubric needs at least one level worth points",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeRubric(&tt.rubric)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.rubric.Title != "Essay" || tt.rubric.Criteria[0].Title != "Content" || tt.rubric.Criteria[0].Levels[0].Title != "Weak" {
+				t.Errorf("titles were not trimmed: %+v", tt.rubric)
+			}
+		})
+	}
+}
@@ -15,6 +15,29 @@ type ServiceFactory interface {
 	ChatService() ChatService
 	AssignmentService() AssignmentService
 	AnnouncementService() AnnouncementService
+	RosterService() RosterService
+	OneRosterService() OneRosterService
+	TermService() TermService
+	AttendanceService() AttendanceService
+	CalendarService() CalendarService
+	QuizService() QuizService
+	QTIService() QTIService
+	QuestionBankService() QuestionBankService
+	RubricService() RubricService
+	SubmissionVersionService() SubmissionVersionService
+	ExtensionService() ExtensionService
+	GroupService() GroupService
+	PeerReviewService() PeerReviewService
+	SimilarityService() SimilarityService
+	NotificationService() NotificationService
+	GradingService() GradingService
+	AnonymousGradingService() AnonymousGradingService
+	SubmissionCommentService() SubmissionCommentService
+	RegradeService() RegradeService
+	AnalyticsService() AnalyticsService
+	AtRiskService() AtRiskService
+	GuardianService() GuardianService
+	ProgressReportService() ProgressReportService
 }
 
 // serviceFactoryImpl implements ServiceFactory
@@ -22,12 +45,35 @@ type serviceFactoryImpl struct {
 	db *gorm.DB
 
 	// Services
-	userService         UserService
-	authService         AuthService
-	classService        ClassService
-	chatService         ChatService
-	assignmentService   AssignmentService
-	announcementService AnnouncementService
+	userService              UserService
+	authService              AuthService
+	classService             ClassService
+	chatService              ChatService
+	assignmentService        AssignmentService
+	announcementService      AnnouncementService
+	rosterService            RosterService
+	oneRosterService         OneRosterService
+	termService              TermService
+	attendanceService        AttendanceService
+	calendarService          CalendarService
+	quizService              QuizService
+	qtiService               QTIService
+	questionBankService      QuestionBankService
+	rubricService            RubricService
+	submissionVersionService SubmissionVersionService
+	extensionService         ExtensionService
+	groupService             GroupService
+	peerReviewService        PeerReviewService
+	similarityService        SimilarityService
+	notificationService      NotificationService
+	gradingService           GradingService
+	anonymousGradingService  AnonymousGradingService
+	submissionCommentService SubmissionCommentService
+	regradeService           RegradeService
+	analyticsService         AnalyticsService
+	atRiskService            AtRiskService
+	guardianService          GuardianService
+	progressReportService    ProgressReportService
 
 	// Mutex for lazy initialization
 	mu sync.Mutex
@@ -111,3 +157,279 @@ func (f *serviceFactoryImpl) AnnouncementService() AnnouncementService {
 
 	return f.announcementService
 }
+
+// RosterService returns the RosterService
+func (f *serviceFactoryImpl) RosterService() RosterService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.rosterService == nil {
+		f.rosterService = NewRosterService(f.db)
+	}
+
+	return f.rosterService
+}
+
+// OneRosterService returns the OneRosterService
+func (f *serviceFactoryImpl) OneRosterService() OneRosterService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.oneRosterService == nil {
+		f.oneRosterService = NewOneRosterService(f.db)
+	}
+
+	return f.oneRosterService
+}
+
+// TermService returns the TermService
+func (f *serviceFactoryImpl) TermService() TermService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.termService == nil {
+		f.termService = NewTermService(f.db)
+	}
+
+	return f.termService
+}
+
+// AttendanceService returns the AttendanceService
+func (f *serviceFactoryImpl) AttendanceService() AttendanceService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.attendanceService == nil {
+		f.attendanceService = NewAttendanceService(f.db)
+	}
+
+	return f.attendanceService
+}
+
+// CalendarService returns the CalendarService
+func (f *serviceFactoryImpl) CalendarService() CalendarService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calendarService == nil {
+		f.calendarService = NewCalendarService(f.db)
+	}
+
+	return f.calendarService
+}
+
+// QuizService returns the QuizService
+func (f *serviceFactoryImpl) QuizService() QuizService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.quizService == nil {
+		f.quizService = NewQuizService(f.db)
+	}
+
+	return f.quizService
+}
+
+// QTIService returns the QTIService
+func (f *serviceFactoryImpl) QTIService() QTIService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.qtiService == nil {
+		f.qtiService = NewQTIService(f.db)
+	}
+
+	return f.qtiService
+}
+
+// QuestionBankService returns the QuestionBankService
+func (f *serviceFactoryImpl) QuestionBankService() QuestionBankService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.questionBankService == nil {
+		f.questionBankService = NewQuestionBankService(f.db)
+	}
+
+	return f.questionBankService
+}
+
+// RubricService returns the RubricService
+func (f *serviceFactoryImpl) RubricService() RubricService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.rubricService == nil {
+		f.rubricService = NewRubricService(f.db)
+	}
+
+	return f.rubricService
+}
+
+// SubmissionVersionService returns the SubmissionVersionService
+func (f *serviceFactoryImpl) SubmissionVersionService() SubmissionVersionService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.submissionVersionService == nil {
+		f.submissionVersionService = NewSubmissionVersionService(f.db)
+	}
+
+	return f.submissionVersionService
+}
+
+// ExtensionService returns the ExtensionService
+func (f *serviceFactoryImpl) ExtensionService() ExtensionService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.extensionService == nil {
+		f.extensionService = NewExtensionService(f.db)
+	}
+
+	return f.extensionService
+}
+
+// GroupService returns the GroupService
+func (f *serviceFactoryImpl) GroupService() GroupService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.groupService == nil {
+		f.groupService = NewGroupService(f.db)
+	}
+
+	return f.groupService
+}
+
+// PeerReviewService returns the PeerReviewService
+func (f *serviceFactoryImpl) PeerReviewService() PeerReviewService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.peerReviewService == nil {
+		f.peerReviewService = NewPeerReviewService(f.db)
+	}
+
+	return f.peerReviewService
+}
+
+// SimilarityService returns the SimilarityService
+func (f *serviceFactoryImpl) SimilarityService() SimilarityService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.similarityService == nil {
+		f.similarityService = NewSimilarityService(f.db)
+	}
+
+	return f.similarityService
+}
+
+// NotificationService returns the NotificationService
+func (f *serviceFactoryImpl) NotificationService() NotificationService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.notificationService == nil {
+		f.notificationService = NewNotificationService(f.db)
+	}
+
+	return f.notificationService
+}
+
+// GradingService returns the GradingService
+func (f *serviceFactoryImpl) GradingService() GradingService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.gradingService == nil {
+		f.gradingService = NewGradingService(f.db)
+	}
+
+	return f.gradingService
+}
+
+// AnonymousGradingService returns the AnonymousGradingService
+func (f *serviceFactoryImpl) AnonymousGradingService() AnonymousGradingService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.anonymousGradingService == nil {
+		f.anonymousGradingService = NewAnonymousGradingService(f.db)
+	}
+
+	return f.anonymousGradingService
+}
+
+// SubmissionCommentService returns the SubmissionCommentService
+func (f *serviceFactoryImpl) SubmissionCommentService() SubmissionCommentService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.submissionCommentService == nil {
+		f.submissionCommentService = NewSubmissionCommentService(f.db)
+	}
+
+	return f.submissionCommentService
+}
+
+// RegradeService returns the RegradeService
+func (f *serviceFactoryImpl) RegradeService() RegradeService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.regradeService == nil {
+		f.regradeService = NewRegradeService(f.db)
+	}
+
+	return f.regradeService
+}
+
+// AnalyticsService returns the AnalyticsService
+func (f *serviceFactoryImpl) AnalyticsService() AnalyticsService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.analyticsService == nil {
+		f.analyticsService = NewAnalyticsService(f.db)
+	}
+
+	return f.analyticsService
+}
+
+// AtRiskService returns the AtRiskService
+func (f *serviceFactoryImpl) AtRiskService() AtRiskService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.atRiskService == nil {
+		f.atRiskService = NewAtRiskService(f.db)
+	}
+
+	return f.atRiskService
+}
+
+// GuardianService returns the GuardianService
+func (f *serviceFactoryImpl) GuardianService() GuardianService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.guardianService == nil {
+		f.guardianService = NewGuardianService(f.db)
+	}
+
+	return f.guardianService
+}
+
+// ProgressReportService returns the ProgressReportService
+func (f *serviceFactoryImpl) ProgressReportService() ProgressReportService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.progressReportService == nil {
+		f.progressReportService = NewProgressReportService(f.db)
+	}
+
+	return f.progressReportService
+}
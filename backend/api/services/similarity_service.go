@@ -0,0 +1,444 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"github.com/yongdilun/classconnect-backend/utils"
+	"gorm.io/gorm"
+)
+
+// defaultSimilarityMinScore is the lowest score of the pairs stored by a
+// similarity check when the teacher does not choose one
+const defaultSimilarityMinScore = 0.1
+
+// similarityJobTimeout is how long a job may run before it is considered
+// interrupted, for example by a server restart
+const similarityJobTimeout = time.Hour
+
+// SimilarityService detects similar submissions. Checks are queued and run
+// in the background on the server: submissions and their files never leave
+// the installation.
+type SimilarityService interface {
+	Service
+	StartCheck(classID, assignmentID, requestedBy int, includeEarlierTerms bool, minScore *float64) (*models.SimilarityJob, error)
+	GetJobs(classID, assignmentID int) ([]models.SimilarityJob, error)
+	GetJob(classID, assignmentID, jobID int) (*models.SimilarityJob, error)
+	RunQueuedJobs() (int, error)
+}
+
+// SimilarityServiceImpl implements SimilarityService
+type SimilarityServiceImpl struct {
+	*BaseService
+}
+
+// NewSimilarityService creates a new SimilarityService
+func NewSimilarityService(db *gorm.DB) SimilarityService {
+	return &SimilarityServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// StartCheck queues a similarity check of an assignment's submissions and
+// starts it in the background
+func (s *SimilarityServiceImpl) StartCheck(classID, assignmentID, requestedBy int, includeEarlierTerms bool, minScore *float64) (*models.SimilarityJob, error) {
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.AssignmentType == models.AssignmentTypeQuiz {
+		return nil, errors.New("quizzes cannot be checked for similarity")
+	}
+
+	if includeEarlierTerms {
+		var class models.Class
+		if err := s.db.Where("class_id = ?", classID).First(&class).Error; err != nil {
+			return nil, fmt.Errorf("class not found: %w", err)
+		}
+		if class.TermID == nil {
+			return nil, errors.New("class is not in a term, earlier terms cannot be included")
+		}
+	}
+
+	job := models.SimilarityJob{
+		AssignmentID:        assignmentID,
+		IncludeEarlierTerms: includeEarlierTerms,
+		MinScore:            defaultSimilarityMinScore,
+		Status:              models.SimilarityJobQueued,
+		RequestedBy:         requestedBy,
+	}
+	if minScore != nil {
+		if *minScore < 0 || *minScore > 1 {
+			return nil, errors.New("minScore must be between 0 and 1")
+		}
+		job.MinScore = *minScore
+	}
+
+	if err := s.db.Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create similarity job: %w", err)
+	}
+
+	// The background worker also picks up queued jobs, the status update in
+	// runSimilarityJob makes sure only one of them runs the job
+	go func() {
+		if _, err := runSimilarityJob(s.db, job.JobID); err != nil {
+			log.Printf("Similarity job %d failed: %v", job.JobID, err)
+		}
+	}()
+
+	return &job, nil
+}
+
+// GetJobs returns the similarity checks of an assignment, newest first
+func (s *SimilarityServiceImpl) GetJobs(classID, assignmentID int) ([]models.SimilarityJob, error) {
+	if _, err := s.getAssignment(classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	var jobs []models.SimilarityJob
+	if err := s.db.Where("assignment_id = ?", assignmentID).Order("created_at DESC").Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get similarity jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// GetJob returns a similarity check with its pairs, most similar first.
+// Students are shown by pseudonym while the assignment's identities are hidden.
+func (s *SimilarityServiceImpl) GetJob(classID, assignmentID, jobID int) (*models.SimilarityJob, error) {
+	assignment, err := s.getAssignment(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.SimilarityJob
+	if err := s.db.Where("job_id = ? AND assignment_id = ?", jobID, assignmentID).First(&job).Error; err != nil {
+		return nil, fmt.Errorf("similarity job not found: %w", err)
+	}
+
+	if err := s.db.Where("job_id = ?", jobID).Order("score DESC").Find(&job.Pairs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get similarity pairs: %w", err)
+	}
+
+	if assignment.IdentitiesHidden() {
+		key, err := anonymityKey(s.db, assignment)
+		if err != nil {
+			return nil, err
+		}
+		for i := range job.Pairs {
+			pair := &job.Pairs[i]
+			pair.AnonymousID, pair.StudentName = studentPseudonym(key, pair.StudentID)
+			pair.OtherAnonymousID, pair.OtherStudentName = studentPseudonym(key, pair.OtherStudentID)
+			pair.StudentID, pair.OtherStudentID = 0, 0
+		}
+		return &job, nil
+	}
+
+	names := make(map[int]string)
+	name := func(studentID int) string {
+		if _, ok := names[studentID]; !ok {
+			names[studentID] = studentDisplayName(s.db, studentID)
+		}
+		return names[studentID]
+	}
+	for i := range job.Pairs {
+		job.Pairs[i].StudentName = name(job.Pairs[i].StudentID)
+		job.Pairs[i].OtherStudentName = name(job.Pairs[i].OtherStudentID)
+	}
+
+	return &job, nil
+}
+
+// RunQueuedJobs runs every queued similarity check and returns the number of
+// checks that completed. Checks left running by a stopped server are marked
+// as failed so that teachers can start them again.
+func (s *SimilarityServiceImpl) RunQueuedJobs() (int, error) {
+	err := s.db.Model(&models.SimilarityJob{}).
+		Where("status = ? AND started_at < ?", models.SimilarityJobRunning, time.Now().Add(-similarityJobTimeout)).
+		Updates(map[string]interface{}{
+			"status":       models.SimilarityJobFailed,
+			"error":        "the check was interrupted, please start it again",
+			"completed_at": time.Now(),
+		}).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to update interrupted similarity jobs: %w", err)
+	}
+
+	var jobIDs []int
+	if err := s.db.Model(&models.SimilarityJob{}).Where("status = ?", models.SimilarityJobQueued).
+		Order("created_at").Pluck("job_id", &jobIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get queued similarity jobs: %w", err)
+	}
+
+	completed := 0
+	for _, jobID := range jobIDs {
+		ran, err := runSimilarityJob(s.db, jobID)
+		if err != nil {
+			log.Printf("Similarity job %d failed: %v", jobID, err)
+			continue
+		}
+		if ran {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+func (s *SimilarityServiceImpl) getAssignment(classID, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("assignment not found: %w", err)
+	}
+	return &assignment, nil
+}
+
+// similarityDocument is the text of one submission prepared for comparison
+type similarityDocument struct {
+	submission   models.Submission
+	text         []rune
+	fingerprints []utils.Fingerprint
+}
+
+// runSimilarityJob runs a queued similarity check. The job is claimed with a
+// conditional status update, so a job that is already running or finished
+// is left alone and false is returned.
+func runSimilarityJob(db *gorm.DB, jobID int) (bool, error) {
+	startedAt := time.Now()
+	result := db.Model(&models.SimilarityJob{}).
+		Where("job_id = ? AND status = ?", jobID, models.SimilarityJobQueued).
+		Updates(map[string]interface{}{"status": models.SimilarityJobRunning, "started_at": startedAt})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to start similarity job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	var job models.SimilarityJob
+	if err := db.Where("job_id = ?", jobID).First(&job).Error; err != nil {
+		return false, fmt.Errorf("similarity job not found: %w", err)
+	}
+
+	pairs, submissionCount, warnings, err := compareSubmissions(db, &job)
+	if err != nil {
+		db.Model(&job).Updates(map[string]interface{}{
+			"status":       models.SimilarityJobFailed,
+			"error":        err.Error(),
+			"completed_at": time.Now(),
+		})
+		return false, err
+	}
+
+	job.Status = models.SimilarityJobCompleted
+	job.Warnings = warnings
+	job.SubmissionCount = submissionCount
+	job.PairCount = len(pairs)
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if len(pairs) > 0 {
+			if err := tx.CreateInBatches(&pairs, 100).Error; err != nil {
+				return fmt.Errorf("failed to save similarity pairs: %w", err)
+			}
+		}
+		if err := tx.Save(&job).Error; err != nil {
+			return fmt.Errorf("failed to complete similarity job: %w", err)
+		}
+		return nil
+	})
+	return err == nil, err
+}
+
+// compareSubmissions compares the submissions of the job's assignment with
+// each other and with those of earlier terms when requested. It returns the
+// pairs scoring at least the job's minimum score, the number of submissions
+// that were compared and a warning for every file that could not be read.
+func compareSubmissions(db *gorm.DB, job *models.SimilarityJob) ([]models.SimilarityPair, int, []string, error) {
+	var assignment models.Assignment
+	if err := db.Where("assignment_id = ?", job.AssignmentID).First(&assignment).Error; err != nil {
+		return nil, 0, nil, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	submissions, err := similaritySubmissions(db, []int{assignment.AssignmentID})
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	var earlierSubmissions []models.Submission
+	if job.IncludeEarlierTerms {
+		earlierIDs, err := earlierTermAssignmentIDs(db, &assignment, job.RequestedBy)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if earlierSubmissions, err = similaritySubmissions(db, earlierIDs); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	var warnings []string
+	fileTexts := make(map[string]string)
+	prepare := func(submissions []models.Submission) []similarityDocument {
+		documents := make([]similarityDocument, 0, len(submissions))
+		for _, submission := range submissions {
+			text := submission.Content
+			if submission.FileURL != "" {
+				fileText, ok := fileTexts[submission.FileURL]
+				if !ok {
+					var readErr error
+					if fileText, readErr = submissionFileText(submission.FileURL); readErr != nil {
+						warnings = append(warnings, fmt.Sprintf("submission %d: %v", submission.SubmissionID, readErr))
+					}
+					fileTexts[submission.FileURL] = fileText
+				}
+				if fileText != "" {
+					text = strings.TrimSpace(text + "\n\n" + fileText)
+				}
+			}
+
+			documents = append(documents, similarityDocument{
+				submission:   submission,
+				text:         []rune(text),
+				fingerprints: utils.Fingerprints(text),
+			})
+		}
+		return documents
+	}
+
+	current := prepare(submissions)
+	earlier := prepare(earlierSubmissions)
+
+	var pairs []models.SimilarityPair
+	for i := range current {
+		for j := i + 1; j < len(current); j++ {
+			if pair, ok := similarityPair(job, &current[i], &current[j]); ok {
+				pairs = append(pairs, pair)
+			}
+		}
+		for j := range earlier {
+			if pair, ok := similarityPair(job, &current[i], &earlier[j]); ok {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+
+	return pairs, len(current), warnings, nil
+}
+
+// similarityPair compares two submissions and reports whether they are
+// similar enough to be stored
+func similarityPair(job *models.SimilarityJob, a, b *similarityDocument) (models.SimilarityPair, bool) {
+	if a.submission.StudentID == b.submission.StudentID {
+		return models.SimilarityPair{}, false
+	}
+
+	comparison := utils.CompareFingerprints(a.fingerprints, b.fingerprints)
+	score := max(comparison.ScoreA, comparison.ScoreB)
+	if len(comparison.Matches) == 0 || score < job.MinScore {
+		return models.SimilarityPair{}, false
+	}
+
+	spans := make([]models.SimilaritySpan, 0, len(comparison.Matches))
+	for _, match := range comparison.Matches {
+		spans = append(spans, models.SimilaritySpan{
+			Start:      match.AStart,
+			End:        match.AEnd,
+			Text:       string(a.text[match.AStart:match.AEnd]),
+			OtherStart: match.BStart,
+			OtherEnd:   match.BEnd,
+			OtherText:  string(b.text[match.BStart:match.BEnd]),
+		})
+	}
+
+	return models.SimilarityPair{
+		JobID:             job.JobID,
+		SubmissionID:      a.submission.SubmissionID,
+		StudentID:         a.submission.StudentID,
+		OtherSubmissionID: b.submission.SubmissionID,
+		OtherStudentID:    b.submission.StudentID,
+		OtherAssignmentID: b.submission.AssignmentID,
+		Score:             score,
+		SubmissionShare:   comparison.ScoreA,
+		OtherShare:        comparison.ScoreB,
+		Spans:             spans,
+	}, true
+}
+
+// similaritySubmissions returns the submissions of the given assignments.
+// Group members share one submission, so only the first row of each group
+// is returned.
+func similaritySubmissions(db *gorm.DB, assignmentIDs []int) ([]models.Submission, error) {
+	if len(assignmentIDs) == 0 {
+		return nil, nil
+	}
+
+	var submissions []models.Submission
+	if err := db.Where("assignment_id IN ?", assignmentIDs).Order("submission_id").Find(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get submissions: %w", err)
+	}
+
+	groups := make(map[int]bool)
+	unique := submissions[:0]
+	for _, submission := range submissions {
+		if submission.GroupID != nil {
+			if groups[*submission.GroupID] {
+				continue
+			}
+			groups[*submission.GroupID] = true
+		}
+		unique = append(unique, submission)
+	}
+
+	return unique, nil
+}
+
+// earlierTermAssignmentIDs returns the copies of an assignment in earlier
+// terms: assignments with the same title in classes with the same name whose
+// term started before the assignment's term. Only classes taught by the
+// teacher who requested the check are searched, so a check never exposes
+// the work of another teacher's students.
+func earlierTermAssignmentIDs(db *gorm.DB, assignment *models.Assignment, teacherID int) ([]int, error) {
+	var class models.Class
+	if err := db.Where("class_id = ?", assignment.ClassID).First(&class).Error; err != nil {
+		return nil, fmt.Errorf("class not found: %w", err)
+	}
+	if class.TermID == nil {
+		return nil, errors.New("class is not in a term, earlier terms cannot be included")
+	}
+
+	var term models.Term
+	if err := db.Where("term_id = ?", *class.TermID).First(&term).Error; err != nil {
+		return nil, fmt.Errorf("term not found: %w", err)
+	}
+
+	var assignmentIDs []int
+	if err := db.Table("assignments").
+		Joins("JOIN classes ON classes.class_id = assignments.class_id").
+		Joins("JOIN terms ON terms.term_id = classes.term_id").
+		Joins("JOIN class_teachers ON class_teachers.class_id = classes.class_id AND class_teachers.user_id = ?", teacherID).
+		Where("terms.start_date < ? AND LOWER(assignments.title) = LOWER(?)", term.StartDate, assignment.Title).
+		Where("classes.class_name = ?", class.ClassName).
+		Pluck("assignments.assignment_id", &assignmentIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get earlier assignments: %w", err)
+	}
+
+	return assignmentIDs, nil
+}
+
+// submissionFileText reads a submitted file from upload storage and extracts its text
+func submissionFileText(fileURL string) (string, error) {
+	name, data, err := utils.ReadUploadedFile(fileURL)
+	if err != nil {
+		return "", err
+	}
+	return utils.ExtractText(name, data)
+}
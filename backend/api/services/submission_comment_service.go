@@ -0,0 +1,484 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// maxCommentLength is the longest comment body accepted, in characters
+const maxCommentLength = 5000
+
+// SubmissionCommentService manages the feedback threads on submissions
+// between a student and the teachers of the class
+type SubmissionCommentService interface {
+	Service
+	GetComments(classID, assignmentID, studentID, viewerID int) ([]models.SubmissionComment, error)
+	AddComment(classID, assignmentID, studentID, authorID int, authorRole, body string, parentID, anchorStart, anchorEnd *int) (*models.SubmissionComment, error)
+	EditComment(classID, assignmentID, studentID, commentID, userID int, body string) (*models.SubmissionComment, error)
+	DeleteComment(classID, assignmentID, studentID, commentID, userID int, isStudent bool) error
+	GetCommentHistory(classID, assignmentID, studentID, commentID int) ([]models.SubmissionCommentEdit, error)
+}
+
+// SubmissionCommentServiceImpl implements SubmissionCommentService
+type SubmissionCommentServiceImpl struct {
+	*BaseService
+}
+
+// NewSubmissionCommentService creates a new SubmissionCommentService
+func NewSubmissionCommentService(db *gorm.DB) SubmissionCommentService {
+	return &SubmissionCommentServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// GetComments returns the comment threads of a student's submission, oldest
+// first, with replies nested under the comment they answer. The student is
+// shown by pseudonym to everyone else while identities are hidden.
+func (s *SubmissionCommentServiceImpl) GetComments(classID, assignmentID, studentID, viewerID int) ([]models.SubmissionComment, error) {
+	assignment, submission, err := s.getSubmission(classID, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []models.SubmissionComment
+	if err := s.db.Where("submission_id = ?", submission.SubmissionID).
+		Order("created_at, comment_id").Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	if len(comments) == 0 {
+		return []models.SubmissionComment{}, nil
+	}
+
+	commentIDs := make([]int, len(comments))
+	for i := range comments {
+		commentIDs[i] = comments[i].CommentID
+	}
+	var editCounts []struct {
+		CommentID int
+		Count     int
+	}
+	if err := s.db.Model(&models.SubmissionCommentEdit{}).
+		Select("comment_id, COUNT(*) AS count").
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id").
+		Scan(&editCounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count comment edits: %w", err)
+	}
+	edits := make(map[int]int, len(editCounts))
+	for _, count := range editCounts {
+		edits[count.CommentID] = count.Count
+	}
+
+	names := newCommentAuthorNames(s.db, assignment, studentID, viewerID != studentID)
+	for i := range comments {
+		comment := &comments[i]
+		comment.EditCount = edits[comment.CommentID]
+		if comment.IsDeleted {
+			comment.Body = ""
+		}
+		if err := names.apply(comment); err != nil {
+			return nil, err
+		}
+	}
+
+	return commentTree(comments), nil
+}
+
+// AddComment adds a comment to a student's submission, either a new thread,
+// optionally anchored to a range of the submission's content, or a reply.
+// The other participants of the thread are notified.
+func (s *SubmissionCommentServiceImpl) AddComment(classID, assignmentID, studentID, authorID int, authorRole, body string, parentID, anchorStart, anchorEnd *int) (*models.SubmissionComment, error) {
+	assignment, submission, err := s.getSubmission(classID, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	body, err = validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := models.SubmissionComment{
+		SubmissionID: submission.SubmissionID,
+		ParentID:     parentID,
+		AuthorID:     authorID,
+		AuthorRole:   authorRole,
+		Body:         body,
+	}
+
+	var parent *models.SubmissionComment
+	if parentID != nil {
+		if anchorStart != nil || anchorEnd != nil {
+			return nil, errors.New("replies cannot be anchored, anchor the comment they answer instead")
+		}
+		parent, err = s.getComment(submission.SubmissionID, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.IsDeleted {
+			return nil, errors.New("cannot reply to a deleted comment")
+		}
+	} else if anchorStart != nil || anchorEnd != nil {
+		if anchorStart == nil || anchorEnd == nil {
+			return nil, errors.New("both anchorStart and anchorEnd are required to anchor a comment")
+		}
+		content := []rune(submission.Content)
+		if *anchorStart < 0 || *anchorEnd <= *anchorStart || *anchorEnd > len(content) {
+			return nil, fmt.Errorf("anchor must be a non-empty range within the %d characters of the submission", len(content))
+		}
+		comment.AnchorStart = anchorStart
+		comment.AnchorEnd = anchorEnd
+		comment.AnchorText = string(content[*anchorStart:*anchorEnd])
+	}
+
+	version, err := countSubmissionVersions(s.db, submission.SubmissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission version: %w", err)
+	}
+	comment.Version = max(version, 1)
+
+	// Teachers may only see the student's pseudonym, the student sees real names
+	staffNames := newCommentAuthorNames(s.db, assignment, studentID, true)
+	studentNames := newCommentAuthorNames(s.db, assignment, studentID, false)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return fmt.Errorf("failed to save comment: %w", err)
+		}
+
+		recipients, err := commentRecipients(tx, assignment, studentID, authorID, authorRole, parent)
+		if err != nil {
+			return fmt.Errorf("failed to get comment recipients: %w", err)
+		}
+
+		notifications := make([]models.Notification, 0, len(recipients))
+		for _, userID := range recipients {
+			names := staffNames
+			if userID == studentID {
+				names = studentNames
+			}
+			authorName, err := names.name(authorID, authorRole)
+			if err != nil {
+				return err
+			}
+			message := fmt.Sprintf("%s commented on the submission for %s.", authorName, assignment.Title)
+			if parent != nil {
+				message = fmt.Sprintf("%s replied to a comment on the submission for %s.", authorName, assignment.Title)
+			}
+			notifications = append(notifications, models.Notification{
+				UserID:       userID,
+				Type:         models.NotificationTypeSubmissionComment,
+				Title:        fmt.Sprintf("New comment: %s", assignment.Title),
+				Message:      message,
+				ClassID:      &assignment.ClassID,
+				AssignmentID: &assignment.AssignmentID,
+			})
+		}
+		if err := createNotifications(tx, notifications); err != nil {
+			return fmt.Errorf("failed to create notifications: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := newCommentAuthorNames(s.db, assignment, studentID, authorID != studentID)
+	if err := names.apply(&comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// EditComment changes the body of a comment. Only its author can edit it,
+// and the previous body is kept in the comment's history.
+func (s *SubmissionCommentServiceImpl) EditComment(classID, assignmentID, studentID, commentID, userID int, body string) (*models.SubmissionComment, error) {
+	assignment, submission, err := s.getSubmission(classID, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getComment(submission.SubmissionID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		return nil, errors.New("only the author can edit a comment")
+	}
+	if comment.IsDeleted {
+		return nil, errors.New("cannot edit a deleted comment")
+	}
+
+	body, err = validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if body == comment.Body {
+		return nil, errors.New("comment is unchanged")
+	}
+
+	editedAt := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		edit := models.SubmissionCommentEdit{
+			CommentID: comment.CommentID,
+			Body:      comment.Body,
+			EditedAt:  editedAt,
+		}
+		if err := tx.Create(&edit).Error; err != nil {
+			return fmt.Errorf("failed to save comment history: %w", err)
+		}
+		if err := tx.Model(comment).Updates(map[string]interface{}{"body": body, "edited_at": editedAt}).Error; err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	comment.Body = body
+	comment.EditedAt = &editedAt
+
+	var editCount int64
+	if err := s.db.Model(&models.SubmissionCommentEdit{}).Where("comment_id = ?", comment.CommentID).Count(&editCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count comment edits: %w", err)
+	}
+	comment.EditCount = int(editCount)
+
+	names := newCommentAuthorNames(s.db, assignment, studentID, userID != studentID)
+	if err := names.apply(comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment removes a comment. Students can delete their own comments
+// and teachers any comment; replies stay in the thread.
+func (s *SubmissionCommentServiceImpl) DeleteComment(classID, assignmentID, studentID, commentID, userID int, isStudent bool) error {
+	_, submission, err := s.getSubmission(classID, assignmentID, studentID)
+	if err != nil {
+		return err
+	}
+
+	// Archived classes are read-only
+	if err := ensureClassWritable(s.db, classID); err != nil {
+		return err
+	}
+
+	comment, err := s.getComment(submission.SubmissionID, commentID)
+	if err != nil {
+		return err
+	}
+	if isStudent && comment.AuthorID != userID {
+		return errors.New("students can only delete their own comments")
+	}
+	if comment.IsDeleted {
+		return nil
+	}
+
+	if err := s.db.Model(comment).Update("is_deleted", true).Error; err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
+
+// GetCommentHistory returns the earlier bodies of an edited comment, oldest first
+func (s *SubmissionCommentServiceImpl) GetCommentHistory(classID, assignmentID, studentID, commentID int) ([]models.SubmissionCommentEdit, error) {
+	_, submission, err := s.getSubmission(classID, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getComment(submission.SubmissionID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted {
+		return nil, errors.New("comment not found: it was deleted")
+	}
+
+	var edits []models.SubmissionCommentEdit
+	if err := s.db.Where("comment_id = ?", comment.CommentID).Order("edited_at, edit_id").Find(&edits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment history: %w", err)
+	}
+
+	return edits, nil
+}
+
+func (s *SubmissionCommentServiceImpl) getSubmission(classID, assignmentID, studentID int) (*models.Assignment, *models.Submission, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, nil, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	var submission models.Submission
+	if err := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&submission).Error; err != nil {
+		return nil, nil, fmt.Errorf("submission not found: %w", err)
+	}
+
+	return &assignment, &submission, nil
+}
+
+func (s *SubmissionCommentServiceImpl) getComment(submissionID, commentID int) (*models.SubmissionComment, error) {
+	var comment models.SubmissionComment
+	if err := s.db.Where("comment_id = ? AND submission_id = ?", commentID, submissionID).First(&comment).Error; err != nil {
+		return nil, fmt.Errorf("comment not found: %w", err)
+	}
+	return &comment, nil
+}
+
+// validateCommentBody trims a comment body and checks its length
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("comment body is required")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", fmt.Errorf("comment body cannot be longer than %d characters", maxCommentLength)
+	}
+	return body, nil
+}
+
+// commentRecipients returns the users to notify about a new comment: the
+// teachers for comments by the student, otherwise the student, plus the
+// author of the comment being answered. The author is never notified.
+func commentRecipients(db *gorm.DB, assignment *models.Assignment, studentID, authorID int, authorRole string, parent *models.SubmissionComment) ([]int, error) {
+	var candidates []int
+	if authorRole == "student" {
+		if err := db.Model(&models.ClassTeacher{}).Where("class_id = ?", assignment.ClassID).
+			Pluck("user_id", &candidates).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		candidates = append(candidates, studentID)
+	}
+	if parent != nil {
+		candidates = append(candidates, parent.AuthorID)
+	}
+
+	seen := map[int]bool{authorID: true}
+	recipients := make([]int, 0, len(candidates))
+	for _, userID := range candidates {
+		if !seen[userID] {
+			seen[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+	return recipients, nil
+}
+
+// commentTree nests replies under the comments they answer. Comments must be
+// in the order they are to be listed.
+func commentTree(comments []models.SubmissionComment) []models.SubmissionComment {
+	children := make(map[int][]int)
+	var roots []int
+	exists := make(map[int]bool, len(comments))
+	for _, comment := range comments {
+		exists[comment.CommentID] = true
+	}
+	for i, comment := range comments {
+		if comment.ParentID != nil && exists[*comment.ParentID] {
+			children[*comment.ParentID] = append(children[*comment.ParentID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var build func(i int) models.SubmissionComment
+	build = func(i int) models.SubmissionComment {
+		comment := comments[i]
+		for _, child := range children[comment.CommentID] {
+			comment.Replies = append(comment.Replies, build(child))
+		}
+		return comment
+	}
+
+	tree := make([]models.SubmissionComment, 0, len(roots))
+	for _, i := range roots {
+		tree = append(tree, build(i))
+	}
+	return tree
+}
+
+// commentAuthorNames looks up the display names of comment authors. When
+// the student's identity is hidden from the viewer, the student is shown
+// by their pseudonym and without their user ID.
+type commentAuthorNames struct {
+	db         *gorm.DB
+	assignment *models.Assignment
+	studentID  int
+	hide       bool
+	names      map[int]string
+}
+
+func newCommentAuthorNames(db *gorm.DB, assignment *models.Assignment, studentID int, viewerIsStaff bool) *commentAuthorNames {
+	return &commentAuthorNames{
+		db:         db,
+		assignment: assignment,
+		studentID:  studentID,
+		hide:       viewerIsStaff && assignment.IdentitiesHidden(),
+		names:      make(map[int]string),
+	}
+}
+
+// name returns the display name of a comment author
+func (n *commentAuthorNames) name(authorID int, authorRole string) (string, error) {
+	if authorRole == "student" && n.hide {
+		key, err := anonymityKey(n.db, n.assignment)
+		if err != nil {
+			return "", err
+		}
+		_, pseudonym := studentPseudonym(key, authorID)
+		return pseudonym, nil
+	}
+
+	if name, ok := n.names[authorID]; ok {
+		return name, nil
+	}
+	var name string
+	if authorRole == "student" {
+		name = studentDisplayName(n.db, authorID)
+	} else {
+		var teacher models.TeacherProfile
+		if err := n.db.Where("user_id = ?", authorID).First(&teacher).Error; err == nil {
+			name = fmt.Sprintf("%s %s", teacher.FirstName, teacher.LastName)
+		} else {
+			name = fmt.Sprintf("Staff #%d", authorID)
+		}
+	}
+	n.names[authorID] = name
+	return name, nil
+}
+
+// apply sets the author name of a comment, hiding the student's user ID
+// when they are shown by pseudonym
+func (n *commentAuthorNames) apply(comment *models.SubmissionComment) error {
+	name, err := n.name(comment.AuthorID, comment.AuthorRole)
+	if err != nil {
+		return err
+	}
+	comment.AuthorName = name
+	if comment.AuthorRole == "student" && n.hide {
+		comment.AuthorID = 0
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// SubmissionVersionService gives access to the earlier versions of a submission
+type SubmissionVersionService interface {
+	Service
+	GetVersions(classID, assignmentID, studentID, viewerID int) ([]models.SubmissionVersion, error)
+	GetVersion(classID, assignmentID, studentID, viewerID, version int) (*models.SubmissionVersion, error)
+	DiffVersions(classID, assignmentID, studentID, viewerID, from, to int) (*models.SubmissionVersionDiff, error)
+}
+
+// SubmissionVersionServiceImpl implements SubmissionVersionService
+type SubmissionVersionServiceImpl struct {
+	*BaseService
+}
+
+// NewSubmissionVersionService creates a new SubmissionVersionService
+func NewSubmissionVersionService(db *gorm.DB) SubmissionVersionService {
+	return &SubmissionVersionServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// versionAuthor identifies the student whose versions are shown. Other
+// viewers see the student's pseudonym while the assignment's identities are hidden.
+type versionAuthor struct {
+	studentID   int
+	anonymousID string
+	name        string
+}
+
+// GetVersions lists all versions of a student's submission, oldest first
+func (s *SubmissionVersionServiceImpl) GetVersions(classID, assignmentID, studentID, viewerID int) ([]models.SubmissionVersion, error) {
+	versions, _, err := s.getVersions(classID, assignmentID, studentID, viewerID)
+	return versions, err
+}
+
+// GetVersion returns a single version of a student's submission
+func (s *SubmissionVersionServiceImpl) GetVersion(classID, assignmentID, studentID, viewerID, version int) (*models.SubmissionVersion, error) {
+	versions, _, err := s.getVersions(classID, assignmentID, studentID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return findSubmissionVersion(versions, version)
+}
+
+// DiffVersions compares the content of two versions of a student's submission
+func (s *SubmissionVersionServiceImpl) DiffVersions(classID, assignmentID, studentID, viewerID, from, to int) (*models.SubmissionVersionDiff, error) {
+	versions, author, err := s.getVersions(classID, assignmentID, studentID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	fromVersion, err := findSubmissionVersion(versions, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := findSubmissionVersion(versions, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.SubmissionVersionDiff{
+		SubmissionID: fromVersion.SubmissionID,
+		StudentID:    author.studentID,
+		AnonymousID:  author.anonymousID,
+		StudentName:  author.name,
+		From:         from,
+		To:           to,
+		FileChanged:  fromVersion.FileURL != toVersion.FileURL,
+		FromFileURL:  fromVersion.FileURL,
+		ToFileURL:    toVersion.FileURL,
+	}
+	lines, ok := diffLines(fromVersion.Content, toVersion.Content)
+	if !ok {
+		diff.TooLarge = true
+		return diff, nil
+	}
+	diff.Lines = lines
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case models.DiffAdded:
+			diff.Added++
+		case models.DiffRemoved:
+			diff.Removed++
+		}
+	}
+
+	return diff, nil
+}
+
+// getVersions looks up the versions of a student's submission for an
+// assignment of the class, with the student as the viewer may see them
+func (s *SubmissionVersionServiceImpl) getVersions(classID, assignmentID, studentID, viewerID int) ([]models.SubmissionVersion, *versionAuthor, error) {
+	var assignment models.Assignment
+	if err := s.db.Where("assignment_id = ? AND class_id = ?", assignmentID, classID).First(&assignment).Error; err != nil {
+		return nil, nil, fmt.Errorf("assignment not found: %w", err)
+	}
+
+	var submission models.Submission
+	if err := s.db.Where("assignment_id = ? AND user_id = ?", assignmentID, studentID).First(&submission).Error; err != nil {
+		return nil, nil, fmt.Errorf("submission not found: %w", err)
+	}
+
+	author := &versionAuthor{studentID: studentID}
+	if viewerID != studentID && assignment.IdentitiesHidden() {
+		key, err := anonymityKey(s.db, &assignment)
+		if err != nil {
+			return nil, nil, err
+		}
+		author.studentID = 0
+		author.anonymousID, author.name = studentPseudonym(key, studentID)
+	} else {
+		author.name = studentDisplayName(s.db, studentID)
+	}
+
+	versions, err := loadSubmissionVersions(s.db, &submission)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get submission versions: %w", err)
+	}
+	for i := range versions {
+		versions[i].StudentID = author.studentID
+		versions[i].AnonymousID = author.anonymousID
+		versions[i].StudentName = author.name
+	}
+
+	return versions, author, nil
+}
+
+// findSubmissionVersion returns the version with the given number
+func findSubmissionVersion(versions []models.SubmissionVersion, version int) (*models.SubmissionVersion, error) {
+	for i := range versions {
+		if versions[i].VersionNumber == version {
+			return &versions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("submission version %d not found", version)
+}
+
+// loadSubmissionVersions returns the versions of a submission, oldest first.
+// Submissions made before versions were recorded are returned as a single
+// version built from the submissions row.
+func loadSubmissionVersions(db *gorm.DB, submission *models.Submission) ([]models.SubmissionVersion, error) {
+	var versions []models.SubmissionVersion
+	if err := db.Where("submission_id = ?", submission.SubmissionID).Order("version_number ASC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		versions = append(versions, initialSubmissionVersion(submission))
+	}
+
+	return versions, nil
+}
+
+// initialSubmissionVersion builds the first version of a submission from the submissions row
+func initialSubmissionVersion(submission *models.Submission) models.SubmissionVersion {
+	return models.SubmissionVersion{
+		SubmissionID:  submission.SubmissionID,
+		VersionNumber: 1,
+		Content:       submission.Content,
+		FileURL:       submission.FileURL,
+		SubmittedAt:   submission.SubmissionDate,
+		IsLate:        submission.IsLate,
+	}
+}
+
+// recordSubmissionVersion stores the current state of a submission as its next
+// version and returns the new version number
+func recordSubmissionVersion(tx *gorm.DB, submission *models.Submission) (int, error) {
+	var latest models.SubmissionVersion
+	number := 1
+	err := tx.Where("submission_id = ?", submission.SubmissionID).Order("version_number DESC").First(&latest).Error
+	if err == nil {
+		number = latest.VersionNumber + 1
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	version := initialSubmissionVersion(submission)
+	version.VersionNumber = number
+	if err := tx.Create(&version).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+// countSubmissionVersions returns the number of stored versions of a submission.
+// It is zero for submissions made before versions were recorded.
+func countSubmissionVersions(db *gorm.DB, submissionID int) (int, error) {
+	var count int64
+	if err := db.Model(&models.SubmissionVersion{}).Where("submission_id = ?", submissionID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// maxDiffCells limits the size of the table diffLines builds, so two long
+// submissions that differ throughout cannot exhaust the server's memory
+const maxDiffCells = 4_000_000
+
+// diffLines compares two texts line by line using their longest common
+// subsequence. Removed lines are listed before the lines that replace them.
+// The boolean is false when the texts are too large to compare.
+func diffLines(from, to string) ([]models.DiffLine, bool) {
+	a := splitDiffLines(from)
+	b := splitDiffLines(to)
+
+	// Lines shared at the start and end are equal without comparing the rest
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	changedA := a[prefix : len(a)-suffix]
+	changedB := b[prefix : len(b)-suffix]
+	if (len(changedA)+1)*(len(changedB)+1) > maxDiffCells {
+		return nil, false
+	}
+
+	lines := make([]models.DiffLine, 0, max(len(a), len(b)))
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: a[i], FromLine: i + 1, ToLine: i + 1})
+	}
+	lines = append(lines, lcsDiff(changedA, changedB, prefix)...)
+	for k := suffix; k > 0; k-- {
+		i, j := len(a)-k, len(b)-k
+		lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: a[i], FromLine: i + 1, ToLine: j + 1})
+	}
+
+	return lines, true
+}
+
+// lcsDiff diffs two lists of lines that start after offset equal lines
+func lcsDiff(a, b []string, offset int) []models.DiffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]models.DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: a[i], FromLine: offset + i + 1, ToLine: offset + j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, models.DiffLine{Op: models.DiffRemoved, Text: a[i], FromLine: offset + i + 1})
+			i++
+		default:
+			lines = append(lines, models.DiffLine{Op: models.DiffAdded, Text: b[j], ToLine: offset + j + 1})
+			j++
+		}
+	}
+
+	return lines
+}
+
+// splitDiffLines splits text into lines, treating CRLF as LF. Empty text has no lines.
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
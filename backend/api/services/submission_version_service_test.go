@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+)
+
+// formatDiff writes diff lines as "=from,to text", "-from text" and "+to text"
+func formatDiff(lines []models.DiffLine) []string {
+	var out []string
+	for _, line := range lines {
+		switch line.Op {
+		case models.DiffEqual:
+			out = append(out, fmt.Sprintf("=%d,%d %s", line.FromLine, line.ToLine, line.Text))
+		case models.DiffRemoved:
+			out = append(out, fmt.Sprintf("-%d %s", line.FromLine, line.Text))
+		case models.DiffAdded:
+			out = append(out, fmt.Sprintf("+%d %s", line.ToLine, line.Text))
+		default:
+			out = append(out, "unknown op "+line.Op)
+		}
+	}
+	return out
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []string
+	}{
+		{"both empty", "", "", nil},
+		{"from empty", "", "a\nb", []string{"+1 a", "+2 b"}},
+		{"to empty", "a\nb\n", "", []string{"-1 a", "-2 b"}},
+		{"identical", "a\nb", "a\nb", []string{"=1,1 a", "=2,2 b"}},
+		{"trailing newline ignored", "a\n", "a", []string{"=1,1 a"}},
+		{"CRLF equals LF", "a\r\nb\r\n", "a\nb\n", []string{"=1,1 a", "=2,2 b"}},
+		{"changed middle line", "a\nb\nc", "a\nx\nc", []string{"=1,1 a", "-2 b", "+2 x", "=3,3 c"}},
+		{"inserted line shifts numbers", "a\nb", "a\nnew\nb", []string{"=1,1 a", "+2 new", "=2,3 b"}},
+		{"removed first line", "a\nb\nc", "b\nc", []string{"-1 a", "=2,1 b", "=3,2 c"}},
+		{"added last line", "a", "a\nb", []string{"=1,1 a", "+2 b"}},
+		{"removed lines come before added lines", "a\nb", "c\nd", []string{"-1 a", "-2 b", "+1 c", "+2 d"}},
+		{"common line inside changes", "x\na\ny", "a", []string{"-1 x", "=2,1 a", "-3 y"}},
+		{"blank lines", "a\n\nb", "a\nb", []string{"=1,1 a", "-2 ", "=3,2 b"}},
+		{"repeated lines", "a\na\na", "a\na", []string{"=1,1 a", "=2,2 a", "-3 a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, ok := diffLines(tt.from, tt.to)
+			if !ok {
+				t.Fatal("diffLines() reported the texts as too large")
+			}
+			if got := formatDiff(lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesSize(t *testing.T) {
+	numbered := func(prefix string, n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("%s %d", prefix, i)
+		}
+		return lines
+	}
+	shared := strings.Join(numbered("shared", 5000), "\n")
+
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantOK    bool
+		wantLines int
+	}{
+		{
+			name:   "long texts that differ throughout",
+			from:   strings.Join(numbered("old", 2500), "\n"),
+			to:     strings.Join(numbered("new", 2500), "\n"),
+			wantOK: false,
+		},
+		{
+			name:      "long texts with a small change",
+			from:      shared + "\nold\n" + shared,
+			to:        shared + "\nnew\n" + shared,
+			wantOK:    true,
+			wantLines: 10002,
+		},
+		{
+			name:      "long text compared with nothing",
+			from:      "",
+			to:        shared,
+			wantOK:    true,
+			wantLines: 5000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, ok := diffLines(tt.from, tt.to)
+			if ok != tt.wantOK {
+				t.Fatalf("diffLines() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(lines) != tt.wantLines {
+				t.Errorf("diffLines() returned %d lines, want %d", len(lines), tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestSplitDiffLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"\n", []string{""}},
+		{"a", []string{"a"}},
+		{"a\nb\n", []string{"a", "b"}},
+		{"a\r\nb", []string{"a", "b"}},
+		{"a\n\n", []string{"a", ""}},
+	}
+
+	for _, tt := range tests {
+		if got := splitDiffLines(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitDiffLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"gorm.io/gorm"
+)
+
+// TermService handles academic terms
+type TermService interface {
+	Service
+	CreateTerm(name string, startDate, endDate time.Time) (*models.Term, error)
+	GetTerms() ([]models.Term, error)
+	UpdateTerm(termID int, name string, startDate, endDate time.Time) (*models.Term, error)
+	DeleteTerm(termID int) error
+	ArchiveEndedTerms() (int, error)
+}
+
+// TermServiceImpl implements TermService
+type TermServiceImpl struct {
+	*BaseService
+}
+
+// NewTermService creates a new TermService
+func NewTermService(db *gorm.DB) TermService {
+	return &TermServiceImpl{
+		BaseService: NewBaseService(db),
+	}
+}
+
+// CreateTerm creates a new term
+func (s *TermServiceImpl) CreateTerm(name string, startDate, endDate time.Time) (*models.Term, error) {
+	if !endDate.After(startDate) {
+		return nil, errors.New("term end date must be after the start date")
+	}
+
+	term := models.Term{
+		Name:      name,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	if err := s.db.Create(&term).Error; err != nil {
+		return nil, fmt.Errorf("failed to create term: %w", err)
+	}
+
+	return &term, nil
+}
+
+// GetTerms retrieves all terms, most recent first
+func (s *TermServiceImpl) GetTerms() ([]models.Term, error) {
+	var terms []models.Term
+	if err := s.db.Order("start_date DESC").Find(&terms).Error; err != nil {
+		return nil, fmt.Errorf("failed to get terms: %w", err)
+	}
+	return terms, nil
+}
+
+// UpdateTerm updates a term's name and dates.
+// Moving the end date into the future allows the term to be auto-archived again.
+func (s *TermServiceImpl) UpdateTerm(termID int, name string, startDate, endDate time.Time) (*models.Term, error) {
+	if !endDate.After(startDate) {
+		return nil, errors.New("term end date must be after the start date")
+	}
+
+	var term models.Term
+	if err := s.db.Where("term_id = ?", termID).First(&term).Error; err != nil {
+		return nil, fmt.Errorf("term not found: %w", err)
+	}
+
+	term.Name = name
+	term.StartDate = startDate
+	term.EndDate = endDate
+	if term.EndDate.After(time.Now()) {
+		term.IsArchived = false
+	}
+
+	if err := s.db.Save(&term).Error; err != nil {
+		return nil, fmt.Errorf("failed to update term: %w", err)
+	}
+
+	return &term, nil
+}
+
+// DeleteTerm deletes a term and detaches its classes
+func (s *TermServiceImpl) DeleteTerm(termID int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var term models.Term
+		if err := tx.Where("term_id = ?", termID).First(&term).Error; err != nil {
+			return fmt.Errorf("term not found: %w", err)
+		}
+
+		if err := tx.Model(&models.Class{}).Where("term_id = ?", termID).Update("term_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach classes: %w", err)
+		}
+
+		return tx.Delete(&term).Error
+	})
+}
+
+// ArchiveEndedTerms archives the classes of every term that has ended and
+// returns the number of terms processed. Each term is only processed once,
+// so teachers can still restore individual classes afterwards.
+func (s *TermServiceImpl) ArchiveEndedTerms() (int, error) {
+	var terms []models.Term
+	if err := s.db.Where("end_date < ? AND is_archived = ?", time.Now(), false).Find(&terms).Error; err != nil {
+		return 0, fmt.Errorf("failed to get ended terms: %w", err)
+	}
+
+	for _, term := range terms {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Class{}).
+				Where("term_id = ? AND is_archived = ?", term.TermID, false).
+				Update("is_archived", true)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			log.Printf("Term %q ended, archived %d classes", term.Name, result.RowsAffected)
+			return tx.Model(&models.Term{}).Where("term_id = ?", term.TermID).Update("is_archived", true).Error
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive term %d: %w", term.TermID, err)
+		}
+	}
+
+	return len(terms), nil
+}
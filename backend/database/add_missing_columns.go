@@ -1,6 +1,7 @@
 package database
 
 import (
+	"fmt"
 	"log"
 )
 
@@ -181,5 +182,53 @@ func AddMissingColumns() {
 		}
 	}
 
+	// Enrollment approval and capacity settings
+	addColumnIfNotExists("classes", "requires_approval", "BIT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("classes", "max_capacity", "INT NULL")
+	addColumnIfNotExists("class_enrollments", "status", "NVARCHAR(20) NOT NULL DEFAULT 'active'")
+	addColumnIfNotExists("announcements", "is_pinned", "BIT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("classes", "term_id", "INT NULL")
+	addColumnIfNotExists("class_enrollments", "section_id", "INT NULL")
+	addColumnIfNotExists("assignments", "section_id", "INT NULL")
+	addColumnIfNotExists("announcements", "section_id", "INT NULL")
+	addColumnIfNotExists("assignments", "assignment_type", "NVARCHAR(20) NOT NULL DEFAULT 'standard'")
+	addColumnIfNotExists("assignments", "time_limit_minutes", "INT NULL")
+	addColumnIfNotExists("assignments", "max_attempts", "INT NULL")
+	addColumnIfNotExists("assignments", "shuffle_questions", "BIT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("assignments", "shuffle_options", "BIT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("quiz_questions", "student_id", "INT NULL")
+	addColumnIfNotExists("quiz_questions", "pool_id", "INT NULL")
+	addColumnIfNotExists("quiz_questions", "bank_question_id", "INT NULL")
+	addColumnIfNotExists("assignments", "rubric_id", "INT NULL")
+	addColumnIfNotExists("assignments", "max_resubmissions", "INT NULL")
+	addColumnIfNotExists("assignments", "allow_resubmit_after_grading", "BIT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("assignments", "group_mode", "NVARCHAR(20) NOT NULL DEFAULT 'none'")
+	addColumnIfNotExists("assignments", "max_group_size", "INT NULL")
+	addColumnIfNotExists("submissions", "group_id", "INT NULL")
+	addColumnIfNotExists("assignments", "peer_review_count", "INT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("assignments", "peer_review_prompts", "NVARCHAR(MAX)")
+	addColumnIfNotExists("assignments", "peer_review_weight", "INT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("assignments", "peer_review_assigned_at", "DATETIMEOFFSET NULL")
+	addColumnIfNotExists("submissions", "grade_released", "BIT NOT NULL DEFAULT 1")
+	addColumnIfNotExists("assignments", "grades_released_at", "DATETIMEOFFSET NULL")
+	addColumnIfNotExists("assignments", "anonymous_grading", "BIT NOT NULL DEFAULT 0")
+	addColumnIfNotExists("assignments", "identities_revealed_at", "DATETIMEOFFSET NULL")
+	addColumnIfNotExists("assignments", "anonymity_key", "NVARCHAR(64) NULL")
+
 	log.Println("Finished checking for missing columns")
 }
+
+// addColumnIfNotExists adds a column to a table unless it is already present
+func addColumnIfNotExists(table, column, definition string) {
+	if err := DB.Exec(fmt.Sprintf(`
+		IF NOT EXISTS (
+			SELECT * FROM sys.columns
+			WHERE object_id = OBJECT_ID('%s') AND name = '%s'
+		)
+		ALTER TABLE %s ADD %s %s
+	`, table, column, table, column, definition)).Error; err != nil {
+		log.Printf("Warning: Failed to add %s column to %s table: %v", column, table, err)
+	} else {
+		log.Printf("%s column added to %s table or already exists", column, table)
+	}
+}
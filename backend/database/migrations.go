@@ -182,6 +182,9 @@ func Migrate() {
 			is_archived BIT NOT NULL DEFAULT 0,
 			theme_color NVARCHAR(50),
 			creator_id INT NOT NULL,
+			requires_approval BIT NOT NULL DEFAULT 0,
+			max_capacity INT NULL,
+			term_id INT NULL,
 			CONSTRAINT fk_classes_users FOREIGN KEY (creator_id) REFERENCES users(user_id)
 		)
 	`).Error; err != nil {
@@ -214,6 +217,8 @@ func Migrate() {
 			class_id INT NOT NULL,
 			enrollment_date DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
 			is_active BIT NOT NULL DEFAULT 1,
+			status NVARCHAR(20) NOT NULL DEFAULT 'active',
+			section_id INT NULL,
 			CONSTRAINT fk_class_enrollments_users FOREIGN KEY (user_id) REFERENCES users(user_id),
 			CONSTRAINT fk_class_enrollments_classes FOREIGN KEY (class_id) REFERENCES classes(class_id)
 		)
@@ -269,6 +274,8 @@ func Migrate() {
 			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
 			scheduled_date DATETIMEOFFSET NULL,
 			is_published BIT NOT NULL DEFAULT 1,
+			is_pinned BIT NOT NULL DEFAULT 0,
+			section_id INT NULL,
 			is_deleted BIT NOT NULL DEFAULT 0,
 			CONSTRAINT fk_announcements_classes FOREIGN KEY (class_id) REFERENCES classes(class_id),
 			CONSTRAINT fk_announcements_users FOREIGN KEY (user_id) REFERENCES users(user_id)
@@ -288,6 +295,25 @@ func Migrate() {
 			due_date DATETIMEOFFSET,
 			points_possible INT NOT NULL DEFAULT 100,
 			is_published BIT NOT NULL DEFAULT 0,
+			section_id INT NULL,
+			assignment_type NVARCHAR(20) NOT NULL DEFAULT 'standard',
+			time_limit_minutes INT NULL,
+			max_attempts INT NULL,
+			shuffle_questions BIT NOT NULL DEFAULT 0,
+			shuffle_options BIT NOT NULL DEFAULT 0,
+			rubric_id INT NULL,
+			max_resubmissions INT NULL,
+			allow_resubmit_after_grading BIT NOT NULL DEFAULT 0,
+			group_mode NVARCHAR(20) NOT NULL DEFAULT 'none',
+			max_group_size INT NULL,
+			peer_review_count INT NOT NULL DEFAULT 0,
+			peer_review_prompts NVARCHAR(MAX),
+			peer_review_weight INT NOT NULL DEFAULT 0,
+			peer_review_assigned_at DATETIMEOFFSET NULL,
+			grades_released_at DATETIMEOFFSET NULL,
+			anonymous_grading BIT NOT NULL DEFAULT 0,
+			identities_revealed_at DATETIMEOFFSET NULL,
+			anonymity_key NVARCHAR(64) NULL,
 			created_at DATETIMEOFFSET DEFAULT GETDATE(),
 			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
 			CONSTRAINT fk_assignments_classes FOREIGN KEY (class_id) REFERENCES classes(class_id)
@@ -308,6 +334,8 @@ func Migrate() {
 			grade INT,
 			feedback NVARCHAR(MAX),
 			status NVARCHAR(50) NOT NULL DEFAULT 'submitted',
+			group_id INT NULL,
+			grade_released BIT NOT NULL DEFAULT 1,
 			created_at DATETIMEOFFSET DEFAULT GETDATE(),
 			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
 			CONSTRAINT fk_submissions_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
@@ -317,6 +345,616 @@ func Migrate() {
 		log.Fatalf("Failed to create submissions table: %v", err)
 	}
 
+	// Create oneroster_mappings table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'oneroster_mappings')
+		CREATE TABLE oneroster_mappings (
+			mapping_id INT IDENTITY(1,1) PRIMARY KEY,
+			entity_type NVARCHAR(50) NOT NULL,
+			sourced_id NVARCHAR(255) NOT NULL,
+			local_id INT NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT uq_oneroster_mappings_sourced_id UNIQUE (entity_type, sourced_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create oneroster_mappings table: %v", err)
+	}
+
+	// Create terms table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'terms')
+		CREATE TABLE terms (
+			term_id INT IDENTITY(1,1) PRIMARY KEY,
+			name NVARCHAR(255) NOT NULL,
+			start_date DATETIMEOFFSET NOT NULL,
+			end_date DATETIMEOFFSET NOT NULL,
+			is_archived BIT NOT NULL DEFAULT 0,
+			created_at DATETIMEOFFSET DEFAULT GETDATE()
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create terms table: %v", err)
+	}
+
+	// Create class_sections table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'class_sections')
+		CREATE TABLE class_sections (
+			section_id INT IDENTITY(1,1) PRIMARY KEY,
+			class_id INT NOT NULL,
+			name NVARCHAR(255) NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_class_sections_classes FOREIGN KEY (class_id) REFERENCES classes(class_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create class_sections table: %v", err)
+	}
+
+	// Create class_schedules table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'class_schedules')
+		CREATE TABLE class_schedules (
+			schedule_id INT IDENTITY(1,1) PRIMARY KEY,
+			class_id INT NOT NULL,
+			day_of_week INT NOT NULL,
+			start_time NVARCHAR(5) NOT NULL,
+			end_time NVARCHAR(5) NOT NULL,
+			start_date DATETIMEOFFSET NOT NULL,
+			end_date DATETIMEOFFSET NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_class_schedules_classes FOREIGN KEY (class_id) REFERENCES classes(class_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create class_schedules table: %v", err)
+	}
+
+	// Create class_sessions table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'class_sessions')
+		CREATE TABLE class_sessions (
+			session_id INT IDENTITY(1,1) PRIMARY KEY,
+			class_id INT NOT NULL,
+			schedule_id INT NULL,
+			start_time DATETIMEOFFSET NOT NULL,
+			end_time DATETIMEOFFSET NOT NULL,
+			check_in_secret NVARCHAR(255) NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_class_sessions_classes FOREIGN KEY (class_id) REFERENCES classes(class_id),
+			CONSTRAINT fk_class_sessions_schedules FOREIGN KEY (schedule_id) REFERENCES class_schedules(schedule_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create class_sessions table: %v", err)
+	}
+
+	// Create attendance_records table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'attendance_records')
+		CREATE TABLE attendance_records (
+			record_id INT IDENTITY(1,1) PRIMARY KEY,
+			session_id INT NOT NULL,
+			student_id INT NOT NULL,
+			status NVARCHAR(20) NOT NULL,
+			note NVARCHAR(500),
+			checked_in_at DATETIMEOFFSET NULL,
+			marked_by INT NULL,
+			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_attendance_records_sessions FOREIGN KEY (session_id) REFERENCES class_sessions(session_id),
+			CONSTRAINT fk_attendance_records_users FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT uq_attendance_records_session_student UNIQUE (session_id, student_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create attendance_records table: %v", err)
+	}
+
+	// Create calendar_feed_tokens table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'calendar_feed_tokens')
+		CREATE TABLE calendar_feed_tokens (
+			token_id INT IDENTITY(1,1) PRIMARY KEY,
+			user_id INT NOT NULL UNIQUE,
+			token NVARCHAR(255) NOT NULL UNIQUE,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_calendar_feed_tokens_users FOREIGN KEY (user_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create calendar_feed_tokens table: %v", err)
+	}
+
+	// Create quiz_questions table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'quiz_questions')
+		CREATE TABLE quiz_questions (
+			question_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			position INT NOT NULL,
+			question_type NVARCHAR(20) NOT NULL,
+			prompt NVARCHAR(MAX) NOT NULL,
+			points FLOAT NOT NULL DEFAULT 1,
+			options NVARCHAR(MAX) NULL,
+			correct_answers NVARCHAR(MAX) NULL,
+			tolerance FLOAT NOT NULL DEFAULT 0,
+			case_sensitive BIT NOT NULL DEFAULT 0,
+			student_id INT NULL,
+			pool_id INT NULL,
+			bank_question_id INT NULL,
+			CONSTRAINT fk_quiz_questions_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create quiz_questions table: %v", err)
+	}
+
+	// Create quiz_attempts table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'quiz_attempts')
+		CREATE TABLE quiz_attempts (
+			attempt_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			student_id INT NOT NULL,
+			attempt_number INT NOT NULL,
+			started_at DATETIMEOFFSET NOT NULL,
+			expires_at DATETIMEOFFSET NULL,
+			submitted_at DATETIMEOFFSET NULL,
+			question_order NVARCHAR(MAX) NULL,
+			option_order NVARCHAR(MAX) NULL,
+			answers NVARCHAR(MAX) NULL,
+			results NVARCHAR(MAX) NULL,
+			score FLOAT NULL,
+			max_score FLOAT NOT NULL DEFAULT 0,
+			CONSTRAINT fk_quiz_attempts_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_quiz_attempts_users FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT uq_quiz_attempts_number UNIQUE (assignment_id, student_id, attempt_number)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create quiz_attempts table: %v", err)
+	}
+
+	// Create question_banks table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'question_banks')
+		CREATE TABLE question_banks (
+			bank_id INT IDENTITY(1,1) PRIMARY KEY,
+			owner_id INT NOT NULL,
+			name NVARCHAR(255) NOT NULL,
+			description NVARCHAR(MAX),
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_question_banks_users FOREIGN KEY (owner_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create question_banks table: %v", err)
+	}
+
+	// Create bank_questions table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'bank_questions')
+		CREATE TABLE bank_questions (
+			bank_question_id INT IDENTITY(1,1) PRIMARY KEY,
+			bank_id INT NOT NULL,
+			question_type NVARCHAR(20) NOT NULL,
+			prompt NVARCHAR(MAX) NOT NULL,
+			points FLOAT NOT NULL DEFAULT 1,
+			options NVARCHAR(MAX) NULL,
+			correct_answers NVARCHAR(MAX) NULL,
+			tolerance FLOAT NOT NULL DEFAULT 0,
+			case_sensitive BIT NOT NULL DEFAULT 0,
+			tags NVARCHAR(MAX) NULL,
+			difficulty NVARCHAR(10) NOT NULL DEFAULT 'medium',
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_bank_questions_question_banks FOREIGN KEY (bank_id) REFERENCES question_banks(bank_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create bank_questions table: %v", err)
+	}
+
+	// Create quiz_pools table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'quiz_pools')
+		CREATE TABLE quiz_pools (
+			pool_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			bank_id INT NOT NULL,
+			draw_count INT NOT NULL,
+			tags NVARCHAR(MAX) NULL,
+			difficulty NVARCHAR(10) NULL,
+			points_per_question FLOAT NULL,
+			CONSTRAINT fk_quiz_pools_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_quiz_pools_question_banks FOREIGN KEY (bank_id) REFERENCES question_banks(bank_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create quiz_pools table: %v", err)
+	}
+
+	// Create rubrics table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'rubrics')
+		CREATE TABLE rubrics (
+			rubric_id INT IDENTITY(1,1) PRIMARY KEY,
+			owner_id INT NOT NULL,
+			title NVARCHAR(255) NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_rubrics_users FOREIGN KEY (owner_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create rubrics table: %v", err)
+	}
+
+	// Create rubric_criteria table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'rubric_criteria')
+		CREATE TABLE rubric_criteria (
+			criterion_id INT IDENTITY(1,1) PRIMARY KEY,
+			rubric_id INT NOT NULL,
+			position INT NOT NULL,
+			title NVARCHAR(255) NOT NULL,
+			description NVARCHAR(MAX),
+			CONSTRAINT fk_rubric_criteria_rubrics FOREIGN KEY (rubric_id) REFERENCES rubrics(rubric_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create rubric_criteria table: %v", err)
+	}
+
+	// Create rubric_levels table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'rubric_levels')
+		CREATE TABLE rubric_levels (
+			level_id INT IDENTITY(1,1) PRIMARY KEY,
+			criterion_id INT NOT NULL,
+			position INT NOT NULL,
+			title NVARCHAR(255) NOT NULL,
+			description NVARCHAR(MAX),
+			points INT NOT NULL,
+			CONSTRAINT fk_rubric_levels_rubric_criteria FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(criterion_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create rubric_levels table: %v", err)
+	}
+
+	// Create rubric_scores table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'rubric_scores')
+		CREATE TABLE rubric_scores (
+			score_id INT IDENTITY(1,1) PRIMARY KEY,
+			submission_id INT NOT NULL,
+			criterion_id INT NOT NULL,
+			level_id INT NOT NULL,
+			points INT NOT NULL,
+			comment NVARCHAR(MAX),
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_rubric_scores_submissions FOREIGN KEY (submission_id) REFERENCES submissions(submission_id),
+			CONSTRAINT fk_rubric_scores_rubric_criteria FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(criterion_id),
+			CONSTRAINT uq_rubric_scores_submission_criterion UNIQUE (submission_id, criterion_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create rubric_scores table: %v", err)
+	}
+
+	// Create submission_versions table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'submission_versions')
+		CREATE TABLE submission_versions (
+			version_id INT IDENTITY(1,1) PRIMARY KEY,
+			submission_id INT NOT NULL,
+			version_number INT NOT NULL,
+			content NVARCHAR(MAX),
+			file_url NVARCHAR(MAX),
+			submitted_at DATETIMEOFFSET NOT NULL,
+			is_late BIT NOT NULL DEFAULT 0,
+			CONSTRAINT fk_submission_versions_submissions FOREIGN KEY (submission_id) REFERENCES submissions(submission_id),
+			CONSTRAINT uq_submission_versions_number UNIQUE (submission_id, version_number)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create submission_versions table: %v", err)
+	}
+
+	// Create assignment_extensions table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'assignment_extensions')
+		CREATE TABLE assignment_extensions (
+			extension_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			student_id INT NOT NULL,
+			due_date DATETIMEOFFSET NOT NULL,
+			reason NVARCHAR(500),
+			granted_by INT NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_assignment_extensions_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_assignment_extensions_users FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT uq_assignment_extensions_student UNIQUE (assignment_id, student_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create assignment_extensions table: %v", err)
+	}
+
+	// Create class_accommodations table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'class_accommodations')
+		CREATE TABLE class_accommodations (
+			accommodation_id INT IDENTITY(1,1) PRIMARY KEY,
+			class_id INT NOT NULL,
+			student_id INT NOT NULL,
+			extra_days INT NOT NULL DEFAULT 0,
+			extra_time_percent INT NOT NULL DEFAULT 0,
+			note NVARCHAR(500),
+			created_by INT NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			updated_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_class_accommodations_classes FOREIGN KEY (class_id) REFERENCES classes(class_id),
+			CONSTRAINT fk_class_accommodations_users FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT uq_class_accommodations_student UNIQUE (class_id, student_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create class_accommodations table: %v", err)
+	}
+
+	// Create assignment_groups table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'assignment_groups')
+		CREATE TABLE assignment_groups (
+			group_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			name NVARCHAR(255) NOT NULL,
+			created_by INT NOT NULL,
+			created_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_assignment_groups_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create assignment_groups table: %v", err)
+	}
+
+	// Create assignment_group_members table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'assignment_group_members')
+		CREATE TABLE assignment_group_members (
+			member_id INT IDENTITY(1,1) PRIMARY KEY,
+			group_id INT NOT NULL,
+			assignment_id INT NOT NULL,
+			student_id INT NOT NULL,
+			joined_at DATETIMEOFFSET DEFAULT GETDATE(),
+			CONSTRAINT fk_assignment_group_members_groups FOREIGN KEY (group_id) REFERENCES assignment_groups(group_id),
+			CONSTRAINT fk_assignment_group_members_users FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT uq_assignment_group_members_student UNIQUE (assignment_id, student_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create assignment_group_members table: %v", err)
+	}
+
+	// Create peer_reviews table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'peer_reviews')
+		CREATE TABLE peer_reviews (
+			review_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			submission_id INT NOT NULL,
+			reviewer_id INT NOT NULL,
+			status NVARCHAR(20) NOT NULL DEFAULT 'assigned',
+			answers NVARCHAR(MAX),
+			rubric_scores NVARCHAR(MAX),
+			points INT NULL,
+			comment NVARCHAR(MAX),
+			quality_score INT NULL,
+			assigned_at DATETIMEOFFSET NOT NULL,
+			completed_at DATETIMEOFFSET NULL,
+			CONSTRAINT fk_peer_reviews_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_peer_reviews_submissions FOREIGN KEY (submission_id) REFERENCES submissions(submission_id),
+			CONSTRAINT fk_peer_reviews_users FOREIGN KEY (reviewer_id) REFERENCES users(user_id),
+			CONSTRAINT uq_peer_reviews_reviewer UNIQUE (submission_id, reviewer_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create peer_reviews table: %v", err)
+	}
+
+	// Create similarity_jobs table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'similarity_jobs')
+		CREATE TABLE similarity_jobs (
+			job_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			include_earlier_terms BIT NOT NULL DEFAULT 0,
+			min_score FLOAT NOT NULL,
+			status NVARCHAR(20) NOT NULL DEFAULT 'queued',
+			error NVARCHAR(MAX),
+			warnings NVARCHAR(MAX),
+			submission_count INT NOT NULL DEFAULT 0,
+			pair_count INT NOT NULL DEFAULT 0,
+			requested_by INT NOT NULL,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			started_at DATETIMEOFFSET NULL,
+			completed_at DATETIMEOFFSET NULL,
+			CONSTRAINT fk_similarity_jobs_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_similarity_jobs_users FOREIGN KEY (requested_by) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create similarity_jobs table: %v", err)
+	}
+
+	// Create similarity_pairs table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'similarity_pairs')
+		CREATE TABLE similarity_pairs (
+			pair_id INT IDENTITY(1,1) PRIMARY KEY,
+			job_id INT NOT NULL,
+			submission_id INT NOT NULL,
+			student_id INT NOT NULL,
+			other_submission_id INT NOT NULL,
+			other_student_id INT NOT NULL,
+			other_assignment_id INT NOT NULL,
+			score FLOAT NOT NULL,
+			submission_share FLOAT NOT NULL,
+			other_share FLOAT NOT NULL,
+			spans NVARCHAR(MAX),
+			CONSTRAINT fk_similarity_pairs_jobs FOREIGN KEY (job_id) REFERENCES similarity_jobs(job_id) ON DELETE CASCADE,
+			CONSTRAINT fk_similarity_pairs_submissions FOREIGN KEY (submission_id) REFERENCES submissions(submission_id),
+			CONSTRAINT fk_similarity_pairs_other_submissions FOREIGN KEY (other_submission_id) REFERENCES submissions(submission_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create similarity_pairs table: %v", err)
+	}
+
+	// Create notifications table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'notifications')
+		CREATE TABLE notifications (
+			notification_id INT IDENTITY(1,1) PRIMARY KEY,
+			user_id INT NOT NULL,
+			type NVARCHAR(50) NOT NULL,
+			title NVARCHAR(255) NOT NULL,
+			message NVARCHAR(MAX),
+			class_id INT NULL,
+			assignment_id INT NULL,
+			is_read BIT NOT NULL DEFAULT 0,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			read_at DATETIMEOFFSET NULL,
+			CONSTRAINT fk_notifications_users FOREIGN KEY (user_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create notifications table: %v", err)
+	}
+
+	// Create grading_audit_log table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'grading_audit_log')
+		CREATE TABLE grading_audit_log (
+			audit_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			grader_id INT NOT NULL,
+			student_id INT NULL,
+			action NVARCHAR(50) NOT NULL,
+			grade INT NULL,
+			identity_hidden BIT NOT NULL DEFAULT 0,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			CONSTRAINT fk_grading_audit_log_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_grading_audit_log_graders FOREIGN KEY (grader_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create grading_audit_log table: %v", err)
+	}
+
+	// Create submission_comments table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'submission_comments')
+		CREATE TABLE submission_comments (
+			comment_id INT IDENTITY(1,1) PRIMARY KEY,
+			submission_id INT NOT NULL,
+			parent_id INT NULL,
+			author_id INT NOT NULL,
+			author_role NVARCHAR(20) NOT NULL,
+			body NVARCHAR(MAX) NOT NULL,
+			anchor_start INT NULL,
+			anchor_end INT NULL,
+			anchor_text NVARCHAR(MAX),
+			version INT NOT NULL DEFAULT 1,
+			is_deleted BIT NOT NULL DEFAULT 0,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			edited_at DATETIMEOFFSET NULL,
+			CONSTRAINT fk_submission_comments_submissions FOREIGN KEY (submission_id) REFERENCES submissions(submission_id),
+			CONSTRAINT fk_submission_comments_parents FOREIGN KEY (parent_id) REFERENCES submission_comments(comment_id),
+			CONSTRAINT fk_submission_comments_authors FOREIGN KEY (author_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create submission_comments table: %v", err)
+	}
+
+	// Create submission_comment_edits table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'submission_comment_edits')
+		CREATE TABLE submission_comment_edits (
+			edit_id INT IDENTITY(1,1) PRIMARY KEY,
+			comment_id INT NOT NULL,
+			body NVARCHAR(MAX) NOT NULL,
+			edited_at DATETIMEOFFSET NOT NULL,
+			CONSTRAINT fk_submission_comment_edits_comments FOREIGN KEY (comment_id) REFERENCES submission_comments(comment_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create submission_comment_edits table: %v", err)
+	}
+
+	// Create regrade_requests table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'regrade_requests')
+		CREATE TABLE regrade_requests (
+			request_id INT IDENTITY(1,1) PRIMARY KEY,
+			assignment_id INT NOT NULL,
+			submission_id INT NOT NULL,
+			student_id INT NOT NULL,
+			reason NVARCHAR(MAX) NOT NULL,
+			criterion_ids NVARCHAR(MAX),
+			status NVARCHAR(20) NOT NULL DEFAULT 'pending',
+			original_grade INT NOT NULL,
+			new_grade INT NULL,
+			response NVARCHAR(MAX),
+			resolved_by INT NULL,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			resolved_at DATETIMEOFFSET NULL,
+			CONSTRAINT fk_regrade_requests_assignments FOREIGN KEY (assignment_id) REFERENCES assignments(assignment_id),
+			CONSTRAINT fk_regrade_requests_submissions FOREIGN KEY (submission_id) REFERENCES submissions(submission_id),
+			CONSTRAINT fk_regrade_requests_students FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT fk_regrade_requests_resolvers FOREIGN KEY (resolved_by) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create regrade_requests table: %v", err)
+	}
+
+	// Create at_risk_rules table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'at_risk_rules')
+		CREATE TABLE at_risk_rules (
+			rule_id INT IDENTITY(1,1) PRIMARY KEY,
+			class_id INT NOT NULL,
+			name NVARCHAR(255) NOT NULL,
+			rule_type NVARCHAR(50) NOT NULL,
+			threshold FLOAT NOT NULL,
+			is_enabled BIT NOT NULL DEFAULT 1,
+			notify_teachers BIT NOT NULL DEFAULT 1,
+			notify_guardians BIT NOT NULL DEFAULT 0,
+			created_by INT NOT NULL,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			updated_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			CONSTRAINT fk_at_risk_rules_classes FOREIGN KEY (class_id) REFERENCES classes(class_id),
+			CONSTRAINT fk_at_risk_rules_users FOREIGN KEY (created_by) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create at_risk_rules table: %v", err)
+	}
+
+	// Create at_risk_flags table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'at_risk_flags')
+		CREATE TABLE at_risk_flags (
+			flag_id INT IDENTITY(1,1) PRIMARY KEY,
+			class_id INT NOT NULL,
+			student_id INT NOT NULL,
+			rule_id INT NOT NULL,
+			detail NVARCHAR(500),
+			flagged_at DATETIMEOFFSET NOT NULL,
+			last_evaluated_at DATETIMEOFFSET NOT NULL,
+			resolved_at DATETIMEOFFSET NULL,
+			CONSTRAINT fk_at_risk_flags_classes FOREIGN KEY (class_id) REFERENCES classes(class_id),
+			CONSTRAINT fk_at_risk_flags_students FOREIGN KEY (student_id) REFERENCES users(user_id),
+			CONSTRAINT fk_at_risk_flags_rules FOREIGN KEY (rule_id) REFERENCES at_risk_rules(rule_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create at_risk_flags table: %v", err)
+	}
+
+	// Create student_guardians table
+	if err := DB.Exec(`
+		IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = 'student_guardians')
+		CREATE TABLE student_guardians (
+			guardian_id INT IDENTITY(1,1) PRIMARY KEY,
+			student_id INT NOT NULL,
+			name NVARCHAR(255) NOT NULL,
+			email NVARCHAR(255) NOT NULL,
+			relationship NVARCHAR(50),
+			receives_alerts BIT NOT NULL DEFAULT 1,
+			created_at DATETIMEOFFSET NOT NULL DEFAULT GETDATE(),
+			CONSTRAINT fk_student_guardians_users FOREIGN KEY (student_id) REFERENCES users(user_id)
+		)
+	`).Error; err != nil {
+		log.Fatalf("Failed to create student_guardians table: %v", err)
+	}
+
 	// All foreign key constraints are now added directly in the table creation
 	log.Println("All foreign key constraints added during table creation")
 
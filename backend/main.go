@@ -197,6 +197,45 @@ func main() {
 	// Setup routes with service factory
 	routes.SetupRoutes(router, serviceFactory)
 
+	// Archive the classes of terms that have ended, checking once an hour
+	go func() {
+		termService := serviceFactory.TermService()
+		for {
+			if count, err := termService.ArchiveEndedTerms(); err != nil {
+				log.Printf("Failed to archive ended terms: %v", err)
+			} else if count > 0 {
+				log.Printf("Archived %d ended terms", count)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+
+	// Run similarity checks left in the queue, for example by a restart
+	go func() {
+		similarityService := serviceFactory.SimilarityService()
+		for {
+			if count, err := similarityService.RunQueuedJobs(); err != nil {
+				log.Printf("Failed to run similarity checks: %v", err)
+			} else if count > 0 {
+				log.Printf("Ran %d queued similarity checks", count)
+			}
+			time.Sleep(5 * time.Minute)
+		}
+	}()
+
+	// Flag at-risk students with each class's rules
+	go func() {
+		atRiskService := serviceFactory.AtRiskService()
+		for {
+			if count, err := atRiskService.EvaluateAllClasses(); err != nil {
+				log.Printf("Failed to evaluate at-risk rules: %v", err)
+			} else if count > 0 {
+				log.Printf("Evaluated at-risk rules of %d classes", count)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+
 	// Get port from environment
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// CSVRecord is a single CSV row keyed by normalized header name
+type CSVRecord struct {
+	Line   int
+	Values map[string]string
+}
+
+// Get returns the first non-empty value among the given header names
+func (r CSVRecord) Get(names ...string) string {
+	for _, name := range names {
+		if value := r.Values[NormalizeCSVHeader(name)]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// NormalizeCSVHeader lowercases a header and strips spaces, dashes and
+// underscores so that "First Name", "first_name" and "firstName" all match
+func NormalizeCSVHeader(header string) string {
+	header = strings.TrimPrefix(header, "\ufeff") // Excel byte order mark
+	header = strings.ToLower(strings.TrimSpace(header))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(header)
+}
+
+// ReadCSVRecords reads a CSV file with a header row into records keyed by
+// normalized header name. Blank lines are skipped.
+func ReadCSVRecords(r io.Reader, maxRows int) ([]CSVRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range header {
+		header[i] = NormalizeCSVHeader(header[i])
+	}
+
+	var records []CSVRecord
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		values := make(map[string]string, len(header))
+		empty := true
+		for i, field := range fields {
+			if i >= len(header) {
+				break
+			}
+			field = strings.TrimSpace(field)
+			if field != "" {
+				empty = false
+			}
+			values[header[i]] = field
+		}
+		if empty {
+			continue
+		}
+
+		records = append(records, CSVRecord{Line: line, Values: values})
+		if maxRows > 0 && len(records) > maxRows {
+			return nil, errors.New("CSV file has too many rows")
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCSVHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"email", "email"},
+		{"First Name", "firstname"},
+		{"first_name", "firstname"},
+		{"firstName", "firstname"},
+		{"  last-name ", "lastname"},
+		{"\ufeffEmail", "email"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeCSVHeader(tt.header); got != tt.want {
+			t.Errorf("NormalizeCSVHeader(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestReadCSVRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		maxRows int
+		want    []CSVRecord
+		wantErr string
+	}{
+		{
+			name: "headers are normalized and values trimmed",
+			csv:  "Email,First Name\n alice@example.com , Alice \n",
+			want: []CSVRecord{
+				{Line: 2, Values: map[string]string{"email": "alice@example.com", "firstname": "Alice"}},
+			},
+		},
+		{
+			name: "blank rows are skipped but keep line numbers",
+			csv:  "email,name\n\na@example.com,A\n,\nb@example.com,B\n",
+			want: []CSVRecord{
+				{Line: 3, Values: map[string]string{"email": "a@example.com", "name": "A"}},
+				{Line: 5, Values: map[string]string{"email": "b@example.com", "name": "B"}},
+			},
+		},
+		{
+			name: "short rows leave columns out and extra fields are ignored",
+			csv:  "email,name\na@example.com\nb@example.com,B,extra\n",
+			want: []CSVRecord{
+				{Line: 2, Values: map[string]string{"email": "a@example.com"}},
+				{Line: 3, Values: map[string]string{"email": "b@example.com", "name": "B"}},
+			},
+		},
+		{
+			name: "quoted fields keep commas",
+			csv:  "name,grade\n\"Smith, Jo\",90\n",
+			want: []CSVRecord{
+				{Line: 2, Values: map[string]string{"name": "Smith, Jo", "grade": "90"}},
+			},
+		},
+		{
+			name: "header only",
+			csv:  "email,name\n",
+			want: nil,
+		},
+		{
+			name:    "empty file",
+			csv:     "",
+			wantErr: "CSV file is empty",
+		},
+		{
+			name:    "too many rows",
+			csv:     "email\na@example.com\nb@example.com\nc@example.com\n",
+			maxRows: 2,
+			wantErr: "CSV file has too many rows",
+		},
+		{
+			name:    "row limit reached exactly",
+			csv:     "email\na@example.com\nb@example.com\n",
+			maxRows: 2,
+			want: []CSVRecord{
+				{Line: 2, Values: map[string]string{"email": "a@example.com"}},
+				{Line: 3, Values: map[string]string{"email": "b@example.com"}},
+			},
+		},
+		{
+			name:    "malformed quotes",
+			csv:     "email\n\"unterminated\n",
+			wantErr: "extraneous or missing \" in quoted-field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSVRecords(strings.NewReader(tt.csv), tt.maxRows)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVRecordGet(t *testing.T) {
+	record := CSVRecord{Values: map[string]string{"email": "", "emailaddress": "a@example.com", "firstname": "Alice"}}
+
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"First Name"}, "Alice"},
+		{[]string{"email", "Email Address"}, "a@example.com"},
+		{[]string{"missing"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := record.Get(tt.names...); got != tt.want {
+			t.Errorf("Get(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
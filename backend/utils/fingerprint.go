@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// Winnowing parameters: every fingerprint covers FingerprintWordCount words,
+// and one fingerprint is kept from each window of fingerprintWindow
+// consecutive word sequences. Any match of at least
+// FingerprintWordCount+fingerprintWindow-1 words is guaranteed to be found.
+const (
+	FingerprintWordCount = 5
+	fingerprintWindow    = 4
+)
+
+// Fingerprint is the hash of a sequence of words and the rune offsets of the
+// sequence in the original text
+type Fingerprint struct {
+	Hash  uint64
+	Start int
+	End   int
+}
+
+// TextMatch is a span of text found in both compared texts, as rune offsets
+type TextMatch struct {
+	AStart int
+	AEnd   int
+	BStart int
+	BEnd   int
+}
+
+// TextComparison is the result of comparing the fingerprints of two texts.
+// ScoreA is the share of A's fingerprints found in B and ScoreB the share of
+// B's fingerprints found in A, both between 0 and 1.
+type TextComparison struct {
+	ScoreA  float64
+	ScoreB  float64
+	Matches []TextMatch
+}
+
+// word is a normalized word and its rune offsets in the original text
+type word struct {
+	text  string
+	start int
+	end   int
+}
+
+// splitWords splits text into lower case words of letters and digits.
+// Punctuation and white space are ignored so that reformatting text does not
+// hide copied passages.
+func splitWords(text string) []word {
+	var words []word
+	var current strings.Builder
+	start := -1
+	position := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = position
+			}
+			current.WriteRune(unicode.ToLower(r))
+		} else if start >= 0 {
+			words = append(words, word{text: current.String(), start: start, end: position})
+			current.Reset()
+			start = -1
+		}
+		position++
+	}
+	if start >= 0 {
+		words = append(words, word{text: current.String(), start: start, end: position})
+	}
+	return words
+}
+
+// Fingerprints returns the winnowed fingerprints of a text, ordered by
+// position. Texts shorter than FingerprintWordCount words have none.
+func Fingerprints(text string) []Fingerprint {
+	words := splitWords(text)
+	if len(words) < FingerprintWordCount {
+		return nil
+	}
+
+	shingles := make([]Fingerprint, 0, len(words)-FingerprintWordCount+1)
+	for i := 0; i+FingerprintWordCount <= len(words); i++ {
+		hash := fnv.New64a()
+		for _, w := range words[i : i+FingerprintWordCount] {
+			hash.Write([]byte(w.text))
+			hash.Write([]byte{0})
+		}
+		shingles = append(shingles, Fingerprint{
+			Hash:  hash.Sum64(),
+			Start: words[i].start,
+			End:   words[i+FingerprintWordCount-1].end,
+		})
+	}
+
+	if len(shingles) <= fingerprintWindow {
+		return []Fingerprint{minFingerprint(shingles)}
+	}
+
+	// Keep the smallest hash of every window, taking the rightmost one on
+	// ties, and record each selected shingle only once
+	var fingerprints []Fingerprint
+	lastSelected := -1
+	for i := 0; i+fingerprintWindow <= len(shingles); i++ {
+		selected := i
+		for j := i; j < i+fingerprintWindow; j++ {
+			if shingles[j].Hash <= shingles[selected].Hash {
+				selected = j
+			}
+		}
+		if selected != lastSelected {
+			fingerprints = append(fingerprints, shingles[selected])
+			lastSelected = selected
+		}
+	}
+	return fingerprints
+}
+
+// minFingerprint returns the fingerprint with the smallest hash
+func minFingerprint(shingles []Fingerprint) Fingerprint {
+	selected := shingles[0]
+	for _, shingle := range shingles[1:] {
+		if shingle.Hash <= selected.Hash {
+			selected = shingle
+		}
+	}
+	return selected
+}
+
+// CompareFingerprints compares the fingerprints of two texts and returns how
+// much of each text is shared, along with the matching spans in the order
+// they appear in A. Neighbouring shared fingerprints are merged into one span
+// when they are also neighbours in the other text.
+func CompareFingerprints(a, b []Fingerprint) TextComparison {
+	var comparison TextComparison
+	if len(a) == 0 || len(b) == 0 {
+		return comparison
+	}
+
+	inA := make(map[uint64]bool, len(a))
+	for _, fingerprint := range a {
+		inA[fingerprint.Hash] = true
+	}
+	inB := make(map[uint64][]Fingerprint, len(b))
+	for _, fingerprint := range b {
+		inB[fingerprint.Hash] = append(inB[fingerprint.Hash], fingerprint)
+	}
+
+	sharedA := 0
+	for _, fingerprint := range a {
+		if _, ok := inB[fingerprint.Hash]; ok {
+			sharedA++
+		}
+	}
+	sharedB := 0
+	for _, fingerprint := range b {
+		if inA[fingerprint.Hash] {
+			sharedB++
+		}
+	}
+	comparison.ScoreA = float64(sharedA) / float64(len(a))
+	comparison.ScoreB = float64(sharedB) / float64(len(b))
+
+	var current *TextMatch
+	for _, fingerprint := range a {
+		occurrences, ok := inB[fingerprint.Hash]
+		if !ok {
+			continue
+		}
+
+		// Prefer the occurrence that continues the current span
+		other := occurrences[0]
+		extends := false
+		if current != nil && fingerprint.Start <= current.AEnd {
+			for _, occurrence := range occurrences {
+				if occurrence.Start >= current.BStart && occurrence.Start <= current.BEnd {
+					other = occurrence
+					extends = true
+					break
+				}
+			}
+		}
+
+		if extends {
+			current.AEnd = max(current.AEnd, fingerprint.End)
+			current.BEnd = max(current.BEnd, other.End)
+			continue
+		}
+
+		comparison.Matches = append(comparison.Matches, TextMatch{
+			AStart: fingerprint.Start,
+			AEnd:   fingerprint.End,
+			BStart: other.Start,
+			BEnd:   other.End,
+		})
+		current = &comparison.Matches[len(comparison.Matches)-1]
+	}
+
+	return comparison
+}
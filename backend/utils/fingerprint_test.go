@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const passage = "The mitochondria is the powerhouse of the cell and produces most of the chemical energy " +
+	"needed to power the biochemical reactions of the cell, stored in a small molecule called adenosine triphosphate."
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []word
+	}{
+		{"", nil},
+		{" ,.! ", nil},
+		{"Hello, World!", []word{{"hello", 0, 5}, {"world", 7, 12}}},
+		{"route66 is\tOPEN", []word{{"route66", 0, 7}, {"is", 8, 10}, {"open", 11, 15}}},
+		// Offsets count runes, not bytes
+		{"Café über-alles", []word{{"café", 0, 4}, {"über", 5, 9}, {"alles", 10, 15}}},
+	}
+
+	for _, tt := range tests {
+		if got := splitWords(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int // number of fingerprints, -1 for at least one
+	}{
+		{"empty", "", 0},
+		{"fewer words than a fingerprint", "one two three four", 0},
+		{"exactly one fingerprint", "one two three four five", 1},
+		{"one window", "one two three four five six seven eight", 1},
+		{"long text", passage, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fingerprints := Fingerprints(tt.text)
+			if tt.want >= 0 && len(fingerprints) != tt.want {
+				t.Fatalf("got %d fingerprints, want %d", len(fingerprints), tt.want)
+			}
+			if tt.want < 0 && len(fingerprints) == 0 {
+				t.Fatal("got no fingerprints")
+			}
+
+			length := utf8.RuneCountInString(tt.text)
+			for i, fingerprint := range fingerprints {
+				if fingerprint.Start < 0 || fingerprint.Start >= fingerprint.End || fingerprint.End > length {
+					t.Errorf("fingerprint %d has offsets %d-%d in a text of %d runes", i, fingerprint.Start, fingerprint.End, length)
+				}
+				if i > 0 && fingerprint.Start <= fingerprints[i-1].Start {
+					t.Errorf("fingerprint %d is not after fingerprint %d", i, i-1)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprintsWindowGuarantee(t *testing.T) {
+	// Every run of fingerprintWindow word sequences must contribute a fingerprint,
+	// so no gap between fingerprints can be larger than the window
+	words := splitWords(passage)
+	fingerprints := Fingerprints(passage)
+
+	sequence := make(map[int]int, len(words))
+	for i, w := range words {
+		sequence[w.start] = i
+	}
+	last := len(words) - FingerprintWordCount
+
+	previous := -1
+	for _, fingerprint := range fingerprints {
+		index, ok := sequence[fingerprint.Start]
+		if !ok {
+			t.Fatalf("fingerprint starts at %d, which is not the start of a word", fingerprint.Start)
+		}
+		if index-previous > fingerprintWindow {
+			t.Errorf("gap of %d word sequences before sequence %d", index-previous, index)
+		}
+		previous = index
+	}
+	if last-previous >= fingerprintWindow {
+		t.Errorf("gap of %d word sequences at the end", last-previous)
+	}
+}
+
+func TestFingerprintsIgnoreFormatting(t *testing.T) {
+	reformatted := strings.ToUpper(strings.NewReplacer(" ", "\n\n  ", ",", ";", ".", "!").Replace(passage))
+
+	a, b := Fingerprints(passage), Fingerprints(reformatted)
+	if len(a) != len(b) {
+		t.Fatalf("got %d and %d fingerprints", len(a), len(b))
+	}
+	for i := range a {
+		if a[i].Hash != b[i].Hash {
+			t.Errorf("fingerprint %d differs after reformatting", i)
+		}
+	}
+}
+
+func TestCompareFingerprints(t *testing.T) {
+	const other = "Photosynthesis converts light energy into chemical energy that plants store as glucose, " +
+		"releasing oxygen into the atmosphere as a by-product of splitting water molecules."
+	copied := "In my own words: ünïcode prefix. " + passage + " That is all I have to say about it today."
+
+	tests := []struct {
+		name        string
+		a           string
+		b           string
+		wantScoreA  float64 // -1 for between 0 and 1, exclusive
+		wantScoreB  float64
+		wantMatches int
+	}{
+		{"empty texts", "", "", 0, 0, 0},
+		{"one empty text", passage, "", 0, 0, 0},
+		{"identical texts", passage, passage, 1, 1, 1},
+		{"unrelated texts", passage, other, 0, 0, 0},
+		{"copied passage", passage, copied, 1, -1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := CompareFingerprints(Fingerprints(tt.a), Fingerprints(tt.b))
+			checkScore(t, "ScoreA", comparison.ScoreA, tt.wantScoreA)
+			checkScore(t, "ScoreB", comparison.ScoreB, tt.wantScoreB)
+
+			if len(comparison.Matches) != tt.wantMatches {
+				t.Fatalf("got %d matches, want %d: %+v", len(comparison.Matches), tt.wantMatches, comparison.Matches)
+			}
+			runesA, runesB := []rune(tt.a), []rune(tt.b)
+			for i, match := range comparison.Matches {
+				textA := string(runesA[match.AStart:match.AEnd])
+				textB := string(runesB[match.BStart:match.BEnd])
+				// Winnowing may skip the first and last words, but a match never leaves the shared passage
+				if len(strings.Fields(textA)) < FingerprintWordCount || !strings.Contains(passage, textA) {
+					t.Errorf("match %d in A = %q, want part of the shared passage", i, textA)
+				}
+				if textB != textA {
+					t.Errorf("match %d in B = %q, want %q", i, textB, textA)
+				}
+			}
+		})
+	}
+}
+
+func checkScore(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if want < 0 {
+		if got <= 0 || got >= 1 {
+			t.Errorf("%s = %v, want between 0 and 1", name, got)
+		}
+		return
+	}
+	if got != want {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// ICalEvent is a single event in an iCalendar (RFC 5545) feed
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	URL         string
+}
+
+// icalTimeFormat is the UTC date-time format used by iCalendar
+const icalTimeFormat = "20060102T150405Z"
+
+// icalEscaper escapes text values as required by RFC 5545
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// WriteICalendar renders events as an iCalendar document
+func WriteICalendar(name string, events []ICalEvent) []byte {
+	var buf bytes.Buffer
+	stamp := time.Now().UTC().Format(icalTimeFormat)
+
+	writeICalLine(&buf, "BEGIN:VCALENDAR")
+	writeICalLine(&buf, "VERSION:2.0")
+	writeICalLine(&buf, "PRODID:-//ClassConnect//Calendar//EN")
+	writeICalLine(&buf, "CALSCALE:GREGORIAN")
+	writeICalLine(&buf, "METHOD:PUBLISH")
+	writeICalLine(&buf, "X-WR-CALNAME:"+icalEscaper.Replace(name))
+
+	for _, event := range events {
+		end := event.End
+		if end.Before(event.Start) {
+			end = event.Start
+		}
+
+		writeICalLine(&buf, "BEGIN:VEVENT")
+		writeICalLine(&buf, "UID:"+event.UID)
+		writeICalLine(&buf, "DTSTAMP:"+stamp)
+		writeICalLine(&buf, "DTSTART:"+event.Start.UTC().Format(icalTimeFormat))
+		writeICalLine(&buf, "DTEND:"+end.UTC().Format(icalTimeFormat))
+		writeICalLine(&buf, "SUMMARY:"+icalEscaper.Replace(event.Summary))
+		if event.Description != "" {
+			writeICalLine(&buf, "DESCRIPTION:"+icalEscaper.Replace(event.Description))
+		}
+		if event.URL != "" {
+			writeICalLine(&buf, "URL:"+event.URL)
+		}
+		writeICalLine(&buf, "END:VEVENT")
+	}
+
+	writeICalLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeICalLine writes a content line, folding it at 75 octets without splitting UTF-8 characters
+func writeICalLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
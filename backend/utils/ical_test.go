@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// unfoldICal joins folded iCalendar lines and splits the document into content lines
+func unfoldICal(doc string) []string {
+	doc = strings.ReplaceAll(doc, "\r\n ", "")
+	return strings.Split(strings.TrimSuffix(doc, "\r\n"), "\r\n")
+}
+
+func TestWriteICalLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantLines int
+	}{
+		{"empty", "", 1},
+		{"short", "SUMMARY:Quiz", 1},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("x", 200), 3},
+		{"multi-byte characters at the fold", "SUMMARY:" + strings.Repeat("é", 60), 2},
+		{"four-byte characters", "SUMMARY:" + strings.Repeat("📚", 40), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeICalLine(&buf, tt.line)
+			out := buf.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line does not end with CRLF: %q", out)
+			}
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(physical) != tt.wantLines {
+				t.Errorf("got %d physical lines, want %d: %q", len(physical), tt.wantLines, physical)
+			}
+			for i, line := range physical {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets, more than 75", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(strings.TrimPrefix(line, " ")) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+			}
+			if got := unfoldICal(out); len(got) != 1 || got[0] != tt.line {
+				t.Errorf("unfolded = %q, want %q", got, tt.line)
+			}
+		})
+	}
+}
+
+func TestWriteICalendarEscaping(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "Essay 1", "Essay 1"},
+		{"comma", "Reading, chapter 2", `Reading\, chapter 2`},
+		{"semicolon", "Part A; part B", `Part A\; part B`},
+		{"backslash", `C:\homework`, `C:\\homework`},
+		{"newline", "line one\nline two", `line one\nline two`},
+		{"CRLF", "line one\r\nline two", `line one\nline two`},
+		{"everything", "a\\b,c;d\ne", `a\\b\,c\;d\ne`},
+	}
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := string(WriteICalendar(tt.value, []ICalEvent{{
+				UID:         "assignment-1@classconnect",
+				Summary:     tt.value,
+				Description: tt.value,
+				Start:       start,
+				End:         start,
+			}}))
+			lines := unfoldICal(doc)
+			for _, want := range []string{"X-WR-CALNAME:" + tt.want, "SUMMARY:" + tt.want, "DESCRIPTION:" + tt.want} {
+				if !containsLine(lines, want) {
+					t.Errorf("missing line %q in %q", want, lines)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteICalendarEvents(t *testing.T) {
+	local := time.FixedZone("UTC+8", 8*60*60)
+	start := time.Date(2026, 3, 2, 17, 30, 0, 0, local)
+
+	tests := []struct {
+		name    string
+		event   ICalEvent
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "times are written in UTC",
+			event: ICalEvent{UID: "a", Summary: "Lab", Start: start, End: start.Add(time.Hour)},
+			want:  []string{"DTSTART:20260302T093000Z", "DTEND:20260302T103000Z"},
+		},
+		{
+			name:  "end before start is moved to the start",
+			event: ICalEvent{UID: "b", Summary: "Due", Start: start, End: start.Add(-time.Hour)},
+			want:  []string{"DTSTART:20260302T093000Z", "DTEND:20260302T093000Z"},
+		},
+		{
+			name:    "optional fields are left out when empty",
+			event:   ICalEvent{UID: "c", Summary: "Quiz", Start: start, End: start},
+			notWant: []string{"DESCRIPTION:", "URL:"},
+		},
+		{
+			name:  "URLs are not escaped",
+			event: ICalEvent{UID: "d", Summary: "Quiz", Start: start, End: start, URL: "http://localhost:3000/classes/1?tab=a,b"},
+			want:  []string{"URL:http://localhost:3000/classes/1?tab=a,b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := unfoldICal(string(WriteICalendar("Calendar", []ICalEvent{tt.event})))
+			for _, want := range append([]string{"BEGIN:VEVENT", "UID:" + tt.event.UID, "END:VEVENT"}, tt.want...) {
+				if !containsLine(lines, want) {
+					t.Errorf("missing line %q in %q", want, lines)
+				}
+			}
+			for _, prefix := range tt.notWant {
+				for _, line := range lines {
+					if strings.HasPrefix(line, prefix) {
+						t.Errorf("unexpected line %q", line)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteICalendarStructure(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	events := []ICalEvent{
+		{UID: "1", Summary: "One", Start: start, End: start},
+		{UID: "2", Summary: "Two", Start: start, End: start},
+	}
+
+	tests := []struct {
+		name       string
+		events     []ICalEvent
+		wantEvents int
+	}{
+		{"no events", nil, 0},
+		{"two events", events, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := string(WriteICalendar("Calendar", tt.events))
+			if strings.Contains(strings.ReplaceAll(doc, "\r\n", ""), "\n") {
+				t.Error("document has a bare LF line ending")
+			}
+
+			lines := unfoldICal(doc)
+			if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+				t.Errorf("document is not wrapped in VCALENDAR: first %q, last %q", lines[0], lines[len(lines)-1])
+			}
+			for _, want := range []string{"VERSION:2.0", "PRODID:-//ClassConnect//Calendar//EN"} {
+				if !containsLine(lines, want) {
+					t.Errorf("missing line %q", want)
+				}
+			}
+			if got := countLines(lines, "BEGIN:VEVENT"); got != tt.wantEvents {
+				t.Errorf("got %d events, want %d", got, tt.wantEvents)
+			}
+			if got := countLines(lines, "END:VEVENT"); got != tt.wantEvents {
+				t.Errorf("got %d event ends, want %d", got, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	return countLines(lines, want) > 0
+}
+
+func countLines(lines []string, want string) int {
+	count := 0
+	for _, line := range lines {
+		if line == want {
+			count++
+		}
+	}
+	return count
+}
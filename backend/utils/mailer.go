@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// SendEmail sends a plain-text email using the SMTP settings from the environment.
+// When SMTP_HOST is not set the email is skipped and only its recipient and
+// subject are logged, which keeps development and offline installations
+// working without a mail server. Bodies are never logged, since they can hold
+// invite tokens and links.
+func SendEmail(to, subject, body string) error {
+	// A line break in a header would let the caller add headers of their own
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("email recipient and subject must not contain line breaks")
+	}
+
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Printf("SMTP_HOST not set, email to %s not sent. Subject: %s", to, subject)
+		return nil
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@classconnect.local"
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(fmt.Sprintf("%s:%s", host, port), auth, from, []string{to}, []byte(message)); err != nil {
+		log.Printf("Error sending email to %s: %v", to, err)
+		return err
+	}
+
+	log.Printf("Email sent to %s: %s", to, subject)
+	return nil
+}
+
+// AppURL returns the public URL of the frontend used in links sent to users
+func AppURL() string {
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	return strings.TrimRight(appURL, "/")
+}
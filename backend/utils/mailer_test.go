@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+func TestSendEmailRejectsLineBreaks(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      string
+		subject string
+		wantErr bool
+	}{
+		{"plain headers", "student@example.com", "You're invited to ClassConnect", false},
+		{"non-ASCII subject", "student@example.com", "Zoë may need support in Français", false},
+		{"line feed in recipient", "student@example.com\nBcc: other@example.com", "Hello", true},
+		{"carriage return in recipient", "student@example.com\r", "Hello", true},
+		{"header in subject", "student@example.com", "Hello\r\nBcc: other@example.com", true},
+	}
+
+	// Without SMTP_HOST nothing is sent, so only the validation runs
+	t.Setenv("SMTP_HOST", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SendEmail(tt.to, tt.subject, "Body")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendEmail() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
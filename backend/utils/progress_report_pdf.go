@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/yongdilun/classconnect-backend/api/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// reportDateFormat is how dates are written on progress reports
+const reportDateFormat = "Jan 2, 2006"
+
+// reportColumn is a column of the assignments table on a progress report
+type reportColumn struct {
+	title string
+	width float64
+	align string
+}
+
+// reportColumns fill the 190 mm between the margins of an A4 page
+var reportColumns = []reportColumn{
+	{"Assignment", 72, "L"},
+	{"Due", 28, "L"},
+	{"Status", 28, "L"},
+	{"Submitted", 34, "L"},
+	{"Grade", 28, "R"},
+}
+
+// WriteProgressReportPDF renders a progress report as an A4 PDF document
+func WriteProgressReportPDF(report models.ProgressReport) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 12, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetTitle(fmt.Sprintf("Progress report: %s, %s", report.StudentName, report.Title), true)
+	pdf.SetCreator("ClassConnect", false)
+	pdf.SetCreationDate(report.GeneratedAt)
+	pdf.AliasNbPages("")
+
+	// Core fonts use the cp1252 code page
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(95, 5, tr(report.StudentName+", "+report.Title), "", 0, "L", false, 0, "")
+		pdf.CellFormat(95, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "Progress Report", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, tr("Student: "+report.StudentName), "", 1, "L", false, 0, "")
+	if len(report.Classes) == 1 && report.Title == report.Classes[0].ClassName {
+		pdf.CellFormat(0, 6, tr("Class: "+report.Title), "", 1, "L", false, 0, "")
+	}
+	if report.TermName != "" {
+		pdf.CellFormat(0, 6, tr("Term: "+report.TermName), "", 1, "L", false, 0, "")
+	}
+	pdf.CellFormat(0, 6, "Generated: "+report.GeneratedAt.Format(reportDateFormat), "", 1, "L", false, 0, "")
+
+	for _, class := range report.Classes {
+		writeClassProgress(pdf, tr, class)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeClassProgress writes the section of a report about one class
+func writeClassProgress(pdf *fpdf.Fpdf, tr func(string) string, class models.ClassProgress) {
+	pdf.Ln(6)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, tr(class.ClassName), "B", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	if len(class.Teachers) > 0 {
+		pdf.CellFormat(0, 6, tr("Teachers: "+strings.Join(class.Teachers, ", ")), "", 1, "L", false, 0, "")
+	}
+
+	average := "-"
+	if class.AveragePercent != nil {
+		average = fmt.Sprintf("%.1f%%", *class.AveragePercent)
+	}
+	pdf.CellFormat(0, 6, fmt.Sprintf("Assigned: %d    Submitted: %d    Late: %d    Missing: %d    Average grade: %s",
+		class.Assigned, class.Submitted, class.Late, class.Missing, average), "", 1, "L", false, 0, "")
+
+	// Assignments
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range reportColumns {
+		pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	if len(class.Assignments) == 0 {
+		pdf.CellFormat(0, 6, "No assignments", "1", 1, "L", false, 0, "")
+	}
+	for _, assignment := range class.Assignments {
+		due := "-"
+		if !assignment.DueDate.IsZero() {
+			due = assignment.DueDate.Format(reportDateFormat)
+		}
+		submitted := "-"
+		if assignment.SubmittedAt != nil {
+			submitted = assignment.SubmittedAt.Format(reportDateFormat)
+			if assignment.IsLate {
+				submitted += " (late)"
+			}
+		}
+		grade := "-"
+		if assignment.Grade != nil {
+			grade = fmt.Sprintf("%d / %d", *assignment.Grade, assignment.PointsPossible)
+		}
+
+		// Missing work stands out
+		if assignment.Status == models.ReportAssignmentMissing {
+			pdf.SetTextColor(180, 0, 0)
+		}
+		values := []string{tr(assignment.Title), due, reportStatusLabel(assignment.Status), submitted, grade}
+		for i, column := range reportColumns {
+			pdf.CellFormat(column.width, 6, fitText(pdf, values[i], column.width-2), "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	// Attendance
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Attendance", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	attendance := class.Attendance
+	if attendance.Sessions == 0 {
+		pdf.CellFormat(0, 6, "No attendance has been taken", "", 1, "L", false, 0, "")
+	} else {
+		line := fmt.Sprintf("Sessions: %d    Present: %d    Late: %d    Absent: %d    Excused: %d",
+			attendance.Sessions, attendance.Present, attendance.Late, attendance.Absent, attendance.Excused)
+		if counted := attendance.Sessions - attendance.Excused; counted > 0 {
+			line += fmt.Sprintf("    Attendance rate: %.0f%%", float64(attendance.Present+attendance.Late)*100/float64(counted))
+		}
+		pdf.CellFormat(0, 6, line, "", 1, "L", false, 0, "")
+	}
+
+	// Teacher comments
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Teacher Comments", "", 1, "L", false, 0, "")
+	if len(class.Comments) == 0 {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, "No comments", "", 1, "L", false, 0, "")
+	}
+	for _, comment := range class.Comments {
+		heading := comment.AssignmentTitle
+		if comment.Author != "" {
+			heading += " - " + comment.Author
+		}
+		heading += ", " + comment.CreatedAt.Format(reportDateFormat)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.MultiCell(0, 5, tr(heading), "", "L", false)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, tr(comment.Body), "", "L", false)
+		pdf.Ln(2)
+	}
+}
+
+// reportStatusLabel returns the label of an assignment status on a report
+func reportStatusLabel(status string) string {
+	switch status {
+	case models.ReportAssignmentGraded:
+		return "Graded"
+	case models.ReportAssignmentSubmitted:
+		return "Submitted"
+	case models.ReportAssignmentMissing:
+		return "Missing"
+	default:
+		return "Upcoming"
+	}
+}
+
+// fitText shortens text with an ellipsis until it fits in width. The text
+// is already translated to the single-byte code page, so it is cut by byte.
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return strings.TrimSpace(text) + "..."
+}
+
+// ProgressReportFilename returns the file name of a progress report PDF
+func ProgressReportFilename(report models.ProgressReport) string {
+	return fmt.Sprintf("progress-report-%s-%d-%s.pdf", slugify(report.StudentName), report.StudentID, slugify(report.Title))
+}
+
+// slugify lowercases text and joins its ASCII letters and digits with
+// dashes, for use in file names. Accents are dropped, so "Zoë" becomes "zoe".
+func slugify(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(strings.ToLower(text)))
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if len(words) == 0 {
+		return "report"
+	}
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxExtractFileSize is the largest submitted file that is read for text
+// extraction
+const maxExtractFileSize = 20 << 20
+
+// uploadPathPrefix is the URL path under which uploaded files are served
+const uploadPathPrefix = "/uploads/"
+
+// plainTextExtensions are file types that are read as they are
+var plainTextExtensions = map[string]bool{
+	".txt": true, ".md": true, ".csv": true, ".rtf": true, ".tex": true,
+	".html": true, ".htm": true, ".xml": true, ".json": true, ".sql": true,
+	".go": true, ".py": true, ".java": true, ".c": true, ".h": true,
+	".cpp": true, ".hpp": true, ".cs": true, ".js": true, ".ts": true,
+	".jsx": true, ".tsx": true, ".rb": true, ".php": true, ".swift": true,
+	".kt": true, ".rs": true, ".m": true, ".r": true, ".sh": true,
+	".css": true, ".scss": true, ".ipynb": true,
+}
+
+// UploadDir returns the directory uploaded files are stored in
+func UploadDir() string {
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return "uploads"
+}
+
+// ReadUploadedFile reads a submitted file from the app's upload storage and
+// returns its name and content. The file URL must be an upload path such as
+// "/uploads/abc.pdf", or start with UPLOAD_URL when that is set. Files
+// elsewhere are never fetched, and files above 20 MB are rejected.
+func ReadUploadedFile(fileURL string) (string, []byte, error) {
+	relative, ok := uploadRelativePath(fileURL)
+	if !ok {
+		return "", nil, fmt.Errorf("file is not in upload storage, external files are not read: %s", fileURL)
+	}
+
+	// Cleaning the path as an absolute one removes any ".." that would leave the directory
+	filename := filepath.Join(UploadDir(), filepath.FromSlash(path.Clean("/"+relative)))
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, fmt.Errorf("uploaded file not found: %s", fileURL)
+		}
+		return "", nil, fmt.Errorf("failed to read %s: %w", fileURL, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return "", nil, fmt.Errorf("uploaded file not found: %s", fileURL)
+	}
+	if info.Size() > maxExtractFileSize {
+		return "", nil, fmt.Errorf("file %s is larger than 20 MB", fileURL)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxExtractFileSize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", fileURL, err)
+	}
+	if len(data) > maxExtractFileSize {
+		return "", nil, fmt.Errorf("file %s is larger than 20 MB", fileURL)
+	}
+
+	return path.Base(relative), data, nil
+}
+
+// uploadRelativePath returns the path of an uploaded file inside the upload
+// storage. The boolean is false for URLs that point anywhere else.
+func uploadRelativePath(fileURL string) (string, bool) {
+	if base := strings.TrimRight(os.Getenv("UPLOAD_URL"), "/"); base != "" && strings.HasPrefix(fileURL, base+"/") {
+		fileURL = uploadPathPrefix + strings.TrimPrefix(fileURL, base+"/")
+	}
+
+	parsed, err := url.Parse(fileURL)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || !strings.HasPrefix(parsed.Path, uploadPathPrefix) {
+		return "", false
+	}
+
+	relative := strings.TrimPrefix(parsed.Path, uploadPathPrefix)
+	if relative == "" {
+		return "", false
+	}
+	return relative, true
+}
+
+// ExtractText returns the text of a plain text, Word (.docx), OpenDocument
+// (.odt) or PDF file. Files of other types are read as text when their
+// content is valid UTF-8.
+func ExtractText(filename string, data []byte) (string, error) {
+	extension := strings.ToLower(path.Ext(filename))
+	switch {
+	case extension == ".docx":
+		return extractZipXMLText(data, "word/document.xml", "p")
+	case extension == ".odt":
+		return extractZipXMLText(data, "content.xml", "p", "h")
+	case extension == ".pdf" || bytes.HasPrefix(data, []byte("%PDF-")):
+		return extractPDFText(data)
+	case plainTextExtensions[extension] || utf8.Valid(data):
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%s is not valid UTF-8 text", filename)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unsupported file type: %s", filename)
+	}
+}
+
+// extractZipXMLText returns the character data of an XML document inside a
+// zip archive, starting a new line after every element in paragraphTags
+func extractZipXMLText(data []byte, name string, paragraphTags ...string) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open document: %w", err)
+	}
+
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == name {
+			document = file
+			break
+		}
+	}
+	if document == nil {
+		return "", fmt.Errorf("document has no %s", name)
+	}
+
+	reader, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open document: %w", err)
+	}
+	defer reader.Close()
+
+	paragraphs := make(map[string]bool, len(paragraphTags))
+	for _, tag := range paragraphTags {
+		paragraphs[tag] = true
+	}
+
+	var text strings.Builder
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read document: %w", err)
+		}
+
+		switch element := token.(type) {
+		case xml.CharData:
+			text.Write(element)
+		case xml.StartElement:
+			if element.Name.Local == "tab" {
+				text.WriteByte('\t')
+			}
+		case xml.EndElement:
+			if paragraphs[element.Name.Local] {
+				text.WriteByte('\n')
+			}
+		}
+	}
+
+	return text.String(), nil
+}
+
+var (
+	pdfStreamPattern = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfTextPattern   = regexp.MustCompile(`(?s)\[(.*?)\]\s*TJ|\((.*?[^\\])?\)\s*(?:Tj|'|")|(T\*|Td|TD|ET)`)
+	pdfStringPattern = regexp.MustCompile(`(?s)\((.*?[^\\])?\)`)
+)
+
+// extractPDFText returns the text shown by the content streams of a PDF.
+// Only uncompressed and FlateDecode streams with literal strings are read,
+// which covers most PDFs exported from word processors; scanned documents
+// and fonts with custom encodings yield little or no text.
+func extractPDFText(data []byte) (string, error) {
+	var text strings.Builder
+	for _, match := range pdfStreamPattern.FindAllSubmatchIndex(data, -1) {
+		dictionary := data[match[2]:match[3]]
+		start := match[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[start : start+end]
+
+		if bytes.Contains(dictionary, []byte("/FlateDecode")) {
+			reader, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			inflated, err := io.ReadAll(io.LimitReader(reader, 10*maxExtractFileSize))
+			reader.Close()
+			if err != nil && len(inflated) == 0 {
+				continue
+			}
+			stream = inflated
+		} else if bytes.Contains(dictionary, []byte("/Filter")) {
+			continue
+		}
+
+		for _, operator := range pdfTextPattern.FindAllSubmatch(stream, -1) {
+			switch {
+			case operator[1] != nil:
+				for _, part := range pdfStringPattern.FindAllSubmatch(operator[1], -1) {
+					text.WriteString(decodePDFString(part[1]))
+				}
+			case operator[3] != nil:
+				text.WriteByte('\n')
+			default:
+				text.WriteString(decodePDFString(operator[2]))
+			}
+		}
+	}
+
+	if strings.TrimSpace(text.String()) == "" {
+		return "", errors.New("no text found in PDF")
+	}
+	return text.String(), nil
+}
+
+// decodePDFString resolves the escape sequences of a PDF literal string,
+// reading its bytes as Latin-1
+func decodePDFString(raw []byte) string {
+	var text strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 == len(raw) {
+			text.WriteRune(rune(raw[i]))
+			continue
+		}
+
+		i++
+		switch raw[i] {
+		case 'n', 'r':
+			text.WriteByte('\n')
+		case 't':
+			text.WriteByte('\t')
+		case 'b', 'f':
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			value := 0
+			digits := 0
+			for ; digits < 3 && i < len(raw) && raw[i] >= '0' && raw[i] <= '7'; digits++ {
+				value = value*8 + int(raw[i]-'0')
+				i++
+			}
+			i--
+			text.WriteRune(rune(value & 0xff))
+		default:
+			text.WriteRune(rune(raw[i]))
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadRelativePath(t *testing.T) {
+	tests := []struct {
+		name      string
+		uploadURL string
+		fileURL   string
+		want      string
+		wantOK    bool
+	}{
+		{"upload path", "", "/uploads/essay.pdf", "essay.pdf", true},
+		{"nested upload path", "", "/uploads/class-1/essay.pdf", "class-1/essay.pdf", true},
+		{"query string dropped", "", "/uploads/essay.pdf?v=2", "essay.pdf", true},
+		{"upload directory itself", "", "/uploads/", "", false},
+		{"other path", "", "/api/users", "", false},
+		{"relative path", "", "uploads/essay.pdf", "", false},
+		{"external URL", "", "https://example.com/uploads/essay.pdf", "", false},
+		{"protocol-relative URL", "", "//example.com/uploads/essay.pdf", "", false},
+		{"internal address", "", "http://169.254.169.254/latest/meta-data", "", false},
+		{"file URL", "", "file:///etc/passwd", "", false},
+		{"UPLOAD_URL", "https://cdn.example.com/files/", "https://cdn.example.com/files/essay.pdf", "essay.pdf", true},
+		{"UPLOAD_URL with the plain path", "https://cdn.example.com/files", "/uploads/essay.pdf", "essay.pdf", true},
+		{"UPLOAD_URL prefix of another path", "https://cdn.example.com/files", "https://cdn.example.com/files-old/essay.pdf", "", false},
+		{"other host with UPLOAD_URL set", "https://cdn.example.com/files", "https://example.com/files/essay.pdf", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UPLOAD_URL", tt.uploadURL)
+			got, ok := uploadRelativePath(tt.fileURL)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("uploadRelativePath(%q) = %q, %v, want %q, %v", tt.fileURL, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadUploadedFile(t *testing.T) {
+	root := t.TempDir()
+	uploads := filepath.Join(root, "uploads")
+	writeTestFile(t, filepath.Join(uploads, "essay.txt"), "My essay")
+	writeTestFile(t, filepath.Join(uploads, "class-1", "notes.md"), "Notes")
+	writeTestFile(t, filepath.Join(root, "secret.txt"), "Secret")
+
+	large, err := os.Create(filepath.Join(uploads, "large.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := large.Truncate(maxExtractFileSize + 1); err != nil {
+		t.Fatal(err)
+	}
+	large.Close()
+
+	tests := []struct {
+		name     string
+		fileURL  string
+		wantName string
+		wantData string
+		wantErr  string
+	}{
+		{"file", "/uploads/essay.txt", "essay.txt", "My essay", ""},
+		{"file in a folder", "/uploads/class-1/notes.md", "notes.md", "Notes", ""},
+		{"missing file", "/uploads/missing.txt", "", "", "uploaded file not found"},
+		{"folder", "/uploads/class-1", "", "", "uploaded file not found"},
+		{"path leaving the upload directory", "/uploads/../secret.txt", "", "", "uploaded file not found"},
+		{"encoded path leaving the upload directory", "/uploads/%2e%2e/secret.txt", "", "", "uploaded file not found"},
+		{"external URL", "https://example.com/uploads/essay.txt", "", "", "external files are not read"},
+		{"file above 20 MB", "/uploads/large.bin", "", "", "larger than 20 MB"},
+	}
+
+	t.Setenv("UPLOAD_DIR", uploads)
+	t.Setenv("UPLOAD_URL", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, data, err := ReadUploadedFile(tt.fileURL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != tt.wantName || string(data) != tt.wantData {
+				t.Errorf("ReadUploadedFile() = %q, %q, want %q, %q", name, data, tt.wantName, tt.wantData)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}